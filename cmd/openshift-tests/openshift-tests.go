@@ -14,7 +14,9 @@ import (
 	collectdiskcertificates "github.com/openshift/origin/pkg/cmd/openshift-tests/collect-disk-certificates"
 	"github.com/openshift/origin/pkg/cmd/openshift-tests/dev"
 	"github.com/openshift/origin/pkg/cmd/openshift-tests/disruption"
+	"github.com/openshift/origin/pkg/cmd/openshift-tests/extensions"
 	"github.com/openshift/origin/pkg/cmd/openshift-tests/images"
+	merge_results "github.com/openshift/origin/pkg/cmd/openshift-tests/merge-results"
 	"github.com/openshift/origin/pkg/cmd/openshift-tests/monitor"
 	run_monitor "github.com/openshift/origin/pkg/cmd/openshift-tests/monitor/run"
 	"github.com/openshift/origin/pkg/cmd/openshift-tests/monitor/timeline"
@@ -86,8 +88,10 @@ func main() {
 		dev.NewDevCommand(),
 		run_monitor.NewRunMonitorCommand(ioStreams),
 		monitor.NewMonitorCommand(ioStreams),
+		extensions.NewExtensionsCommand(ioStreams),
 		disruption.NewDisruptionCommand(ioStreams),
 		risk_analysis.NewTestFailureRiskAnalysisCommand(),
+		merge_results.NewMergeResultsCommand(ioStreams),
 		run_resource_watch.NewRunResourceWatchCommand(),
 		timeline.NewTimelineCommand(ioStreams),
 		run_disruption.NewRunInClusterDisruptionMonitorCommand(ioStreams),
@@ -77,6 +77,8 @@ func (f *TestSuiteSelectionFlags) SelectSuite(
 	if len(f.TestFile) > 0 && len(args) == 0 {
 		suite = &testginkgo.TestSuite{
 			Name: "files",
+			// the tests are selected by the required match func for the file
+			Matches: func(name string) bool { return true },
 		}
 	}
 	if suite == nil && len(args) == 0 {
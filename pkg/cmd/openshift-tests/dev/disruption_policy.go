@@ -0,0 +1,167 @@
+package dev
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/allowedbackenddisruption"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionpolicy"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+type disruptionPolicyOpts struct {
+	alertInvariantOpts
+	policyFile  string
+	runDuration time.Duration
+}
+
+func newEvaluateDisruptionPolicyCommand() *cobra.Command {
+	opts := disruptionPolicyOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "evaluate-disruption-policy",
+		Short: "Evaluate the disruption in an intervals file on disk against a disruption policy",
+		Long: templates.LongDesc(`
+Evaluate the disruption of every backend in an e2e intervals json file against the budgets
+of a disruption policy file, the way the disruption tests do when --disruption-policy names it.
+Requires the caller to specify the job variants as we do not query them live from
+a running cluster.  The length of the run is taken from the intervals unless --run-duration
+is set.
+`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy, err := disruptionpolicy.ReadPolicy(opts.policyFile)
+			if err != nil {
+				return err
+			}
+
+			logrus.WithField("intervalsFile", opts.intervalsFile).Info("loading e2e intervals")
+			intervals, err := readIntervalsFromFile(opts.intervalsFile)
+			if err != nil {
+				return err
+			}
+			logrus.Infof("loaded %d intervals", len(intervals))
+
+			jobType := platformidentification.JobType{
+				Release:      opts.release,
+				FromRelease:  opts.fromRelease,
+				Platform:     opts.platform,
+				Architecture: opts.architecture,
+				Network:      opts.network,
+				Topology:     opts.topology,
+			}
+			runDuration := opts.runDuration
+			if runDuration == 0 {
+				runDuration = intervalsDuration(intervals)
+			}
+
+			failed := []string{}
+			disruptionIntervals := intervals.Filter(monitorapi.IsDisruptionEvent)
+			for _, backendName := range backendNames(disruptionIntervals) {
+				historicalAllowed, _, err := allowedbackenddisruption.GetAllowedDisruption(backendName, jobType)
+				if err != nil {
+					return err
+				}
+				budget := policy.BudgetFor(backendName, jobType)
+				switch {
+				case budget == nil:
+					logrus.Infof("SKIP: %s has no budget", backendName)
+					continue
+				case !policy.Applies(historicalAllowed != nil):
+					logrus.Infof("SKIP: %s uses the historical P99 of %s", backendName, *historicalAllowed)
+					continue
+				}
+
+				disrupted := disruptionIntervals.Filter(monitorapi.And(
+					monitorapi.IsForDisruptionBackend(backendName),
+					monitorapi.IsErrorEvent,
+				))
+				if violations := budget.Evaluate(disrupted, runDuration); len(violations) > 0 {
+					failed = append(failed, backendName)
+					logrus.Errorf("FAIL: %s exceeds %s", backendName, policy.Provenance(budget))
+					logrus.Error(strings.Join(violations, "\n"))
+				} else {
+					logrus.Infof("PASS: %s is within %s", backendName, policy.Provenance(budget))
+				}
+			}
+
+			if len(failed) > 0 {
+				return fmt.Errorf("%d backends exceed their disruption budget: %s", len(failed), strings.Join(failed, ", "))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.policyFile,
+		"policy", "disruption-policy.yaml",
+		"Path to a disruption policy file.")
+	cmd.Flags().DurationVar(&opts.runDuration,
+		"run-duration", 0,
+		"Length of the run for budgets relative to it.  Defaults to the time covered by the intervals.")
+	cmd.Flags().StringVar(&opts.intervalsFile,
+		"intervals-file", "e2e-events.json",
+		"Path to an intervals file (i.e. e2e-events_20230214-203340.json). Can be obtained from a CI run in openshift-tests junit artifacts.")
+	cmd.Flags().StringVar(
+		&opts.platform,
+		"platform", "gcp",
+		"Platform for simulated cluster under test when intervals were gathered (aws, azure, gcp, metal, vsphere, etc)")
+	cmd.Flags().StringVar(
+		&opts.network,
+		"network", "ovn",
+		"Network plugin for simulated cluster under test when intervals were gathered")
+	cmd.Flags().StringVar(
+		&opts.release,
+		"release", "",
+		"Release for simulated cluster under test when intervals were gathered")
+	cmd.Flags().StringVar(
+		&opts.fromRelease,
+		"from-release", "",
+		"FromRelease simulated cluster under test was upgraded from when intervals were gathered (use \"\" for non-upgrade jobs, use matching value to --release for micro upgrades)")
+	cmd.Flags().StringVar(
+		&opts.architecture,
+		"arch", "amd64",
+		"Architecture for simulated cluster under test when intervals were gathered")
+	cmd.Flags().StringVar(
+		&opts.topology,
+		"topology", "ha",
+		"Topology for simulated cluster under test when intervals were gathered (ha, single)")
+	return cmd
+}
+
+func backendNames(disruptionIntervals monitorapi.Intervals) []string {
+	names := map[string]bool{}
+	for _, interval := range disruptionIntervals {
+		if name := monitorapi.BackendDisruptionNameFromLocator(interval.Locator); len(name) > 0 {
+			names[name] = true
+		}
+	}
+	ret := []string{}
+	for name := range names {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func intervalsDuration(intervals monitorapi.Intervals) time.Duration {
+	var first, last time.Time
+	for _, interval := range intervals {
+		if first.IsZero() || interval.From.Before(first) {
+			first = interval.From
+		}
+		if interval.To.After(last) {
+			last = interval.To
+		}
+	}
+	if first.IsZero() || !last.After(first) {
+		return 0
+	}
+	return last.Sub(first)
+}
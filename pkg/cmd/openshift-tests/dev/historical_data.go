@@ -0,0 +1,89 @@
+package dev
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/historicaldata"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+type historicalDataOpts struct {
+	artifactsDir   string
+	disruptionFile string
+	alertsFile     string
+}
+
+func newComputeHistoricalDataCommand() *cobra.Command {
+	opts := historicalDataOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "compute-historical-data",
+		Short: "Compute disruption and alert percentiles from the artifacts of past job runs",
+		Long: templates.LongDesc(`
+Compute the historical disruption and alert data the disruption and alert tests compare
+against from a directory of past job artifacts, for CI installations that cannot query
+the data warehouse the embedded query_results.json files come from.
+
+Every directory under --artifacts-dir with a cluster-data_*.json is a job run, and its
+backend-disruption_*.json and alerts_*.json are read from the same directory.  Runs are
+grouped by job type, and P50, P75, P95, P99 and JobRuns are written in the format of the
+query_results.json files.
+`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logrus.WithField("artifactsDir", opts.artifactsDir).Info("reading job runs")
+			jobRuns, err := historicaldata.ReadJobRunDataFromDirs(opts.artifactsDir)
+			if err != nil {
+				return err
+			}
+			if len(jobRuns) == 0 {
+				return fmt.Errorf("no cluster-data*.json found under %q", opts.artifactsDir)
+			}
+			logrus.Infof("read %d job runs", len(jobRuns))
+
+			disruptionJSON, err := json.MarshalIndent(historicaldata.ComputeDisruptionData(jobRuns), "", "  ")
+			if err != nil {
+				return err
+			}
+			// make sure the tests can read what we write
+			disruptionMatcher, err := historicaldata.NewDisruptionMatcher(disruptionJSON)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(opts.disruptionFile, disruptionJSON, 0644); err != nil {
+				return err
+			}
+			logrus.Infof("wrote %d disruption entries to %s", len(disruptionMatcher.HistoricalData), opts.disruptionFile)
+
+			alertsJSON, err := json.MarshalIndent(historicaldata.ComputeAlertData(jobRuns), "", "  ")
+			if err != nil {
+				return err
+			}
+			alertMatcher, err := historicaldata.NewAlertMatcher(alertsJSON)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(opts.alertsFile, alertsJSON, 0644); err != nil {
+				return err
+			}
+			logrus.Infof("wrote %d alert entries to %s", len(alertMatcher.HistoricalData), opts.alertsFile)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.artifactsDir,
+		"artifacts-dir", "",
+		"Directory holding the artifacts of past job runs, searched recursively.")
+	cmd.Flags().StringVar(&opts.disruptionFile,
+		"disruption-output", "disruption_query_results.json",
+		"File to write disruption data to, in the format of allowedbackenddisruption/query_results.json.")
+	cmd.Flags().StringVar(&opts.alertsFile,
+		"alerts-output", "alert_query_results.json",
+		"File to write alert data to, in the format of allowedalerts/query_results.json.")
+	return cmd
+}
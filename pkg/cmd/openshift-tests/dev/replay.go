@@ -0,0 +1,415 @@
+package dev
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/defaultmonitortests"
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionpolicy"
+	"github.com/openshift/origin/pkg/test"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// resourceFileRegex matches the files written by the tracked resources serializer, for instance
+// resource-pods_20230214-203340.zip
+var resourceFileRegex = regexp.MustCompile(`^resource-(.+?)(_\d{8}-\d{6})?\.zip$`)
+
+type replayOpts struct {
+	artifactDir         string
+	intervalsFile       string
+	junitFile           string
+	recorderDir         string
+	outputDir           string
+	clusterStability    string
+	disruptionPolicy    string
+	disruptionBackends  string
+	exactMonitorTests   []string
+	disableMonitorTests []string
+}
+
+func newReplayCommand() *cobra.Command {
+	o := replayOpts{
+		clusterStability: string(monitortestframework.Stable),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Re-run monitor tests against the artifacts of a previous run",
+		Long: templates.LongDesc(`
+Re-run the monitor tests against the intervals, resources, and cluster data saved by a
+previous run without a live cluster.
+
+Monitor tests first compute their intervals again from the recorded intervals and are then
+evaluated. Tests that require a live cluster are skipped where possible, but some monitor
+tests do not support offline evaluation and may fail; use --disable-monitor to exclude them.
+Any pass/fail verdict that differs from the original e2e-monitor-tests junit is reported.
+`),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.Run(cmd.Context())
+		},
+	}
+	monitorNames := defaultmonitortests.ListAllMonitorTests()
+	cmd.Flags().StringVar(&o.artifactDir, "artifact-dir", o.artifactDir,
+		"The artifact directory of the run to replay.  Intervals, resource-*.zip, and cluster-data*.json files are read from here.")
+	cmd.Flags().StringVar(&o.intervalsFile, "intervals-file", o.intervalsFile,
+		"Path to an intervals file (i.e. e2e-events_20230214-203340.json).  Defaults to the e2e-events file in --artifact-dir.")
+	cmd.Flags().StringVar(&o.junitFile, "junit-file", o.junitFile,
+		"Path to the e2e-monitor-tests junit of the run to compare verdicts with.  Defaults to the e2e-monitor-tests file in --artifact-dir.")
+	cmd.Flags().StringVar(&o.recorderDir, "recorder-dir", o.recorderDir,
+		"Load intervals and resources from a directory written by --recorder-dir instead of the intervals file.")
+	cmd.Flags().StringVar(&o.outputDir, "output-dir", o.outputDir,
+		"The directory to write replayed results to.  Required.")
+	cmd.Flags().StringVar(&o.clusterStability, "cluster-stability", o.clusterStability,
+		"The cluster stability of the original run (Stable, Disruptive).")
+	cmd.Flags().StringVar(&o.disruptionPolicy, "disruption-policy", o.disruptionPolicy,
+		"The disruption policy file to evaluate disruption against, like --disruption-policy of the original run.")
+	cmd.Flags().StringVar(&o.disruptionBackends, "disruption-backends", o.disruptionBackends,
+		"The file of backend specs sampled from inside the cluster, like --disruption-backends of the original run.")
+	cmd.Flags().StringSliceVar(&o.exactMonitorTests, "monitor", o.exactMonitorTests,
+		fmt.Sprintf("list of exactly which monitors to enable. All others will be disabled.  Current monitors are: [%s]", strings.Join(monitorNames, ", ")))
+	cmd.Flags().StringSliceVar(&o.disableMonitorTests, "disable-monitor", o.disableMonitorTests, "list of monitors to disable.  Defaults for others will be honored.")
+	return cmd
+}
+
+func (o *replayOpts) Run(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(o.outputDir) == 0 {
+		return fmt.Errorf("--output-dir is required")
+	}
+	if len(o.artifactDir) == 0 && len(o.intervalsFile) == 0 && len(o.recorderDir) == 0 {
+		return fmt.Errorf("one of --artifact-dir, --intervals-file, or --recorder-dir is required")
+	}
+	originalJunitFile, err := o.originalJunitFile()
+	if err != nil {
+		return err
+	}
+	var policy *disruptionpolicy.Policy
+	if len(o.disruptionPolicy) > 0 {
+		if policy, err = disruptionpolicy.ReadPolicy(o.disruptionPolicy); err != nil {
+			return fmt.Errorf("invalid --disruption-policy: %w", err)
+		}
+	}
+	if err := os.MkdirAll(o.outputDir, 0755); err != nil {
+		return err
+	}
+
+	intervals, resources, err := o.loadRecordedState()
+	if err != nil {
+		return err
+	}
+	logrus.Infof("loaded %d intervals and %d resource types", len(intervals), len(resources))
+	beginning, end := intervalBounds(intervals)
+
+	// saved intervals files already hold what the constructors computed during the original run, drop those
+	// so that constructing them again below does not record every one of them twice.  A recorder directory only
+	// holds what was recorded.
+	if len(o.recorderDir) == 0 {
+		probeMonitorTests, err := o.newMonitorTests(policy)
+		if err != nil {
+			return err
+		}
+		intervals = recordedIntervals(ctx, probeMonitorTests, o.offlineEvaluationInfo(), intervals, resources, beginning, end)
+		logrus.Infof("replaying %d recorded intervals", len(intervals))
+	}
+
+	monitorTests, err := o.newMonitorTests(policy)
+	if err != nil {
+		return err
+	}
+
+	junits := []*junitapi.JUnitTestCase{}
+	offlineJunits, err := monitorTests.PrepareForOfflineEvaluation(ctx, o.offlineEvaluationInfo())
+	if err != nil {
+		logrus.WithError(err).Warn("error preparing for offline evaluation, continuing, junit will reflect this")
+	}
+	junits = append(junits, offlineJunits...)
+
+	computedIntervals, computedJunits, err := monitorTests.ConstructComputedIntervals(ctx, intervals, resources, beginning, end)
+	if err != nil {
+		logrus.WithError(err).Warn("error computing intervals, continuing, junit will reflect this")
+	}
+	junits = append(junits, computedJunits...)
+
+	finalIntervals := append(intervals, computedIntervals...)
+	sort.Sort(finalIntervals)
+	evaluationJunits, err := monitorTests.EvaluateTestsFromConstructedIntervals(ctx, finalIntervals)
+	if err != nil {
+		logrus.WithError(err).Warn("error evaluating tests, continuing, junit will reflect this")
+	}
+	junits = append(junits, evaluationJunits...)
+
+	timeSuffix := fmt.Sprintf("_%s", time.Now().UTC().Format("20060102-150405"))
+	storageJunits, err := monitorTests.WriteContentToStorage(ctx, o.outputDir, timeSuffix, finalIntervals, resources)
+	if err != nil {
+		logrus.WithError(err).Warn("error writing to storage, continuing, junit will reflect this")
+	}
+	junits = append(junits, storageJunits...)
+
+	if err := writeReplayJunit(o.outputDir, timeSuffix, junits); err != nil {
+		return err
+	}
+
+	if len(originalJunitFile) == 0 {
+		logrus.Info("no original e2e-monitor-tests junit found, not comparing verdicts")
+		return nil
+	}
+	reportChangedVerdicts(originalJunitFile, junits)
+	return nil
+}
+
+func (o *replayOpts) newMonitorTests(policy *disruptionpolicy.Policy) (monitortestframework.MonitorTestRegistry, error) {
+	return defaultmonitortests.NewMonitorTestsFor(monitortestframework.MonitorTestInitializationInfo{
+		ClusterStabilityDuringTest: monitortestframework.ClusterStabilityDuringTest(o.clusterStability),
+		ExactMonitorTests:          o.exactMonitorTests,
+		DisableMonitorTests:        o.disableMonitorTests,
+		DisruptionPolicy:           policy,
+		DisruptionBackends:         o.disruptionBackends,
+	})
+}
+
+func (o *replayOpts) offlineEvaluationInfo() monitortestframework.OfflineEvaluationInfo {
+	return monitortestframework.OfflineEvaluationInfo{ArtifactDir: o.artifactDir}
+}
+
+// loadRecordedState returns the intervals and resources of the run to replay.
+func (o *replayOpts) loadRecordedState() (monitorapi.Intervals, monitorapi.ResourcesMap, error) {
+	if len(o.recorderDir) > 0 {
+		logrus.WithField("recorderDir", o.recorderDir).Info("loading recorder directory")
+		recorder, err := monitor.LoadDurableRecorderDir(o.recorderDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		return recorder.Intervals(time.Time{}, time.Time{}), recorder.CurrentResourceState(), nil
+	}
+
+	intervalsFile := o.intervalsFile
+	if len(intervalsFile) == 0 {
+		matches, err := filepath.Glob(filepath.Join(o.artifactDir, "e2e-events*.json"))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(matches) == 0 {
+			return nil, nil, fmt.Errorf("no e2e-events*.json file found in %q", o.artifactDir)
+		}
+		if len(matches) > 1 {
+			return nil, nil, severalArtifactsError(o.artifactDir, matches, "--intervals-file")
+		}
+		intervalsFile = matches[0]
+	}
+	logrus.WithField("intervalsFile", intervalsFile).Info("loading e2e intervals")
+	intervals, err := readIntervalsFromFile(intervalsFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resources := monitorapi.ResourcesMap{}
+	if len(o.artifactDir) == 0 {
+		return intervals, resources, nil
+	}
+	entries, err := os.ReadDir(o.artifactDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, entry := range entries {
+		matches := resourceFileRegex.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+		instances, err := monitorserialization.InstanceMapFromFile(filepath.Join(o.artifactDir, entry.Name()))
+		if err != nil {
+			return nil, nil, err
+		}
+		resources[matches[1]] = instances
+	}
+	return intervals, resources, nil
+}
+
+// severalArtifactsError reports an artifact dir that holds the files of several runs, like the upgrade and the
+// conformance phase of a job, which one to use has to be passed with flag.
+func severalArtifactsError(artifactDir string, matches []string, flag string) error {
+	sort.Strings(matches)
+	return fmt.Errorf("%q holds the files of more than one run, pass the one to use with %s: %s", artifactDir, flag, strings.Join(matches, ", "))
+}
+
+// originalJunitFile returns the e2e-monitor-tests junit written by the original run, if there is one.
+func (o *replayOpts) originalJunitFile() (string, error) {
+	if len(o.junitFile) > 0 || len(o.artifactDir) == 0 {
+		return o.junitFile, nil
+	}
+	matches, err := filepath.Glob(filepath.Join(o.artifactDir, "e2e-monitor-tests*.xml"))
+	if err != nil {
+		return "", err
+	}
+	if len(matches) > 1 {
+		return "", severalArtifactsError(o.artifactDir, matches, "--junit-file")
+	}
+	if len(matches) == 0 {
+		return "", nil
+	}
+	return matches[0], nil
+}
+
+// reportChangedVerdicts logs every test whose verdict differs from the junit written by the original run.
+func reportChangedVerdicts(originalJunitFile string, junits []*junitapi.JUnitTestCase) {
+	content, err := os.ReadFile(originalJunitFile)
+	if err != nil {
+		logrus.WithError(err).Warn("unable to read original junit")
+		return
+	}
+	originalSuite := &junitapi.JUnitTestSuite{}
+	if err := xml.Unmarshal(content, originalSuite); err != nil {
+		logrus.WithError(err).Warn("unable to parse original junit")
+		return
+	}
+
+	originalFailures, originalPasses := junitVerdicts(originalSuite.TestCases)
+	replayedFailures, replayedPasses := junitVerdicts(junits)
+	newlyFailing := replayedFailures.Difference(replayedPasses).Intersection(originalPasses.Difference(originalFailures))
+	newlyPassing := replayedPasses.Difference(replayedFailures).Intersection(originalFailures.Difference(originalPasses))
+	for _, name := range newlyFailing.List() {
+		logrus.Warnf("NOW FAILING: %s", name)
+	}
+	for _, name := range newlyPassing.List() {
+		logrus.Infof("NOW PASSING: %s", name)
+	}
+	logrus.Infof("%d tests changed from pass to fail, %d tests changed from fail to pass", len(newlyFailing), len(newlyPassing))
+}
+
+func junitVerdicts(junits []*junitapi.JUnitTestCase) (failures, passes sets.String) {
+	failures, passes = sets.NewString(), sets.NewString()
+	for _, junit := range junits {
+		switch {
+		case junit.FailureOutput != nil:
+			failures.Insert(junit.Name)
+		case junit.SkipMessage != nil:
+		default:
+			passes.Insert(junit.Name)
+		}
+	}
+	return failures, passes
+}
+
+// recordedIntervals returns the saved intervals that were recorded during the run, leaving out the ones
+// ConstructComputedIntervals built from them.  Saved intervals marked with AnnotationConstructed were constructed.
+// Constructors that do not set it, and artifacts written before they did, leave nothing to tell them by, so the monitor
+// tests construct once from everything that was saved and the saved intervals of a kind they construct are left out,
+// unless the saved intervals of that kind carry the annotation.  How many were left out that way is logged by kind.
+// monitorTests are only used for this and must not be reused.
+func recordedIntervals(ctx context.Context, monitorTests monitortestframework.MonitorTestRegistry, info monitortestframework.OfflineEvaluationInfo, saved monitorapi.Intervals, resources monitorapi.ResourcesMap, beginning, end time.Time) monitorapi.Intervals {
+	if _, err := monitorTests.PrepareForOfflineEvaluation(ctx, info); err != nil {
+		logrus.WithError(err).Debug("error preparing to tell constructed intervals apart, continuing")
+	}
+	constructed, _, err := monitorTests.ConstructComputedIntervals(ctx, saved, resources, beginning, end)
+	if err != nil {
+		logrus.WithError(err).Debug("error constructing intervals to tell them apart, continuing")
+	}
+	constructedKinds := sets.New[intervalKind]()
+	for _, interval := range constructed {
+		constructedKinds.Insert(kindOf(interval))
+	}
+	annotatedKinds := sets.New[intervalKind]()
+	for _, interval := range saved {
+		if isConstructed(interval) {
+			annotatedKinds.Insert(kindOf(interval))
+		}
+	}
+
+	ret := monitorapi.Intervals{}
+	droppedByKind := map[intervalKind]int{}
+	for _, interval := range saved {
+		kind := kindOf(interval)
+		switch {
+		case isConstructed(interval):
+			continue
+		case constructedKinds.Has(kind) && !annotatedKinds.Has(kind):
+			droppedByKind[kind]++
+			continue
+		}
+		ret = append(ret, interval)
+	}
+
+	kinds := make([]intervalKind, 0, len(droppedByKind))
+	for kind := range droppedByKind {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].String() < kinds[j].String() })
+	for _, kind := range kinds {
+		logrus.WithField("kind", kind.String()).Infof("left out %d saved intervals without %q of a kind that is constructed", droppedByKind[kind], monitorapi.AnnotationConstructed)
+	}
+	return ret
+}
+
+func isConstructed(interval monitorapi.Interval) bool {
+	return len(interval.Message.Annotations[monitorapi.AnnotationConstructed]) > 0
+}
+
+// intervalKind tells intervals apart by what they are about rather than when, the intervals a constructor builds
+// differ in time and message from run to run but not in kind.  The e2e test spans, for instance, have the source of
+// the recorded e2e test events but no reason.
+type intervalKind struct {
+	source      monitorapi.IntervalSource
+	locatorType monitorapi.LocatorType
+	reason      monitorapi.IntervalReason
+}
+
+func kindOf(interval monitorapi.Interval) intervalKind {
+	return intervalKind{source: interval.Source, locatorType: interval.Locator.Type, reason: interval.Message.Reason}
+}
+
+func (k intervalKind) String() string {
+	return fmt.Sprintf("source/%s locator/%s reason/%s", k.source, k.locatorType, k.reason)
+}
+
+func intervalBounds(intervals monitorapi.Intervals) (time.Time, time.Time) {
+	var beginning, end time.Time
+	for _, interval := range intervals {
+		if !interval.From.IsZero() && (beginning.IsZero() || interval.From.Before(beginning)) {
+			beginning = interval.From
+		}
+		if interval.To.After(end) {
+			end = interval.To
+		}
+	}
+	return beginning, end
+}
+
+func writeReplayJunit(outputDir, timeSuffix string, junits []*junitapi.JUnitTestCase) error {
+	junitSuite := junitapi.JUnitTestSuite{
+		Name: "openshift-tests-monitor-replay",
+	}
+	for _, junit := range junits {
+		junitSuite.NumTests++
+		if junit.FailureOutput != nil {
+			junitSuite.NumFailed++
+		} else if junit.SkipMessage != nil {
+			junitSuite.NumSkipped++
+		}
+		junitSuite.TestCases = append(junitSuite.TestCases, junit)
+	}
+
+	out, err := xml.MarshalIndent(junitSuite, "", "    ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(outputDir, fmt.Sprintf("e2e-monitor-tests%s.xml", timeSuffix))
+	logrus.Infof("writing %d junits (%d failed, %d skipped) to %s", junitSuite.NumTests, junitSuite.NumFailed, junitSuite.NumSkipped, path)
+	return os.WriteFile(path, test.StripANSI(out), 0640)
+}
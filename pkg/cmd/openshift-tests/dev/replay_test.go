@@ -0,0 +1,238 @@
+package dev
+
+import (
+	"context"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+func TestReplayDoesNotDuplicateConstructedIntervals(t *testing.T) {
+	beginning := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minute int) time.Time { return beginning.Add(time.Duration(minute) * time.Minute) }
+	node := monitorapi.NewLocator().NodeFromName("master-0")
+	e2eTest := monitorapi.NewLocator().E2ETest("[sig-node] a test")
+	recorded := monitorapi.Intervals{
+		monitorapi.NewInterval(monitorapi.SourceNodeMonitor, monitorapi.Warning).Locator(node).
+			Message(monitorapi.NewMessage().Reason("NotReady").HumanMessage("node is not ready")).
+			Build(at(1), at(1)),
+		monitorapi.NewInterval(monitorapi.SourceNodeMonitor, monitorapi.Info).Locator(node).
+			Message(monitorapi.NewMessage().Reason("Ready").HumanMessage("node is ready")).
+			Build(at(3), at(3)),
+		monitorapi.NewInterval(monitorapi.SourceE2ETest, monitorapi.Info).Locator(e2eTest).
+			Message(monitorapi.NewMessage().Reason(monitorapi.E2ETestStarted).HumanMessage("started")).
+			Build(at(2), at(2)),
+		monitorapi.NewInterval(monitorapi.SourceE2ETest, monitorapi.Info).Locator(e2eTest).
+			Message(monitorapi.NewMessage().Reason(monitorapi.E2ETestFinished).HumanMessage("finished").
+				WithAnnotation(monitorapi.AnnotationStatus, "Passed")).
+			Build(at(4), at(4)),
+	}
+	recordedFile := filepath.Join(t.TempDir(), "e2e-events_20240101-000000.json")
+	if err := monitorserialization.EventsToFile(recordedFile, recorded); err != nil {
+		t.Fatal(err)
+	}
+
+	replay := func(intervalsFile string) (string, monitorapi.Intervals) {
+		t.Helper()
+		o := replayOpts{
+			intervalsFile:     intervalsFile,
+			outputDir:         t.TempDir(),
+			clusterStability:  "Stable",
+			exactMonitorTests: []string{"node-state-analyzer", "e2e-test-analyzer", "interval-serializer"},
+		}
+		if err := o.Run(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		matches, err := filepath.Glob(filepath.Join(o.outputDir, "e2e-events*.json"))
+		if err != nil || len(matches) != 1 {
+			t.Fatalf("expected one intervals file in %s, found %v: %v", o.outputDir, matches, err)
+		}
+		intervals, err := readIntervalsFromFile(matches[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return matches[0], intervals
+	}
+
+	// the first replay stands in for the original run, which saves the constructed intervals alongside the
+	// recorded ones.
+	savedFile, saved := replay(recordedFile)
+	if len(saved) != len(recorded)+2 {
+		t.Fatalf("expected a node state and an e2e test interval to be constructed, got %d intervals: %v", len(saved), saved)
+	}
+	_, replayed := replay(savedFile)
+	if len(replayed) != len(saved) {
+		t.Errorf("expected the %d saved intervals after the replay, got %d: %v", len(saved), len(replayed), replayed)
+	}
+	seen := map[string]bool{}
+	for _, interval := range replayed {
+		key := interval.String()
+		if seen[key] {
+			t.Errorf("duplicate interval after replay: %s", key)
+		}
+		seen[key] = true
+	}
+}
+
+func TestRecordedIntervalsKeepsUnannotatedIntervalsOfAnnotatedKinds(t *testing.T) {
+	beginning := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minute int) time.Time { return beginning.Add(time.Duration(minute) * time.Minute) }
+	node := monitorapi.NewLocator().NodeFromName("master-0")
+	recorded := monitorapi.Intervals{
+		monitorapi.NewInterval(monitorapi.SourceNodeMonitor, monitorapi.Warning).Locator(node).
+			Message(monitorapi.NewMessage().Reason("NotReady").HumanMessage("node is not ready")).
+			Build(at(1), at(1)),
+		monitorapi.NewInterval(monitorapi.SourceNodeMonitor, monitorapi.Info).Locator(node).
+			Message(monitorapi.NewMessage().Reason("Ready").HumanMessage("node is ready")).
+			Build(at(3), at(3)),
+	}
+	o := replayOpts{clusterStability: "Stable", exactMonitorTests: []string{"node-state-analyzer"}}
+	monitorTests, err := o.newMonitorTests(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	constructed, _, err := monitorTests.ConstructComputedIntervals(context.Background(), recorded, nil, at(0), at(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(constructed) != 1 || !isConstructed(constructed[0]) {
+		t.Fatalf("expected one annotated node state interval, got %v", constructed)
+	}
+	// an interval of the constructed kind that the original run recorded, it has no annotation
+	sameKind := constructed[0]
+	sameKind.Message = monitorapi.NewMessage().Reason(sameKind.Message.Reason).HumanMessage("recorded").Build()
+	sameKind.From, sameKind.To = at(4), at(4)
+	saved := append(append(monitorapi.Intervals{}, recorded...), constructed[0], sameKind)
+
+	probeMonitorTests, err := o.newMonitorTests(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := recordedIntervals(context.Background(), probeMonitorTests, o.offlineEvaluationInfo(), saved, nil, at(0), at(5))
+	want := append(append(monitorapi.Intervals{}, recorded...), sameKind)
+	if len(got) != len(want) {
+		t.Fatalf("expected the recorded intervals %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i].String() != want[i].String() {
+			t.Errorf("expected %s, got %s", want[i], got[i])
+		}
+	}
+
+	// without any annotated interval of the kind, the artifact predates the annotation and the kind is left out
+	if probeMonitorTests, err = o.newMonitorTests(nil); err != nil {
+		t.Fatal(err)
+	}
+	got = recordedIntervals(context.Background(), probeMonitorTests, o.offlineEvaluationInfo(), append(append(monitorapi.Intervals{}, recorded...), sameKind), nil, at(0), at(5))
+	if len(got) != len(recorded) {
+		t.Errorf("expected only the node monitor intervals, got %v", got)
+	}
+}
+
+func TestReplayEvaluatesDisruptionOffline(t *testing.T) {
+	artifactDir := t.TempDir()
+	beginning := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	kubeAPI := monitorapi.NewLocator().LocateDisruptionCheck("kube-api-new-connections", "openshift-tests", monitorapi.NewConnectionType)
+	recorded := monitorapi.Intervals{
+		monitorapi.NewInterval(monitorapi.SourceDisruption, monitorapi.Error).Locator(kubeAPI).
+			Message(monitorapi.NewMessage().Reason(monitorapi.DisruptionBeganEventReason).HumanMessage("stopped responding")).
+			Build(beginning.Add(10*time.Minute), beginning.Add(40*time.Minute)),
+		monitorapi.NewInterval(monitorapi.SourceDisruption, monitorapi.Info).Locator(kubeAPI).
+			Message(monitorapi.NewMessage().Reason(monitorapi.DisruptionEndedEventReason).HumanMessage("started responding")).
+			Build(beginning.Add(40*time.Minute), beginning.Add(60*time.Minute)),
+	}
+	if err := monitorserialization.EventsToFile(filepath.Join(artifactDir, "e2e-events_20240101-000000.json"), recorded); err != nil {
+		t.Fatal(err)
+	}
+	clusterData := `{"Release":"4.18","FromRelease":"","Platform":"aws","Architecture":"amd64","Network":"ovn","Topology":"ha"}`
+	if err := os.WriteFile(filepath.Join(artifactDir, "cluster-data_20240101-000000.json"), []byte(clusterData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	o := replayOpts{
+		artifactDir:       artifactDir,
+		outputDir:         t.TempDir(),
+		clusterStability:  "Stable",
+		exactMonitorTests: []string{"apiserver-availability"},
+	}
+	if err := o.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	matches, err := filepath.Glob(filepath.Join(o.outputDir, "e2e-monitor-tests*.xml"))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("expected one junit in %s, found %v: %v", o.outputDir, matches, err)
+	}
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	suite := &junitapi.JUnitTestSuite{}
+	if err := xml.Unmarshal(data, suite); err != nil {
+		t.Fatal(err)
+	}
+	junits := map[string]*junitapi.JUnitTestCase{}
+	for _, junit := range suite.TestCases {
+		if strings.Contains(junit.Name, "disruption/") {
+			junits[junit.Name] = junit
+		}
+	}
+
+	// the sampled backend is judged against the job type of the saved cluster data, the backends that were not
+	// sampled are skipped
+	newConnections := junits["[sig-api-machinery] disruption/kube-api connection/new should be available throughout the test"]
+	if newConnections == nil || newConnections.FailureOutput == nil {
+		t.Errorf("expected thirty minutes of kube-api disruption to fail, got %#v", newConnections)
+	}
+	reusedConnections := junits["[sig-api-machinery] disruption/kube-api connection/reused should be available throughout the test"]
+	if reusedConnections == nil || reusedConnections.SkipMessage == nil {
+		t.Errorf("expected the reused connections that were not sampled to be skipped, got %#v", reusedConnections)
+	}
+	if len(junits) != 12 {
+		t.Errorf("expected the new and reused connection tests of the six api server backends, got %d", len(junits))
+	}
+	for name, junit := range junits {
+		if junit.FailureOutput != nil && junit != newConnections {
+			t.Errorf("unexpected failure of %s: %s", name, junit.FailureOutput.Output)
+		}
+	}
+}
+
+func TestReplayRejectsSeveralRuns(t *testing.T) {
+	artifactDir := t.TempDir()
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	intervals := monitorapi.Intervals{
+		monitorapi.NewInterval(monitorapi.SourceNodeMonitor, monitorapi.Info).Locator(monitorapi.NewLocator().NodeFromName("master-0")).
+			Message(monitorapi.NewMessage().Reason("Ready").HumanMessage("node is ready")).
+			Build(at, at),
+	}
+	for _, name := range []string{"e2e-events_20240101-000000.json", "e2e-events_20240101-020000.json"} {
+		if err := monitorserialization.EventsToFile(filepath.Join(artifactDir, name), intervals); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o := replayOpts{artifactDir: artifactDir, outputDir: t.TempDir(), clusterStability: "Stable"}
+	err := o.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "--intervals-file") || !strings.Contains(err.Error(), "e2e-events_20240101-020000.json") {
+		t.Fatalf("expected an error listing the intervals files and pointing at --intervals-file, got %v", err)
+	}
+
+	// the junits of several runs are as ambiguous as their intervals
+	for _, name := range []string{"e2e-monitor-tests_20240101-000000.xml", "e2e-monitor-tests_20240101-020000.xml"} {
+		if err := os.WriteFile(filepath.Join(artifactDir, name), []byte("<testsuite/>"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	o.intervalsFile = filepath.Join(artifactDir, "e2e-events_20240101-020000.json")
+	err = o.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "--junit-file") {
+		t.Fatalf("expected an error pointing at --junit-file, got %v", err)
+	}
+}
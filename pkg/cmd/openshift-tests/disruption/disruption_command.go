@@ -2,6 +2,7 @@ package disruption
 
 import (
 	poll_service "github.com/openshift/origin/pkg/cmd/openshift-tests/disruption/poll-service"
+	sample_backends "github.com/openshift/origin/pkg/cmd/openshift-tests/disruption/sample-backends"
 	watch_endpointslice "github.com/openshift/origin/pkg/cmd/openshift-tests/disruption/watch-endpointslice"
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -16,6 +17,7 @@ func NewDisruptionCommand(streams genericclioptions.IOStreams) *cobra.Command {
 	cmd.AddCommand(
 		watch_endpointslice.NewWatchEndpointSlice(streams),
 		poll_service.NewPollService(streams),
+		sample_backends.NewSampleBackends(streams),
 	)
 	return cmd
 }
@@ -0,0 +1,191 @@
+package sample_backends
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/monitortestlibrary/inclusterdisruption"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+// SampleBackendsController starts the samplers of the backends and stops them once the stop configmap exists.  Once
+// stopped they are not started again.
+type SampleBackendsController struct {
+	backends          []inclusterdisruption.BackendSpec
+	nodeName          string
+	namespaceName     string
+	stopConfigMapName string
+	recorder          monitorapi.RecorderWriter
+	outFile           io.Writer
+
+	configmapLister corelisters.ConfigMapLister
+
+	informersToSync []cache.InformerSynced
+
+	samplersLock sync.Mutex
+	samplers     []disruptionlibrary.Sampler
+	stopped      bool
+
+	syncHandler func(ctx context.Context, key string) error
+	queue       workqueue.RateLimitingInterface
+}
+
+func NewSampleBackendsController(
+	backends []inclusterdisruption.BackendSpec,
+	nodeName string,
+	namespaceName string,
+	recorder monitorapi.RecorderWriter,
+	outFile io.Writer,
+	stopConfigMapName string,
+	configmapInformer coreinformers.ConfigMapInformer,
+) *SampleBackendsController {
+
+	c := &SampleBackendsController{
+		backends:          backends,
+		nodeName:          nodeName,
+		namespaceName:     namespaceName,
+		recorder:          recorder,
+		stopConfigMapName: stopConfigMapName,
+		outFile:           outFile,
+
+		configmapLister: configmapInformer.Lister(),
+		informersToSync: []cache.InformerSynced{
+			configmapInformer.Informer().HasSynced,
+		},
+
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "BackendSampler"),
+	}
+
+	c.syncHandler = c.syncBackendSamplers
+
+	configmapInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.queue.Add("check")
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.queue.Add("check")
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.queue.Add("check")
+		},
+	})
+
+	return c
+}
+
+func (c *SampleBackendsController) syncBackendSamplers(ctx context.Context, key string) error {
+	_, err := c.configmapLister.ConfigMaps(c.namespaceName).Get(c.stopConfigMapName)
+	switch {
+	case err == nil:
+		c.removeAllSamplers()
+		return nil
+	case apierrors.IsNotFound(err):
+		// did not find the stopConfigMap
+	case err != nil:
+		return err
+	}
+
+	c.samplersLock.Lock()
+	defer c.samplersLock.Unlock()
+
+	if c.stopped || c.samplers != nil {
+		return nil
+	}
+
+	samplers := []disruptionlibrary.Sampler{}
+	for _, backend := range c.backends {
+		backendSamplers, err := inclusterdisruption.NewSamplers(backend, c.nodeName)
+		if err != nil {
+			return err
+		}
+		samplers = append(samplers, backendSamplers...)
+	}
+	for _, sampler := range samplers {
+		fmt.Fprintf(c.outFile, "Adding and starting: %v on node/%v\n", sampler.GetDisruptionBackendName(), c.nodeName)
+		if err := sampler.StartEndpointMonitoring(ctx, c.recorder, nil); err != nil {
+			return err
+		}
+		c.samplers = append(c.samplers, sampler)
+		fmt.Fprintf(c.outFile, "Successfully started: %v on node/%v\n", sampler.GetDisruptionBackendName(), c.nodeName)
+	}
+	return nil
+}
+
+func (c *SampleBackendsController) removeAllSamplers() {
+	c.samplersLock.Lock()
+	defer c.samplersLock.Unlock()
+
+	c.stopped = true
+	if len(c.samplers) == 0 {
+		fmt.Fprintf(c.outFile, "No samplers running, skipping removal\n")
+		return
+	}
+
+	// every sampler has to drain, so stop them in parallel
+	wg := sync.WaitGroup{}
+	for _, sampler := range c.samplers {
+		fmt.Fprintf(c.outFile, "Stopping and removing: %v for node/%v\n", sampler.GetDisruptionBackendName(), c.nodeName)
+		wg.Add(1)
+		go func(sampler disruptionlibrary.Sampler) {
+			defer utilruntime.HandleCrash()
+			defer wg.Done()
+			sampler.Stop()
+		}(sampler)
+	}
+	wg.Wait()
+	c.samplers = nil
+	fmt.Fprintf(c.outFile, "Stopped all samplers\n")
+}
+
+func (c *SampleBackendsController) Run(ctx context.Context, finishedCleanup chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+	defer close(finishedCleanup)
+
+	logger := klog.FromContext(ctx)
+	logger.Info("Starting SampleBackends controller")
+	defer logger.Info("Shutting down SampleBackends controller")
+
+	if !cache.WaitForNamedCacheSync("BackendSampler", ctx.Done(), c.informersToSync...) {
+		return
+	}
+	go wait.UntilWithContext(ctx, c.runWorker, time.Second)
+
+	<-ctx.Done()
+	c.removeAllSamplers()
+}
+
+func (c *SampleBackendsController) runWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *SampleBackendsController) processNextWorkItem(ctx context.Context) bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.syncHandler(ctx, key.(string))
+	if err == nil {
+		c.queue.Forget(key)
+		return true
+	}
+	utilruntime.HandleError(fmt.Errorf("%v failed with : %v", key, err))
+	c.queue.AddRateLimited(key)
+
+	return true
+}
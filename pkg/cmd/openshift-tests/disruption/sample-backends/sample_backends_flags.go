@@ -0,0 +1,168 @@
+package sample_backends
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/openshift/origin/pkg/clioptions/iooptions"
+	"github.com/openshift/origin/pkg/monitortestlibrary/inclusterdisruption"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+type SampleBackendsFlags struct {
+	ConfigFlags       *genericclioptions.ConfigFlags
+	OutputFlags       *iooptions.OutputFlags
+	BackendsFile      string
+	BackendNames      []string
+	MyNodeName        string
+	StopConfigMapName string
+
+	genericclioptions.IOStreams
+}
+
+func NewSampleBackendsFlags(streams genericclioptions.IOStreams) *SampleBackendsFlags {
+	return &SampleBackendsFlags{
+		ConfigFlags: genericclioptions.NewConfigFlags(false),
+		OutputFlags: iooptions.NewOutputOptions(),
+		IOStreams:   streams,
+	}
+}
+
+func NewSampleBackends(ioStreams genericclioptions.IOStreams) *cobra.Command {
+	f := NewSampleBackendsFlags(ioStreams)
+	cmd := &cobra.Command{
+		Use:   "sample-backends",
+		Short: "Continuously sample the backends of a backends file to check their availability",
+		Long: templates.LongDesc(`
+Continuously sample the backends described in a backends file until the stop configmap
+is created.  This is run by the sampler pods deployed for in-cluster disruption backends,
+the intervals are written to the output file and logged so they can be collected.
+`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancelFn := context.WithCancel(context.Background())
+			defer cancelFn()
+			abortCh := make(chan os.Signal, 2)
+			go func() {
+				<-abortCh
+				fmt.Fprintf(f.ErrOut, "Interrupted, terminating\n")
+				cancelFn()
+
+				sig := <-abortCh
+				fmt.Fprintf(f.ErrOut, "Interrupted twice, exiting (%s)\n", sig)
+				switch sig {
+				case syscall.SIGINT:
+					os.Exit(130)
+				default:
+					os.Exit(0)
+				}
+			}()
+			signal.Notify(abortCh, syscall.SIGINT, syscall.SIGTERM)
+
+			if err := f.Validate(); err != nil {
+				return err
+			}
+			o, err := f.ToOptions()
+			if err != nil {
+				return err
+			}
+			return o.Run(ctx)
+		},
+	}
+
+	f.BindOptions(cmd.Flags())
+
+	return cmd
+}
+
+func (f *SampleBackendsFlags) BindOptions(flags *pflag.FlagSet) {
+	flags.StringVar(&f.BackendsFile, "backends-file", f.BackendsFile, "the file of backend specs to sample")
+	flags.StringSliceVar(&f.BackendNames, "backend", f.BackendNames, "the names of the backends in the backends file to sample, all of them if not set")
+	flags.StringVar(&f.MyNodeName, "my-node-name", f.MyNodeName, "the name of the node running this pod")
+	flags.StringVar(&f.StopConfigMapName, "stop-configmap", f.StopConfigMapName, "the name of the configmap that indicates that this pod should stop all samplers.")
+	f.ConfigFlags.AddFlags(flags)
+	f.OutputFlags.BindFlags(flags)
+}
+
+func (f *SampleBackendsFlags) Validate() error {
+	if len(f.OutputFlags.OutFile) == 0 {
+		return fmt.Errorf("output-file must be specified")
+	}
+	if len(f.BackendsFile) == 0 {
+		return fmt.Errorf("backends-file must be specified")
+	}
+	if len(f.StopConfigMapName) == 0 {
+		return fmt.Errorf("stop-configmap must be specified")
+	}
+	return nil
+}
+
+func (f *SampleBackendsFlags) SetIOStreams(streams genericclioptions.IOStreams) {
+	f.IOStreams = streams
+}
+
+func (f *SampleBackendsFlags) ToOptions() (*SampleBackendsOptions, error) {
+	backends, err := inclusterdisruption.ReadBackendSpecs(f.BackendsFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.BackendNames) > 0 {
+		byName := map[string]inclusterdisruption.BackendSpec{}
+		for _, backend := range backends {
+			byName[backend.Name] = backend
+		}
+		backends = []inclusterdisruption.BackendSpec{}
+		for _, name := range f.BackendNames {
+			backend, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("backend %q is not in %s", name, f.BackendsFile)
+			}
+			backends = append(backends, backend)
+		}
+	}
+
+	originalOutStream := f.IOStreams.Out
+	closeFn, err := f.OutputFlags.ConfigureIOStreams(f.IOStreams, f)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, _, err := f.ConfigFlags.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, err
+	}
+	if len(namespace) == 0 {
+		return nil, fmt.Errorf("namespace must be specified")
+	}
+
+	restConfig, err := f.ConfigFlags.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &SampleBackendsOptions{
+		KubeClient:        kubeClient,
+		Namespace:         namespace,
+		Backends:          backends,
+		OutputFile:        f.OutputFlags.OutFile,
+		StopConfigMapName: f.StopConfigMapName,
+		MyNodeName:        f.MyNodeName,
+		CloseFn:           closeFn,
+
+		OriginalOutFile: originalOutStream,
+		IOStreams:       f.IOStreams,
+	}, nil
+}
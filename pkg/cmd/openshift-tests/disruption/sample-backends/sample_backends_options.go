@@ -0,0 +1,74 @@
+package sample_backends
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/openshift/origin/pkg/clioptions/iooptions"
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitortestlibrary/inclusterdisruption"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type SampleBackendsOptions struct {
+	KubeClient kubernetes.Interface
+	Namespace  string
+	Backends   []inclusterdisruption.BackendSpec
+
+	OutputFile        string
+	MyNodeName        string
+	StopConfigMapName string
+
+	OriginalOutFile io.Writer
+	CloseFn         iooptions.CloseFunc
+	genericclioptions.IOStreams
+}
+
+func (o *SampleBackendsOptions) Run(ctx context.Context) error {
+	defer o.CloseFn()
+	fmt.Fprintf(o.OriginalOutFile, "Initializing to sample %d backends\n", len(o.Backends))
+
+	startingContent, err := os.ReadFile(o.OutputFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(startingContent) > 0 {
+		// print starting content to the log so that we can simply scrape the log to find all entries at the end
+		o.OriginalOutFile.Write(startingContent)
+	}
+
+	recorder := monitor.WrapWithJSONLRecorder(monitor.NewRecorder(), o.IOStreams.Out, nil)
+
+	kubeInformers := informers.NewSharedInformerFactory(o.KubeClient, 0)
+	namespacedScopedCoreInformers := coreinformers.New(kubeInformers, o.Namespace, nil)
+
+	cleanupFinished := make(chan struct{})
+	backendSampler := NewSampleBackendsController(
+		o.Backends,
+		o.MyNodeName,
+		o.Namespace,
+		recorder,
+		o.OriginalOutFile,
+		o.StopConfigMapName,
+		namespacedScopedCoreInformers.ConfigMaps(),
+	)
+
+	go backendSampler.Run(ctx, cleanupFinished)
+	go kubeInformers.Start(ctx.Done())
+
+	fmt.Fprintf(o.OriginalOutFile, "Watching configmaps...\n")
+
+	<-ctx.Done()
+
+	// now wait for the samplers to shutdown
+	fmt.Fprintf(o.OriginalOutFile, "Waiting for samplers to close...\n")
+	<-cleanupFinished
+	fmt.Fprintf(o.OriginalOutFile, "Exiting...\n")
+
+	return nil
+}
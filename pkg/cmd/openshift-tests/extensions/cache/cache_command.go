@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/origin/pkg/test/extensions"
+)
+
+func NewCacheCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and manage the extension binary cache",
+		Long: templates.LongDesc(`
+		Inspect and manage the extension binary cache.
+
+		Extension binaries extracted from the release payload are cached in $XDG_CACHE_HOME, or
+		$HOME/.cache/openshift-tests, keyed by the digest of the image they were extracted from.
+		The least recently used binaries are evicted once the cache grows beyond
+		$OPENSHIFT_TESTS_CACHE_MAX_SIZE (10Gi by default).
+		`),
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(
+		newListCommand(streams),
+		newPruneCommand(streams),
+		newWarmCommand(streams),
+	)
+	return cmd
+}
+
+func newListCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	return &cobra.Command{
+		Use:          "ls",
+		Short:        "List the cached extension binaries, most recently used first",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, err := openCache()
+			if err != nil {
+				return err
+			}
+			entries, err := cache.Entries()
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(streams.Out, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "KEY\tBINARY\tSIZE\tLAST USED\tIMAGE")
+			var total int64
+			for _, entry := range entries {
+				total += entry.Size
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+					entry.Key[:12], entry.BinaryPath, humanSize(entry.Size), entry.LastUsed.Local().Format(time.RFC3339), entry.Image)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			fmt.Fprintf(streams.Out, "\n%d binaries, %s in %s\n", len(entries), humanSize(total), cache.Dir())
+			return nil
+		},
+	}
+}
+
+type pruneOptions struct {
+	MaxSize string
+	All     bool
+}
+
+func newPruneCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	o := &pruneOptions{}
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Evict the least recently used extension binaries",
+		Long: templates.LongDesc(`
+		Evict the least recently used extension binaries until the cache is no larger than
+		--max-size, which defaults to $OPENSHIFT_TESTS_CACHE_MAX_SIZE or 10Gi.  Use --all to
+		empty the cache.
+		`),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			maxSize, err := extensions.DefaultBinaryCacheMaxSize()
+			if err != nil {
+				return err
+			}
+			switch {
+			case o.All && len(o.MaxSize) > 0:
+				return fmt.Errorf("--all and --max-size are mutually exclusive")
+			case o.All:
+				maxSize = 0
+			case len(o.MaxSize) > 0:
+				quantity, err := resource.ParseQuantity(o.MaxSize)
+				if err != nil {
+					return fmt.Errorf("invalid --max-size: %w", err)
+				}
+				maxSize = quantity.Value()
+			}
+
+			cache, err := openCache()
+			if err != nil {
+				return err
+			}
+			evicted, err := cache.Prune(maxSize)
+			var freed int64
+			for _, entry := range evicted {
+				freed += entry.Size
+			}
+			fmt.Fprintf(streams.Out, "Evicted %d binaries, freed %s\n", len(evicted), humanSize(freed))
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&o.MaxSize, "max-size", o.MaxSize, "Size to prune the cache to, for instance 5Gi.")
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "Evict every cached binary.")
+	return cmd
+}
+
+type warmOptions struct {
+	Parallelism int
+}
+
+func newWarmCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	o := &warmOptions{Parallelism: 10}
+	cmd := &cobra.Command{
+		Use:   "warm",
+		Short: "Extract the extension binaries of the release payload into the cache",
+		Long: templates.LongDesc(`
+		Extract the extension binaries of the release payload into the cache, and cache their info.
+
+		The release payload is determined the same way as for the run command: from
+		$EXTENSIONS_PAYLOAD_OVERRIDE, $RELEASE_IMAGE_LATEST, or the cluster under test.  Test lists
+		depend on the cluster and are cached on first use.
+		`),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(os.Getenv("OPENSHIFT_TESTS_DISABLE_CACHE")) > 0 {
+				return fmt.Errorf("the extension binary cache is disabled by OPENSHIFT_TESTS_DISABLE_CACHE")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+			defer cancel()
+			cleanUpFn, binaries, err := extensions.ExtractAllTestBinaries(ctx, o.Parallelism)
+			if err != nil {
+				return err
+			}
+			defer cleanUpFn()
+			infos, err := binaries.Info(ctx, o.Parallelism)
+			if err != nil {
+				return err
+			}
+			for _, info := range infos {
+				fmt.Fprintf(streams.Out, "Cached %s from %s\n", info.Source.SourceBinary, info.Source.SourceImage)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&o.Parallelism, "parallelism", o.Parallelism, "Number of binaries to extract at once.")
+	return cmd
+}
+
+func openCache() (*extensions.BinaryCache, error) {
+	return extensions.NewBinaryCache(extensions.DefaultBinaryCacheDir(), 0)
+}
+
+func humanSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
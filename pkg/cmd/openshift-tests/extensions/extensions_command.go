@@ -0,0 +1,19 @@
+package extensions
+
+import (
+	"github.com/openshift/origin/pkg/cmd/openshift-tests/extensions/cache"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func NewExtensionsCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "extensions",
+		Short:         "Manage the extension test binaries from the release payload",
+		SilenceErrors: true,
+	}
+	cmd.AddCommand(
+		cache.NewCacheCommand(streams),
+	)
+	return cmd
+}
@@ -0,0 +1,47 @@
+package merge_results
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	testginkgo "github.com/openshift/origin/pkg/test/ginkgo"
+)
+
+func NewMergeResultsCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	o := &testginkgo.MergeResultsOptions{IOStreams: streams}
+
+	cmd := &cobra.Command{
+		Use:   "merge-results --output-dir DIR SHARD_JUNIT_DIR...",
+		Short: "Merge the results of the shards of a run into one report",
+		Long: templates.LongDesc(`
+		Merge the results of the shards of a run into one report
+
+		Each argument is the --junit-dir of one "openshift-tests run --shard=i/N" invocation.  The
+		e2e junit, extension test results, monitor intervals, and test failure summaries for risk
+		analysis are combined and written to --output-dir, along with a test-durations.json
+		database that can be passed to --shard-durations to balance later runs.
+		`),
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.Dirs = args
+			output, err := filepath.Abs(o.OutputDir)
+			if err != nil {
+				return err
+			}
+			for _, dir := range args {
+				if dir, err := filepath.Abs(dir); err == nil && dir == output {
+					return fmt.Errorf("--output-dir must not be one of the merged directories")
+				}
+			}
+			return o.Run()
+		},
+	}
+	cmd.Flags().StringVar(&o.OutputDir, "output-dir", o.OutputDir, "The directory to write the merged results to.")
+	cmd.MarkFlagRequired("output-dir")
+	return cmd
+}
@@ -0,0 +1,137 @@
+package convert
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openshift/origin/pkg/monitor"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	formatJSON    = "json"
+	formatCompact = "compact"
+)
+
+type ConvertOptions struct {
+	InputFilename  string
+	OutputFilename string
+	OutputFormat   string
+	Compact        bool
+	Strict         bool
+
+	IOStreams genericclioptions.IOStreams
+}
+
+func NewConvertOptions(ioStreams genericclioptions.IOStreams) *ConvertOptions {
+	return &ConvertOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+func NewConvertCommand(ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewConvertOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "convert -f e2e-events.json -o e2e-events.jsonl.gz",
+		Short: "Convert an intervals file between formats",
+		Long: templates.LongDesc(`
+		Convert an intervals file between the JSON format and the compact format.
+
+		The input format is detected.  The output format is compact when the output file ends in .jsonl.gz and JSON
+		otherwise, unless --output-format is set.  With --compact-intervals, runs of identical point-in-time intervals
+		are merged into one interval with a count annotation.
+
+		Files written before intervals had an apiVersion, including those with string locators, are converted to the
+		current version.  With --strict, unknown locator keys and reasons are errors.
+
+		openshift-tests monitor convert -f e2e-events_20240101-000000.json -o e2e-events_20240101-000000.jsonl.gz
+		openshift-tests monitor convert -f e2e-events_20240101-000000.jsonl.gz -o e2e-events_20240101-000000.json
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	o.Bind(cmd.Flags())
+
+	return cmd
+}
+
+func (o *ConvertOptions) Bind(flagset *pflag.FlagSet) {
+	flagset.StringVarP(&o.InputFilename, "filename", "f", o.InputFilename, "intervals file to read, in any format")
+	flagset.StringVarP(&o.OutputFilename, "output", "o", o.OutputFilename, "intervals file to write")
+	flagset.StringVar(&o.OutputFormat, "output-format", o.OutputFormat, fmt.Sprintf("format to write: [%s,%s].  Detected from --output by default.", formatCompact, formatJSON))
+	flagset.BoolVar(&o.Compact, "compact-intervals", o.Compact, "merge runs of identical point-in-time intervals")
+	flagset.BoolVar(&o.Strict, "strict", o.Strict, "fail on unknown locator keys and reasons")
+}
+
+func (o *ConvertOptions) Complete() error {
+	if len(o.OutputFormat) == 0 {
+		o.OutputFormat = formatJSON
+		if strings.HasSuffix(o.OutputFilename, monitorserialization.CompactFileExtension) {
+			o.OutputFormat = formatCompact
+		}
+	}
+	return nil
+}
+
+func (o *ConvertOptions) Validate() error {
+	if len(o.InputFilename) == 0 {
+		return fmt.Errorf("missing -f")
+	}
+	if len(o.OutputFilename) == 0 {
+		return fmt.Errorf("missing -o")
+	}
+	if o.OutputFormat != formatJSON && o.OutputFormat != formatCompact {
+		return fmt.Errorf("unknown --output-format %q", o.OutputFormat)
+	}
+	return nil
+}
+
+func (o *ConvertOptions) Run() error {
+	intervals, err := monitorserialization.EventsFromFile(o.InputFilename)
+	if err != nil {
+		return err
+	}
+	if o.Strict {
+		if err := monitorserialization.ValidateIntervals(intervals); err != nil {
+			return fmt.Errorf("%s: %w", o.InputFilename, err)
+		}
+	}
+	read := len(intervals)
+	if o.Compact {
+		intervals = monitor.CompactIntervals(intervals)
+	}
+
+	switch o.OutputFormat {
+	case formatCompact:
+		err = monitorserialization.IntervalsToCompactFile(o.OutputFilename, intervals)
+	default:
+		err = monitorserialization.EventsToFile(o.OutputFilename, intervals)
+	}
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(o.OutputFilename)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.IOStreams.Out, "Wrote %d of %d intervals to %s (%d bytes)\n", len(intervals), read, o.OutputFilename, info.Size())
+	return nil
+}
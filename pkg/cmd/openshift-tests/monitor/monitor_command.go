@@ -1,6 +1,8 @@
 package monitor
 
 import (
+	"github.com/openshift/origin/pkg/cmd/openshift-tests/monitor/convert"
+	"github.com/openshift/origin/pkg/cmd/openshift-tests/monitor/query"
 	"github.com/openshift/origin/pkg/cmd/openshift-tests/monitor/run"
 	summarize_audit_logs "github.com/openshift/origin/pkg/cmd/openshift-tests/monitor/summarize-audit-logs"
 	"github.com/openshift/origin/pkg/monitor/apiserveravailability"
@@ -15,6 +17,8 @@ func NewMonitorCommand(streams genericclioptions.IOStreams) *cobra.Command {
 	}
 	cmd.AddCommand(
 		run.NewRunCommand(streams),
+		query.NewQueryCommand(streams),
+		convert.NewConvertCommand(streams),
 		summarize_audit_logs.AuditLogSummaryCommand(),
 		apiserveravailability.LogSummaryCommand(),
 	)
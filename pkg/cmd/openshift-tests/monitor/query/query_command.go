@@ -0,0 +1,181 @@
+package query
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/intervalquery"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+type QueryOptions struct {
+	MonitorEventFilename string
+	Query                string
+	OutputType           string
+
+	KnownPrinters map[string]PrintFunc
+	IOStreams     genericclioptions.IOStreams
+}
+
+type PrintFunc func(out io.Writer, intervals monitorapi.Intervals) error
+
+func NewQueryOptions(ioStreams genericclioptions.IOStreams) *QueryOptions {
+	return &QueryOptions{
+		OutputType: "table",
+
+		IOStreams: ioStreams,
+		KnownPrinters: map[string]PrintFunc{
+			"table": printTable,
+			"json":  printJSON,
+			"csv":   printCSV,
+		},
+	}
+}
+
+func NewQueryCommand(ioStreams genericclioptions.IOStreams) *cobra.Command {
+	o := NewQueryOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:   "query -f e2e-events.json EXPRESSION",
+		Short: "Print the intervals matching an expression",
+		Long: templates.LongDesc(`
+		Print the intervals from an e2e-events json file that match a CEL expression.
+
+		The expression can refer to source, level, display, locatorType, locator (map),
+		reason, cause, message, annotations (map), from, to, and duration.  overlaps("expression")
+		is true when another interval matching the nested expression overlaps in time.
+
+		openshift-tests monitor query -f e2e-events.json 'locator.namespace == "openshift-etcd" && level == "Error"'
+		openshift-tests monitor query -f e2e-events.json -ocsv 'reason == "NotReady" && overlaps("source == \"Disruption\"")'
+		`),
+
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(args); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	o.Bind(cmd.Flags())
+
+	return cmd
+}
+
+func (o *QueryOptions) Bind(flagset *pflag.FlagSet) {
+	flagset.StringVarP(&o.MonitorEventFilename, "filename", "f", o.MonitorEventFilename, "e2e-events.json file")
+	flagset.StringVarP(&o.Query, "query", "q", o.Query, "expression selecting the intervals to print.  May also be passed as the only argument.")
+	flagset.StringVarP(&o.OutputType, "output", "o", o.OutputType, fmt.Sprintf("type of output: [%s]", strings.Join(sets.StringKeySet(o.KnownPrinters).List(), ",")))
+}
+
+func (o *QueryOptions) Complete(args []string) error {
+	switch {
+	case len(args) > 1:
+		return fmt.Errorf("only one expression may be specified")
+	case len(args) == 1 && len(o.Query) > 0:
+		return fmt.Errorf("specify the expression as an argument or with --query, not both")
+	case len(args) == 1:
+		o.Query = args[0]
+	}
+	return nil
+}
+
+func (o *QueryOptions) Validate() error {
+	if len(o.MonitorEventFilename) == 0 {
+		return fmt.Errorf("missing -f")
+	}
+	if len(o.Query) == 0 {
+		return fmt.Errorf("missing expression")
+	}
+	if o.KnownPrinters[o.OutputType] == nil {
+		return fmt.Errorf("unknown -o")
+	}
+	return nil
+}
+
+func (o *QueryOptions) Run() error {
+	query, err := intervalquery.Compile(o.Query)
+	if err != nil {
+		return err
+	}
+	intervals, err := monitorserialization.EventsFromFile(o.MonitorEventFilename)
+	if err != nil {
+		return err
+	}
+
+	matches := query.Filter(intervals)
+	sort.Sort(matches)
+	return o.KnownPrinters[o.OutputType](o.IOStreams.Out, matches)
+}
+
+var columns = []string{"FROM", "TO", "DURATION", "LEVEL", "SOURCE", "LOCATOR", "REASON", "MESSAGE"}
+
+func row(interval monitorapi.Interval) []string {
+	to := ""
+	duration := ""
+	if !interval.To.IsZero() {
+		to = interval.To.UTC().Format(time.RFC3339)
+		duration = interval.To.Sub(interval.From).String()
+	}
+	return []string{
+		interval.From.UTC().Format(time.RFC3339),
+		to,
+		duration,
+		interval.Level.String(),
+		string(interval.Source),
+		interval.Locator.OldLocator(),
+		string(interval.Message.Reason),
+		interval.Message.HumanMessage,
+	}
+}
+
+func printTable(out io.Writer, intervals monitorapi.Intervals) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(columns, "\t"))
+	for _, interval := range intervals {
+		fields := row(interval)
+		// keep each interval on one line
+		fields[len(fields)-1] = strings.ReplaceAll(fields[len(fields)-1], "\n", "\\n")
+		fmt.Fprintln(w, strings.Join(fields, "\t"))
+	}
+	return w.Flush()
+}
+
+func printJSON(out io.Writer, intervals monitorapi.Intervals) error {
+	content, err := monitorserialization.IntervalsToJSON(intervals)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(content)
+	return err
+}
+
+func printCSV(out io.Writer, intervals monitorapi.Intervals) error {
+	w := csv.NewWriter(out)
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+	for _, interval := range intervals {
+		if err := w.Write(row(interval)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
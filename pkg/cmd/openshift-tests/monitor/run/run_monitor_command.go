@@ -13,7 +13,9 @@ import (
 
 	"github.com/openshift/origin/pkg/clioptions/imagesetup"
 	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionpolicy"
 
+	"github.com/openshift/origin/pkg/monitor/intervalserver"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	"github.com/openshift/origin/test/extended/util/image"
 
@@ -29,9 +31,14 @@ import (
 
 type RunMonitorFlags struct {
 	ArtifactDir         string
+	RecorderDir         string
+	ListenAddress       string
 	DisplayFromNow      bool
 	ExactMonitorTests   []string
 	DisableMonitorTests []string
+	MonitorPlugins      []string
+	PhaseTimeouts       map[string]string
+	DisruptionPolicy    string
 	FromRepository      string
 
 	genericclioptions.IOStreams
@@ -85,10 +92,15 @@ func (f *RunMonitorFlags) BindFlags(flags *pflag.FlagSet) {
 	monitorNames := defaultmonitortests.ListAllMonitorTests()
 
 	flags.StringVar(&f.ArtifactDir, "artifact-dir", f.ArtifactDir, "The directory where monitor events will be stored.")
+	flags.StringVar(&f.RecorderDir, "recorder-dir", f.RecorderDir, "If set, intervals and resources are persisted to this directory as they are recorded, so they survive the monitor being killed.  Existing content is loaded on start.")
+	flags.StringVar(&f.ListenAddress, "listen-address", f.ListenAddress, "If set, for instance to localhost:8080, the recorded intervals are served on this address, including a server-sent-events stream of intervals as they are recorded.")
 	flags.BoolVar(&f.DisplayFromNow, "display-from-now", f.DisplayFromNow, "Only display intervals from at or after this comand was started.")
 	flags.StringSliceVar(&f.ExactMonitorTests, "monitor", f.ExactMonitorTests,
 		fmt.Sprintf("list of exactly which monitors to enable. All others will be disabled.  Current monitors are: [%s]", strings.Join(monitorNames, ", ")))
 	flags.StringSliceVar(&f.DisableMonitorTests, "disable-monitor", f.DisableMonitorTests, "list of monitors to disable.  Defaults for others will be honored.")
+	flags.StringToStringVar(&f.PhaseTimeouts, "monitor-phase-timeout", f.PhaseTimeouts, "How long a single monitor test may take for a phase, for instance CollectData=90m, before it fails that phase and is abandoned.  Phases that are not given have no timeout.")
+	flags.StringSliceVar(&f.MonitorPlugins, "monitor-plugin", f.MonitorPlugins, "Monitor test plugin binaries to run in addition to the built-in monitors, either a local path or <image tag>:<path> to extract the binary from the release payload.")
+	flags.StringVar(&f.DisruptionPolicy, "disruption-policy", f.DisruptionPolicy, "A file of disruption budgets for backends, applied by the disruption tests instead of or in addition to historical data, see the evaluate-disruption-policy dev command.")
 	flags.StringVar(&f.FromRepository, "from-repository", f.FromRepository, "A container image repository to retrieve test images from.")
 }
 
@@ -111,6 +123,8 @@ func (f *RunMonitorFlags) ToOptions() (*RunMonitorOptions, error) {
 
 	return &RunMonitorOptions{
 		ArtifactDir:     f.ArtifactDir,
+		RecorderDir:     f.RecorderDir,
+		ListenAddress:   f.ListenAddress,
 		DisplayFilterFn: displayFilterFn,
 		MonitorTests:    monitorTestRegistry,
 		IOStreams:       f.IOStreams,
@@ -119,16 +133,32 @@ func (f *RunMonitorFlags) ToOptions() (*RunMonitorOptions, error) {
 }
 
 func (f *RunMonitorFlags) getMonitorTestRegistry() (monitortestframework.MonitorTestRegistry, error) {
+	phaseTimeouts, err := monitortestframework.ParsePhaseTimeouts(f.PhaseTimeouts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --monitor-phase-timeout: %w", err)
+	}
+	var disruptionPolicy *disruptionpolicy.Policy
+	if len(f.DisruptionPolicy) > 0 {
+		disruptionPolicy, err = disruptionpolicy.ReadPolicy(f.DisruptionPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --disruption-policy: %w", err)
+		}
+	}
 	monitorTestInfo := monitortestframework.MonitorTestInitializationInfo{
 		ClusterStabilityDuringTest: monitortestframework.Stable,
 		ExactMonitorTests:          f.ExactMonitorTests,
 		DisableMonitorTests:        f.DisableMonitorTests,
+		MonitorPlugins:             f.MonitorPlugins,
+		PhaseTimeouts:              phaseTimeouts,
+		DisruptionPolicy:           disruptionPolicy,
 	}
 	return defaultmonitortests.NewMonitorTestsFor(monitorTestInfo)
 }
 
 type RunMonitorOptions struct {
 	ArtifactDir     string
+	RecorderDir     string
+	ListenAddress   string
 	DisplayFilterFn monitorapi.EventIntervalMatchesFunc
 	MonitorTests    monitortestframework.MonitorTestRegistry
 	FromRepository  string
@@ -169,7 +199,28 @@ func (o *RunMonitorOptions) Run() error {
 	}()
 	signal.Notify(abortCh, syscall.SIGINT, syscall.SIGTERM)
 
-	recorder := monitor.WrapWithJSONLRecorder(monitor.NewRecorder(), o.Out, o.DisplayFilterFn)
+	var delegateRecorder monitorapi.Recorder = monitor.NewRecorder()
+	if len(o.RecorderDir) > 0 {
+		durableRecorder, err := monitor.NewDurableRecorder(context.Background(), o.RecorderDir)
+		if err != nil {
+			return err
+		}
+		defer durableRecorder.Close()
+		fmt.Fprintf(o.Out, "Persisting monitor data to %s, loaded %d existing intervals.\n", o.RecorderDir, len(durableRecorder.Intervals(time.Time{}, time.Time{})))
+		delegateRecorder = durableRecorder
+	}
+	recorder := monitor.WrapWithJSONLRecorder(delegateRecorder, o.Out, o.DisplayFilterFn)
+	if len(o.ListenAddress) > 0 {
+		streamingRecorder := monitor.WrapWithStreamingRecorder(recorder)
+		server := intervalserver.NewServer(streamingRecorder)
+		address, err := server.Start(o.ListenAddress)
+		if err != nil {
+			return err
+		}
+		defer server.Shutdown(context.Background())
+		fmt.Fprintf(o.Out, "Serving intervals on http://%s/api/v1/intervals\n", address)
+		recorder = streamingRecorder
+	}
 	m := monitor.NewMonitor(
 		recorder,
 		restConfig,
@@ -9,6 +9,7 @@ import (
 
 	"github.com/openshift/origin/pkg/monitortests/testframework/timelineserializer"
 
+	"github.com/openshift/origin/pkg/monitor/intervalquery"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
 	"github.com/openshift/origin/test/extended/testdata"
@@ -25,6 +26,7 @@ type TimelineOptions struct {
 
 	LocatorMatchers []string
 	Namespaces      []string
+	Query           string
 	OutputType      string
 	EndDate         string
 
@@ -96,6 +98,7 @@ func (o *TimelineOptions) Bind(flagset *pflag.FlagSet) error {
 	flagset.StringVar(&o.TimelineType, "type", o.TimelineType, "type of timeline to produce: "+strings.Join(sets.StringKeySet(o.KnownTimelines).List(), ","))
 	flagset.StringVar(&o.PodResourceFilename, "known-pods", o.PodResourceFilename, "resource-pods_<timestamp>.zip filename from openshift-tests.")
 	flagset.StringSliceVarP(&o.LocatorMatchers, "locator", "l", o.LocatorMatchers, "key=value selector for monitor event locators (where value is a regex).  for instance -lpod=openshift-etcd-installer.  The same key listed multiple times means an OR.  Each separate key is logically ANDed.  Precede value with a dash for anti-match")
+	flagset.StringVar(&o.Query, "query", o.Query, "expression selecting the intervals to include, for instance 'level == \"Error\" && locator.namespace == \"openshift-etcd\"'.  See openshift-tests monitor query --help.")
 	flagset.StringVarP(&o.EndDate, "end-date", "e", o.EndDate, fmt.Sprintf("Stop date (default is one hour after latest event) in RFC3399 format in UTC timezone: %s", time.RFC3339))
 
 	return nil
@@ -129,6 +132,12 @@ func (o *TimelineOptions) Validate() error {
 		}
 	}
 
+	if len(o.Query) > 0 {
+		if _, err := intervalquery.Compile(o.Query); err != nil {
+			return err
+		}
+	}
+
 	if len(o.EndDate) > 0 {
 		_, err := time.ParseInLocation(time.RFC3339, o.EndDate, time.UTC)
 		if err != nil {
@@ -155,6 +164,12 @@ func (o *TimelineOptions) ToTimeline() *Timeline {
 		}
 	}
 
+	var query *intervalquery.Query
+	if len(o.Query) > 0 {
+		// already validated
+		query = intervalquery.MustCompile(o.Query)
+	}
+
 	var endDateTime = &time.Time{}
 	if len(o.EndDate) > 0 {
 		parsedTime, _ := time.Parse(time.RFC3339, o.EndDate)
@@ -171,6 +186,7 @@ func (o *TimelineOptions) ToTimeline() *Timeline {
 		RemovedLocatorMatcher: inverseLocatorMatcher,
 		Namespaces:            o.Namespaces,
 		EndDate:               endDateTime,
+		Query:                 query,
 
 		Renderer:       o.KnownRenderers[o.OutputType],
 		TimelineFilter: o.KnownTimelines[o.TimelineType],
@@ -186,6 +202,7 @@ type Timeline struct {
 	RemovedLocatorMatcher map[string][]*regexp.Regexp
 	Namespaces            []string
 	EndDate               *time.Time
+	Query                 *intervalquery.Query
 
 	Renderer       RenderFunc
 	TimelineFilter monitorapi.EventIntervalMatchesFunc
@@ -210,6 +227,10 @@ func (o *Timeline) Run() error {
 	if len(o.RemovedLocatorMatcher) > 0 {
 		filteredEvents = filteredEvents.Filter(monitorapi.NotContainsAllParts(o.RemovedLocatorMatcher))
 	}
+	if o.Query != nil {
+		// overlaps() considers every interval, not only the ones that survived the other filters.
+		filteredEvents = filteredEvents.Filter(o.Query.MatchesFunc(consumedEvents))
+	}
 	// compute intervals from raw
 	var to time.Time
 
@@ -60,6 +60,10 @@ func (f *RunUpgradeSuiteFlags) SetIOStreams(streams genericclioptions.IOStreams)
 }
 
 func (f *RunUpgradeSuiteFlags) ToOptions(args []string) (*RunUpgradeSuiteOptions, error) {
+	if err := f.GinkgoRunSuiteOptions.Validate(); err != nil {
+		return nil, err
+	}
+
 	adminRESTConfig, err := kubeconfig.GetStaticRESTConfig()
 	if err != nil {
 		return nil, err
@@ -101,16 +101,33 @@ func (o *RunUpgradeSuiteOptions) Run(ctx context.Context) error {
 	// TODO fix the upstream so that the AfterReadingAllFlags will properly check for either of the inputs having values.
 	k8simage.Init("")
 
+	// upgrades are monitored as Stable, faults would only be injected by Disruptive suites
+	if len(o.GinkgoRunSuiteOptions.ChaosSchedule) > 0 {
+		return fmt.Errorf("--chaos-schedule requires a Disruptive suite, upgrade suites run with the cluster stability %v", monitortestframework.Stable)
+	}
+
 	if err := o.UpgradeTestPreSuite(); err != nil {
 		return err
 	}
 
+	phaseTimeouts, err := monitortestframework.ParsePhaseTimeouts(o.GinkgoRunSuiteOptions.MonitorPhaseTimeouts)
+	if err != nil {
+		return err
+	}
+	disruptionPolicy, err := o.GinkgoRunSuiteOptions.ReadDisruptionPolicy()
+	if err != nil {
+		return err
+	}
 	// TODO the gingkoRunSuiteOptions needs to have flags then calculated options to express specified versus computed values
 	monitorTestInfo := monitortestframework.MonitorTestInitializationInfo{
 		ClusterStabilityDuringTest:        monitortestframework.Stable,
 		UpgradeTargetPayloadImagePullSpec: o.ToImage,
 		ExactMonitorTests:                 o.GinkgoRunSuiteOptions.ExactMonitorTests,
 		DisableMonitorTests:               o.GinkgoRunSuiteOptions.DisableMonitorTests,
+		MonitorPlugins:                    o.GinkgoRunSuiteOptions.MonitorPlugins,
+		PhaseTimeouts:                     phaseTimeouts,
+		DisruptionBackends:                o.GinkgoRunSuiteOptions.DisruptionBackends,
+		DisruptionPolicy:                  disruptionPolicy,
 	}
 
 	o.GinkgoRunSuiteOptions.CommandEnv = o.TestCommandEnvironment()
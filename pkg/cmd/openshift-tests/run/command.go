@@ -3,8 +3,11 @@ package run
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/openshift/origin/pkg/clioptions/imagesetup"
+	"github.com/openshift/origin/pkg/test/extensions"
+	testginkgo "github.com/openshift/origin/pkg/test/ginkgo"
 	"github.com/openshift/origin/pkg/testsuites"
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -28,6 +31,10 @@ func NewRunCommand(streams genericclioptions.IOStreams) *cobra.Command {
 		command with the --file argument. You may also pipe a list of test names, one per line, on
 		standard input by passing "-f -".
 
+		Extension binaries in the release payload may advertise additional suites, or add tests to
+		the suites below. The suites advertised by the extension binaries in the local cache are
+		listed after the built-in suites, see "openshift-tests extensions cache warm".
+
 		`) + testsuites.SuitesString(testsuites.StandardTestSuites(), "\n\nAvailable test suites:\n\n"),
 
 		SilenceUsage:  true,
@@ -48,6 +55,30 @@ func NewRunCommand(streams genericclioptions.IOStreams) *cobra.Command {
 			return nil
 		},
 	}
+	// the cache is only read when help is asked for
+	defaultHelpFn := cmd.HelpFunc()
+	cmd.SetHelpFunc(func(c *cobra.Command, args []string) {
+		c.Long += extensionSuitesString()
+		defaultHelpFn(c, args)
+	})
 	f.BindFlags(cmd.Flags())
 	return cmd
 }
+
+// extensionSuitesString lists the suites advertised by the cached extension binaries that are not built in.
+func extensionSuitesString() string {
+	if len(os.Getenv("OPENSHIFT_TESTS_DISABLE_CACHE")) > 0 {
+		return ""
+	}
+	infos, err := extensions.CachedExtensionInfo(extensions.DefaultBinaryCacheDir())
+	if err != nil || len(infos) == 0 {
+		return ""
+	}
+	standardSuites := testsuites.StandardTestSuites()
+	// the advertised suites follow the built-in ones
+	suites := testginkgo.MergeExtensionSuites(standardSuites, infos)[len(standardSuites):]
+	if len(suites) == 0 {
+		return ""
+	}
+	return testsuites.SuitesString(suites, "Test suites advertised by cached extensions, which may differ from those of the cluster:\n\n")
+}
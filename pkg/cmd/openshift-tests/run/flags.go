@@ -2,6 +2,8 @@ package run
 
 import (
 	"fmt"
+	"os"
+
 	"github.com/openshift/origin/pkg/clioptions/clusterdiscovery"
 	"github.com/openshift/origin/pkg/clioptions/iooptions"
 	"github.com/openshift/origin/pkg/clioptions/kubeconfig"
@@ -75,7 +77,25 @@ func (f *RunSuiteFlags) SetIOStreams(streams genericclioptions.IOStreams) {
 	f.GinkgoRunSuiteOptions.SetIOStreams(streams)
 }
 
+// extensionsNeededToSelect reports whether the suite named by args is not built in, or lists extension tests with
+// qualifiers of its own, so that the extension binaries have to be extracted before it is selected.
+func (f *RunSuiteFlags) extensionsNeededToSelect(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	for _, suite := range f.AvailableSuites {
+		if suite.Name == args[0] {
+			return len(suite.Qualifiers) > 0
+		}
+	}
+	return true
+}
+
 func (f *RunSuiteFlags) ToOptions(args []string) (*RunSuiteOptions, error) {
+	if err := f.GinkgoRunSuiteOptions.Validate(); err != nil {
+		return nil, err
+	}
+
 	adminRESTConfig, err := kubeconfig.GetStaticRESTConfig()
 	switch {
 	case err != nil && f.GinkgoRunSuiteOptions.DryRun:
@@ -97,8 +117,19 @@ func (f *RunSuiteFlags) ToOptions(args []string) (*RunSuiteOptions, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	// extensions may advertise suites of their own, those are only known once the extensions are extracted.  The
+	// tests extensions add to a built-in suite are found when it runs.
+	suites := f.AvailableSuites
+	if len(os.Getenv("OPENSHIFT_SKIP_EXTERNAL_TESTS")) == 0 && f.extensionsNeededToSelect(args) {
+		ginkgoOptions.Extensions, err = testginkgo.ExtractExtensionBinaries()
+		if err != nil {
+			return nil, err
+		}
+		suites = testginkgo.MergeExtensionSuites(suites, ginkgoOptions.Extensions.Info)
+	}
 	suite, err := f.TestSuiteSelectionFlags.SelectSuite(
-		f.AvailableSuites,
+		suites,
 		args,
 		kubeconfig.NewDiscoveryGetter(adminRESTConfig),
 		kubeconfig.NewConfigClientGetter(adminRESTConfig),
@@ -106,6 +137,7 @@ func (f *RunSuiteFlags) ToOptions(args []string) (*RunSuiteOptions, error) {
 		providerConfig.MatchFn(),
 	)
 	if err != nil {
+		ginkgoOptions.Extensions.CleanUp()
 		return nil, err
 	}
 
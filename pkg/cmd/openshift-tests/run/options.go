@@ -79,10 +79,27 @@ func (o *RunSuiteOptions) Run(ctx context.Context) error {
 		stabilitySetting = o.Suite.ClusterStabilityDuringTest
 	}
 
+	if len(o.GinkgoRunSuiteOptions.ChaosSchedule) > 0 && stabilitySetting != testginkgo.Disruptive {
+		return fmt.Errorf("--chaos-schedule requires a Disruptive suite or --cluster-stability=Disruptive, the cluster stability is %v", stabilitySetting)
+	}
+
+	phaseTimeouts, err := monitortestframework.ParsePhaseTimeouts(o.GinkgoRunSuiteOptions.MonitorPhaseTimeouts)
+	if err != nil {
+		return err
+	}
+	disruptionPolicy, err := o.GinkgoRunSuiteOptions.ReadDisruptionPolicy()
+	if err != nil {
+		return err
+	}
 	monitorTestInfo := monitortestframework.MonitorTestInitializationInfo{
 		ClusterStabilityDuringTest: monitortestframework.ClusterStabilityDuringTest(stabilitySetting),
 		ExactMonitorTests:          o.GinkgoRunSuiteOptions.ExactMonitorTests,
 		DisableMonitorTests:        o.GinkgoRunSuiteOptions.DisableMonitorTests,
+		MonitorPlugins:             o.GinkgoRunSuiteOptions.MonitorPlugins,
+		PhaseTimeouts:              phaseTimeouts,
+		ChaosSchedule:              o.GinkgoRunSuiteOptions.ChaosSchedule,
+		DisruptionBackends:         o.GinkgoRunSuiteOptions.DisruptionBackends,
+		DisruptionPolicy:           disruptionPolicy,
 	}
 
 	o.GinkgoRunSuiteOptions.CommandEnv = o.TestCommandEnvironment()
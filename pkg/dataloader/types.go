@@ -12,6 +12,7 @@ const (
 	DataTypeFloat64 DataType = "float64"
 	DataTypeString  DataType = "string"
 	DataTypeInteger DataType = "int64"
+	DataTypeBool    DataType = "bool"
 	// RFC3339  based value "2006-01-02T15:04:05Z07:00
 	DataTypeTimestamp DataType = "timestamp"
 	DataTypeJSON      DataType = "json"
@@ -1,9 +1,11 @@
 package defaultmonitortests
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestframework/plugin"
 	"github.com/openshift/origin/pkg/monitortests/authentication/legacyauthenticationmonitortests"
 	"github.com/openshift/origin/pkg/monitortests/authentication/requiredsccmonitortests"
 	azuremetrics "github.com/openshift/origin/pkg/monitortests/cloud/azure/metrics"
@@ -25,6 +27,8 @@ import (
 	"github.com/openshift/origin/pkg/monitortests/machines/watchmachines"
 	"github.com/openshift/origin/pkg/monitortests/monitoring/disruptionmetricsapi"
 	"github.com/openshift/origin/pkg/monitortests/monitoring/statefulsetsrecreation"
+	"github.com/openshift/origin/pkg/monitortests/network/disruptionclusterdns"
+	"github.com/openshift/origin/pkg/monitortests/network/disruptioninclustersamplers"
 	"github.com/openshift/origin/pkg/monitortests/network/disruptioningress"
 	"github.com/openshift/origin/pkg/monitortests/network/disruptionpodnetwork"
 	"github.com/openshift/origin/pkg/monitortests/network/disruptionserviceloadbalancer"
@@ -38,6 +42,7 @@ import (
 	"github.com/openshift/origin/pkg/monitortests/storage/legacystoragemonitortests"
 	"github.com/openshift/origin/pkg/monitortests/testframework/additionaleventscollector"
 	"github.com/openshift/origin/pkg/monitortests/testframework/alertanalyzer"
+	"github.com/openshift/origin/pkg/monitortests/testframework/chaosinjector"
 	"github.com/openshift/origin/pkg/monitortests/testframework/clusterinfoserializer"
 	"github.com/openshift/origin/pkg/monitortests/testframework/disruptionexternalawscloudservicemonitoring"
 	"github.com/openshift/origin/pkg/monitortests/testframework/disruptionexternalazurecloudservicemonitoring"
@@ -45,6 +50,7 @@ import (
 	"github.com/openshift/origin/pkg/monitortests/testframework/disruptionexternalservicemonitoring"
 	"github.com/openshift/origin/pkg/monitortests/testframework/disruptionserializer"
 	"github.com/openshift/origin/pkg/monitortests/testframework/e2etestanalyzer"
+	"github.com/openshift/origin/pkg/monitortests/testframework/failurecorrelation"
 	"github.com/openshift/origin/pkg/monitortests/testframework/intervalserializer"
 	"github.com/openshift/origin/pkg/monitortests/testframework/knownimagechecker"
 	"github.com/openshift/origin/pkg/monitortests/testframework/legacytestframeworkmonitortests"
@@ -58,6 +64,7 @@ import (
 	"github.com/openshift/origin/pkg/monitortests/testframework/watchnamespaces"
 	"github.com/openshift/origin/pkg/monitortests/testframework/watchrequestcountscollector"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 // ListAllMonitorTests is a helper that returns a simple list of
@@ -92,17 +99,55 @@ func NewMonitorTestsFor(info monitortestframework.MonitorTestInitializationInfo)
 		panic(fmt.Sprintf("unknown cluster stability level: %q", info.ClusterStabilityDuringTest))
 	}
 
+	// plugins are added before filtering so that they can be selected and disabled like any other monitor test
+	monitorPlugins := []*plugin.Plugin{}
+	for _, spec := range info.MonitorPlugins {
+		monitorPlugin, err := plugin.Load(context.TODO(), spec)
+		if err != nil {
+			cleanupPluginsExcept(monitorPlugins, nil)
+			return nil, err
+		}
+		monitorPlugins = append(monitorPlugins, monitorPlugin)
+		if err := startingRegistry.AddMonitorTest(monitorPlugin.Name(), monitorPlugin.JiraComponent(), monitorPlugin); err != nil {
+			cleanupPluginsExcept(monitorPlugins, nil)
+			return nil, fmt.Errorf("unable to add monitor plugin %q: %w", spec, err)
+		}
+	}
+
+	startingRegistry.SetPhaseTimeouts(info.PhaseTimeouts)
+
+	finalRegistry := startingRegistry
+	var err error
 	switch {
 	case len(info.ExactMonitorTests) > 0:
-		return startingRegistry.GetRegistryFor(info.ExactMonitorTests...)
+		finalRegistry, err = startingRegistry.GetRegistryFor(info.ExactMonitorTests...)
 
 	case len(info.DisableMonitorTests) > 0:
 		testsToInclude := startingRegistry.ListMonitorTests()
 		testsToInclude.Delete(info.DisableMonitorTests...)
-		return startingRegistry.GetRegistryFor(testsToInclude.List()...)
+		finalRegistry, err = startingRegistry.GetRegistryFor(testsToInclude.List()...)
 	}
+	if err != nil {
+		cleanupPluginsExcept(monitorPlugins, nil)
+		return nil, err
+	}
+	// plugins that were filtered out are never cleaned up by the registry, but their binaries may have been extracted
+	cleanupPluginsExcept(monitorPlugins, finalRegistry)
+
+	return finalRegistry, nil
+}
 
-	return startingRegistry, nil
+// cleanupPluginsExcept cleans up the plugins that are not in registry, which removes the binaries extracted for them.
+func cleanupPluginsExcept(monitorPlugins []*plugin.Plugin, registry monitortestframework.MonitorTestRegistry) {
+	kept := sets.NewString()
+	if registry != nil {
+		kept = registry.ListMonitorTests()
+	}
+	for _, monitorPlugin := range monitorPlugins {
+		if !kept.Has(monitorPlugin.Name()) {
+			monitorPlugin.Cleanup(context.TODO())
+		}
+	}
 }
 
 func newDefaultMonitorTests(info monitortestframework.MonitorTestInitializationInfo) monitortestframework.MonitorTestRegistry {
@@ -110,20 +155,25 @@ func newDefaultMonitorTests(info monitortestframework.MonitorTestInitializationI
 
 	monitorTestRegistry.AddRegistryOrDie(newUniversalMonitorTests(info))
 
-	monitorTestRegistry.AddMonitorTestOrDie("image-registry-availability", "Image Registry", disruptionimageregistry.NewAvailabilityInvariant())
+	monitorTestRegistry.AddMonitorTestOrDie("image-registry-availability", "Image Registry", disruptionimageregistry.NewAvailabilityInvariant(info))
 
-	monitorTestRegistry.AddMonitorTestOrDie("apiserver-availability", "kube-apiserver", disruptionlegacyapiservers.NewAvailabilityInvariant())
+	monitorTestRegistry.AddMonitorTestOrDie("apiserver-availability", "kube-apiserver", disruptionlegacyapiservers.NewAvailabilityInvariant(info))
 	monitorTestRegistry.AddMonitorTestOrDie("apiserver-new-disruption-invariant", "kube-apiserver", disruptionnewapiserver.NewDisruptionInvariant())
 
 	monitorTestRegistry.AddMonitorTestOrDie("pod-network-avalibility", "Network / ovn-kubernetes", disruptionpodnetwork.NewPodNetworkAvalibilityInvariant(info))
-	monitorTestRegistry.AddMonitorTestOrDie("service-type-load-balancer-availability", "Networking / router", disruptionserviceloadbalancer.NewAvailabilityInvariant())
-	monitorTestRegistry.AddMonitorTestOrDie("ingress-availability", "Networking / router", disruptioningress.NewAvailabilityInvariant())
+	// samplers deployed into the cluster are opt-in
+	if len(info.DisruptionBackends) > 0 {
+		monitorTestRegistry.AddMonitorTestOrDie("cluster-dns-availability", "DNS", disruptionclusterdns.NewAvailabilityInvariant(info))
+		monitorTestRegistry.AddMonitorTestOrDie(disruptioninclustersamplers.MonitorName, "Test Framework", disruptioninclustersamplers.NewInClusterSamplersInvariant(info))
+	}
+	monitorTestRegistry.AddMonitorTestOrDie("service-type-load-balancer-availability", "Networking / router", disruptionserviceloadbalancer.NewAvailabilityInvariant(info))
+	monitorTestRegistry.AddMonitorTestOrDie("ingress-availability", "Networking / router", disruptioningress.NewAvailabilityInvariant(info))
 
 	monitorTestRegistry.AddMonitorTestOrDie("on-prem-haproxy", "Networking / On-Prem Host Networking", onpremhaproxy.InitialAndFinalOperatorLogScraper())
 
 	monitorTestRegistry.AddMonitorTestOrDie("alert-summary-serializer", "Test Framework", alertanalyzer.NewAlertSummarySerializer())
 	monitorTestRegistry.AddMonitorTestOrDie("metrics-endpoints-down", "Test Framework", metricsendpointdown.NewMetricsEndpointDown())
-	monitorTestRegistry.AddMonitorTestOrDie("external-service-availability", "Test Framework", disruptionexternalservicemonitoring.NewAvailabilityInvariant())
+	monitorTestRegistry.AddMonitorTestOrDie("external-service-availability", "Test Framework", disruptionexternalservicemonitoring.NewAvailabilityInvariant(info))
 	monitorTestRegistry.AddMonitorTestOrDie("external-gcp-cloud-service-availability", "Test Framework", disruptionexternalgcpcloudservicemonitoring.NewCloudAvailabilityInvariant())
 	monitorTestRegistry.AddMonitorTestOrDie("external-aws-cloud-service-availability", "Test Framework", disruptionexternalawscloudservicemonitoring.NewCloudAvailabilityInvariant())
 	monitorTestRegistry.AddMonitorTestOrDie("external-azure-cloud-service-availability", "Test Framework", disruptionexternalazurecloudservicemonitoring.NewCloudAvailabilityInvariant())
@@ -131,7 +181,7 @@ func newDefaultMonitorTests(info monitortestframework.MonitorTestInitializationI
 	monitorTestRegistry.AddMonitorTestOrDie("disruption-summary-serializer", "Test Framework", disruptionserializer.NewDisruptionSummarySerializer())
 
 	monitorTestRegistry.AddMonitorTestOrDie("monitoring-statefulsets-recreation", "Monitoring", statefulsetsrecreation.NewStatefulsetsChecker())
-	monitorTestRegistry.AddMonitorTestOrDie("metrics-api-availability", "Monitoring", disruptionmetricsapi.NewAvailabilityInvariant())
+	monitorTestRegistry.AddMonitorTestOrDie("metrics-api-availability", "Monitoring", disruptionmetricsapi.NewAvailabilityInvariant(info))
 	monitorTestRegistry.AddMonitorTestOrDie(apiunreachablefromclientmetrics.MonitorName, "kube-apiserver", apiunreachablefromclientmetrics.NewMonitorTest())
 	monitorTestRegistry.AddMonitorTestOrDie(faultyloadbalancer.MonitorName, "kube-apiserver", faultyloadbalancer.NewMonitorTest())
 	monitorTestRegistry.AddMonitorTestOrDie(staticpodinstall.MonitorName, "kube-apiserver", staticpodinstall.NewStaticPodInstallMonitorTest())
@@ -151,6 +201,10 @@ func newDisruptiveMonitorTests(info monitortestframework.MonitorTestInitializati
 	// monitorTestRegistry.AddMonitorTestOrDie("ingress-availability", "Networking / router", disruptioningress.NewRecordAvailabilityOnly())
 	// monitorTestRegistry.AddMonitorTestOrDie("external-service-availability", "Test Framework", disruptionexternalservicemonitoring.NewRecordAvailabilityOnly())
 
+	if len(info.ChaosSchedule) > 0 {
+		monitorTestRegistry.AddMonitorTestOrDie(chaosinjector.MonitorName, "Test Framework", chaosinjector.NewChaosInjector(info.ChaosSchedule))
+	}
+
 	return monitorTestRegistry
 }
 
@@ -191,6 +245,7 @@ func newUniversalMonitorTests(info monitortestframework.MonitorTestInitializatio
 	monitorTestRegistry.AddMonitorTestOrDie("additional-events-collector", "Test Framework", additionaleventscollector.NewIntervalSerializer())
 	monitorTestRegistry.AddMonitorTestOrDie("known-image-checker", "Test Framework", knownimagechecker.NewEnsureValidImages())
 	monitorTestRegistry.AddMonitorTestOrDie("e2e-test-analyzer", "Test Framework", e2etestanalyzer.NewAnalyzer())
+	monitorTestRegistry.AddMonitorTestOrDie("failure-correlation", "Test Framework", failurecorrelation.NewFailureCorrelation())
 	monitorTestRegistry.AddMonitorTestOrDie("event-collector", "Test Framework", watchevents.NewEventWatcher())
 	monitorTestRegistry.AddMonitorTestOrDie("clusteroperator-collector", "Test Framework", watchclusteroperators.NewOperatorWatcher())
 	monitorTestRegistry.AddMonitorTestOrDie("initial-and-final-operator-log-scraper", "Test Framework", operatorloganalyzer.InitialAndFinalOperatorLogScraper())
@@ -42,6 +42,16 @@ type RequestContextAssociatedData struct {
 	// round trip for this request.
 	RoundTripDuration time.Duration
 
+	// DNSDuration, ConnectDuration and TLSHandshakeDuration are how
+	// long the phases of the request took, they are zero when the
+	// request is sent over a reused connection.  TimeToFirstByte is
+	// the time from the start of the request to the first byte of
+	// the response, these are obtained from the client trace.
+	DNSDuration          time.Duration
+	ConnectDuration      time.Duration
+	TLSHandshakeDuration time.Duration
+	TimeToFirstByte      time.Duration
+
 	// ResponseBody is the stored bytes that was obtained from reading
 	// off the body of the response received from the server
 	ResponseBody []byte
@@ -9,6 +9,14 @@ type ProtocolType string
 const (
 	ProtocolHTTP1 ProtocolType = "http1"
 	ProtocolHTTP2 ProtocolType = "http2"
+
+	// ProtocolTCP connects to the backend and, optionally, expects
+	// it to echo what is written to it.
+	ProtocolTCP ProtocolType = "tcp"
+	// ProtocolDNS resolves a name using the backend as the DNS server.
+	ProtocolDNS ProtocolType = "dns"
+	// ProtocolGRPC calls the gRPC health service of the backend.
+	ProtocolGRPC ProtocolType = "grpc"
 )
 
 type LoadBalancerType string
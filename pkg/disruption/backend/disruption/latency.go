@@ -0,0 +1,97 @@
+package disruption
+
+import (
+	"github.com/openshift/origin/pkg/disruption/backend"
+	backendsampler "github.com/openshift/origin/pkg/disruption/backend/sampler"
+	"github.com/openshift/origin/pkg/monitor/backenddisruption"
+	"github.com/openshift/origin/pkg/monitor/disruptionlatency"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+)
+
+// NewLatencyTracker returns a SampleCollector that counts the latency of
+// the successful samples in the histograms of the backend, and records
+// a degraded interval when the backend keeps responding slower than
+// the given SLO.  When the sampler stops, it records the interval that
+// carries the latency of the backend.
+//
+//	delegate: the next SampleCollector in the chain to be invoked
+//	descriptor: the disruption test, it locates the intervals
+//	slo: the latency the backend is expected to respond within
+//	monitorRecorder: Monitor API to record the intervals in CI
+func NewLatencyTracker(delegate backendsampler.SampleCollector, descriptor backend.TestDescriptor, slo backenddisruption.LatencySLO,
+	monitorRecorder monitorapi.RecorderWriter) (backendsampler.SampleCollector, backend.WantEventRecorderAndMonitorRecorder) {
+	t := &latencyTracker{
+		delegate:        delegate,
+		tracker:         backenddisruption.NewLatencyTracker(slo, descriptor.DisruptionLocator(), descriptor.Name(), descriptor.GetConnectionType()),
+		monitorRecorder: monitorRecorder,
+	}
+	return t, t
+}
+
+var _ backend.WantEventRecorderAndMonitorRecorder = &latencyTracker{}
+
+type latencyTracker struct {
+	delegate        backendsampler.SampleCollector
+	tracker         *backenddisruption.LatencyTracker
+	monitorRecorder monitorapi.RecorderWriter
+
+	last *backend.SampleResult
+}
+
+// SetEventRecorder is a no-op, the latency tracker does not create events
+func (t *latencyTracker) SetEventRecorder(events.EventRecorder) {}
+
+// SetMonitor sets the interval recorder provided by the monitor API
+func (t *latencyTracker) SetMonitorRecorder(monitorRecorder monitorapi.RecorderWriter) {
+	t.monitorRecorder = monitorRecorder
+}
+
+func (t *latencyTracker) Collect(bs backend.SampleResult) {
+	// we receive sample in ordered sequence, 1, 2, ... n
+	if t.delegate != nil {
+		t.delegate.Collect(bs)
+	}
+	t.collect(bs)
+}
+
+func (t *latencyTracker) collect(result backend.SampleResult) {
+	if result.Sample == nil {
+		// no more sample arriving
+		if t.last == nil {
+			return
+		}
+		intervals, err := t.tracker.Finish(t.last.Sample.FinishedAt)
+		if err != nil {
+			utilruntime.HandleError(err)
+		}
+		t.monitorRecorder.AddIntervals(intervals...)
+		return
+	}
+
+	t.last = &result
+	if degraded := t.tracker.Observe(result.Sample.StartedAt, sampleLatency(result), result.Err()); degraded != nil {
+		klog.V(4).Info(degraded.Message.HumanMessage)
+		t.monitorRecorder.AddIntervals(*degraded)
+	}
+}
+
+// sampleLatency returns the latency of the sample from the client trace
+// of the request, or from the probe.
+func sampleLatency(result backend.SampleResult) disruptionlatency.SampleLatency {
+	if probe := result.Probe; probe != nil {
+		return disruptionlatency.SampleLatency{
+			Connect: probe.ConnectDuration,
+			Total:   probe.RoundTripDuration,
+		}
+	}
+	return disruptionlatency.SampleLatency{
+		DNS:     result.DNSDuration,
+		Connect: result.ConnectDuration,
+		TLS:     result.TLSHandshakeDuration,
+		TTFB:    result.TimeToFirstByte,
+		Total:   result.RoundTripDuration,
+	}
+}
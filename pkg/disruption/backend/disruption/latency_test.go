@@ -0,0 +1,83 @@
+package disruption
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/disruption/backend"
+	"github.com/openshift/origin/pkg/disruption/sampler"
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/backenddisruption"
+	"github.com/openshift/origin/pkg/monitor/disruptionlatency"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestLatencyTracker(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	probeSample := func(id int, roundTrip time.Duration, err error) backend.SampleResult {
+		startedAt := start.Add(time.Duration(id) * time.Second)
+		return backend.SampleResult{
+			Sample: &sampler.Sample{ID: uint64(id), StartedAt: startedAt, FinishedAt: startedAt.Add(roundTrip), Err: err},
+			RequestResponse: backend.RequestResponse{
+				Probe: &backend.ProbeResponse{Protocol: backend.ProtocolTCP, RoundTripDuration: roundTrip},
+			},
+		}
+	}
+
+	recorder := monitor.NewRecorder()
+	slo := backenddisruption.LatencySLO{Threshold: 2 * time.Second, SustainedFor: 2 * time.Second}
+	collector, want := NewLatencyTracker(nil, fakeDescriptor{}, slo, nil)
+	want.SetMonitorRecorder(recorder)
+	for _, result := range []backend.SampleResult{
+		probeSample(0, 100*time.Millisecond, nil),
+		probeSample(1, 3*time.Second, nil),
+		probeSample(2, 3*time.Second, nil),
+		probeSample(3, 3*time.Second, nil),
+		probeSample(4, time.Second, fmt.Errorf("connection refused")),
+		probeSample(5, 200*time.Millisecond, nil),
+		{Sample: nil},
+	} {
+		collector.Collect(result)
+	}
+
+	intervals := recorder.Intervals(time.Time{}, time.Time{})
+	degraded := intervals.Filter(backenddisruption.IsLatencyDegraded)
+	if len(degraded) != 1 {
+		t.Fatalf("expected one degraded interval, got %v", degraded)
+	}
+	if !degraded[0].From.Equal(start.Add(time.Second)) || !degraded[0].To.Equal(start.Add(4*time.Second)) {
+		t.Errorf("unexpected degraded interval %v", degraded[0])
+	}
+
+	summaries := intervals.Filter(disruptionlatency.IsLatencySummary)
+	if len(summaries) != 1 {
+		t.Fatalf("expected one latency summary, got %v", summaries)
+	}
+	latency, err := disruptionlatency.FromSummaryInterval(summaries[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latency.BackendName != "fake-backend" || latency.Total.Count != 5 || latency.Connect.Count != 0 {
+		t.Errorf("unexpected latency %+v", latency)
+	}
+	if !summaries[0].To.Equal(start.Add(5*time.Second + 200*time.Millisecond)) {
+		t.Errorf("expected the summary to end with the last sample, got %v", summaries[0])
+	}
+}
+
+type fakeDescriptor struct{}
+
+func (fakeDescriptor) Name() string { return "fake-backend" }
+func (fakeDescriptor) DisruptionLocator() monitorapi.Locator {
+	return monitorapi.NewLocator().LocateDisruptionCheck("fake-backend", "fake", monitorapi.NewConnectionType)
+}
+func (fakeDescriptor) ShutdownLocator() monitorapi.Locator { return monitorapi.Locator{} }
+func (fakeDescriptor) GetLoadBalancerType() backend.LoadBalancerType {
+	return backend.ServiceNetworkType
+}
+func (fakeDescriptor) GetProtocol() backend.ProtocolType { return backend.ProtocolTCP }
+func (fakeDescriptor) GetConnectionType() monitorapi.BackendConnectionType {
+	return monitorapi.NewConnectionType
+}
+func (fakeDescriptor) GetTargetServerName() string { return "fake" }
@@ -0,0 +1,53 @@
+package backend
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProbeResponse holds the result of a probe sent to the backend over a
+// protocol other than HTTP, it takes the place of the HTTP request and
+// response of a RequestResponse.
+type ProbeResponse struct {
+	// Protocol is the protocol the probe was sent over
+	Protocol ProtocolType
+
+	// Target is the address the probe was sent to
+	Target string
+
+	// ConnectionReused is true if the probe was sent over a
+	// connection opened for an earlier probe.
+	ConnectionReused bool
+
+	// RoundTripDuration is the latency incurred by the probe.
+	RoundTripDuration time.Duration
+
+	// ConnectDuration is how long it took to open the connection,
+	// it is zero if the connection was reused or the protocol
+	// does not dial before sending the probe.
+	ConnectDuration time.Duration
+
+	// Sent is what the probe sent that the backend is expected to
+	// answer with, it is empty if the answer is not an echo.
+	Sent string
+
+	// Answer is what the backend answered: the echoed bytes for
+	// TCP, the resolved addresses for DNS, and the serving
+	// status for gRPC.
+	Answer string
+}
+
+func (p ProbeResponse) String() string {
+	return fmt.Sprintf("protocol=%s target=%s conn-reused=%t roundtrip=%s answer=%q",
+		p.Protocol, p.Target, p.ConnectionReused, p.RoundTripDuration.Round(time.Millisecond), p.Answer)
+}
+
+func (p ProbeResponse) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"protocol":    p.Protocol,
+		"target":      p.Target,
+		"conn-reused": p.ConnectionReused,
+		"roundtrip":   p.RoundTripDuration.Round(time.Millisecond),
+		"answer":      p.Answer,
+	}
+}
@@ -21,9 +21,16 @@ type RequestResponse struct {
 	// RequestContextAssociatedData holds the data
 	// stored in the request context.
 	RequestContextAssociatedData
+
+	// Probe is set instead of Request and Response when the
+	// backend is sampled over a protocol other than HTTP.
+	Probe *ProbeResponse
 }
 
 func (rr RequestResponse) String() string {
+	if rr.Probe != nil {
+		return rr.Probe.String()
+	}
 	s := fmt.Sprintf("audit-id=%s conn-reused=%s status-code=%s protocol=%s roundtrip=%s retry-after=%s",
 		rr.GetAuditID(), rr.ConnectionReused(), rr.StatusCode(), rr.Protocol(), rr.RoundTripDuration.Round(time.Millisecond), rr.RetryAfter())
 	if rr.ShutdownResponse != nil {
@@ -33,6 +40,9 @@ func (rr RequestResponse) String() string {
 }
 
 func (rr RequestResponse) Fields() map[string]interface{} {
+	if rr.Probe != nil {
+		return rr.Probe.Fields()
+	}
 	fields := map[string]interface{}{}
 	fields["audit-id"] = rr.GetAuditID()
 	fields["conn-reused"] = rr.ConnectionReused()
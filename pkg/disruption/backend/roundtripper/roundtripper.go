@@ -2,6 +2,7 @@ package roundtripper
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
@@ -89,6 +90,9 @@ func WithAuditID(delegate backend.Client) backend.Client {
 //	 DNSDone: this client trace is called when a DNS lookup ends, and we
 //	   can obtain the error that occurred during the DNS lookup, if any.
 //
+// It also times the DNS lookup, connect and TLS handshake, and the time
+// to the first byte of the response.
+//
 // This function will attach the data obtained from the client trace
 // to the request context so it can be retrieved later.
 func WithGotConnTrace(delegate backend.Client) backend.Client {
@@ -98,6 +102,16 @@ func WithGotConnTrace(delegate backend.Client) backend.Client {
 	lock := sync.Mutex{}
 
 	return backend.ClientFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		var dnsStart, connectStart, tlsStart time.Time
+		// phaseDone adds to the phase, dialing happens once per address tried.
+		phaseDone := func(phaseStart time.Time, phase func(*backend.RequestContextAssociatedData) *time.Duration) {
+			lock.Lock()
+			defer lock.Unlock()
+			if data := backend.RequestContextAssociatedDataFrom(req.Context()); data != nil && !phaseStart.IsZero() {
+				*phase(data) += time.Since(phaseStart)
+			}
+		}
 		trace := &httptrace.ClientTrace{
 			GotConn: func(ci httptrace.GotConnInfo) {
 				connInfo := &backend.GotConnInfo{}
@@ -112,12 +126,37 @@ func WithGotConnTrace(delegate backend.Client) backend.Client {
 				}
 				lock.Unlock()
 			},
+			DNSStart: func(httptrace.DNSStartInfo) {
+				lock.Lock()
+				dnsStart = time.Now()
+				lock.Unlock()
+			},
 			DNSDone: func(d httptrace.DNSDoneInfo) {
 				lock.Lock()
 				if data := backend.RequestContextAssociatedDataFrom(req.Context()); data != nil {
 					data.DNSErr = d.Err
 				}
 				lock.Unlock()
+				phaseDone(dnsStart, func(data *backend.RequestContextAssociatedData) *time.Duration { return &data.DNSDuration })
+			},
+			ConnectStart: func(_, _ string) {
+				lock.Lock()
+				connectStart = time.Now()
+				lock.Unlock()
+			},
+			ConnectDone: func(_, _ string, _ error) {
+				phaseDone(connectStart, func(data *backend.RequestContextAssociatedData) *time.Duration { return &data.ConnectDuration })
+			},
+			TLSHandshakeStart: func() {
+				lock.Lock()
+				tlsStart = time.Now()
+				lock.Unlock()
+			},
+			TLSHandshakeDone: func(tls.ConnectionState, error) {
+				phaseDone(tlsStart, func(data *backend.RequestContextAssociatedData) *time.Duration { return &data.TLSHandshakeDuration })
+			},
+			GotFirstResponseByte: func() {
+				phaseDone(start, func(data *backend.RequestContextAssociatedData) *time.Duration { return &data.TimeToFirstByte })
 			},
 		}
 		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
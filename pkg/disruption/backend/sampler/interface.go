@@ -50,3 +50,30 @@ func (c ResponseCheckerFunc) CheckResponse(rr backend.RequestResponse) error {
 type SampleCollector interface {
 	Collect(backend.SampleResult)
 }
+
+// Prober is the equivalent of Requestor for backends that are not sampled
+// over HTTP, it sends a single probe to the backend and returns what the
+// backend answered.  A Prober that also implements io.Closer is closed
+// once sampling has stopped and the last probe returned.
+type Prober interface {
+	// GetBaseURL returns the address of the backend as a URL with the
+	// protocol as the scheme, for instance tcp://10.0.0.1:8080
+	GetBaseURL() string
+
+	// Probe sends a new probe, it can use the given sample ID to
+	// generate a unique probe.  It returns an error if the probe
+	// could not be sent or no answer was received.
+	Probe(ctx context.Context, sampleID uint64) (*backend.ProbeResponse, error)
+}
+
+// ProbeChecker is the equivalent of ResponseChecker for probes, it checks
+// the answer the backend sent to a probe.
+// If it returns an error, the sample is deemed to have failed.
+type ProbeChecker interface {
+	// CheckError checks the the given error for any known types
+	CheckError(error) error
+
+	// CheckProbe checks the answer to the probe, the given
+	// ProbeResponse is never nil.
+	CheckProbe(*backend.ProbeResponse) error
+}
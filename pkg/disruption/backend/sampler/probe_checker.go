@@ -0,0 +1,48 @@
+package sampler
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/openshift/origin/pkg/disruption/backend"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func NewProbeChecker() ProbeChecker {
+	return &probeChecker{}
+}
+
+type probeChecker struct{}
+
+func (c probeChecker) CheckProbe(probe *backend.ProbeResponse) error {
+	switch probe.Protocol {
+	case backend.ProtocolTCP:
+		if len(probe.Sent) > 0 && probe.Answer != probe.Sent {
+			return &KnownError{
+				category: "TCPEchoMismatch",
+				err:      fmt.Errorf("expected the backend to echo %q, got %q", probe.Sent, probe.Answer),
+			}
+		}
+	case backend.ProtocolDNS:
+		if len(probe.Answer) == 0 {
+			return &KnownError{category: "DNSError", err: fmt.Errorf("no addresses resolved")}
+		}
+	case backend.ProtocolGRPC:
+		if probe.Answer != healthpb.HealthCheckResponse_SERVING.String() {
+			return &KnownError{
+				category: "GRPCServiceNotServing",
+				err:      fmt.Errorf("unexpected health status: %s", probe.Answer),
+			}
+		}
+	}
+	return nil
+}
+
+func (c probeChecker) CheckError(err error) error {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return &KnownError{category: "DNSError", err: err}
+	}
+	return err
+}
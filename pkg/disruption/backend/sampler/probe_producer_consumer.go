@@ -0,0 +1,69 @@
+package sampler
+
+import (
+	"context"
+	"io"
+
+	"github.com/openshift/origin/pkg/disruption/backend"
+	"github.com/openshift/origin/pkg/disruption/sampler"
+	"k8s.io/klog/v2"
+)
+
+// NewProbeProducerConsumer returns a ProducerConsumer for backends that
+// are not sampled over HTTP, the Producer sends a probe to the backend
+// using the given Prober, and the consumer feeds the result of the probe
+// to the specified SampleCollector for further analysis.
+//
+//	prober: a Prober that can send a probe to the backend
+//	 over the desired protocol.
+//	checker: the ProbeChecker can check the answer and determine
+//	 whether this probe should be treated as a failure.
+//	collector: user specified SampleCollector that will collect each
+//	 sample result for further analysis.
+func NewProbeProducerConsumer(prober Prober, checker ProbeChecker, collector SampleCollector) sampler.ProducerConsumer {
+	return &probeProducerConsumer{
+		prober:    prober,
+		checker:   checker,
+		collector: collector,
+	}
+}
+
+type probeProducerConsumer struct {
+	prober    Prober
+	checker   ProbeChecker
+	collector SampleCollector
+}
+
+func (pc *probeProducerConsumer) Produce(stop context.Context, sampleID uint64) (interface{}, error) {
+	rr := backend.RequestResponse{}
+
+	// the prober sets the deadline of the probe, like the round tripper
+	// does for HTTP requests we don't want a probe in progress to be
+	// canceled by the stop context.
+	probe, err := pc.prober.Probe(context.Background(), sampleID)
+	rr.Probe = probe
+	if err != nil {
+		return rr, pc.checker.CheckError(err)
+	}
+	return rr, pc.checker.CheckProbe(probe)
+}
+
+func (pc probeProducerConsumer) Consume(s *sampler.Sample, custom interface{}) {
+	// should never happen, we panic if for some programmer error
+	rr := custom.(backend.RequestResponse)
+	pc.collector.Collect(backend.SampleResult{
+		Sample:          s,
+		RequestResponse: rr,
+	})
+}
+
+func (pc probeProducerConsumer) Close() {
+	// the producer is done, release the connections the prober reuses
+	if closer, ok := pc.prober.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			klog.Errorf("failed to close prober for %s: %v", pc.prober.GetBaseURL(), err)
+		}
+	}
+	// no more sample available, send an empty value
+	pc.collector.Collect(backend.SampleResult{})
+}
@@ -0,0 +1,254 @@
+package sampler
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/disruption/backend"
+	"github.com/openshift/origin/pkg/disruption/sampler"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestTCPProbeProducer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					// sample 3 gets the wrong answer
+					if strings.TrimSpace(line) == "sample-id=3" {
+						line = "sample-id=42\n"
+					}
+					if _, err := conn.Write([]byte(line)); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	for _, reuse := range []bool{false, true} {
+		var producer sampler.Producer
+		producer = NewProbeProducerConsumer(NewTCPProber(listener.Addr().String(), true, reuse, 5*time.Second), NewProbeChecker(), nil)
+
+		for _, sampleID := range []uint64{1, 2} {
+			info, err := producer.Produce(context.TODO(), sampleID)
+			if err != nil {
+				t.Fatalf("reuse=%t: expected no error, but got: %v", reuse, err)
+			}
+			probe := info.(backend.RequestResponse).Probe
+			if probe == nil {
+				t.Fatalf("reuse=%t: expected the probe, but got nil", reuse)
+			}
+			if want := fmt.Sprintf("sample-id=%d", sampleID); probe.Answer != want {
+				t.Errorf("reuse=%t: expected answer %q, but got %q", reuse, want, probe.Answer)
+			}
+			if wantReused := reuse && sampleID > 1; probe.ConnectionReused != wantReused {
+				t.Errorf("reuse=%t: expected sample %d to have conn-reused=%t", reuse, sampleID, wantReused)
+			}
+		}
+
+		_, err := producer.Produce(context.TODO(), 3)
+		var known *KnownError
+		if !errors.As(err, &known) || known.Category() != "TCPEchoMismatch" {
+			t.Errorf("reuse=%t: expected a TCPEchoMismatch error, but got: %v", reuse, err)
+		}
+	}
+
+	listener.Close()
+	producer := NewProbeProducerConsumer(NewTCPProber(listener.Addr().String(), true, false, time.Second), NewProbeChecker(), nil)
+	if _, err := producer.Produce(context.TODO(), 4); err == nil {
+		t.Errorf("expected an error once the listener is closed")
+	}
+}
+
+func TestTCPProbeWithoutEchoNoticesClosedConnection(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		accepted <- conn
+	}()
+
+	var producer sampler.Producer
+	producer = NewProbeProducerConsumer(NewTCPProber(listener.Addr().String(), false, true, 5*time.Second), NewProbeChecker(), nil)
+	for _, sampleID := range []uint64{1, 2} {
+		if _, err := producer.Produce(context.TODO(), sampleID); err != nil {
+			t.Fatalf("expected no error for sample %d, but got: %v", sampleID, err)
+		}
+	}
+
+	// the backend goes away, the reused connection must not keep looking available
+	(<-accepted).Close()
+	listener.Close()
+	if _, err := producer.Produce(context.TODO(), 3); err == nil {
+		t.Errorf("expected an error once the server closed the connection")
+	}
+}
+
+func TestDNSProbeProducer(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer conn.Close()
+	go serveDNS(conn, net.IPv4(10, 0, 0, 10).To4())
+
+	var producer sampler.Producer
+	producer = NewProbeProducerConsumer(NewDNSProber(conn.LocalAddr().String(), "kubernetes.default.svc.cluster.local", 5*time.Second), NewProbeChecker(), nil)
+	info, err := producer.Produce(context.TODO(), 1)
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	probe := info.(backend.RequestResponse).Probe
+	if probe.Answer != "10.0.0.10" {
+		t.Errorf("expected answer 10.0.0.10, but got %q", probe.Answer)
+	}
+	if probe.Protocol != backend.ProtocolDNS {
+		t.Errorf("expected protocol %s, but got %s", backend.ProtocolDNS, probe.Protocol)
+	}
+}
+
+// serveDNS answers A queries with ip, and all other queries with no records.
+func serveDNS(conn net.PacketConn, ip net.IP) {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		query := buf[:n]
+		// the question starts after the 12 byte header, the name is a
+		// sequence of length prefixed labels ending with a zero length.
+		end := 12
+		for end < len(query) && query[end] != 0 {
+			end += int(query[end]) + 1
+		}
+		end += 1 + 4
+		if end > len(query) {
+			continue
+		}
+		qtype := binary.BigEndian.Uint16(query[end-4:])
+
+		resp := make([]byte, 12, 512)
+		copy(resp, query[:2])
+		binary.BigEndian.PutUint16(resp[2:], 0x8180) // response, recursion desired and available
+		binary.BigEndian.PutUint16(resp[4:], 1)
+		resp = append(resp, query[12:end]...)
+		if qtype == 1 {
+			binary.BigEndian.PutUint16(resp[6:], 1)
+			resp = append(resp,
+				0xc0, 12, // the name of the question
+				0, 1, // A
+				0, 1, // IN
+				0, 0, 0, 30, // ttl
+				0, 4) // length
+			resp = append(resp, ip...)
+		}
+		if _, err := conn.WriteTo(resp, addr); err != nil {
+			return
+		}
+	}
+}
+
+func TestGRPCProbeProducer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	healthServer.SetServingStatus("test", healthpb.HealthCheckResponse_SERVING)
+	for _, reuse := range []bool{false, true} {
+		var producer sampler.Producer
+		producer = NewProbeProducerConsumer(NewGRPCProber(listener.Addr().String(), "test", reuse, 5*time.Second), NewProbeChecker(), nil)
+		for _, sampleID := range []uint64{1, 2} {
+			info, err := producer.Produce(context.TODO(), sampleID)
+			if err != nil {
+				t.Fatalf("reuse=%t: expected no error, but got: %v", reuse, err)
+			}
+			probe := info.(backend.RequestResponse).Probe
+			if wantReused := reuse && sampleID > 1; probe.ConnectionReused != wantReused {
+				t.Errorf("reuse=%t: expected sample %d to have conn-reused=%t", reuse, sampleID, wantReused)
+			}
+		}
+	}
+
+	healthServer.SetServingStatus("test", healthpb.HealthCheckResponse_NOT_SERVING)
+	producer := NewProbeProducerConsumer(NewGRPCProber(listener.Addr().String(), "test", false, 5*time.Second), NewProbeChecker(), nil)
+	_, err = producer.Produce(context.TODO(), 3)
+	var known *KnownError
+	if !errors.As(err, &known) || known.Category() != "GRPCServiceNotServing" {
+		t.Errorf("expected a GRPCServiceNotServing error, but got: %v", err)
+	}
+}
+
+type discardCollector struct{}
+
+func (discardCollector) Collect(backend.SampleResult) {}
+
+func TestProbeConsumerClosesProber(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	defer server.Stop()
+	healthServer.SetServingStatus("test", healthpb.HealthCheckResponse_SERVING)
+
+	prober := NewGRPCProber(listener.Addr().String(), "test", true, 5*time.Second)
+	pc := NewProbeProducerConsumer(prober, NewProbeChecker(), discardCollector{})
+	if _, err := pc.Produce(context.TODO(), 1); err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	conn := prober.conn
+	if conn == nil {
+		t.Fatalf("expected the connection to be kept for reuse")
+	}
+
+	pc.Close()
+	if prober.conn != nil {
+		t.Errorf("expected the prober to release the connection")
+	}
+	if state := conn.GetState(); state != connectivity.Shutdown {
+		t.Errorf("expected the connection to be closed, but it is %s", state)
+	}
+}
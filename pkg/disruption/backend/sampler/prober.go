@@ -0,0 +1,300 @@
+package sampler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/disruption/backend"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// NewTCPProber returns a new Prober that connects to the given address,
+// and if echo is true writes a line with the sample ID to the connection
+// and expects the backend to echo it back:
+//
+//	sample-id=1\n
+//
+// If reuseConnection is true, the connection opened by the first probe is
+// used by the following ones until it fails, otherwise every probe opens
+// a new connection.  Without echo, a probe of a reused connection fails
+// once the backend has closed or reset it.
+func NewTCPProber(address string, echo, reuseConnection bool, timeout time.Duration) *tcpProber {
+	return &tcpProber{
+		address:         address,
+		echo:            echo,
+		reuseConnection: reuseConnection,
+		timeout:         timeout,
+	}
+}
+
+type tcpProber struct {
+	address         string
+	echo            bool
+	reuseConnection bool
+	timeout         time.Duration
+
+	// the reused connection, a probe holds the lock while it uses it
+	lock sync.Mutex
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+func (p *tcpProber) GetBaseURL() string {
+	return fmt.Sprintf("%s://%s", backend.ProtocolTCP, p.address)
+}
+
+func (p *tcpProber) Probe(ctx context.Context, sampleID uint64) (*backend.ProbeResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	probe := &backend.ProbeResponse{Protocol: backend.ProtocolTCP, Target: p.address}
+	var conn net.Conn
+	var rw *bufio.ReadWriter
+	if p.reuseConnection {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		conn, rw = p.conn, p.rw
+	}
+
+	// waiting for a probe in progress to release the connection is not
+	// part of the round trip.
+	start := time.Now()
+	defer func() {
+		probe.RoundTripDuration = time.Since(start)
+	}()
+	if conn == nil {
+		var err error
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", p.address)
+		probe.ConnectDuration = time.Since(start)
+		if err != nil {
+			return probe, err
+		}
+		rw = bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	} else {
+		probe.ConnectionReused = true
+	}
+
+	err := p.exchange(ctx, conn, rw, sampleID, probe)
+	switch {
+	case !p.reuseConnection:
+		conn.Close()
+	case err != nil:
+		// a connection that failed can be in any state, the next
+		// probe opens a new one.
+		conn.Close()
+		p.conn, p.rw = nil, nil
+	default:
+		p.conn, p.rw = conn, rw
+	}
+	return probe, err
+}
+
+// Close closes the reused connection, if any.
+func (p *tcpProber) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn, p.rw = nil, nil
+	return err
+}
+
+func (p *tcpProber) exchange(ctx context.Context, conn net.Conn, rw *bufio.ReadWriter, sampleID uint64, probe *backend.ProbeResponse) error {
+	if !p.echo {
+		if !probe.ConnectionReused {
+			// the connection was just opened
+			return nil
+		}
+		return checkOpen(conn, rw)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	probe.Sent = fmt.Sprintf("sample-id=%d", sampleID)
+	if _, err := rw.WriteString(probe.Sent + "\n"); err != nil {
+		return err
+	}
+	if err := rw.Flush(); err != nil {
+		return err
+	}
+	line, err := rw.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	probe.Answer = strings.TrimSuffix(line, "\n")
+	return nil
+}
+
+// openCheckTimeout is how long a probe without echo reads from a reused
+// connection to find out whether the backend closed it.
+const openCheckTimeout = 10 * time.Millisecond
+
+// checkOpen returns an error if the backend closed or reset conn.  Without
+// echo nothing is sent, so a read that times out means the connection is
+// still open, while the read of a closed or reset connection fails right
+// away.  Anything the backend sent is discarded.
+func checkOpen(conn net.Conn, rw *bufio.ReadWriter) error {
+	if _, err := rw.Discard(rw.Reader.Buffered()); err != nil {
+		return err
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(openCheckTimeout)); err != nil {
+		return err
+	}
+	if _, err := rw.ReadByte(); err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+		return err
+	}
+	return conn.SetReadDeadline(time.Time{})
+}
+
+// NewDNSProber returns a new Prober that resolves the given name using
+// the DNS server at the given address, for instance the service IP of
+// dns-default.  Every probe sends new queries over UDP.
+func NewDNSProber(server, name string, timeout time.Duration) *dnsProber {
+	if !strings.HasSuffix(name, ".") {
+		// we want the name to be resolved as is, not relative
+		// to the search domains of the host.
+		name = name + "."
+	}
+	return &dnsProber{
+		server:  server,
+		name:    name,
+		timeout: timeout,
+		resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "udp", server)
+			},
+		},
+	}
+}
+
+type dnsProber struct {
+	server   string
+	name     string
+	timeout  time.Duration
+	resolver *net.Resolver
+}
+
+func (p *dnsProber) GetBaseURL() string {
+	return fmt.Sprintf("%s://%s/%s", backend.ProtocolDNS, p.server, p.name)
+}
+
+func (p *dnsProber) Probe(ctx context.Context, sampleID uint64) (*backend.ProbeResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	probe := &backend.ProbeResponse{Protocol: backend.ProtocolDNS, Target: p.server}
+	start := time.Now()
+	addrs, err := p.resolver.LookupHost(ctx, p.name)
+	probe.RoundTripDuration = time.Since(start)
+	if err != nil {
+		return probe, err
+	}
+	sort.Strings(addrs)
+	probe.Answer = strings.Join(addrs, ",")
+	return probe, nil
+}
+
+// NewGRPCProber returns a new Prober that calls the gRPC health service
+// at the given address for the given service, an empty service asks for
+// the health of the server as a whole.  If reuseConnection is true, all
+// probes share a single client connection, otherwise every probe opens
+// a new one.
+func NewGRPCProber(address, service string, reuseConnection bool, timeout time.Duration) *grpcProber {
+	return &grpcProber{
+		address:         address,
+		service:         service,
+		reuseConnection: reuseConnection,
+		timeout:         timeout,
+	}
+}
+
+type grpcProber struct {
+	address         string
+	service         string
+	reuseConnection bool
+	timeout         time.Duration
+
+	lock sync.Mutex
+	conn *grpc.ClientConn
+}
+
+func (p *grpcProber) GetBaseURL() string {
+	return fmt.Sprintf("%s://%s/%s", backend.ProtocolGRPC, p.address, p.service)
+}
+
+func (p *grpcProber) Probe(ctx context.Context, sampleID uint64) (*backend.ProbeResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	probe := &backend.ProbeResponse{Protocol: backend.ProtocolGRPC, Target: p.address}
+	conn, reused, err := p.getConn()
+	if err != nil {
+		return probe, err
+	}
+	if !p.reuseConnection {
+		defer conn.Close()
+	}
+	probe.ConnectionReused = reused
+
+	start := time.Now()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: p.service})
+	probe.RoundTripDuration = time.Since(start)
+	if err != nil {
+		return probe, err
+	}
+	probe.Answer = resp.GetStatus().String()
+	return probe, nil
+}
+
+// getConn returns the shared client connection if connections are
+// reused, the client connection reconnects on its own when the
+// backend drops it.
+func (p *grpcProber) getConn() (*grpc.ClientConn, bool, error) {
+	if !p.reuseConnection {
+		conn, err := p.newConn()
+		return conn, false, err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.conn != nil {
+		return p.conn, true, nil
+	}
+	conn, err := p.newConn()
+	if err != nil {
+		return nil, false, err
+	}
+	p.conn = conn
+	return conn, false, nil
+}
+
+// Close closes the shared client connection, if any.
+func (p *grpcProber) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
+
+func (p *grpcProber) newConn() (*grpc.ClientConn, error) {
+	return grpc.NewClient(p.address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
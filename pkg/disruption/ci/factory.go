@@ -14,6 +14,7 @@ import (
 	"github.com/openshift/origin/pkg/disruption/backend/shutdown"
 	"github.com/openshift/origin/pkg/disruption/backend/transport"
 	"github.com/openshift/origin/pkg/disruption/sampler"
+	"github.com/openshift/origin/pkg/monitor/backenddisruption"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 
 	"k8s.io/client-go/rest"
@@ -24,6 +25,8 @@ type ServerNameType string
 const (
 	KubeAPIServer      ServerNameType = "kube-api"
 	OpenShiftAPIServer ServerNameType = "openshift-api"
+	// ClusterDNS is the dns-default service of the cluster DNS operator
+	ClusterDNS ServerNameType = "cluster-dns"
 )
 
 // Factory creates a new instance of a Disruption test from
@@ -66,6 +69,10 @@ type TestConfiguration struct {
 	// response header extractor, this should be true only when the
 	// request(s) are being sent to the kube-apiserver.
 	EnableShutdownResponseHeader bool
+
+	// LatencySLO is the latency the target server is expected to respond
+	// within, backenddisruption.DefaultLatencySLO is used if it is not set.
+	LatencySLO *backenddisruption.LatencySLO
 }
 
 // TestDescriptor defines the disruption test type, the user must
@@ -83,11 +90,31 @@ type TestDescriptor struct {
 	ConnectionType monitorapi.BackendConnectionType
 
 	// Protocol specifies the protocol used by the test,
-	// whether it is http/1x or http/2.0
+	// whether it is http/1x or http/2.0, or tcp, dns or grpc
+	// for tests that do not sample the server over HTTP.
 	Protocol backend.ProtocolType
+
+	// BackendName overrides the disruption backend name that is
+	// otherwise derived from the fields above.
+	BackendName string
+}
+
+// HasLatencySLO is true if LatencySLO is set rather than defaulted.
+func (c TestConfiguration) HasLatencySLO() bool {
+	return c.LatencySLO != nil
+}
+
+func (c TestConfiguration) GetLatencySLO() backenddisruption.LatencySLO {
+	if c.LatencySLO == nil {
+		return backenddisruption.DefaultLatencySLO
+	}
+	return *c.LatencySLO
 }
 
 func (t TestDescriptor) Name() string {
+	if len(t.BackendName) > 0 {
+		return t.BackendName
+	}
 	return fmt.Sprintf("%s-%s-%s-%s-connections", t.TargetServer, t.Protocol, t.LoadBalancerType, t.ConnectionType)
 }
 
@@ -185,6 +212,7 @@ func (b *testFactory) New(c TestConfiguration) (Sampler, error) {
 
 	// we don't have access to the monitor and event recorder yet
 	collector, want := disruption.NewIntervalTracker(b.sharedShutdownInterval, c, nil, nil)
+	collector, wantLatency := disruption.NewLatencyTracker(collector, c, c.GetLatencySLO(), nil)
 	collector = logger.NewLogger(collector, c)
 
 	pc := backendsampler.NewSampleProducerConsumer(client, requestor, backendsampler.NewResponseChecker(), collector)
@@ -192,7 +220,7 @@ func (b *testFactory) New(c TestConfiguration) (Sampler, error) {
 	backendSampler := &BackendSampler{
 		TestConfiguration:           c,
 		SampleRunner:                runner,
-		wantEventRecorderAndMonitor: []backend.WantEventRecorderAndMonitorRecorder{b.wantMonitorAndRecorder, want},
+		wantEventRecorderAndMonitor: []backend.WantEventRecorderAndMonitorRecorder{b.wantMonitorAndRecorder, want, wantLatency},
 		baseURL:                     requestor.GetBaseURL(),
 		hostNameDecoder:             b.hostNameDecoder,
 	}
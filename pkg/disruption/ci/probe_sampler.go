@@ -0,0 +1,73 @@
+package ci
+
+import (
+	"fmt"
+
+	"github.com/openshift/origin/pkg/disruption/backend"
+	"github.com/openshift/origin/pkg/disruption/backend/disruption"
+	"github.com/openshift/origin/pkg/disruption/backend/logger"
+	backendsampler "github.com/openshift/origin/pkg/disruption/backend/sampler"
+	"github.com/openshift/origin/pkg/disruption/sampler"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// NewTCPSampler returns a disruption test that connects to the given
+// address, and if echo is true expects the server to echo what is
+// written to it.  Path is not used.
+func NewTCPSampler(c TestConfiguration, address string, echo bool) (Sampler, error) {
+	if c.Protocol != backend.ProtocolTCP {
+		return nil, fmt.Errorf("expected protocol %q, got %q", backend.ProtocolTCP, c.Protocol)
+	}
+	prober := backendsampler.NewTCPProber(address, echo, c.ConnectionType == monitorapi.ReusedConnectionType, c.Timeout)
+	return NewProbeSampler(c, prober, backendsampler.NewProbeChecker())
+}
+
+// NewDNSSampler returns a disruption test that resolves the given name
+// using the DNS server at the given address, for instance the service
+// IP of dns-default.  Every sample sends new queries, so the connection
+// type must be new.  Path is not used.
+func NewDNSSampler(c TestConfiguration, server, name string) (Sampler, error) {
+	if c.Protocol != backend.ProtocolDNS {
+		return nil, fmt.Errorf("expected protocol %q, got %q", backend.ProtocolDNS, c.Protocol)
+	}
+	if c.ConnectionType != monitorapi.NewConnectionType {
+		return nil, fmt.Errorf("DNS queries are sent over UDP, the connection type must be %q", monitorapi.NewConnectionType)
+	}
+	prober := backendsampler.NewDNSProber(server, name, c.Timeout)
+	return NewProbeSampler(c, prober, backendsampler.NewProbeChecker())
+}
+
+// NewGRPCSampler returns a disruption test that calls the gRPC health
+// service at the given address, Path is the name of the service whose
+// health is checked.
+func NewGRPCSampler(c TestConfiguration, address string) (Sampler, error) {
+	if c.Protocol != backend.ProtocolGRPC {
+		return nil, fmt.Errorf("expected protocol %q, got %q", backend.ProtocolGRPC, c.Protocol)
+	}
+	prober := backendsampler.NewGRPCProber(address, c.Path, c.ConnectionType == monitorapi.ReusedConnectionType, c.Timeout)
+	return NewProbeSampler(c, prober, backendsampler.NewProbeChecker())
+}
+
+// NewProbeSampler returns a disruption test that samples the server
+// with the given Prober instead of HTTP requests.  The test has no
+// shutdown interval tracker and no host name decoder since those
+// rely on response headers of the kube-apiserver.
+func NewProbeSampler(c TestConfiguration, prober backendsampler.Prober, checker backendsampler.ProbeChecker) (Sampler, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	// we don't have access to the monitor and event recorder yet
+	collector, want := disruption.NewIntervalTracker(nil, c, nil, nil)
+	collector, wantLatency := disruption.NewLatencyTracker(collector, c, c.GetLatencySLO(), nil)
+	collector = logger.NewLogger(collector, c)
+
+	pc := backendsampler.NewProbeProducerConsumer(prober, checker, collector)
+	runner := sampler.NewWithProducerConsumer(c.SampleInterval, pc)
+	return &BackendSampler{
+		TestConfiguration:           c,
+		SampleRunner:                runner,
+		wantEventRecorderAndMonitor: []backend.WantEventRecorderAndMonitorRecorder{want, wantLatency},
+		baseURL:                     prober.GetBaseURL(),
+	}, nil
+}
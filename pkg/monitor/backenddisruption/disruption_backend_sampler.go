@@ -16,6 +16,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
+	"github.com/openshift/origin/pkg/monitor/disruptionlatency"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	v1 "k8s.io/api/core/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -65,6 +66,11 @@ type BackendSampler struct {
 	// userAgent used to sets the User-Agent HTTP Header for all requests that are sent by this sampler
 	userAgent string
 
+	// latencySLO is the latency the backend is expected to respond within, DefaultLatencySLO if nil.
+	latencySLO *LatencySLO
+	// sampleInterval is how often the backend is sampled, every second if nil.
+	sampleInterval *time.Duration
+
 	// initHTTPClient ensures we only create the http client once
 	initHTTPClient sync.Once
 	// httpClient is used to connect to the host+path
@@ -212,6 +218,25 @@ func (b *BackendSampler) WithUserAgent(userAgent string) *BackendSampler {
 	return b
 }
 
+// WithLatencySLO sets the latency the backend is expected to respond within.  Samples that succeed but keep taking
+// longer than the SLO produce degraded intervals.
+func (b *BackendSampler) WithLatencySLO(slo LatencySLO) *BackendSampler {
+	b.latencySLO = &slo
+	return b
+}
+
+// WithTimeout sets the timeout of every sample.
+func (b *BackendSampler) WithTimeout(timeout time.Duration) *BackendSampler {
+	b.timeout = &timeout
+	return b
+}
+
+// WithSampleInterval sets how often the backend is sampled.
+func (b *BackendSampler) WithSampleInterval(interval time.Duration) *BackendSampler {
+	b.sampleInterval = &interval
+	return b
+}
+
 // WithExpectedBodyRegex allows a specification of specific body to be returned. This useful when passing through proxies and the
 // like since a connection may not be the one you expect.  If not specified, then the default behavior is that any 2xx
 // or 3xx response is acceptable.
@@ -244,6 +269,25 @@ func (b *BackendSampler) GetConnectionType() monitorapi.BackendConnectionType {
 	return b.connectionType
 }
 
+// HasLatencySLO is true if the SLO was set with WithLatencySLO rather than defaulted.
+func (b *BackendSampler) HasLatencySLO() bool {
+	return b.latencySLO != nil
+}
+
+func (b *BackendSampler) GetLatencySLO() LatencySLO {
+	if b.latencySLO == nil {
+		return DefaultLatencySLO
+	}
+	return *b.latencySLO
+}
+
+func (b *BackendSampler) getSampleInterval() time.Duration {
+	if b.sampleInterval == nil {
+		return 1 * time.Second
+	}
+	return *b.sampleInterval
+}
+
 func (b *BackendSampler) getTimeout() time.Duration {
 	if b.timeout == nil {
 		return 20 * time.Second
@@ -347,23 +391,30 @@ func (b *BackendSampler) GetHTTPClient() (*http.Client, error) {
 
 // CheckConnnection returns the audit request UID and an error if there was one.
 func (b *BackendSampler) CheckConnection(ctx context.Context) (string, error) {
+	uid, _, err := b.checkConnection(ctx)
+	return uid, err
+}
+
+// checkConnection returns the audit request UID, the latency of the request and an error if there was one.
+func (b *BackendSampler) checkConnection(ctx context.Context) (string, disruptionlatency.SampleLatency, error) {
 	httpClient, err := b.GetHTTPClient()
 	if err != nil {
-		return "", err
+		return "", disruptionlatency.SampleLatency{}, err
 	}
 
 	url, err := b.GetURL()
 	if err != nil {
-		return "", err
+		return "", disruptionlatency.SampleLatency{}, err
 	}
 
 	// this is longer than the http client timeout to avoid tripping, but is here to be sure we finish eventually
 	backstopContextTimeout := b.getTimeout() * 3 / 2 // (1.5)
 	requestContext, requestCancel := context.WithTimeout(ctx, backstopContextTimeout)
 	defer requestCancel()
-	req, err := http.NewRequestWithContext(requestContext, http.MethodGet, url, nil)
+	traceContext, trace := newLatencyTrace(requestContext)
+	req, err := http.NewRequestWithContext(traceContext, http.MethodGet, url, nil)
 	if err != nil {
-		return "", err
+		return "", disruptionlatency.SampleLatency{}, err
 	}
 
 	uid := uuid.New().String()
@@ -372,7 +423,7 @@ func (b *BackendSampler) CheckConnection(ctx context.Context) (string, error) {
 	resp, getErr := httpClient.Do(req)
 	if requestContext.Err() == context.Canceled {
 		// this isn't an error, we were simply cancelled
-		return uid, nil
+		return uid, disruptionlatency.SampleLatency{}, nil
 	}
 
 	var body []byte
@@ -383,6 +434,7 @@ func (b *BackendSampler) CheckConnection(ctx context.Context) (string, error) {
 			framework.Logf("error closing body: %v: %v", b.GetLocator(), closeErr)
 		}
 	}
+	latency := trace.done()
 
 	// we don't have an error, but the response code was an error, then we have to set an artificial error for the logic below to work.
 	switch {
@@ -400,7 +452,7 @@ func (b *BackendSampler) CheckConnection(ctx context.Context) (string, error) {
 		}
 	}
 
-	return uid, sampleErr
+	return uid, latency, sampleErr
 }
 
 // RunEndpointMonitoring sets up a client for the given BackendSampler, starts checking the endpoint, and recording
@@ -433,7 +485,7 @@ func (b *BackendSampler) RunEndpointMonitoring(ctx context.Context, monitorRecor
 		eventRecorder = fakeEventRecorder
 	}
 
-	interval := 1 * time.Second
+	interval := b.getSampleInterval()
 	disruptionSampler := newDisruptionSampler(b)
 	go disruptionSampler.produceSamples(samplerContext, interval)
 	go disruptionSampler.consumeSamples(samplerContext, b.consumptionFinished, interval, monitorRecorder, eventRecorder)
@@ -526,9 +578,10 @@ func (b *disruptionSampler) produceSamples(ctx context.Context, interval time.Du
 		// was actually 30s before.
 		currDisruptionSample := b.newSample(ctx)
 		go func() {
-			uid, sampleErr := b.backendSampler.CheckConnection(ctx)
+			uid, latency, sampleErr := b.backendSampler.checkConnection(ctx)
 			currDisruptionSample.setSampleError(sampleErr)
 			currDisruptionSample.setRequestAuditID(uid)
+			currDisruptionSample.setLatency(latency)
 			if sampleErr != nil {
 				// We'd like to include these UUIDs in the backend-disruption.json file but this is
 				// not possible without some work as we're basing everything off intervals today. There is
@@ -541,6 +594,7 @@ func (b *disruptionSampler) produceSamples(ctx context.Context, interval time.Du
 					"backend":       b.backendSampler.GetDisruptionBackendName(),
 					"type":          b.backendSampler.connectionType,
 					"auditID":       uid,
+					"latency":       latency.String(),
 				}).Errorf("disruption sample failed: %v", sampleErr)
 			}
 			close(currDisruptionSample.finished)
@@ -562,6 +616,8 @@ func (b *disruptionSampler) consumeSamples(ctx context.Context, consumerDoneCh c
 	previousError := fmt.Errorf("never checked before")
 	previousIntervalID := -1
 	var previousSampleTime *time.Time
+	latencyTracker := NewLatencyTracker(b.backendSampler.GetLatencySLO(), b.backendSampler.GetLocator(),
+		b.backendSampler.GetDisruptionBackendName(), b.backendSampler.GetConnectionType())
 
 	// when we exit this function, we want to set a final duration of failure.  We don't actually know whether it ended
 	// or how long it took to ask
@@ -569,6 +625,13 @@ func (b *disruptionSampler) consumeSamples(ctx context.Context, consumerDoneCh c
 		if previousIntervalID != -1 && previousSampleTime != nil {
 			monitorRecorder.EndInterval(previousIntervalID, previousSampleTime.Add(interval))
 		}
+		if previousSampleTime != nil {
+			latencyIntervals, err := latencyTracker.Finish(previousSampleTime.Add(interval))
+			if err != nil {
+				utilruntime.HandleError(err)
+			}
+			monitorRecorder.AddIntervals(latencyIntervals...)
+		}
 	}()
 
 	for {
@@ -664,6 +727,11 @@ func (b *disruptionSampler) consumeSamples(ctx context.Context, consumerDoneCh c
 			panic("math broke resulting in this weird error you need to find")
 		}
 
+		if degraded := latencyTracker.Observe(currSampleTime, currSample.getLatency(), currentError); degraded != nil {
+			framework.Logf(degraded.Message.HumanMessage)
+			monitorRecorder.AddIntervals(*degraded)
+		}
+
 		firstSample = false
 		previousError = currentError
 		t := currSampleTime // make sure we get a copy
@@ -703,6 +771,7 @@ type disruptionSample struct {
 	startTime      time.Time
 	sampleErr      error
 	requestAuditID string
+	latency        disruptionlatency.SampleLatency
 
 	finished chan struct{}
 }
@@ -737,3 +806,15 @@ func (s *disruptionSample) getRequestAuditID() string {
 	defer s.lock.Unlock()
 	return s.requestAuditID
 }
+
+func (s *disruptionSample) setLatency(latency disruptionlatency.SampleLatency) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.latency = latency
+}
+
+func (s *disruptionSample) getLatency() disruptionlatency.SampleLatency {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.latency
+}
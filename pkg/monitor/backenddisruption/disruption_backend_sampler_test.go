@@ -11,6 +11,7 @@ import (
 
 	monitor2 "github.com/openshift/origin/pkg/monitor"
 
+	"github.com/openshift/origin/pkg/monitor/disruptionlatency"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	"github.com/stretchr/testify/assert"
 
@@ -159,10 +160,13 @@ func Test_disruptionSampler_consumeSamples(t *testing.T) {
 		estimatedTime   time.Duration
 		produceSamples  func(ctx context.Context, backendSampler *disruptionSampler)
 		validateSamples func(t *testing.T, eventIntervals []monitorapi.Interval) error
+		// successfulSamples are counted by the latency summary recorded when the consumer stops
+		successfulSamples int64
 	}{
 		{
-			name:          "in-order",
-			estimatedTime: 1 * time.Second,
+			name:              "in-order",
+			estimatedTime:     1 * time.Second,
+			successfulSamples: 2,
 			produceSamples: func(ctx context.Context, backendSampler *disruptionSampler) {
 				now := time.Now()
 				firstSample := backendSampler.newSample(ctx)
@@ -205,8 +209,9 @@ func Test_disruptionSampler_consumeSamples(t *testing.T) {
 			},
 		},
 		{
-			name:          "out-of-order-finish",
-			estimatedTime: 1 * time.Second,
+			name:              "out-of-order-finish",
+			estimatedTime:     1 * time.Second,
+			successfulSamples: 2,
 			produceSamples: func(ctx context.Context, backendSampler *disruptionSampler) {
 				now := time.Now()
 				firstSample := backendSampler.newSample(ctx)
@@ -296,8 +301,9 @@ func Test_disruptionSampler_consumeSamples(t *testing.T) {
 		},
 		{
 			// Disruption with a message of "dial tcp: lookup [hostname]: i/o timeout" should not be considered real disruption
-			name:          "dial-tcp-lookup-warn-not-error",
-			estimatedTime: 1 * time.Second,
+			name:              "dial-tcp-lookup-warn-not-error",
+			estimatedTime:     1 * time.Second,
+			successfulSamples: 2,
 			produceSamples: func(ctx context.Context, backendSampler *disruptionSampler) {
 				now := time.Now()
 				firstSample := backendSampler.newSample(ctx)
@@ -353,7 +359,21 @@ func Test_disruptionSampler_consumeSamples(t *testing.T) {
 			cancel()
 			<-consumptionDone
 
-			tt.validateSamples(t, monitor.Intervals(time.Time{}, time.Time{}))
+			intervals := monitor.Intervals(time.Time{}, time.Time{})
+			tt.validateSamples(t, intervals.Filter(monitorapi.IsDisruptionEvent))
+
+			summaries := intervals.Filter(disruptionlatency.IsLatencySummary)
+			if tt.successfulSamples == 0 {
+				assert.Empty(t, summaries)
+				return
+			}
+			if !assert.Len(t, summaries, 1) {
+				return
+			}
+			latency, err := disruptionlatency.FromSummaryInterval(summaries[0])
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.successfulSamples, latency.Total.Count)
+			}
 		})
 	}
 }
@@ -0,0 +1,188 @@
+package backenddisruption
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/disruptionlatency"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// LatencySLO is the latency a backend is expected to respond within.  A backend that keeps responding slower than
+// Threshold for at least SustainedFor is degraded, even though it is available.
+type LatencySLO struct {
+	Threshold    time.Duration
+	SustainedFor time.Duration
+
+	// MaxDegradedDuringUpgrade is how long the backend may be degraded in total during an upgrade before the latency
+	// test fails rather than flakes.
+	MaxDegradedDuringUpgrade time.Duration
+}
+
+// DefaultLatencySLO is used by samplers that do not set their own with WithLatencySLO.
+var DefaultLatencySLO = LatencySLO{
+	Threshold:                3 * time.Second,
+	SustainedFor:             30 * time.Second,
+	MaxDegradedDuringUpgrade: 2 * time.Minute,
+}
+
+// latencyTrace records the phases of a request into a disruptionlatency.SampleLatency.
+type latencyTrace struct {
+	lock    sync.Mutex
+	start   time.Time
+	latency disruptionlatency.SampleLatency
+
+	dnsStart, connectStart, tlsStart time.Time
+}
+
+func newLatencyTrace(ctx context.Context) (context.Context, *latencyTrace) {
+	t := &latencyTrace{start: time.Now()}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.phaseStart(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.phaseDone(&t.dnsStart, &t.latency.DNS) },
+		ConnectStart: func(_, _ string) {
+			t.phaseStart(&t.connectStart)
+		},
+		ConnectDone: func(_, _ string, _ error) {
+			t.phaseDone(&t.connectStart, &t.latency.Connect)
+		},
+		TLSHandshakeStart: func() { t.phaseStart(&t.tlsStart) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.phaseDone(&t.tlsStart, &t.latency.TLS)
+		},
+		GotFirstResponseByte: func() {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			t.latency.TTFB = time.Since(t.start)
+		},
+	}), t
+}
+
+func (t *latencyTrace) phaseStart(start *time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	*start = time.Now()
+}
+
+// phaseDone adds to the phase, dialing happens once per address tried.
+func (t *latencyTrace) phaseDone(start *time.Time, phase *time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if !start.IsZero() {
+		*phase += time.Since(*start)
+	}
+}
+
+// done returns the latency of the request once the body has been read.
+func (t *latencyTrace) done() disruptionlatency.SampleLatency {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.latency.Total = time.Since(t.start)
+	return t.latency
+}
+
+// LatencyTracker takes the samples of a backend in order.  It counts the latency of the successful samples in the
+// histograms of the backend and finds the periods the backend kept responding slower than its SLO.
+type LatencyTracker struct {
+	locator  monitorapi.Locator
+	latency  *disruptionlatency.BackendLatency
+	degraded *degradedLatencyTracker
+
+	firstSampleTime time.Time
+}
+
+func NewLatencyTracker(slo LatencySLO, locator monitorapi.Locator, backendName string, connectionType monitorapi.BackendConnectionType) *LatencyTracker {
+	return &LatencyTracker{
+		locator:  locator,
+		latency:  disruptionlatency.NewBackendLatency(backendName, connectionType),
+		degraded: newDegradedLatencyTracker(slo, locator),
+	}
+}
+
+// Observe returns a degraded interval when a sustained period of slow samples ends.
+func (t *LatencyTracker) Observe(sampleTime time.Time, latency disruptionlatency.SampleLatency, sampleErr error) *monitorapi.Interval {
+	if t.firstSampleTime.IsZero() {
+		t.firstSampleTime = sampleTime
+	}
+	if sampleErr == nil {
+		t.latency.Observe(latency)
+	}
+	return t.degraded.observe(sampleTime, latency.Total, sampleErr)
+}
+
+// Finish is called when sampling stops at end, it returns the degraded interval of the current period of slow
+// samples, if any, and the interval that carries the latency of the backend.
+func (t *LatencyTracker) Finish(end time.Time) (monitorapi.Intervals, error) {
+	ret := monitorapi.Intervals{}
+	if degraded := t.degraded.finish(end); degraded != nil {
+		ret = append(ret, *degraded)
+	}
+	if t.latency.Total.Count == 0 {
+		return ret, nil
+	}
+	summary, err := t.latency.SummaryInterval(t.locator, t.firstSampleTime, end)
+	if err != nil {
+		return ret, err
+	}
+	return append(ret, summary), nil
+}
+
+// degradedLatencyTracker finds the periods a backend kept responding slower than its SLO.  Failed samples end a
+// period, they are disruption rather than latency.
+type degradedLatencyTracker struct {
+	slo     LatencySLO
+	locator monitorapi.Locator
+
+	from        time.Time
+	slowSamples int
+	worst       time.Duration
+}
+
+func newDegradedLatencyTracker(slo LatencySLO, locator monitorapi.Locator) *degradedLatencyTracker {
+	return &degradedLatencyTracker{slo: slo, locator: locator}
+}
+
+// observe takes the samples in order and returns a degraded interval when a sustained period of slow samples ends.
+func (t *degradedLatencyTracker) observe(sampleTime time.Time, latency time.Duration, sampleErr error) *monitorapi.Interval {
+	if sampleErr == nil && latency > t.slo.Threshold {
+		if t.slowSamples == 0 {
+			t.from = sampleTime
+		}
+		t.slowSamples++
+		if latency > t.worst {
+			t.worst = latency
+		}
+		return nil
+	}
+	return t.finish(sampleTime)
+}
+
+// finish ends the current period of slow samples at end.
+func (t *degradedLatencyTracker) finish(end time.Time) *monitorapi.Interval {
+	if t.slowSamples == 0 {
+		return nil
+	}
+	from, slowSamples, worst := t.from, t.slowSamples, t.worst
+	t.from, t.slowSamples, t.worst = time.Time{}, 0, 0
+	if end.Sub(from) < t.slo.SustainedFor {
+		return nil
+	}
+
+	interval := monitorapi.NewInterval(monitorapi.SourceDisruption, monitorapi.Warning).
+		Locator(t.locator).
+		Message(monitorapi.NewMessage().
+			Reason(monitorapi.DisruptionLatencyDegradedEventReason).
+			HumanMessagef("%s responded slower than %s for %s, the slowest of %d samples took %s",
+				t.locator.OldLocator(), t.slo.Threshold, end.Sub(from).Round(time.Second), slowSamples, worst.Round(time.Millisecond))).
+		Display().
+		Build(from, end)
+	return &interval
+}
+
+// IsLatencyDegraded matches the intervals of backends that responded slower than their latency SLO.
+func IsLatencyDegraded(interval monitorapi.Interval) bool {
+	return interval.Source == monitorapi.SourceDisruption && interval.Message.Reason == monitorapi.DisruptionLatencyDegradedEventReason
+}
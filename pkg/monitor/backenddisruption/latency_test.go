@@ -0,0 +1,137 @@
+package backenddisruption
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/disruptionlatency"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDegradedLatencyTracker(t *testing.T) {
+	slo := LatencySLO{Threshold: 2 * time.Second, SustainedFor: 5 * time.Second}
+	locator := monitorapi.NewLocator().LocateDisruptionCheck("kube-api-new-connections", OpenshiftTestsSource, monitorapi.NewConnectionType)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(second int) time.Time {
+		return start.Add(time.Duration(second) * time.Second)
+	}
+
+	type sample struct {
+		latency time.Duration
+		err     error
+	}
+	tests := []struct {
+		name     string
+		samples  []sample
+		expected [][2]int
+	}{
+		{
+			name:    "fast",
+			samples: []sample{{latency: 100 * time.Millisecond}, {latency: 200 * time.Millisecond}},
+		},
+		{
+			name: "slow but not sustained",
+			samples: []sample{
+				{latency: 3 * time.Second}, {latency: 3 * time.Second}, {latency: 3 * time.Second},
+				{latency: 100 * time.Millisecond},
+			},
+		},
+		{
+			name: "sustained",
+			samples: []sample{
+				{latency: 100 * time.Millisecond},
+				{latency: 3 * time.Second}, {latency: 3 * time.Second}, {latency: 4 * time.Second},
+				{latency: 3 * time.Second}, {latency: 3 * time.Second}, {latency: 3 * time.Second},
+				{latency: 100 * time.Millisecond},
+			},
+			expected: [][2]int{{1, 7}},
+		},
+		{
+			name: "failed samples end the period",
+			samples: []sample{
+				{latency: 3 * time.Second}, {latency: 3 * time.Second}, {latency: 3 * time.Second},
+				{latency: 3 * time.Second, err: fmt.Errorf("timeout")},
+				{latency: 3 * time.Second}, {latency: 3 * time.Second}, {latency: 3 * time.Second},
+			},
+		},
+		{
+			name: "still degraded at the end",
+			samples: []sample{
+				{latency: 3 * time.Second}, {latency: 3 * time.Second}, {latency: 3 * time.Second},
+				{latency: 3 * time.Second}, {latency: 3 * time.Second},
+			},
+			expected: [][2]int{{0, 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newDegradedLatencyTracker(slo, locator)
+			actual := monitorapi.Intervals{}
+			for i, s := range tt.samples {
+				if interval := tracker.observe(at(i), s.latency, s.err); interval != nil {
+					actual = append(actual, *interval)
+				}
+			}
+			// the sampler finishes one sample interval after the last sample
+			if interval := tracker.finish(at(len(tt.samples))); interval != nil {
+				actual = append(actual, *interval)
+			}
+
+			if !assert.Len(t, actual, len(tt.expected)) {
+				return
+			}
+			for i, expected := range tt.expected {
+				assert.Equal(t, at(expected[0]), actual[i].From)
+				assert.Equal(t, at(expected[1]), actual[i].To)
+				assert.Equal(t, monitorapi.Warning, actual[i].Level)
+				assert.True(t, IsLatencyDegraded(actual[i]))
+			}
+		})
+	}
+}
+
+func TestLatencyTracker(t *testing.T) {
+	slo := LatencySLO{Threshold: 2 * time.Second, SustainedFor: 2 * time.Second}
+	locator := monitorapi.NewLocator().LocateDisruptionCheck("kube-api-new-connections", OpenshiftTestsSource, monitorapi.NewConnectionType)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(second int) time.Time {
+		return start.Add(time.Duration(second) * time.Second)
+	}
+
+	tracker := NewLatencyTracker(slo, locator, "kube-api-new-connections", monitorapi.NewConnectionType)
+	assert.Nil(t, tracker.Observe(at(0), disruptionlatency.SampleLatency{Total: 100 * time.Millisecond}, nil))
+	assert.Nil(t, tracker.Observe(at(1), disruptionlatency.SampleLatency{Total: 3 * time.Second}, fmt.Errorf("timeout")))
+	assert.Nil(t, tracker.Observe(at(2), disruptionlatency.SampleLatency{Total: 3 * time.Second}, nil))
+	assert.Nil(t, tracker.Observe(at(3), disruptionlatency.SampleLatency{Total: 3 * time.Second}, nil))
+
+	intervals, err := tracker.Finish(at(4))
+	if !assert.NoError(t, err) || !assert.Len(t, intervals, 2) {
+		return
+	}
+	assert.True(t, IsLatencyDegraded(intervals[0]))
+	assert.Equal(t, at(2), intervals[0].From)
+	assert.True(t, disruptionlatency.IsLatencySummary(intervals[1]))
+	assert.Equal(t, at(0), intervals[1].From)
+	assert.Equal(t, at(4), intervals[1].To)
+
+	// failed samples are disruption, they are not counted
+	latency, err := disruptionlatency.FromSummaryInterval(intervals[1])
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int64(3), latency.Total.Count)
+	assert.Equal(t, "kube-api-new-connections", latency.BackendName)
+}
+
+func TestLatencyTrackerWithoutSuccessfulSamples(t *testing.T) {
+	locator := monitorapi.NewLocator().LocateDisruptionCheck("kube-api-new-connections", OpenshiftTestsSource, monitorapi.NewConnectionType)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tracker := NewLatencyTracker(DefaultLatencySLO, locator, "kube-api-new-connections", monitorapi.NewConnectionType)
+	assert.Nil(t, tracker.Observe(start, disruptionlatency.SampleLatency{}, fmt.Errorf("connection refused")))
+	intervals, err := tracker.Finish(start.Add(time.Second))
+	assert.NoError(t, err)
+	assert.Empty(t, intervals)
+}
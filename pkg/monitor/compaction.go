@@ -0,0 +1,127 @@
+package monitor
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// CompactIntervals merges runs of identical point-in-time intervals into a single interval spanning the run, with
+// the size of the run in the monitorapi.AnnotationCount annotation.  Long runs repeat the same observation many
+// times, an event seen on every resync for instance, and compacting them keeps the number of intervals manageable.
+//
+// Intervals are identical when their source, level, display, locator and message all match.  A run is broken by any
+// other interval with the same locator, so the order of what happened to a locator is kept.  Intervals that already
+// have a count, like those made from kube events, are left alone because their count means something else.
+//
+// The result is sorted.  Code that counts intervals must read the count annotation of compacted intervals.
+func CompactIntervals(intervals monitorapi.Intervals) monitorapi.Intervals {
+	sorted := make(monitorapi.Intervals, len(intervals))
+	copy(sorted, intervals)
+	sort.Sort(sorted)
+
+	type run struct {
+		// index of the first interval of the run in compacted
+		index int
+		count int
+	}
+	compacted := make(monitorapi.Intervals, 0, len(sorted))
+	finish := func(r *run) {
+		if r != nil && r.count > 1 {
+			setCount(&compacted[r.index], r.count)
+		}
+	}
+
+	// the run in progress for each locator
+	runs := map[string]*run{}
+	// sorting puts the same locator next to each other often, so avoid building the same key again
+	var lastLocator monitorapi.Locator
+	var lastKey string
+	for i, interval := range sorted {
+		key := lastKey
+		if i == 0 || !interval.Locator.Equal(lastLocator) {
+			key = locatorKey(interval.Locator)
+			lastLocator, lastKey = interval.Locator, key
+		}
+
+		current := runs[key]
+		if compactable(interval) && current != nil && identical(compacted[current.index], interval) {
+			current.count++
+			compacted[current.index].To = interval.From
+			continue
+		}
+
+		finish(current)
+		delete(runs, key)
+		if compactable(interval) {
+			runs[key] = &run{index: len(compacted), count: 1}
+		}
+		compacted = append(compacted, interval)
+	}
+	for _, current := range runs {
+		finish(current)
+	}
+
+	// extending runs can change the order
+	sort.Sort(compacted)
+	return compacted
+}
+
+// locatorKey identifies a locator, it is cheaper to build than OldLocator.
+func locatorKey(locator monitorapi.Locator) string {
+	keys := make([]string, 0, len(locator.Keys))
+	for k := range locator.Keys {
+		keys = append(keys, string(k))
+	}
+	sort.Strings(keys)
+
+	key := strings.Builder{}
+	key.WriteString(string(locator.Type))
+	for _, k := range keys {
+		key.WriteByte(0)
+		key.WriteString(k)
+		key.WriteByte(0)
+		key.WriteString(locator.Keys[monitorapi.LocatorKey(k)])
+	}
+	return key.String()
+}
+
+// compactable reports whether interval can be merged with others.
+func compactable(interval monitorapi.Interval) bool {
+	if !interval.To.IsZero() && !interval.From.Equal(interval.To) {
+		return false
+	}
+	_, hasCount := interval.Message.Annotations[monitorapi.AnnotationCount]
+	return !hasCount
+}
+
+// identical reports whether next repeats the run that starts with first, ignoring when they happened.
+func identical(first, next monitorapi.Interval) bool {
+	if first.Source != next.Source || first.Level != next.Level || first.Display != next.Display {
+		return false
+	}
+	if first.Message.Reason != next.Message.Reason || first.Message.Cause != next.Message.Cause || first.Message.HumanMessage != next.Message.HumanMessage {
+		return false
+	}
+	if len(first.Message.Annotations) != len(next.Message.Annotations) {
+		return false
+	}
+	for k, v := range next.Message.Annotations {
+		if otherV, ok := first.Message.Annotations[k]; !ok || otherV != v {
+			return false
+		}
+	}
+	return first.Locator.Equal(next.Locator)
+}
+
+// setCount copies the annotations before setting the count, they are shared with the intervals that were compacted.
+func setCount(interval *monitorapi.Interval, count int) {
+	annotations := make(map[monitorapi.AnnotationKey]string, len(interval.Message.Annotations)+1)
+	for k, v := range interval.Message.Annotations {
+		annotations[k] = v
+	}
+	annotations[monitorapi.AnnotationCount] = strconv.Itoa(count)
+	interval.Message.Annotations = annotations
+}
@@ -0,0 +1,66 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestCompactIntervals(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+	point := func(pod, message string, seconds int) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourcePodMonitor, monitorapi.Info).
+			Locator(monitorapi.NewLocator().PodFromNames("ns", pod, "")).
+			Message(monitorapi.NewMessage().Reason("Probe").HumanMessage(message)).
+			Build(at(seconds), at(seconds))
+	}
+	counted := point("a", "event", 7)
+	counted.Message.Annotations[monitorapi.AnnotationCount] = "12"
+
+	intervals := monitorapi.Intervals{
+		point("a", "failed", 1),
+		point("a", "failed", 2),
+		point("b", "failed", 2),
+		point("a", "failed", 3),
+		point("b", "failed", 3),
+		// breaks the run of a
+		point("a", "passed", 4),
+		point("a", "failed", 5),
+		point("a", "failed", 6),
+		counted,
+		point("a", "failed", 8),
+	}
+	original := intervals.Strings()
+
+	compacted := CompactIntervals(intervals)
+	expected := []struct {
+		pod      string
+		message  string
+		from, to int
+		count    string
+	}{
+		{pod: "a", message: "failed", from: 1, to: 3, count: "3"},
+		{pod: "b", message: "failed", from: 2, to: 3, count: "2"},
+		{pod: "a", message: "passed", from: 4, to: 4},
+		{pod: "a", message: "failed", from: 5, to: 6, count: "2"},
+		{pod: "a", message: "event", from: 7, to: 7, count: "12"},
+		{pod: "a", message: "failed", from: 8, to: 8},
+	}
+	if len(compacted) != len(expected) {
+		t.Fatalf("expected %d intervals, got\n%v", len(expected), compacted.Strings())
+	}
+	for i, want := range expected {
+		got := compacted[i]
+		if got.Locator.Keys[monitorapi.LocatorPodKey] != want.pod || got.Message.HumanMessage != want.message ||
+			!got.From.Equal(at(want.from)) || !got.To.Equal(at(want.to)) || got.Message.Annotations[monitorapi.AnnotationCount] != want.count {
+			t.Errorf("interval %d: expected %+v, got %v", i, want, got.String())
+		}
+	}
+
+	// the input is left alone
+	if after := intervals.Strings(); len(after) != len(original) || after[0] != original[0] {
+		t.Errorf("expected the input not to be modified, got %v", after)
+	}
+}
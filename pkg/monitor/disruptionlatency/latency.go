@@ -0,0 +1,231 @@
+package disruptionlatency
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SampleLatency is how long the phases of a single sample took.  Phases that did not happen, like the DNS lookup and
+// connect of a request over a reused connection, are zero.
+type SampleLatency struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	// TTFB is the time from the start of the sample to the first byte of the response, so it includes the DNS
+	// lookup, connect and TLS handshake.
+	TTFB  time.Duration
+	Total time.Duration
+}
+
+func (l SampleLatency) String() string {
+	return fmt.Sprintf("dns=%s connect=%s tls=%s ttfb=%s total=%s",
+		l.DNS.Round(time.Millisecond), l.Connect.Round(time.Millisecond), l.TLS.Round(time.Millisecond),
+		l.TTFB.Round(time.Millisecond), l.Total.Round(time.Millisecond))
+}
+
+// latencyBucketBounds are the upper bounds of the buckets of a LatencyHistogram, the last bucket has no bound.
+var latencyBucketBounds = []time.Duration{
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// LatencyHistogram counts samples by latency.
+type LatencyHistogram struct {
+	// Buckets hold the number of samples with a latency above the bound of the previous bucket and at most the bound
+	// of the bucket, LE is "+Inf" for the last one.
+	Buckets []LatencyBucket
+	Count   int64
+	Sum     metav1.Duration
+	Max     metav1.Duration
+}
+
+type LatencyBucket struct {
+	LE    string
+	Count int64
+}
+
+func NewLatencyHistogram() *LatencyHistogram {
+	h := &LatencyHistogram{}
+	for _, bound := range latencyBucketBounds {
+		h.Buckets = append(h.Buckets, LatencyBucket{LE: bound.String()})
+	}
+	h.Buckets = append(h.Buckets, LatencyBucket{LE: "+Inf"})
+	return h
+}
+
+func (h *LatencyHistogram) Observe(latency time.Duration) {
+	i := 0
+	for i < len(latencyBucketBounds) && latency > latencyBucketBounds[i] {
+		i++
+	}
+	h.Buckets[i].Count++
+	h.Count++
+	h.Sum.Duration += latency
+	if latency > h.Max.Duration {
+		h.Max.Duration = latency
+	}
+}
+
+// Add adds the samples counted by other, which must have the same buckets.
+func (h *LatencyHistogram) Add(other *LatencyHistogram) error {
+	if err := h.checkBuckets(other); err != nil {
+		return err
+	}
+	for i := range h.Buckets {
+		h.Buckets[i].Count += other.Buckets[i].Count
+	}
+	h.Count += other.Count
+	h.Sum.Duration += other.Sum.Duration
+	if other.Max.Duration > h.Max.Duration {
+		h.Max.Duration = other.Max.Duration
+	}
+	return nil
+}
+
+// checkBuckets returns an error if other does not count samples in the same buckets, like a histogram read from a
+// summary written by a build with other bounds.
+func (h *LatencyHistogram) checkBuckets(other *LatencyHistogram) error {
+	if len(other.Buckets) != len(h.Buckets) {
+		return fmt.Errorf("unable to add a latency histogram of %d buckets to one of %d", len(other.Buckets), len(h.Buckets))
+	}
+	for i := range h.Buckets {
+		if other.Buckets[i].LE != h.Buckets[i].LE {
+			return fmt.Errorf("unable to add a latency histogram with bucket le=%s to one with le=%s", other.Buckets[i].LE, h.Buckets[i].LE)
+		}
+	}
+	return nil
+}
+
+// Percentile returns the upper bound of the bucket holding the pth percentile, or the longest latency if that is in
+// the last bucket.
+func (h *LatencyHistogram) Percentile(p float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	// the nearest rank, p is multiplied first so that whole ranks like 99.9% of 1000 are not rounded up by the
+	// error of p / 100
+	rank := int64(math.Ceil(p * float64(h.Count) / 100))
+	if rank < 1 {
+		rank = 1
+	}
+	seen := int64(0)
+	for i, bucket := range h.Buckets {
+		seen += bucket.Count
+		if seen >= rank && i < len(latencyBucketBounds) {
+			return latencyBucketBounds[i]
+		}
+	}
+	return h.Max.Duration
+}
+
+// BackendLatency holds the latency histograms of the successful samples of a backend, one for every phase of the
+// samples.
+type BackendLatency struct {
+	BackendName    string
+	ConnectionType string
+
+	DNS     *LatencyHistogram
+	Connect *LatencyHistogram
+	TLS     *LatencyHistogram
+	TTFB    *LatencyHistogram
+	Total   *LatencyHistogram
+}
+
+func NewBackendLatency(backendName string, connectionType monitorapi.BackendConnectionType) *BackendLatency {
+	return &BackendLatency{
+		BackendName:    backendName,
+		ConnectionType: string(connectionType),
+		DNS:            NewLatencyHistogram(),
+		Connect:        NewLatencyHistogram(),
+		TLS:            NewLatencyHistogram(),
+		TTFB:           NewLatencyHistogram(),
+		Total:          NewLatencyHistogram(),
+	}
+}
+
+// Observe adds the latency of a successful sample to the histograms.
+func (l *BackendLatency) Observe(latency SampleLatency) {
+	for _, phase := range []struct {
+		histogram *LatencyHistogram
+		latency   time.Duration
+	}{
+		{l.DNS, latency.DNS},
+		{l.Connect, latency.Connect},
+		{l.TLS, latency.TLS},
+		{l.TTFB, latency.TTFB},
+	} {
+		if phase.latency > 0 {
+			phase.histogram.Observe(phase.latency)
+		}
+	}
+	l.Total.Observe(latency.Total)
+}
+
+// Add adds the samples counted by other, a backend can be sampled by more than one sampler, like the in-cluster
+// samplers that run on every node.  Nothing is added if the buckets of any phase differ.
+func (l *BackendLatency) Add(other *BackendLatency) error {
+	phases := []struct {
+		name      string
+		histogram *LatencyHistogram
+		added     *LatencyHistogram
+	}{
+		{"dns", l.DNS, other.DNS},
+		{"connect", l.Connect, other.Connect},
+		{"tls", l.TLS, other.TLS},
+		{"ttfb", l.TTFB, other.TTFB},
+		{"total", l.Total, other.Total},
+	}
+	for _, phase := range phases {
+		if err := phase.histogram.checkBuckets(phase.added); err != nil {
+			return fmt.Errorf("%s %s: %w", l.BackendName, phase.name, err)
+		}
+	}
+	for _, phase := range phases {
+		if err := phase.histogram.Add(phase.added); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SummaryInterval returns an interval from the first to the last sample that carries the histograms, the samplers
+// record it when they stop so that the latency is written out from the final intervals like the disruption is.
+func (l *BackendLatency) SummaryInterval(locator monitorapi.Locator, from, to time.Time) (monitorapi.Interval, error) {
+	latency, err := json.Marshal(l)
+	if err != nil {
+		return monitorapi.Interval{}, err
+	}
+	return monitorapi.NewInterval(monitorapi.SourceDisruptionLatency, monitorapi.Info).
+		Locator(locator).
+		Message(monitorapi.NewMessage().
+			Reason(monitorapi.DisruptionLatencySummaryEventReason).
+			WithAnnotation(monitorapi.AnnotationLatency, string(latency)).
+			HumanMessagef("%d successful samples, p50=%s p99=%s max=%s",
+				l.Total.Count, l.Total.Percentile(50), l.Total.Percentile(99), l.Total.Max.Duration.Round(time.Millisecond))).
+		Build(from, to), nil
+}
+
+// IsLatencySummary matches the intervals returned by SummaryInterval.
+func IsLatencySummary(interval monitorapi.Interval) bool {
+	return interval.Source == monitorapi.SourceDisruptionLatency && interval.Message.Reason == monitorapi.DisruptionLatencySummaryEventReason
+}
+
+// FromSummaryInterval returns the latency carried by an interval returned by SummaryInterval.
+func FromSummaryInterval(interval monitorapi.Interval) (*BackendLatency, error) {
+	latency := &BackendLatency{}
+	if err := json.Unmarshal([]byte(interval.Message.Annotations[monitorapi.AnnotationLatency]), latency); err != nil {
+		return nil, fmt.Errorf("unable to read the latency of %s: %w", interval.Locator.OldLocator(), err)
+	}
+	return latency, nil
+}
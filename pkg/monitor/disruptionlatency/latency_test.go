@@ -0,0 +1,125 @@
+package disruptionlatency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	h := NewLatencyHistogram()
+	assert.Equal(t, time.Duration(0), h.Percentile(99))
+
+	for i := 0; i < 90; i++ {
+		h.Observe(40 * time.Millisecond)
+	}
+	for i := 0; i < 9; i++ {
+		h.Observe(700 * time.Millisecond)
+	}
+	h.Observe(12 * time.Second)
+
+	assert.Equal(t, int64(100), h.Count)
+	assert.Equal(t, int64(90), h.Buckets[0].Count)
+	assert.Equal(t, "50ms", h.Buckets[0].LE)
+	assert.Equal(t, int64(9), h.Buckets[4].Count)
+	assert.Equal(t, "+Inf", h.Buckets[len(h.Buckets)-1].LE)
+	assert.Equal(t, int64(1), h.Buckets[len(h.Buckets)-1].Count)
+	assert.Equal(t, 12*time.Second, h.Max.Duration)
+
+	assert.Equal(t, 50*time.Millisecond, h.Percentile(50))
+	assert.Equal(t, 1*time.Second, h.Percentile(95))
+	assert.Equal(t, 1*time.Second, h.Percentile(99))
+	assert.Equal(t, 12*time.Second, h.Percentile(100))
+}
+
+func TestLatencyHistogramPercentileRank(t *testing.T) {
+	h := NewLatencyHistogram()
+	for i := 0; i < 9; i++ {
+		h.Observe(40 * time.Millisecond)
+	}
+	h.Observe(700 * time.Millisecond)
+	// the 95th percentile of 10 samples is the 10th, not the 9th
+	assert.Equal(t, 1*time.Second, h.Percentile(95))
+	assert.Equal(t, 50*time.Millisecond, h.Percentile(90))
+
+	h = NewLatencyHistogram()
+	for i := 0; i < 999; i++ {
+		h.Observe(40 * time.Millisecond)
+	}
+	h.Observe(700 * time.Millisecond)
+	assert.Equal(t, 50*time.Millisecond, h.Percentile(99.9))
+	assert.Equal(t, 1*time.Second, h.Percentile(99.95))
+}
+
+func TestLatencyHistogramAddOtherBuckets(t *testing.T) {
+	h := NewLatencyHistogram()
+	h.Observe(40 * time.Millisecond)
+
+	fewer := NewLatencyHistogram()
+	fewer.Buckets = fewer.Buckets[1:]
+	fewer.Buckets[0].Count = 1
+	assert.Error(t, h.Add(fewer))
+
+	otherBounds := NewLatencyHistogram()
+	otherBounds.Buckets[0].LE = "25ms"
+	assert.Error(t, h.Add(otherBounds))
+
+	assert.Equal(t, int64(1), h.Count)
+	assert.Equal(t, int64(1), h.Buckets[0].Count)
+}
+
+func TestBackendLatency(t *testing.T) {
+	latency := NewBackendLatency("test-backend-new-connections", monitorapi.NewConnectionType)
+	latency.Observe(SampleLatency{
+		DNS: 5 * time.Millisecond, Connect: 10 * time.Millisecond, TLS: 20 * time.Millisecond, TTFB: 80 * time.Millisecond, Total: 90 * time.Millisecond,
+	})
+	latency.Observe(SampleLatency{
+		TTFB: 30 * time.Millisecond, Total: 40 * time.Millisecond,
+	})
+
+	assert.Equal(t, "new", latency.ConnectionType)
+	assert.Equal(t, int64(2), latency.Total.Count)
+	assert.Equal(t, int64(2), latency.TTFB.Count)
+	// phases that did not happen are not counted
+	assert.Equal(t, int64(1), latency.DNS.Count)
+	assert.Equal(t, int64(1), latency.TLS.Count)
+
+	other := NewBackendLatency("test-backend-new-connections", monitorapi.NewConnectionType)
+	other.Observe(SampleLatency{Total: 3 * time.Second})
+	assert.NoError(t, latency.Add(other))
+	assert.Equal(t, int64(3), latency.Total.Count)
+	assert.Equal(t, int64(1), latency.Total.Buckets[6].Count)
+	assert.Equal(t, 3*time.Second, latency.Total.Max.Duration)
+	assert.Equal(t, int64(1), latency.DNS.Count)
+
+	// nothing is added when one of the phases has other buckets
+	other.TLS.Buckets = other.TLS.Buckets[:1]
+	assert.Error(t, latency.Add(other))
+	assert.Equal(t, int64(3), latency.Total.Count)
+}
+
+func TestSummaryInterval(t *testing.T) {
+	locator := monitorapi.NewLocator().LocateDisruptionCheck("test-backend-new-connections", "openshift-tests", monitorapi.NewConnectionType)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	latency := NewBackendLatency("test-backend-new-connections", monitorapi.NewConnectionType)
+	latency.Observe(SampleLatency{TTFB: 30 * time.Millisecond, Total: 40 * time.Millisecond})
+
+	interval, err := latency.SummaryInterval(locator, start, start.Add(time.Hour))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, IsLatencySummary(interval))
+	assert.Equal(t, "1 successful samples, p50=50ms p99=50ms max=40ms", interval.Message.HumanMessage)
+
+	actual, err := FromSummaryInterval(interval)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, latency, actual)
+
+	interval.Message.Annotations[monitorapi.AnnotationLatency] = "{"
+	_, err = FromSummaryInterval(interval)
+	assert.Error(t, err)
+}
@@ -0,0 +1,360 @@
+package monitor
+
+import (
+	"sort"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// bucketWidth is the span of time covered by one time bucket of an intervalIndex.
+const bucketWidth = time.Minute
+
+// IntervalSelector narrows down the intervals of a recorder.  Empty fields select everything, the fields that are
+// set must all match.
+type IntervalSelector struct {
+	Sources      []monitorapi.IntervalSource
+	LocatorTypes []monitorapi.LocatorType
+	Namespaces   []string
+	// From and To select the intervals that end at or after From and start at or before To.  The end of an interval
+	// without a To is its From.
+	From time.Time
+	To   time.Time
+}
+
+// IntervalQuerier narrows down recorded intervals through their index instead of scanning all of them.  The results
+// are copies, in order of their occurrence, and safe to update.
+type IntervalQuerier interface {
+	// Filter returns the intervals selected by selector for which matches, if set, returns true.
+	Filter(selector IntervalSelector, matches monitorapi.EventIntervalMatchesFunc) monitorapi.Intervals
+	// Cut behaves like monitorapi.Intervals.Cut.
+	Cut(from, to time.Time) monitorapi.Intervals
+}
+
+// timeBucket holds the positions of the intervals that start within one bucketWidth.
+type timeBucket struct {
+	positions []int
+	// latestEnd is at least the latest end among the intervals of the bucket.  It does not go back when an
+	// interval is ended earlier than before, which only costs a bucket that is looked at for nothing.
+	latestEnd time.Time
+}
+
+// intervalIndex orders the intervals of a recorder by monitorapi.Intervals.Less without copying them, so that reads
+// only have to sort what was added since the last one and find the start of a time range by binary search.  It also
+// keeps the positions of the intervals by source, locator type, namespace and time bucket, so that Filter and Cut
+// only look at the intervals that can match.
+type intervalIndex struct {
+	// order holds the positions of the indexed intervals in the recorder's events, sorted.
+	order []int
+	// rank[position] is where position sits in order.
+	rank []int
+	// latestEnd[i] is the latest end among the intervals at order[:i+1].  The end of an interval without a To is its
+	// From, which matches how monitorapi.Intervals.Slice treats them.
+	latestEnd []time.Time
+
+	// the key indexes hold positions in increasing order, the keys of an interval never change once recorded.
+	bySource      map[monitorapi.IntervalSource][]int
+	byLocatorType map[monitorapi.LocatorType][]int
+	byNamespace   map[string][]int
+	// buckets are keyed by the Unix time their bucketWidth starts at, bucketStarts holds those keys sorted.
+	buckets      map[int64]*timeBucket
+	bucketStarts []int64
+}
+
+func intervalEnd(interval *monitorapi.Interval) time.Time {
+	if interval.To.IsZero() {
+		return interval.From
+	}
+	return interval.To
+}
+
+func bucketStart(t time.Time) int64 {
+	return t.Truncate(bucketWidth).Unix()
+}
+
+// update indexes the events added since the last update.
+func (x *intervalIndex) update(events monitorapi.Intervals) {
+	if len(x.order) == len(events) {
+		return
+	}
+	added := make([]int, 0, len(events)-len(x.order))
+	for i := len(x.order); i < len(events); i++ {
+		added = append(added, i)
+	}
+	x.indexKeys(events, added)
+	sort.SliceStable(added, func(i, j int) bool {
+		return events[added[i]].Less(&events[added[j]])
+	})
+
+	// merge, the first position that changed is where the earliest addition went
+	merged := make([]int, 0, len(events))
+	firstChanged := -1
+	i, j := 0, 0
+	for i < len(x.order) && j < len(added) {
+		if events[added[j]].Less(&events[x.order[i]]) {
+			if firstChanged < 0 {
+				firstChanged = len(merged)
+			}
+			merged = append(merged, added[j])
+			j++
+		} else {
+			merged = append(merged, x.order[i])
+			i++
+		}
+	}
+	merged = append(merged, x.order[i:]...)
+	if firstChanged < 0 {
+		firstChanged = len(merged)
+	}
+	merged = append(merged, added[j:]...)
+	x.order = merged
+
+	x.rank = append(x.rank, make([]int, len(added))...)
+	for k := firstChanged; k < len(x.order); k++ {
+		x.rank[x.order[k]] = k
+	}
+	x.latestEnd = append(x.latestEnd, make([]time.Time, len(added))...)
+	x.reindexEnds(events, firstChanged, len(x.order))
+}
+
+// indexKeys adds positions, which must be increasing and after all indexed ones, to the key and time bucket indexes.
+func (x *intervalIndex) indexKeys(events monitorapi.Intervals, positions []int) {
+	if x.buckets == nil {
+		x.bySource = map[monitorapi.IntervalSource][]int{}
+		x.byLocatorType = map[monitorapi.LocatorType][]int{}
+		x.byNamespace = map[string][]int{}
+		x.buckets = map[int64]*timeBucket{}
+	}
+	for _, position := range positions {
+		interval := &events[position]
+		x.bySource[interval.Source] = append(x.bySource[interval.Source], position)
+		x.byLocatorType[interval.Locator.Type] = append(x.byLocatorType[interval.Locator.Type], position)
+		if namespace, ok := interval.Locator.Keys[monitorapi.LocatorNamespaceKey]; ok {
+			x.byNamespace[namespace] = append(x.byNamespace[namespace], position)
+		}
+
+		start := bucketStart(interval.From)
+		bucket, ok := x.buckets[start]
+		if !ok {
+			bucket = &timeBucket{}
+			x.buckets[start] = bucket
+			k := sort.Search(len(x.bucketStarts), func(k int) bool { return x.bucketStarts[k] > start })
+			x.bucketStarts = append(x.bucketStarts, 0)
+			copy(x.bucketStarts[k+1:], x.bucketStarts[k:])
+			x.bucketStarts[k] = start
+		}
+		bucket.positions = append(bucket.positions, position)
+		if end := intervalEnd(interval); end.After(bucket.latestEnd) {
+			bucket.latestEnd = end
+		}
+	}
+}
+
+// reindexEnds recomputes latestEnd from position first on.  Past position last, it stops as soon as a value is
+// unchanged, since nothing after it can change either.
+func (x *intervalIndex) reindexEnds(events monitorapi.Intervals, first, last int) {
+	var latest time.Time
+	if first > 0 {
+		latest = x.latestEnd[first-1]
+	}
+	for i := first; i < len(x.order); i++ {
+		if end := intervalEnd(&events[x.order[i]]); end.After(latest) {
+			latest = end
+		}
+		if i >= last && x.latestEnd[i].Equal(latest) {
+			return
+		}
+		x.latestEnd[i] = latest
+	}
+}
+
+// change updates events[position] with fn and moves it to where it now sorts.  fn must not change the From or the
+// keys of the interval.
+func (x *intervalIndex) change(events monitorapi.Intervals, position int, fn func(*monitorapi.Interval)) {
+	fn(&events[position])
+	if position >= len(x.order) {
+		return
+	}
+	if bucket, ok := x.buckets[bucketStart(events[position].From)]; ok {
+		if end := intervalEnd(&events[position]); end.After(bucket.latestEnd) {
+			bucket.latestEnd = end
+		}
+	}
+
+	k := x.rank[position]
+	x.order = append(x.order[:k], x.order[k+1:]...)
+	interval := &events[position]
+	p := sort.Search(len(x.order), func(p int) bool {
+		return interval.Less(&events[x.order[p]])
+	})
+	x.order = append(x.order, 0)
+	copy(x.order[p+1:], x.order[p:])
+	x.order[p] = position
+
+	first, last := k, p
+	if p < k {
+		first, last = p, k
+	}
+	for i := first; i <= last; i++ {
+		x.rank[x.order[i]] = i
+	}
+	x.reindexEnds(events, first, last+1)
+}
+
+// slice returns a copy of the indexed intervals monitorapi.Intervals.Slice would return for from and to.
+func (x *intervalIndex) slice(events monitorapi.Intervals, from, to time.Time) monitorapi.Intervals {
+	first := 0
+	if !from.IsZero() {
+		first = sort.Search(len(x.latestEnd), func(i int) bool {
+			return !x.latestEnd[i].Before(from)
+		})
+	}
+	last := len(x.order)
+	if !to.IsZero() {
+		last = first + sort.Search(len(x.order)-first, func(i int) bool {
+			return events[x.order[first+i]].From.After(to)
+		})
+	}
+	if first >= last {
+		return monitorapi.Intervals{}
+	}
+	ret := make(monitorapi.Intervals, 0, last-first)
+	for _, position := range x.order[first:last] {
+		ret = append(ret, events[position])
+	}
+	return ret
+}
+
+// bucketed returns the positions in the time buckets that can hold intervals ending at or after from and starting at
+// or before to.  Zero times do not bound the range.
+func (x *intervalIndex) bucketed(from, to time.Time) [][]int {
+	last := len(x.bucketStarts)
+	if !to.IsZero() {
+		last = sort.Search(len(x.bucketStarts), func(k int) bool {
+			return x.bucketStarts[k] > bucketStart(to)
+		})
+	}
+	lists := [][]int{}
+	for _, start := range x.bucketStarts[:last] {
+		bucket := x.buckets[start]
+		if !from.IsZero() && bucket.latestEnd.Before(from) {
+			continue
+		}
+		lists = append(lists, bucket.positions)
+	}
+	return lists
+}
+
+// inOrder returns copies of the intervals at positions, sorted.
+func (x *intervalIndex) inOrder(events monitorapi.Intervals, positions []int) monitorapi.Intervals {
+	ranks := make([]int, 0, len(positions))
+	for _, position := range positions {
+		ranks = append(ranks, x.rank[position])
+	}
+	sort.Ints(ranks)
+	ret := make(monitorapi.Intervals, 0, len(ranks))
+	for _, rank := range ranks {
+		ret = append(ret, events[x.order[rank]])
+	}
+	return ret
+}
+
+// cut behaves like monitorapi.Intervals.Cut on the sorted intervals, but only looks at the time buckets that can
+// overlap [from,to).
+func (x *intervalIndex) cut(events monitorapi.Intervals, from, to time.Time) monitorapi.Intervals {
+	candidates := []int{}
+	for _, positions := range x.bucketed(from, to) {
+		for _, position := range positions {
+			interval := &events[position]
+			if interval.From.Before(to) && !intervalEnd(interval).Before(from) {
+				candidates = append(candidates, position)
+			}
+		}
+	}
+	return x.inOrder(events, candidates).Cut(from, to)
+}
+
+// filter returns the intervals selected by selector for which matches, if set, returns true, sorted.  Only the
+// smallest index that applies is scanned.
+func (x *intervalIndex) filter(events monitorapi.Intervals, selector IntervalSelector, matches monitorapi.EventIntervalMatchesFunc) monitorapi.Intervals {
+	var smallest [][]int
+	consider := func(lists [][]int) {
+		if smallest == nil || total(lists) < total(smallest) {
+			smallest = lists
+		}
+	}
+	if len(selector.Sources) > 0 {
+		consider(lookup(x.bySource, selector.Sources))
+	}
+	if len(selector.LocatorTypes) > 0 {
+		consider(lookup(x.byLocatorType, selector.LocatorTypes))
+	}
+	if len(selector.Namespaces) > 0 {
+		consider(lookup(x.byNamespace, selector.Namespaces))
+	}
+	if !selector.From.IsZero() || !selector.To.IsZero() {
+		consider(x.bucketed(selector.From, selector.To))
+	}
+	if smallest == nil {
+		smallest = [][]int{x.order}
+	}
+
+	candidates := []int{}
+	for _, positions := range smallest {
+		for _, position := range positions {
+			if selected(&events[position], selector) && (matches == nil || matches(events[position])) {
+				candidates = append(candidates, position)
+			}
+		}
+	}
+	return x.inOrder(events, candidates)
+}
+
+func selected(interval *monitorapi.Interval, selector IntervalSelector) bool {
+	if len(selector.Sources) > 0 && !contains(selector.Sources, interval.Source) {
+		return false
+	}
+	if len(selector.LocatorTypes) > 0 && !contains(selector.LocatorTypes, interval.Locator.Type) {
+		return false
+	}
+	if len(selector.Namespaces) > 0 {
+		namespace, ok := interval.Locator.Keys[monitorapi.LocatorNamespaceKey]
+		if !ok || !contains(selector.Namespaces, namespace) {
+			return false
+		}
+	}
+	if !selector.From.IsZero() && intervalEnd(interval).Before(selector.From) {
+		return false
+	}
+	if !selector.To.IsZero() && interval.From.After(selector.To) {
+		return false
+	}
+	return true
+}
+
+func lookup[K comparable](index map[K][]int, keys []K) [][]int {
+	lists := [][]int{}
+	for _, key := range keys {
+		if list, ok := index[key]; ok {
+			lists = append(lists, list)
+		}
+	}
+	return lists
+}
+
+func total(lists [][]int) int {
+	count := 0
+	for _, list := range lists {
+		count += len(list)
+	}
+	return count
+}
+
+func contains[K comparable](values []K, value K) bool {
+	for _, curr := range values {
+		if curr == value {
+			return true
+		}
+	}
+	return false
+}
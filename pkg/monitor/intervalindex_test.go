@@ -0,0 +1,169 @@
+package monitor
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// generateIntervals returns count intervals a tenth of a second apart.  Pods and nodes repeat the same observations, as
+// watches and samplers do, and now and then change state.
+func generateIntervals(count int, random *rand.Rand) monitorapi.Intervals {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	intervals := make(monitorapi.Intervals, 0, count)
+	for i := 0; i < count; i++ {
+		t := start.Add(time.Duration(i) * 100 * time.Millisecond)
+		var interval monitorapi.Interval
+		switch kind := random.Intn(10); {
+		case kind < 6:
+			pod := random.Intn(500)
+			level := monitorapi.Info
+			reason := monitorapi.IntervalReason("Ready")
+			if random.Intn(20) == 0 {
+				level, reason = monitorapi.Error, "NotReady"
+			}
+			interval = monitorapi.NewInterval(monitorapi.SourcePodMonitor, level).
+				Locator(monitorapi.NewLocator().PodFromNames(fmt.Sprintf("namespace-%d", pod%20), fmt.Sprintf("pod-%d", pod), fmt.Sprintf("uid-%d", pod))).
+				Message(monitorapi.NewMessage().Reason(reason).HumanMessage("container is "+string(reason))).
+				Build(t, t)
+		case kind < 9:
+			node := random.Intn(6)
+			interval = monitorapi.NewInterval(monitorapi.SourceNodeMonitor, monitorapi.Warning).
+				Locator(monitorapi.NewLocator().NodeFromName(fmt.Sprintf("node-%d", node))).
+				Message(monitorapi.NewMessage().Reason("NodeHasSufficientMemory").HumanMessage("node is healthy")).
+				Build(t, t)
+		default:
+			backend := random.Intn(4)
+			interval = monitorapi.NewInterval(monitorapi.SourceDisruption, monitorapi.Error).
+				Locator(monitorapi.NewLocator().Disruption(fmt.Sprintf("backend-%d", backend), "", "", "", "", monitorapi.NewConnectionType)).
+				Message(monitorapi.NewMessage().Reason(monitorapi.DisruptionBeganEventReason).HumanMessage("disruption")).
+				Build(t, t.Add(time.Duration(random.Intn(5000))*time.Millisecond))
+		}
+		intervals = append(intervals, interval)
+	}
+	// recorders receive intervals slightly out of order
+	for i := 0; i < count/100; i++ {
+		j, k := random.Intn(count), random.Intn(count)
+		intervals[j], intervals[k] = intervals[k], intervals[j]
+	}
+	return intervals
+}
+
+func TestRecorderIntervalsStaySorted(t *testing.T) {
+	intervals := generateIntervals(3000, rand.New(rand.NewSource(3)))
+	recorder := NewRecorder()
+
+	// add in batches, reading in between, so that new intervals are merged into what is already indexed
+	for i := 0; i < len(intervals); i += 1000 {
+		recorder.AddIntervals(intervals[i : i+1000]...)
+		recorder.Intervals(time.Time{}, time.Time{})
+	}
+	started := recorder.StartInterval(intervals[0])
+	recorder.Intervals(time.Time{}, time.Time{})
+	recorder.EndInterval(started, intervals[0].From.Add(time.Hour))
+
+	expected := append(monitorapi.Intervals{}, intervals...)
+	ended := intervals[0]
+	ended.To = intervals[0].From.Add(time.Hour)
+	expected = append(expected, ended)
+	sort.Sort(expected)
+
+	got := recorder.Intervals(time.Time{}, time.Time{})
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d intervals, got %d", len(expected), len(got))
+	}
+	for i := range got {
+		if got[i].Less(&expected[i]) || expected[i].Less(&got[i]) {
+			t.Fatalf("interval %d: expected %v, got %v", i, expected[i].String(), got[i].String())
+		}
+	}
+}
+
+func TestRecorderIntervalsMatchScan(t *testing.T) {
+	intervals := generateIntervals(3000, rand.New(rand.NewSource(4)))
+	recorder := NewRecorder().(*recorder)
+	scanned := monitorapi.Intervals{}
+
+	// read in between batches, so that every read has to index what was added, and end some of the intervals that
+	// are already indexed, so that they move
+	random := rand.New(rand.NewSource(5))
+	started := []int{}
+	for i := 0; i < len(intervals); i += 500 {
+		for _, interval := range intervals[i : i+500] {
+			started = append(started, recorder.StartInterval(interval))
+		}
+		scanned = append(scanned, intervals[i:i+500]...)
+		recorder.Intervals(time.Time{}, time.Time{})
+
+		for j := 0; j < 50; j++ {
+			position := started[random.Intn(len(started))]
+			end := scanned[position].From.Add(time.Duration(random.Intn(600)) * time.Second)
+			recorder.EndInterval(position, end)
+			if scanned[position].From.Before(end) {
+				scanned[position].To = end
+			}
+		}
+
+		sorted := append(monitorapi.Intervals{}, scanned...)
+		sort.Sort(sorted)
+		start, last := sorted[0].From, sorted[len(sorted)-1].From
+		from := start.Add(time.Duration(random.Int63n(int64(last.Sub(start)))))
+		to := from.Add(time.Duration(random.Int63n(int64(last.Sub(from)) + 1)))
+
+		for _, bounds := range [][2]time.Time{{from, to}, {{}, to}, {from, {}}, {{}, {}}} {
+			if expected, got := sorted.Slice(bounds[0], bounds[1]), recorder.Intervals(bounds[0], bounds[1]); !sameIntervals(expected, got) {
+				t.Fatalf("Intervals(%v, %v): expected %d intervals, got %d", bounds[0], bounds[1], len(expected), len(got))
+			}
+		}
+		if expected, got := sorted.Cut(from, to), recorder.Cut(from, to); !sameIntervals(expected, got) {
+			t.Fatalf("Cut(%v, %v): expected %d intervals, got %d", from, to, len(expected), len(got))
+		}
+
+		errors := func(interval monitorapi.Interval) bool { return interval.Level == monitorapi.Error }
+		for _, selector := range []IntervalSelector{
+			{Sources: []monitorapi.IntervalSource{monitorapi.SourcePodMonitor}, Namespaces: []string{"namespace-1", "namespace-2"}, From: from, To: to},
+			{LocatorTypes: []monitorapi.LocatorType{monitorapi.LocatorTypeDisruption}, From: from},
+			{From: from, To: to},
+			{},
+		} {
+			expected := sorted.Filter(func(interval monitorapi.Interval) bool {
+				namespace := interval.Locator.Keys[monitorapi.LocatorNamespaceKey]
+				end := interval.To
+				if end.IsZero() {
+					end = interval.From
+				}
+				return (len(selector.Sources) == 0 || interval.Source == selector.Sources[0]) &&
+					(len(selector.LocatorTypes) == 0 || interval.Locator.Type == selector.LocatorTypes[0]) &&
+					(len(selector.Namespaces) == 0 || namespace == selector.Namespaces[0] || namespace == selector.Namespaces[1]) &&
+					(selector.From.IsZero() || !end.Before(selector.From)) &&
+					(selector.To.IsZero() || !interval.From.After(selector.To)) &&
+					errors(interval)
+			})
+			if got := recorder.Filter(selector, errors); !sameIntervals(expected, got) {
+				t.Fatalf("Filter(%+v): expected %d intervals, got %d", selector, len(expected), len(got))
+			}
+		}
+	}
+}
+
+func TestRecorderIntervalsAreCopies(t *testing.T) {
+	recorder := NewRecorder()
+	interval := generateIntervals(1, rand.New(rand.NewSource(6)))[0]
+	recorder.AddIntervals(interval)
+
+	got := recorder.Intervals(time.Time{}, time.Time{})
+	got[0].Message.Reason = "Changed"
+	if reason := recorder.Intervals(time.Time{}, time.Time{})[0].Message.Reason; reason != interval.Message.Reason {
+		t.Fatalf("expected the recorded reason %q to be kept, got %q", interval.Message.Reason, reason)
+	}
+}
+
+// sameIntervals treats nil and empty as the same.
+func sameIntervals(a, b monitorapi.Intervals) bool {
+	return len(a) == len(b) && (len(a) == 0 || reflect.DeepEqual(a, b))
+}
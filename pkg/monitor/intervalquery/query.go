@@ -0,0 +1,250 @@
+// Package intervalquery provides a small expression language, based on CEL, for selecting intervals.
+//
+// An expression is evaluated once per interval and must produce a bool.  The following variables are available:
+//
+//	source       string               the interval source, for instance "KubeEvent"
+//	level        string               "Info", "Warning", or "Error"
+//	display      bool                 whether the source suggested displaying the interval
+//	locatorType  string               the locator type, for instance "Pod"
+//	locator      map(string, string)  the locator keys, for instance locator.namespace
+//	reason       string               the message reason
+//	cause        string               the message cause
+//	message      string               the human message
+//	annotations  map(string, string)  the message annotations
+//	from         timestamp            the start of the interval
+//	to           timestamp            the end of the interval, equal to from if the interval has no end
+//	duration     duration             to - from
+//
+// In addition, overlaps(expression) is true when any other interval matching the nested expression
+// overlaps this interval in time.  For example
+//
+//	source == "KubeEvent" && locator.namespace == "openshift-etcd" && duration > duration("30s")
+//	reason == "NotReady" && overlaps("source == 'Disruption' && level == 'Error'")
+//
+// Missing map keys are not an error: an expression that fails to evaluate for an interval simply does not
+// match it.  Use has(locator.pod) or "pod" in locator to test for presence explicitly.
+package intervalquery
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+const overlapsFunction = "overlaps"
+
+// Query is a compiled interval expression.
+type Query struct {
+	expression string
+	program    cel.Program
+
+	// nested holds the compiled expressions passed to overlaps(), keyed by expression.
+	nested map[string]*Query
+
+	// evaluationLock serializes evaluation, because overlaps() needs to know which interval is being evaluated.
+	evaluationLock sync.Mutex
+	current        *evaluation
+}
+
+type evaluation struct {
+	interval monitorapi.Interval
+	// nestedMatches caches, per nested expression, the intervals of all that match it.
+	nestedMatches map[string]monitorapi.Intervals
+}
+
+// Compile parses and type checks expression.
+func Compile(expression string) (*Query, error) {
+	q := &Query{
+		expression: expression,
+		nested:     map[string]*Query{},
+	}
+
+	env, err := cel.NewEnv(
+		cel.Variable("source", cel.StringType),
+		cel.Variable("level", cel.StringType),
+		cel.Variable("display", cel.BoolType),
+		cel.Variable("locatorType", cel.StringType),
+		cel.Variable("locator", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("reason", cel.StringType),
+		cel.Variable("cause", cel.StringType),
+		cel.Variable("message", cel.StringType),
+		cel.Variable("annotations", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("from", cel.TimestampType),
+		cel.Variable("to", cel.TimestampType),
+		cel.Variable("duration", cel.DurationType),
+		cel.Function(overlapsFunction,
+			cel.Overload("overlaps_string", []*cel.Type{cel.StringType}, cel.BoolType,
+				cel.UnaryBinding(q.overlaps),
+			),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid interval query %q: %w", expression, issues.Err())
+	}
+	if !ast.OutputType().IsExactType(cel.BoolType) {
+		return nil, fmt.Errorf("invalid interval query %q: must evaluate to a bool, not %v", expression, ast.OutputType())
+	}
+
+	// compile the nested expressions up front so that mistakes are reported now instead of silently not matching.
+	var nestedErr error
+	celast.PreOrderVisit(ast.NativeRep().Expr(), celast.NewExprVisitor(func(e celast.Expr) {
+		if nestedErr != nil || e.Kind() != celast.CallKind || e.AsCall().FunctionName() != overlapsFunction {
+			return
+		}
+		arg := e.AsCall().Args()[0]
+		if arg.Kind() != celast.LiteralKind {
+			nestedErr = fmt.Errorf("invalid interval query %q: %s() requires a string literal", expression, overlapsFunction)
+			return
+		}
+		nestedExpression := string(arg.AsLiteral().(types.String))
+		if _, ok := q.nested[nestedExpression]; ok {
+			return
+		}
+		nestedQuery, err := Compile(nestedExpression)
+		if err != nil {
+			nestedErr = err
+			return
+		}
+		q.nested[nestedExpression] = nestedQuery
+	}))
+	if nestedErr != nil {
+		return nil, nestedErr
+	}
+
+	q.program, err = env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("invalid interval query %q: %w", expression, err)
+	}
+	return q, nil
+}
+
+// MustCompile is like Compile, but panics on an invalid expression.
+func MustCompile(expression string) *Query {
+	q, err := Compile(expression)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// String returns the source expression.
+func (q *Query) String() string {
+	return q.expression
+}
+
+// MatchesFunc returns a matcher for the query.  all is the set of intervals that overlaps() searches; it
+// may be nil if the expression does not use overlaps().
+func (q *Query) MatchesFunc(all monitorapi.Intervals) monitorapi.EventIntervalMatchesFunc {
+	nestedMatches := map[string]monitorapi.Intervals{}
+	for nestedExpression, nestedQuery := range q.nested {
+		nestedMatches[nestedExpression] = all.Filter(nestedQuery.MatchesFunc(all))
+	}
+
+	return func(eventInterval monitorapi.Interval) bool {
+		return q.matches(&evaluation{
+			interval:      eventInterval,
+			nestedMatches: nestedMatches,
+		})
+	}
+}
+
+// Filter returns the intervals that match the query.  overlaps() searches intervals.
+func (q *Query) Filter(intervals monitorapi.Intervals) monitorapi.Intervals {
+	return intervals.Filter(q.MatchesFunc(intervals))
+}
+
+func (q *Query) matches(e *evaluation) bool {
+	q.evaluationLock.Lock()
+	defer q.evaluationLock.Unlock()
+	q.current = e
+	defer func() { q.current = nil }()
+
+	out, _, err := q.program.Eval(activationFor(e.interval))
+	if err != nil {
+		return false
+	}
+	matched, ok := out.Value().(bool)
+	return ok && matched
+}
+
+func (q *Query) overlaps(arg ref.Val) ref.Val {
+	nestedExpression, ok := arg.(types.String)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(arg)
+	}
+	if q.current == nil {
+		return types.NewErr("%s() called outside of evaluation", overlapsFunction)
+	}
+
+	interval := q.current.interval
+	for _, candidate := range q.current.nestedMatches[string(nestedExpression)] {
+		if !intervalsOverlap(interval, candidate) {
+			continue
+		}
+		// an interval does not overlap itself.
+		if candidate.From.Equal(interval.From) && candidate.To.Equal(interval.To) && reflect.DeepEqual(candidate, interval) {
+			continue
+		}
+		return types.True
+	}
+	return types.False
+}
+
+// intervalsOverlap treats a zero To as an interval that has not ended.
+func intervalsOverlap(a, b monitorapi.Interval) bool {
+	if !a.To.IsZero() && b.From.After(a.To) {
+		return false
+	}
+	if !b.To.IsZero() && a.From.After(b.To) {
+		return false
+	}
+	return true
+}
+
+func activationFor(interval monitorapi.Interval) map[string]interface{} {
+	locator := make(map[string]string, len(interval.Locator.Keys))
+	for k, v := range interval.Locator.Keys {
+		locator[string(k)] = v
+	}
+	annotations := make(map[string]string, len(interval.Message.Annotations))
+	for k, v := range interval.Message.Annotations {
+		annotations[string(k)] = v
+	}
+
+	to := interval.To
+	if to.IsZero() {
+		to = interval.From
+	}
+	var duration time.Duration
+	if to.After(interval.From) {
+		duration = to.Sub(interval.From)
+	}
+
+	return map[string]interface{}{
+		"source":      string(interval.Source),
+		"level":       interval.Level.String(),
+		"display":     interval.Display,
+		"locatorType": string(interval.Locator.Type),
+		"locator":     locator,
+		"reason":      string(interval.Message.Reason),
+		"cause":       interval.Message.Cause,
+		"message":     interval.Message.HumanMessage,
+		"annotations": annotations,
+		"from":        interval.From,
+		"to":          to,
+		"duration":    duration,
+	}
+}
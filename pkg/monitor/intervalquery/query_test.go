@@ -0,0 +1,104 @@
+package intervalquery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestQuery(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	notReady := monitorapi.NewInterval(monitorapi.SourceNodeMonitor, monitorapi.Warning).
+		Locator(monitorapi.NewLocator().NodeFromName("node-a")).
+		Message(monitorapi.NewMessage().Reason(monitorapi.NodeNotReadyReason).HumanMessage("node went not ready")).
+		Build(start, start.Add(2*time.Minute))
+	podEvent := monitorapi.NewInterval(monitorapi.SourceKubeEvent, monitorapi.Info).
+		Locator(monitorapi.NewLocator().PodFromNames("openshift-etcd", "etcd-0", "uid")).
+		Message(monitorapi.NewMessage().Reason("Killing").HumanMessage("stopping container etcd").WithAnnotation("count", "3")).
+		Build(start.Add(10*time.Minute), start.Add(10*time.Minute))
+	disruption := monitorapi.NewInterval(monitorapi.SourceDisruption, monitorapi.Error).
+		Locator(monitorapi.NewLocator().DisruptionRequiredOnly("kube-api-new-connections", "kube-api")).
+		Message(monitorapi.NewMessage().Reason(monitorapi.DisruptionBeganEventReason).HumanMessage("disrupted")).
+		Build(start.Add(time.Minute), start.Add(90*time.Second))
+	intervals := monitorapi.Intervals{notReady, podEvent, disruption}
+
+	tests := []struct {
+		name       string
+		expression string
+		expected   monitorapi.Intervals
+	}{
+		{
+			name:       "level",
+			expression: `level == "Error"`,
+			expected:   monitorapi.Intervals{disruption},
+		},
+		{
+			name:       "locator key",
+			expression: `source == "KubeEvent" && locator.namespace == "openshift-etcd"`,
+			expected:   monitorapi.Intervals{podEvent},
+		},
+		{
+			name:       "missing locator key does not match",
+			expression: `locator.namespace.startsWith("openshift-")`,
+			expected:   monitorapi.Intervals{podEvent},
+		},
+		{
+			name:       "annotations and reason",
+			expression: `reason == "Killing" && annotations["count"] == "3"`,
+			expected:   monitorapi.Intervals{podEvent},
+		},
+		{
+			name:       "duration",
+			expression: `duration > duration("1m")`,
+			expected:   monitorapi.Intervals{notReady},
+		},
+		{
+			name:       "message regex",
+			expression: `message.matches("^(node|stopping)")`,
+			expected:   monitorapi.Intervals{notReady, podEvent},
+		},
+		{
+			name:       "overlaps",
+			expression: `reason == "NotReady" && overlaps("source == 'Disruption'")`,
+			expected:   monitorapi.Intervals{notReady},
+		},
+		{
+			name:       "does not overlap itself",
+			expression: `overlaps("level == 'Error'")`,
+			expected:   monitorapi.Intervals{notReady},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Compile(tt.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := q.Filter(intervals)
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected.Strings(), actual.Strings())
+			}
+			for i := range actual {
+				if actual[i].String() != tt.expected[i].String() {
+					t.Fatalf("expected %v, got %v", tt.expected.Strings(), actual.Strings())
+				}
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expression := range []string{
+		`level ==`,
+		`level`,
+		`unknown == "foo"`,
+		`overlaps("level ==")`,
+		`overlaps(message)`,
+	} {
+		if _, err := Compile(expression); err == nil {
+			t.Errorf("expected %q to fail to compile", expression)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+)
+
+// generatedFixtureSize is the number of intervals generated for the benchmarks when INTERVALS_FIXTURE is not set,
+// about five and a half hours of a busy run.
+const generatedFixtureSize = 200000
+
+// The benchmarks run on the intervals file named by INTERVALS_FIXTURE, the e2e-events_*.json of a long upgrade job
+// for instance, and on generatedFixtureSize generated intervals without one.
+//
+//	INTERVALS_FIXTURE=e2e-events_20240101-000000.json go test ./pkg/monitor -run xxx -bench Intervals -benchmem
+var (
+	fixtureOnce      sync.Once
+	fixture          monitorapi.Intervals
+	fixtureCompacted monitorapi.Intervals
+)
+
+func benchmarkFixture(b *testing.B) monitorapi.Intervals {
+	fixtureOnce.Do(func() {
+		if path := os.Getenv("INTERVALS_FIXTURE"); len(path) > 0 {
+			intervals, err := monitorserialization.EventsFromFile(path)
+			if err != nil {
+				panic(err)
+			}
+			fixture = intervals
+		} else {
+			fixture = generateIntervals(generatedFixtureSize, rand.New(rand.NewSource(1)))
+		}
+		fixtureCompacted = CompactIntervals(fixture)
+	})
+	b.ReportMetric(float64(len(fixture)), "intervals")
+	return fixture
+}
+
+func BenchmarkIntervalsSort(b *testing.B) {
+	intervals := benchmarkFixture(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sorted := make(monitorapi.Intervals, len(intervals))
+		copy(sorted, intervals)
+		sort.Sort(sorted)
+	}
+}
+
+// BenchmarkIntervalsRecorderRead reads from a recorder the way the monitor does after every phase.
+func BenchmarkIntervalsRecorderRead(b *testing.B) {
+	intervals := benchmarkFixture(b)
+	recorder := NewRecorder()
+	recorder.AddIntervals(intervals...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		recorder.AddIntervals(intervals[i%len(intervals)])
+		recorder.Intervals(time.Time{}, time.Time{})
+	}
+}
+
+func BenchmarkIntervalsSerialize(b *testing.B) {
+	benchmarkFixture(b)
+	for _, bench := range []struct {
+		name      string
+		intervals monitorapi.Intervals
+	}{
+		{name: "raw", intervals: fixture},
+		{name: "compacted", intervals: fixtureCompacted},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportMetric(float64(len(bench.intervals)), "intervals")
+			for i := 0; i < b.N; i++ {
+				if _, err := monitorserialization.IntervalsToJSON(bench.intervals); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(bench.name+"-compact", func(b *testing.B) {
+			b.ReportMetric(float64(len(bench.intervals)), "intervals")
+			for i := 0; i < b.N; i++ {
+				if err := monitorserialization.IntervalsToCompact(io.Discard, bench.intervals); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkIntervalsCompact(b *testing.B) {
+	intervals := benchmarkFixture(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CompactIntervals(intervals)
+	}
+}
+
+// BenchmarkIntervalsEvaluate narrows intervals down the way monitor tests evaluating a run do: a time range, then
+// the intervals of one source in a few namespaces.
+func BenchmarkIntervalsEvaluate(b *testing.B) {
+	intervals := benchmarkFixture(b)
+	sorted := make(monitorapi.Intervals, len(intervals))
+	copy(sorted, intervals)
+	sort.Sort(sorted)
+	recorder := NewRecorder().(*recorder)
+	recorder.AddIntervals(intervals...)
+	from := sorted[len(sorted)/2].From
+	to := from.Add(10 * time.Minute)
+	namespaces := []string{"namespace-1", "namespace-2"}
+	errors := func(interval monitorapi.Interval) bool { return interval.Level == monitorapi.Error }
+
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sorted.Cut(from, to)
+			sorted.Filter(func(interval monitorapi.Interval) bool {
+				namespace := interval.Locator.Keys[monitorapi.LocatorNamespaceKey]
+				return interval.Source == monitorapi.SourcePodMonitor && (namespace == namespaces[0] || namespace == namespaces[1]) && errors(interval)
+			})
+		}
+	})
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			recorder.Cut(from, to)
+			recorder.Filter(IntervalSelector{Sources: []monitorapi.IntervalSource{monitorapi.SourcePodMonitor}, Namespaces: namespaces}, errors)
+		}
+	})
+}
@@ -0,0 +1,275 @@
+// Package intervalserver serves the intervals of a running monitor over HTTP, so that they can be watched
+// while a long run is in progress instead of only once it has finished.
+//
+// The following endpoints are served:
+//
+//	GET /api/v1/intervals?from=&to=&query=
+//	    the intervals recorded so far, as an EventIntervalList like the artifacts written at the end of the run.
+//	    from and to are RFC3339 times and default to the whole run, query is an intervalquery expression.
+//	GET /api/v1/intervals/watch?query=&replay=true
+//	    a server-sent-events stream of "added" and "updated" events as intervals are recorded and ended.  The
+//	    id of an event identifies its interval, updates have the id the interval was added with.  With replay
+//	    the intervals recorded so far are sent first, in the order they were recorded, as "added" events with
+//	    their id, so that later updates to intervals still open at connect time can be matched.  overlaps()
+//	    is not available to watch queries.
+//	GET /api/v1/resources
+//	    the keys of the resources currently tracked, by resource type.
+package intervalserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/intervalquery"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+)
+
+const (
+	// watchBuffer is how many updates a watcher may fall behind before it is dropped.
+	watchBuffer = 10000
+	// heartbeatInterval keeps idle watch connections from being closed by proxies.
+	heartbeatInterval = 15 * time.Second
+)
+
+type Server struct {
+	recorder monitor.StreamingRecorder
+	server   *http.Server
+
+	// stop ends the requests in progress, watches would otherwise keep Shutdown waiting.
+	stop context.CancelFunc
+}
+
+func NewServer(recorder monitor.StreamingRecorder) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{recorder: recorder, stop: cancel}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/intervals", s.handleIntervals)
+	mux.HandleFunc("/api/v1/intervals/watch", s.handleWatch)
+	mux.HandleFunc("/api/v1/resources", s.handleResources)
+	s.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	return s
+}
+
+// Handler returns the handler for all endpoints.
+func (s *Server) Handler() http.Handler {
+	return s.server.Handler
+}
+
+// Start listens on address and serves in the background until Shutdown.  It returns the address listened on,
+// which differs from address when it has port 0.
+func (s *Server) Start(address string) (net.Addr, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serve intervals: %w", err)
+	}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.WithError(err).Error("Interval server stopped")
+		}
+	}()
+	return listener.Addr(), nil
+}
+
+// Shutdown stops serving.  Open watches are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.stop()
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) handleIntervals(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	from, err := parseTime(req, "from")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := parseTime(req, "to")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query, err := parseQuery(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	intervals := s.recorder.Intervals(from, to)
+	if query != nil {
+		intervals = query.Filter(intervals)
+	}
+	data, err := monitorserialization.IntervalsToJSON(intervals)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (s *Server) handleWatch(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	query, err := parseQuery(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	matches := func(monitorapi.Interval) bool { return true }
+	if query != nil {
+		matches = query.MatchesFunc(nil)
+	}
+
+	ctx := req.Context()
+	var replayed []monitor.IntervalUpdate
+	var updates <-chan monitor.IntervalUpdate
+	if req.URL.Query().Get("replay") == "true" {
+		// list and watch together, so that nothing recorded in between is missed or sent twice
+		replayed, updates = s.recorder.ListAndWatch(ctx, watchBuffer)
+	} else {
+		updates = s.recorder.Watch(ctx, watchBuffer)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for _, update := range replayed {
+		if !matches(update.Interval) {
+			continue
+		}
+		if err := writeEvent(w, update.Type, eventID(update.ID), update.Interval); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case update, ok := <-updates:
+			if !ok {
+				// the watcher fell too far behind, the client has to reconnect and replay
+				fmt.Fprint(w, "event: dropped\ndata: {}\n\n")
+				flusher.Flush()
+				return
+			}
+			if !matches(update.Interval) {
+				continue
+			}
+			if err := writeEvent(w, update.Type, eventID(update.ID), update.Interval); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// eventID is the id of the event for the interval with id, empty when the id is unknown.
+func eventID(id int) string {
+	if id < 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
+
+func writeEvent(w http.ResponseWriter, eventType monitor.IntervalUpdateType, id string, interval monitorapi.Interval) error {
+	data, err := monitorserialization.IntervalToOneLineJSON(interval)
+	if err != nil {
+		return err
+	}
+	if len(id) > 0 {
+		_, err = fmt.Fprintf(w, "event: %s\nid: %s\ndata: %s\n\n", eventType, id, data)
+	} else {
+		_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, data)
+	}
+	return err
+}
+
+func (s *Server) handleResources(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	keys := map[string][]resourceKey{}
+	for resourceType, instances := range s.recorder.CurrentResourceState() {
+		resourceKeys := make([]resourceKey, 0, len(instances))
+		for key := range instances {
+			resourceKeys = append(resourceKeys, resourceKey(key))
+		}
+		sort.Slice(resourceKeys, func(i, j int) bool {
+			if resourceKeys[i].Namespace != resourceKeys[j].Namespace {
+				return resourceKeys[i].Namespace < resourceKeys[j].Namespace
+			}
+			if resourceKeys[i].Name != resourceKeys[j].Name {
+				return resourceKeys[i].Name < resourceKeys[j].Name
+			}
+			return resourceKeys[i].UID < resourceKeys[j].UID
+		})
+		keys[resourceType] = resourceKeys
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(keys); err != nil {
+		logrus.WithError(err).Warning("Failed to write resources")
+	}
+}
+
+// resourceKey is the JSON form of a monitorapi.InstanceKey.
+type resourceKey struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	UID       string `json:"uid"`
+}
+
+func parseTime(req *http.Request, name string) (time.Time, error) {
+	value := req.URL.Query().Get(name)
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return t, nil
+}
+
+func parseQuery(req *http.Request) (*intervalquery.Query, error) {
+	expression := req.URL.Query().Get("query")
+	if len(expression) == 0 {
+		return nil, nil
+	}
+	return intervalquery.Compile(expression)
+}
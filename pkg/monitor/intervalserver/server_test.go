@@ -0,0 +1,135 @@
+package intervalserver
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+)
+
+func TestServer(t *testing.T) {
+	recorder := monitor.WrapWithStreamingRecorder(monitor.NewRecorder())
+	now := time.Now().UTC().Truncate(time.Second)
+	recorder.AddIntervals(
+		newInterval(monitorapi.Info, "info", now),
+		newInterval(monitorapi.Error, "error", now.Add(time.Minute)),
+	)
+	recorder.RecordResource("pods", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod", UID: "uid"}})
+
+	server := httptest.NewServer(NewServer(recorder).Handler())
+	defer server.Close()
+
+	// snapshot with a query
+	resp, err := http.Get(server.URL + "/api/v1/intervals?query=" + url.QueryEscape(`level == "Error"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	intervals, err := monitorserialization.IntervalsFromJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intervals) != 1 || intervals[0].Message.HumanMessage != "error" {
+		t.Errorf("expected only the error interval, got %v", intervals.Strings())
+	}
+
+	// invalid queries are rejected
+	resp, err = http.Get(server.URL + "/api/v1/intervals?query=" + url.QueryEscape(`level ==`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected an invalid query to be rejected, got %d", resp.StatusCode)
+	}
+
+	// resources
+	resp, err = http.Get(server.URL + "/api/v1/resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string][]resourceKey{}
+	err = json.NewDecoder(resp.Body).Decode(&keys)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (resourceKey{Namespace: "ns", Name: "pod", UID: "uid"}); len(keys["pods"]) != 1 || keys["pods"][0] != expected {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+
+	// watch, replaying the error interval and then streaming new ones
+	resp, err = http.Get(server.URL + "/api/v1/intervals/watch?replay=true&query=" + url.QueryEscape(`level == "Error"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+	events := bufio.NewReader(resp.Body)
+
+	replayed := readEvent(t, events)
+	if replayed["event"] != "added" || replayed["id"] != "1" || !strings.Contains(replayed["data"], `"humanMessage":"error"`) {
+		t.Errorf("expected the error interval to be replayed, got %v", replayed)
+	}
+
+	recorder.AddIntervals(newInterval(monitorapi.Info, "filtered out", now))
+	started := recorder.StartInterval(newInterval(monitorapi.Error, "started", now.Add(2*time.Minute)))
+	recorder.EndInterval(started, now.Add(3*time.Minute))
+
+	added := readEvent(t, events)
+	if added["event"] != "added" || added["id"] != "3" || !strings.Contains(added["data"], `"humanMessage":"started"`) {
+		t.Errorf("expected the started interval, got %v", added)
+	}
+	updated := readEvent(t, events)
+	if updated["event"] != "updated" || updated["id"] != "3" {
+		t.Errorf("expected the started interval to be updated, got %v", updated)
+	}
+}
+
+// readEvent reads the fields of the next server-sent event.
+func readEvent(t *testing.T, r *bufio.Reader) map[string]string {
+	fields := map[string]string{}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if len(line) == 0 {
+			if len(fields) > 0 {
+				return fields
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		name, value, _ := strings.Cut(line, ": ")
+		fields[name] = value
+	}
+}
+
+func newInterval(level monitorapi.IntervalLevel, message string, t time.Time) monitorapi.Interval {
+	return monitorapi.NewInterval(monitorapi.SourceE2ETest, level).
+		Locator(monitorapi.NewLocator().E2ETestSuite("suite")).
+		Message(monitorapi.NewMessage().HumanMessage(message)).
+		Build(t, t)
+}
@@ -203,7 +203,7 @@ func (m *Monitor) SerializeResults(ctx context.Context, junitSuiteName, timeSuff
 		return err
 	}
 
-	if err := riskanalysis.WriteJobRunTestFailureSummary(m.storageDir, timeSuffix, junitSuite, "", "_monitor"); err != nil {
+	if err := riskanalysis.WriteJobRunTestFailureSummary(m.storageDir, timeSuffix, junitSuite, "", "_monitor", nil); err != nil {
 		fmt.Fprintf(os.Stderr, "error: Unable to write e2e job run failures summary: %v", err)
 	}
 
@@ -514,6 +514,12 @@ func (b *LocatorBuilder) E2ETest(testName string) Locator {
 	return b.Build()
 }
 
+func (b *LocatorBuilder) E2ETestSuite(suiteName string) Locator {
+	b.targetType = LocatorTypeE2ETestSuite
+	b.annotations[LocatorE2ETestSuiteKey] = suiteName
+	return b.Build()
+}
+
 func (b *LocatorBuilder) ClusterOperator(name string) Locator {
 	b.targetType = LocatorTypeClusterOperator
 	b.annotations[LocatorClusterOperatorKey] = name
@@ -7,7 +7,8 @@ import (
 const EventDir = "monitor-events"
 
 // BackendDisruptionSeconds return duration of disruption observed (rounded to nearest second),
-// disruptionMessages, and New or Reused connection type.
+// disruptionMessages, and New or Reused connection type.  Disruption observed at the same time by
+// several samplers of the backend is counted once.
 func BackendDisruptionSeconds(backendDisruptionName string, events Intervals) (time.Duration, []string) {
 	disruptionEvents := events.Filter(
 		And(
@@ -17,7 +18,8 @@ func BackendDisruptionSeconds(backendDisruptionName string, events Intervals) (t
 	)
 	disruptionMessages := disruptionEvents.Strings()
 
-	return disruptionEvents.Duration(1 * time.Second).Round(time.Second), disruptionMessages
+	// backends sampled from several places record the same outage more than once
+	return disruptionEvents.Union().Duration(1 * time.Second).Round(time.Second), disruptionMessages
 }
 
 func IsDisruptionEvent(eventInterval Interval) bool {
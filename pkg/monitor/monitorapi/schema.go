@@ -0,0 +1,166 @@
+package monitorapi
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// KnownLocatorKeys are the locator keys declared in this package.  New keys must be added here for intervals using
+// them to pass strict validation.
+var KnownLocatorKeys = sets.New[LocatorKey](
+	LocatorClusterOperatorKey,
+	LocatorClusterVersionKey,
+	LocatorNamespaceKey,
+	LocatorDeploymentKey,
+	LocatorDaemonSetKey,
+	LocatorStatefulSetKey,
+	LocatorNodeKey,
+	LocatorMachineKey,
+	LocatorEtcdMemberKey,
+	LocatorNameKey,
+	LocatorHmsgKey,
+	LocatorInstanceKey,
+	LocatorPodKey,
+	LocatorUIDKey,
+	LocatorMirrorUIDKey,
+	LocatorMetricsPathKey,
+	LocatorServiceKey,
+	LocatorContainerKey,
+	LocatorAlertKey,
+	LocatorRouteKey,
+	LocatorBackendDisruptionNameKey,
+	LocatorDisruptionKey,
+	LocatorE2ETestKey,
+	LocatorE2ETestSuiteKey,
+	LocatorLoadBalancerKey,
+	LocatorConnectionKey,
+	LocatorProtocolKey,
+	LocatorTargetKey,
+	LocatorRowKey,
+	LocatorServerKey,
+	LocatorMetricKey,
+	LocatorAPIUnreachableHostKey,
+	LocatorOnPremKubeapiUnreachableFromHaproxyKey,
+	LocatorTypeKubeletSyncLoopProbeType,
+	LocatorTypeKubeletSyncLoopPLEGType,
+	LocatorStaticPodInstallType,
+)
+
+// KnownReasons are the interval reasons declared in this package.  New reasons must be added here for intervals using
+// them to pass strict validation.
+var KnownReasons = sets.New[IntervalReason](
+	IPTablesNotPermitted,
+	DisruptionBeganEventReason,
+	DisruptionEndedEventReason,
+	DisruptionSamplerOutageBeganEventReason,
+	DisruptionLatencyDegradedEventReason,
+	DisruptionLatencySummaryEventReason,
+	GracefulAPIServerShutdown,
+	IncompleteAPIServerShutdown,
+	HttpClientConnectionLost,
+	PodPendingReason,
+	PodNotPendingReason,
+	PodReasonCreated,
+	PodReasonGracefulDeleteStarted,
+	PodReasonForceDelete,
+	PodReasonDeleted,
+	PodReasonScheduled,
+	PodReasonEvicted,
+	PodReasonPreempted,
+	PodReasonFailed,
+	PodReasonReady,
+	PodReasonNotReady,
+	ContainerReasonContainerExit,
+	ContainerReasonContainerStart,
+	ContainerReasonContainerWait,
+	ContainerReasonReadinessFailed,
+	ContainerReasonReadinessErrored,
+	ContainerReasonStartupProbeFailed,
+	ContainerReasonReady,
+	ContainerReasonRestarted,
+	ContainerReasonNotReady,
+	TerminationStateCleared,
+	PodReasonDeletedBeforeScheduling,
+	PodReasonDeletedAfterCompletion,
+	NodeUpdateReason,
+	NodeNotReadyReason,
+	NodeFailedLease,
+	NodeUnexpectedReadyReason,
+	NodeUnexpectedUnreachableReason,
+	NodeUnreachable,
+	NodeFailedLeaseBackoff,
+	NodeDiskPressure,
+	NodeNoDiskPressure,
+	MachineConfigChangeReason,
+	MachineConfigReachedReason,
+	MachineCreated,
+	MachineDeletedInAPI,
+	MachinePhaseChanged,
+	MachinePhase,
+	OnPremHaproxyDetectsDown,
+	OnPremHaproxyStatusChange,
+	Timeout,
+	E2ETestStarted,
+	E2ETestFinished,
+	E2ETestRunInterrupted,
+	CloudMetricsExtrenuous,
+	FailedToDeleteCGroupsPath,
+	FailedToAuthenticateWithOpenShiftUser,
+	FailedContactingAPIReason,
+	UpgradeStartedReason,
+	UpgradeVersionReason,
+	UpgradeRollbackReason,
+	UpgradeFailedReason,
+	UpgradeCompleteReason,
+	NodeInstallerReason,
+	APIUnreachableFromClientMetrics,
+	LeaseAcquiring,
+	LeaseAcquiringStarted,
+	LeaseAcquired,
+	ReasonBadOperatorApply,
+	ReasonKubeAPIServer500s,
+	ReasonHighGeneration,
+	ReasonInvalidGeneration,
+	ReasonEtcdBootstrap,
+	ChaosFaultInjected,
+	ChaosFaultFailed,
+	DisruptionAttributed,
+)
+
+// freeFormKeyLocatorTypes name their keys after what they locate, the kind of the object a kube event is about for
+// instance, so any key is allowed.
+var freeFormKeyLocatorTypes = sets.New[LocatorType](
+	LocatorTypeKind,
+	LocatorTypeKubeEvent,
+)
+
+// freeFormReasonSources copy their reasons from what they observe in the cluster, event reasons or condition reasons,
+// so any reason is allowed.
+var freeFormReasonSources = sets.New[IntervalSource](
+	SourceKubeEvent,
+	SourceNodeMonitor,
+	SourceClusterOperatorMonitor,
+	SourceOperatorState,
+	SourceKubeletLog,
+	SourceStaticPodInstallMonitor,
+	SourcePathologicalEventMarker,
+	SourceAlert,
+)
+
+// ValidateInterval returns what is wrong with interval in strict mode: locator keys and reasons must be known, unless
+// the locator type or source allows anything.
+func ValidateInterval(interval Interval) []error {
+	var errs []error
+	if !freeFormKeyLocatorTypes.Has(interval.Locator.Type) {
+		for _, k := range sets.List(sets.KeySet(interval.Locator.Keys)) {
+			if !KnownLocatorKeys.Has(k) {
+				errs = append(errs, fmt.Errorf("unknown locator key %q", k))
+			}
+		}
+	}
+	if len(interval.Message.Reason) > 0 && !freeFormReasonSources.Has(interval.Source) && !KnownReasons.Has(interval.Message.Reason) {
+		errs = append(errs, fmt.Errorf("unknown reason %q for source %q", interval.Message.Reason, interval.Source))
+	}
+	return errs
+}
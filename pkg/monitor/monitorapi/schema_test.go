@@ -0,0 +1,87 @@
+package monitorapi
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+// TestKnownKeysAndReasons makes sure new locator keys and reasons declared in types.go are added to the known sets.
+func TestKnownKeysAndReasons(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "types.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			typeName, ok := value.Type.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			for _, name := range value.Names {
+				switch typeName.Name {
+				case "LocatorKey":
+					if !KnownLocatorKeys.Has(constantValue[LocatorKey](t, value)) {
+						t.Errorf("%s is missing from KnownLocatorKeys", name.Name)
+					}
+				case "IntervalReason":
+					if !KnownReasons.Has(constantValue[IntervalReason](t, value)) {
+						t.Errorf("%s is missing from KnownReasons", name.Name)
+					}
+				}
+			}
+		}
+	}
+}
+
+func constantValue[T ~string](t *testing.T, value *ast.ValueSpec) T {
+	literal, ok := value.Values[0].(*ast.BasicLit)
+	if !ok || literal.Kind != token.STRING {
+		t.Fatalf("expected a string literal, got %#v", value.Values[0])
+	}
+	return T(literal.Value[1 : len(literal.Value)-1])
+}
+
+func TestValidateInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval Interval
+		errors   int
+	}{
+		{
+			name: "known",
+			interval: NewInterval(SourcePodMonitor, Info).
+				Locator(NewLocator().PodFromNames("ns", "pod", "uid")).
+				Message(NewMessage().Reason(PodReasonCreated)).
+				BuildNow(),
+		},
+		{
+			name: "unknown key and reason",
+			interval: NewInterval(SourcePodMonitor, Info).
+				Locator(Locator{Type: LocatorTypePod, Keys: map[LocatorKey]string{"shoe": "left"}}).
+				Message(NewMessage().Reason("Dancing")).
+				BuildNow(),
+			errors: 2,
+		},
+		{
+			name: "kube event",
+			interval: NewInterval(SourceKubeEvent, Info).
+				Locator(Locator{Type: LocatorTypeKind, Keys: map[LocatorKey]string{"shoe": "left"}}).
+				Message(NewMessage().Reason("Dancing")).
+				BuildNow(),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if errs := ValidateInterval(test.interval); len(errs) != test.errors {
+				t.Errorf("expected %d errors, got %v", test.errors, errs)
+			}
+		})
+	}
+}
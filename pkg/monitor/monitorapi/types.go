@@ -105,6 +105,7 @@ const (
 	LocatorTypeDisruption      LocatorType = "Disruption"
 	LocatorTypeKubeEvent       LocatorType = "KubeEvent"
 	LocatorTypeE2ETest         LocatorType = "E2ETest"
+	LocatorTypeE2ETestSuite    LocatorType = "E2ETestSuite"
 	LocatorTypeAPIServer       LocatorType = "APIServer"
 	LocatorTypeClusterVersion  LocatorType = "ClusterVersion"
 	LocatorTypeKind            LocatorType = "Kind"
@@ -147,6 +148,7 @@ const (
 	LocatorBackendDisruptionNameKey LocatorKey = "backend-disruption-name"
 	LocatorDisruptionKey            LocatorKey = "disruption"
 	LocatorE2ETestKey               LocatorKey = "e2e-test"
+	LocatorE2ETestSuiteKey          LocatorKey = "e2e-test-suite"
 	LocatorLoadBalancerKey          LocatorKey = "load-balancer"
 	LocatorConnectionKey            LocatorKey = "connection"
 	LocatorProtocolKey              LocatorKey = "protocol"
@@ -178,6 +180,8 @@ const (
 	DisruptionBeganEventReason              IntervalReason = "DisruptionBegan"
 	DisruptionEndedEventReason              IntervalReason = "DisruptionEnded"
 	DisruptionSamplerOutageBeganEventReason IntervalReason = "DisruptionSamplerOutageBegan"
+	DisruptionLatencyDegradedEventReason    IntervalReason = "DisruptionLatencyDegraded"
+	DisruptionLatencySummaryEventReason     IntervalReason = "DisruptionLatencySummary"
 	GracefulAPIServerShutdown               IntervalReason = "GracefulAPIServerShutdown"
 	IncompleteAPIServerShutdown             IntervalReason = "IncompleteAPIServerShutdown"
 
@@ -236,6 +240,8 @@ const (
 
 	E2ETestStarted  IntervalReason = "E2ETestStarted"
 	E2ETestFinished IntervalReason = "E2ETestFinished"
+	// E2ETestRunInterrupted covers the time between an interrupted test run and its resumption.
+	E2ETestRunInterrupted IntervalReason = "E2ETestRunInterrupted"
 
 	CloudMetricsExtrenuous                IntervalReason = "CloudMetricsExtrenuous"
 	FailedToDeleteCGroupsPath             IntervalReason = "FailedToDeleteCGroupsPath"
@@ -264,6 +270,13 @@ const (
 	ReasonInvalidGeneration IntervalReason = "GenerationViolation"
 
 	ReasonEtcdBootstrap IntervalReason = "EtcdBootstrap"
+
+	// a fault was injected in the cluster on purpose, see the chaos package
+	ChaosFaultInjected IntervalReason = "ChaosFaultInjected"
+	ChaosFaultFailed   IntervalReason = "ChaosFaultFailed"
+
+	// the likely cause of a disruption interval, see the disruptioncause package
+	DisruptionAttributed IntervalReason = "DisruptionAttributed"
 )
 
 type AnnotationKey string
@@ -297,6 +310,8 @@ const (
 	AnnotationStatus         AnnotationKey = "status"
 	AnnotationCondition      AnnotationKey = "condition"
 	AnnotationPercentage     AnnotationKey = "percentage"
+	AnnotationChaosFault     AnnotationKey = "fault"
+	AnnotationLatency        AnnotationKey = "latency"
 )
 
 // ConstructionOwner was originally meant to signify that an interval was derived from other intervals.
@@ -368,6 +383,13 @@ const (
 	SourceGenerationMonitor IntervalSource = "GenerationMonitor"
 
 	SourceStaticPodInstallMonitor IntervalSource = "StaticPodInstallMonitor"
+
+	// SourceChaos intervals are faults injected on purpose, so that the disruption they induce can be told apart.
+	SourceChaos IntervalSource = "Chaos"
+	// SourceDisruptionCause intervals span a disruption interval, with the same locator, and annotate its likely cause.
+	SourceDisruptionCause IntervalSource = "DisruptionCause"
+	// SourceDisruptionLatency intervals span the sampling of a backend and carry the latency of its successful samples.
+	SourceDisruptionLatency IntervalSource = "DisruptionLatency"
 )
 
 type Interval struct {
@@ -459,6 +481,19 @@ func (i Locator) OldLocator() string {
 	return annotationString
 }
 
+// Equal reports whether the locators have the same type and keys.  It is much cheaper than comparing OldLocator.
+func (i Locator) Equal(other Locator) bool {
+	if i.Type != other.Type || len(i.Keys) != len(other.Keys) {
+		return false
+	}
+	for k, v := range i.Keys {
+		if otherV, ok := other.Keys[k]; !ok || otherV != v {
+			return false
+		}
+	}
+	return true
+}
+
 func (i Locator) HasKey(k LocatorKey) bool {
 	_, ok := i.Keys[k]
 	return ok
@@ -470,20 +505,20 @@ func (i Locator) HasKey(k LocatorKey) bool {
 // got container events separated from their pod events on the intervals chart.
 // This will hopefully eventually go away but for now we need it.
 // Courtesy of ChatGPT but unit tested.
-func sortKeys(keys []string) []string {
-
-	// Ensure these keys appear in this order. Other keys can be mixed in and will appear at the end in alphabetical
-	// order.
-	orderedKeys := []string{"namespace", "node", "pod", "uid", "server", "container", "shutdown", "row"}
-
-	// Create a map to store the indices of keys in the orderedKeys array.
-	// This will allow us to efficiently check if a key is in orderedKeys and find its position.
-	orderedKeyIndices := make(map[string]int)
-
-	for i, key := range orderedKeys {
-		orderedKeyIndices[key] = i
-	}
+// orderedKeyIndices holds the position of the keys that sortKeys puts first, in this order.  Other keys can be
+// mixed in and will appear at the end in alphabetical order.
+var orderedKeyIndices = map[string]int{
+	"namespace": 0,
+	"node":      1,
+	"pod":       2,
+	"uid":       3,
+	"server":    4,
+	"container": 5,
+	"shutdown":  6,
+	"row":       7,
+}
 
+func sortKeys(keys []string) []string {
 	// Define a custom sorting function that orders the keys based on the orderedKeys array.
 	sort.Slice(keys, func(i, j int) bool {
 		// Get the indices of keys i and j in orderedKeys.
@@ -536,30 +571,38 @@ type Intervals []Interval
 var _ sort.Interface = Intervals{}
 
 func (intervals Intervals) Less(i, j int) bool {
+	return intervals[i].Less(&intervals[j])
+}
+
+// Less reports whether i sorts before other.
+func (i *Interval) Less(other *Interval) bool {
 	// currently synced with https://github.com/openshift/origin/blob/9b001745ec8006eb406bd92e3555d1070b9b656e/pkg/monitor/serialization/serialize.go#L175
 
-	switch d := intervals[i].From.Sub(intervals[j].From); {
+	switch d := i.From.Sub(other.From); {
 	case d < 0:
 		return true
 	case d > 0:
 		return false
 	}
-	switch d := intervals[i].To.Sub(intervals[j].To); {
+	switch d := i.To.Sub(other.To); {
 	case d < 0:
 		return true
 	case d > 0:
 		return false
 	}
-	if intervals[i].Message.Reason != intervals[j].Message.Reason {
-		return intervals[i].Message.Reason < intervals[j].Message.Reason
+	if i.Message.Reason != other.Message.Reason {
+		return i.Message.Reason < other.Message.Reason
 	}
-	if intervals[i].Message.HumanMessage != intervals[j].Message.HumanMessage {
-		return intervals[i].Message.HumanMessage < intervals[j].Message.HumanMessage
+	if i.Message.HumanMessage != other.Message.HumanMessage {
+		return i.Message.HumanMessage < other.Message.HumanMessage
 	}
 
-	// TODO: this could be a bit slow, but leaving it simple if we can get away with it. Sorting structured locators
-	// that use keys is trickier than the old flat string method.
-	return intervals[i].Locator.OldLocator() < intervals[j].Locator.OldLocator()
+	// Sorting structured locators that use keys is trickier than the old flat string method.  Building the old
+	// locators is slow, so avoid it for the common case of identical locators.
+	if i.Locator.Equal(other.Locator) {
+		return false
+	}
+	return i.Locator.OldLocator() < other.Locator.OldLocator()
 }
 func (intervals Intervals) Len() int { return len(intervals) }
 func (intervals Intervals) Swap(i, j int) {
@@ -601,6 +644,33 @@ func (intervals Intervals) Duration(minCurrentDuration time.Duration) time.Durat
 	return totalDuration
 }
 
+// Union returns the time covered by intervals as intervals that do not overlap, sorted by From.  Intervals that overlap
+// or touch are merged into the earliest of them, so that time observed by several samplers, like the samplers of a
+// backend on every node, is only counted once by Duration.  Intervals without a To are left out.
+func (intervals Intervals) Union() Intervals {
+	sorted := make(Intervals, 0, len(intervals))
+	for _, interval := range intervals {
+		if !interval.To.IsZero() {
+			sorted = append(sorted, interval)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].From.Before(sorted[j].From)
+	})
+
+	ret := Intervals{}
+	for _, interval := range sorted {
+		if last := len(ret) - 1; last >= 0 && !interval.From.After(ret[last].To) {
+			if interval.To.After(ret[last].To) {
+				ret[last].To = interval.To
+			}
+			continue
+		}
+		ret = append(ret, interval)
+	}
+	return ret
+}
+
 // EventIntervalMatchesFunc is a function for matching eventIntervales
 type EventIntervalMatchesFunc func(eventInterval Interval) bool
 
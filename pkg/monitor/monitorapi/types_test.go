@@ -100,6 +100,24 @@ func TestIntervals_Duration(t *testing.T) {
 // to structured locators, it would be best if the legacy one kept coming out with
 // keys in the same order they were before, as the intervals chart sorts on these and
 // some are expected to be grouped together.
+func TestIntervals_Union(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+	intervals := Intervals{
+		{From: at(20), To: at(25)},
+		{From: at(0), To: at(10)},
+		{From: at(5), To: at(12)},
+		{From: at(12), To: at(14)},
+		{From: at(6), To: at(8)},
+		// events are left out
+		{From: at(30)},
+	}
+	union := intervals.Union()
+	assert.Equal(t, Intervals{{From: at(0), To: at(14)}, {From: at(20), To: at(25)}}, union)
+	assert.Equal(t, 19*time.Second, union.Duration(time.Second))
+	assert.Equal(t, at(0), intervals[1].From, "the intervals passed in are left as they were")
+}
+
 func TestLocatorOldLocator(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -2,7 +2,6 @@ package monitor
 
 import (
 	"fmt"
-	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -15,6 +14,8 @@ import (
 type recorder struct {
 	lock   sync.Mutex
 	events monitorapi.Intervals
+	// index orders the events for reads, it is brought up to date by the first read after a write.
+	index intervalIndex
 
 	recordedResourceLock sync.Mutex
 	recordedResources    monitorapi.ResourcesMap
@@ -28,6 +29,7 @@ func NewRecorder() monitorapi.Recorder {
 }
 
 var _ monitorapi.Recorder = &recorder{}
+var _ IntervalQuerier = &recorder{}
 
 func (m *recorder) CurrentResourceState() monitorapi.ResourcesMap {
 	m.recordedResourceLock.Lock()
@@ -152,7 +154,9 @@ func (m *recorder) EndInterval(startedInterval int, t time.Time) *monitorapi.Int
 	defer m.lock.Unlock()
 	if startedInterval < len(m.events) {
 		if m.events[startedInterval].From.Before(t) {
-			m.events[startedInterval].To = t
+			m.index.change(m.events, startedInterval, func(interval *monitorapi.Interval) {
+				interval.To = t
+			})
 		}
 		t := m.events[startedInterval]
 		return &t
@@ -177,23 +181,38 @@ func (m *recorder) RecordAt(t time.Time, conditions ...monitorapi.Condition) {
 	m.AddIntervals(intervals...)
 }
 
-func (m *recorder) snapshot() monitorapi.Intervals {
-	m.lock.Lock()
-	defer m.lock.Unlock()
-	eventsCopy := make(monitorapi.Intervals, len(m.events))
-	for i, event := range m.events {
-		eventsCopy[i] = event
-	}
-	return eventsCopy
-}
-
 // Intervals returns all events that occur between from and to, including
 // any sampled conditions that were encountered during that period.
 // Intervals are returned in order of their occurrence. The returned slice
 // is a copy of the monitor's state and is safe to update.
 func (m *recorder) Intervals(from, to time.Time) monitorapi.Intervals {
-	events := m.snapshot()
-	// we must sort *before*, we use the slice function
-	sort.Sort(events)
-	return events.Slice(from, to)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.index.update(m.events)
+	return m.index.slice(m.events, from, to)
+}
+
+// intervalsByID returns a copy of the events in the order they were recorded, so that the position of each
+// interval is the index StartInterval handed out for it.
+func (m *recorder) intervalsByID() monitorapi.Intervals {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return append(monitorapi.Intervals{}, m.events...)
+}
+
+// Filter returns the intervals selected by selector for which matches, if set, returns true, in order of their
+// occurrence.
+func (m *recorder) Filter(selector IntervalSelector, matches monitorapi.EventIntervalMatchesFunc) monitorapi.Intervals {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.index.update(m.events)
+	return m.index.filter(m.events, selector, matches)
+}
+
+// Cut returns the intervals that overlap [from,to), limited to that range, like monitorapi.Intervals.Cut.
+func (m *recorder) Cut(from, to time.Time) monitorapi.Intervals {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.index.update(m.events)
+	return m.index.cut(m.events, from, to)
 }
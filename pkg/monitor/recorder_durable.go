@@ -0,0 +1,514 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	durableSegmentPrefix     = "segment-"
+	durableSegmentSuffix     = ".jsonl"
+	durableResourcesSnapshot = "resources-snapshot.json"
+
+	defaultMaxSegmentBytes  = 64 * 1024 * 1024
+	defaultSnapshotInterval = time.Minute
+)
+
+type segmentOp string
+
+const (
+	segmentOpAdd   segmentOp = "add"
+	segmentOpStart segmentOp = "start"
+	segmentOpEnd   segmentOp = "end"
+)
+
+// segmentEntry is one line of the append-only segment log.  Replaying every entry in order against an empty
+// recorder reproduces the interval list, including the indexes handed out by StartInterval.
+type segmentEntry struct {
+	Op        segmentOp         `json:"op"`
+	Index     int               `json:"index,omitempty"`
+	Intervals []durableInterval `json:"intervals,omitempty"`
+	To        time.Time         `json:"to,omitempty"`
+}
+
+// durableInterval mirrors monitorapi.Interval, but keeps nanosecond precision on From/To, unlike the
+// metav1.Time based serialization used for artifacts.
+type durableInterval struct {
+	Level   string                    `json:"level"`
+	Source  monitorapi.IntervalSource `json:"source,omitempty"`
+	Display bool                      `json:"display,omitempty"`
+	Locator monitorapi.Locator        `json:"locator"`
+	Message monitorapi.Message        `json:"message"`
+	From    time.Time                 `json:"from"`
+	To      time.Time                 `json:"to"`
+}
+
+// resourceSnapshotEntry is a single tracked resource in the resources snapshot.
+type resourceSnapshotEntry struct {
+	ResourceType string                 `json:"resourceType"`
+	Key          monitorapi.InstanceKey `json:"key"`
+	// Object is kept as a plain map because objects from typed informers usually have no kind set, which
+	// unstructured.Unstructured refuses to decode.
+	Object map[string]interface{} `json:"object"`
+}
+
+// DurableRecorder is a monitorapi.Recorder that survives the death of the process that owns it.
+type DurableRecorder interface {
+	monitorapi.Recorder
+	IntervalQuerier
+
+	// SnapshotResources writes the current ResourcesMap to disk.
+	SnapshotResources() error
+	// Close writes a final resource snapshot and closes the segment log.  Writes after Close are kept in memory only.
+	Close() error
+}
+
+// durableRecorder keeps the in-memory recorder as the source of truth for reads and persists every write into
+// an append-only segment log under dir.  Tracked resources change far more often than they are read, so instead
+// of logging them we periodically snapshot the whole ResourcesMap.
+// Segment writes go straight to the file, so they survive the process being OOM killed or evicted.  Segments
+// are fsync'd on rotation and on Close.
+type durableRecorder struct {
+	delegate *recorder
+
+	dir string
+
+	// segmentLock serializes writes to the delegate and the segment log, so that the order of entries
+	// in the log matches the indexes the delegate hands out.
+	segmentLock     sync.Mutex
+	segment         *os.File
+	segmentIndex    int
+	segmentBytes    int64
+	maxSegmentBytes int64
+	closed          bool
+
+	snapshotLock   sync.Mutex
+	stopSnapshotFn context.CancelFunc
+}
+
+var _ DurableRecorder = &durableRecorder{}
+
+// NewDurableRecorder creates a recorder that persists to dir.  If dir already holds the segments and snapshot of a
+// previous recorder, that content is loaded first and new writes continue where the previous recorder stopped.
+// Resources are snapshot every minute until ctx is done or the recorder is closed.
+func NewDurableRecorder(ctx context.Context, dir string) (DurableRecorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("unable to create recorder dir %q: %w", dir, err)
+	}
+
+	delegate := NewRecorder().(*recorder)
+	lastSegment, err := loadDurableRecorderDir(dir, delegate)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &durableRecorder{
+		delegate:        delegate,
+		dir:             dir,
+		segmentIndex:    lastSegment,
+		maxSegmentBytes: defaultMaxSegmentBytes,
+	}
+	if err := ret.rotateSegmentLocked(); err != nil {
+		return nil, err
+	}
+
+	ctx, ret.stopSnapshotFn = context.WithCancel(ctx)
+	go wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := ret.SnapshotResources(); err != nil {
+			logrus.WithError(err).Warn("unable to snapshot recorded resources")
+		}
+	}, defaultSnapshotInterval)
+
+	return ret, nil
+}
+
+// LoadDurableRecorderDir reads the content written by a DurableRecorder into a new in-memory recorder.  The directory
+// is not modified.  Resources are restored with monitorserialization.DecodeResource.
+func LoadDurableRecorderDir(dir string) (monitorapi.Recorder, error) {
+	ret := NewRecorder().(*recorder)
+	if _, err := loadDurableRecorderDir(dir, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// loadDurableRecorderDir replays the snapshot and segments in dir into target and returns the index of the last
+// segment found, or zero if there are none.
+func loadDurableRecorderDir(dir string, target *recorder) (int, error) {
+	if err := loadResourcesSnapshot(filepath.Join(dir, durableResourcesSnapshot), target); err != nil {
+		return 0, err
+	}
+
+	segments, err := listSegments(dir)
+	if err != nil {
+		return 0, err
+	}
+	lastSegment := 0
+	for _, segmentIndex := range segments {
+		if err := replaySegment(filepath.Join(dir, segmentFilename(segmentIndex)), target); err != nil {
+			return 0, err
+		}
+		lastSegment = segmentIndex
+	}
+	return lastSegment, nil
+}
+
+func segmentFilename(index int) string {
+	return fmt.Sprintf("%s%06d%s", durableSegmentPrefix, index, durableSegmentSuffix)
+}
+
+func listSegments(dir string) ([]int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	segments := []int{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, durableSegmentPrefix) || !strings.HasSuffix(name, durableSegmentSuffix) {
+			continue
+		}
+		var index int
+		if _, err := fmt.Sscanf(strings.TrimSuffix(strings.TrimPrefix(name, durableSegmentPrefix), durableSegmentSuffix), "%d", &index); err != nil {
+			return nil, fmt.Errorf("unexpected segment name %q: %w", name, err)
+		}
+		segments = append(segments, index)
+	}
+	sort.Ints(segments)
+	return segments, nil
+}
+
+// replaySegment applies every entry in the segment to target.  A process killed mid-write leaves a truncated
+// final line, which is skipped with a warning.  Corruption anywhere else is an error.
+func replaySegment(filename string, target *recorder) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for lineNumber := 1; ; lineNumber++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return fmt.Errorf("unable to read %q: %w", filename, readErr)
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			entry := segmentEntry{}
+			if err := json.Unmarshal(line, &entry); err != nil {
+				if readErr == io.EOF {
+					logrus.WithError(err).Warnf("skipping truncated final entry in %q", filename)
+					return nil
+				}
+				return fmt.Errorf("unable to parse %q line %d: %w", filename, lineNumber, err)
+			}
+			if err := applySegmentEntry(entry, target); err != nil {
+				return fmt.Errorf("unable to replay %q line %d: %w", filename, lineNumber, err)
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+	}
+}
+
+func applySegmentEntry(entry segmentEntry, target *recorder) error {
+	switch entry.Op {
+	case segmentOpAdd:
+		intervals, err := fromDurableIntervals(entry.Intervals)
+		if err != nil {
+			return err
+		}
+		target.AddIntervals(intervals...)
+	case segmentOpStart:
+		intervals, err := fromDurableIntervals(entry.Intervals)
+		if err != nil {
+			return err
+		}
+		if len(intervals) != 1 {
+			return fmt.Errorf("start entry must have exactly one interval, found %d", len(intervals))
+		}
+		if index := target.StartInterval(intervals[0]); index != entry.Index {
+			return fmt.Errorf("start entry expected index %d, replay produced %d", entry.Index, index)
+		}
+	case segmentOpEnd:
+		target.EndInterval(entry.Index, entry.To)
+	default:
+		return fmt.Errorf("unknown op %q", entry.Op)
+	}
+	return nil
+}
+
+func toDurableIntervals(intervals []monitorapi.Interval) []durableInterval {
+	ret := make([]durableInterval, 0, len(intervals))
+	for _, interval := range intervals {
+		ret = append(ret, durableInterval{
+			Level:   interval.Level.String(),
+			Source:  interval.Source,
+			Display: interval.Display,
+			Locator: interval.Locator,
+			Message: interval.Message,
+			From:    interval.From,
+			To:      interval.To,
+		})
+	}
+	return ret
+}
+
+func fromDurableIntervals(intervals []durableInterval) (monitorapi.Intervals, error) {
+	ret := make(monitorapi.Intervals, 0, len(intervals))
+	for _, interval := range intervals {
+		level, err := monitorapi.ConditionLevelFromString(interval.Level)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, monitorapi.Interval{
+			Condition: monitorapi.Condition{
+				Level:   level,
+				Locator: interval.Locator,
+				Message: interval.Message,
+			},
+			Source:  interval.Source,
+			Display: interval.Display,
+			From:    interval.From,
+			To:      interval.To,
+		})
+	}
+	return ret, nil
+}
+
+func loadResourcesSnapshot(filename string, target *recorder) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	entries := []resourceSnapshotEntry{}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("unable to parse %q: %w", filename, err)
+	}
+
+	// the snapshot already carries the observed update and recreation annotations, so bypass RecordResource.
+	target.recordedResourceLock.Lock()
+	defer target.recordedResourceLock.Unlock()
+	for i := range entries {
+		entry := entries[i]
+		instances, ok := target.recordedResources[entry.ResourceType]
+		if !ok {
+			instances = monitorapi.InstanceMap{}
+			target.recordedResources[entry.ResourceType] = instances
+		}
+		instances[entry.Key] = monitorserialization.DecodeResource(entry.ResourceType, entry.Object)
+	}
+	return nil
+}
+
+// rotateSegmentLocked closes the current segment, if any, and opens the next one.  Callers must hold segmentLock.
+func (m *durableRecorder) rotateSegmentLocked() error {
+	if m.segment != nil {
+		if err := m.segment.Sync(); err != nil {
+			return err
+		}
+		if err := m.segment.Close(); err != nil {
+			return err
+		}
+	}
+	m.segmentIndex++
+	segment, err := os.OpenFile(filepath.Join(m.dir, segmentFilename(m.segmentIndex)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	m.segment = segment
+	m.segmentBytes = 0
+	return nil
+}
+
+// appendLocked writes one entry to the segment log.  Callers must hold segmentLock.
+func (m *durableRecorder) appendLocked(entry segmentEntry) {
+	if m.closed {
+		return
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error serializing recorder entry: %v\n", err)
+		return
+	}
+	line = append(line, '\n')
+	n, err := m.segment.Write(line)
+	m.segmentBytes += int64(n)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error writing recorder entry: %v\n", err)
+		return
+	}
+	if m.segmentBytes >= m.maxSegmentBytes {
+		if err := m.rotateSegmentLocked(); err != nil {
+			fmt.Fprintf(os.Stderr, "error rotating recorder segment, further intervals are kept in memory only: %v\n", err)
+			m.closed = true
+		}
+	}
+}
+
+func (m *durableRecorder) Intervals(from, to time.Time) monitorapi.Intervals {
+	return m.delegate.Intervals(from, to)
+}
+
+func (m *durableRecorder) intervalsByID() monitorapi.Intervals {
+	return m.delegate.intervalsByID()
+}
+
+func (m *durableRecorder) Filter(selector IntervalSelector, matches monitorapi.EventIntervalMatchesFunc) monitorapi.Intervals {
+	return m.delegate.Filter(selector, matches)
+}
+
+func (m *durableRecorder) Cut(from, to time.Time) monitorapi.Intervals {
+	return m.delegate.Cut(from, to)
+}
+
+func (m *durableRecorder) CurrentResourceState() monitorapi.ResourcesMap {
+	return m.delegate.CurrentResourceState()
+}
+
+func (m *durableRecorder) RecordResource(resourceType string, obj runtime.Object) {
+	m.delegate.RecordResource(resourceType, obj)
+}
+
+// Record captures one or more conditions at the current time. All conditions are recorded
+// in monotonic order as EventInterval objects.
+func (m *durableRecorder) Record(conditions ...monitorapi.Condition) {
+	m.RecordAt(time.Now().UTC(), conditions...)
+}
+
+// RecordAt captures one or more conditions at the provided time. All conditions are recorded
+// as EventInterval objects.
+func (m *durableRecorder) RecordAt(t time.Time, conditions ...monitorapi.Condition) {
+	if len(conditions) == 0 {
+		return
+	}
+	intervals := monitorapi.Intervals{}
+	for _, condition := range conditions {
+		intervals = append(intervals, monitorapi.Interval{
+			Condition: condition,
+			From:      t,
+			To:        t,
+		})
+	}
+	m.AddIntervals(intervals...)
+}
+
+// AddIntervals provides a mechanism to directly inject eventIntervals
+func (m *durableRecorder) AddIntervals(eventIntervals ...monitorapi.Interval) {
+	if len(eventIntervals) == 0 {
+		return
+	}
+	m.segmentLock.Lock()
+	defer m.segmentLock.Unlock()
+	m.delegate.AddIntervals(eventIntervals...)
+	m.appendLocked(segmentEntry{Op: segmentOpAdd, Intervals: toDurableIntervals(eventIntervals)})
+}
+
+// StartInterval inserts a record at time t with the provided condition and returns an opaque
+// locator to the interval. The caller may close the sample at any point by invoking EndInterval().
+func (m *durableRecorder) StartInterval(interval monitorapi.Interval) int {
+	m.segmentLock.Lock()
+	defer m.segmentLock.Unlock()
+	index := m.delegate.StartInterval(interval)
+	m.appendLocked(segmentEntry{Op: segmentOpStart, Index: index, Intervals: toDurableIntervals([]monitorapi.Interval{interval})})
+	return index
+}
+
+// EndInterval updates the To of the interval started by StartInterval if it is greater than
+// the from.
+func (m *durableRecorder) EndInterval(startedInterval int, t time.Time) *monitorapi.Interval {
+	m.segmentLock.Lock()
+	defer m.segmentLock.Unlock()
+	ret := m.delegate.EndInterval(startedInterval, t)
+	m.appendLocked(segmentEntry{Op: segmentOpEnd, Index: startedInterval, To: t})
+	return ret
+}
+
+// SnapshotResources writes the current ResourcesMap to a temporary file and renames it over the previous snapshot,
+// so a crash during the write leaves the previous snapshot intact.
+func (m *durableRecorder) SnapshotResources() error {
+	m.snapshotLock.Lock()
+	defer m.snapshotLock.Unlock()
+
+	entries := []resourceSnapshotEntry{}
+	for resourceType, instances := range m.delegate.CurrentResourceState() {
+		for key, obj := range instances {
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+			if err != nil {
+				return fmt.Errorf("unable to convert %s %s/%s: %w", resourceType, key.Namespace, key.Name, err)
+			}
+			entries = append(entries, resourceSnapshotEntry{
+				ResourceType: resourceType,
+				Key:          key,
+				Object:       content,
+			})
+		}
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(m.dir, durableResourcesSnapshot)
+	tmpFilename := filename + ".tmp"
+	tmpFile, err := os.Create(tmpFilename)
+	if err != nil {
+		return err
+	}
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFilename, filename)
+}
+
+func (m *durableRecorder) Close() error {
+	m.stopSnapshotFn()
+	snapshotErr := m.SnapshotResources()
+
+	m.segmentLock.Lock()
+	defer m.segmentLock.Unlock()
+	if m.closed {
+		return snapshotErr
+	}
+	m.closed = true
+	if err := m.segment.Sync(); err != nil {
+		m.segment.Close()
+		return err
+	}
+	if err := m.segment.Close(); err != nil {
+		return err
+	}
+	return snapshotErr
+}
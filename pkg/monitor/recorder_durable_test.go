@@ -0,0 +1,118 @@
+package monitor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/diff"
+)
+
+func TestDurableRecorder_Reopen(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	durable, err := NewDurableRecorder(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 123456789, time.UTC)
+	added := monitorapi.NewInterval(monitorapi.SourceTestData, monitorapi.Warning).
+		Locator(monitorapi.NewLocator().NodeFromName("foo")).
+		Message(monitorapi.NewMessage().Reason(monitorapi.NodeNotReadyReason).HumanMessage("added")).
+		Build(from, from.Add(time.Second))
+	started := monitorapi.NewInterval(monitorapi.SourceTestData, monitorapi.Info).
+		Locator(monitorapi.NewLocator().NodeFromName("bar")).
+		Message(monitorapi.NewMessage().HumanMessage("started")).
+		Build(from.Add(2*time.Second), time.Time{})
+
+	durable.AddIntervals(added)
+	index := durable.StartInterval(started)
+	durable.EndInterval(index, from.Add(5*time.Second))
+	durable.RecordResource("pods", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pod", UID: "uid"}})
+	if err := durable.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := durable.Intervals(time.Time{}, time.Time{})
+	if len(expected) != 2 {
+		t.Fatalf("expected 2 intervals, got %d", len(expected))
+	}
+
+	reopened, err := NewDurableRecorder(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := reopened.Intervals(time.Time{}, time.Time{}); !reflect.DeepEqual(expected, actual) {
+		t.Fatal(diff.ObjectReflectDiff(expected, actual))
+	}
+
+	pods := reopened.CurrentResourceState()["pods"]
+	pod, ok := pods[monitorapi.InstanceKey{Namespace: "ns", Name: "pod", UID: "uid"}].(*corev1.Pod)
+	if !ok {
+		t.Fatalf("expected restored pod, got %#v", pods)
+	}
+	if pod.Name != "pod" {
+		t.Fatalf("expected restored pod, got %#v", pod)
+	}
+
+	// indexes handed out after reopening must continue after the replayed ones
+	nextIndex := reopened.StartInterval(started)
+	if nextIndex != 2 {
+		t.Fatalf("expected index 2 after reopening, got %d", nextIndex)
+	}
+	if err := reopened.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadDurableRecorderDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := loaded.Intervals(time.Time{}, time.Time{}); len(actual) != 3 {
+		t.Fatalf("expected 3 intervals, got %d", len(actual))
+	}
+}
+
+func TestDurableRecorder_TruncatedSegment(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	durable, err := NewDurableRecorder(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	durable.Record(monitorapi.NewInterval(monitorapi.SourceTestData, monitorapi.Info).
+		Locator(monitorapi.NewLocator().NodeFromName("foo")).
+		Message(monitorapi.NewMessage().HumanMessage("1")).BuildCondition())
+	if err := durable.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate a process killed in the middle of writing an entry
+	segment, err := os.OpenFile(filepath.Join(dir, segmentFilename(1)), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := segment.WriteString(`{"op":"add","intervals":[{"level":"Info"`); err != nil {
+		t.Fatal(err)
+	}
+	segment.Close()
+
+	loaded, err := LoadDurableRecorderDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := loaded.Intervals(time.Time{}, time.Time{}); len(actual) != 1 {
+		t.Fatalf("expected 1 interval, got %d", len(actual))
+	}
+}
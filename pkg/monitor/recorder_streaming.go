@@ -0,0 +1,195 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type IntervalUpdateType string
+
+const (
+	// IntervalAdded is sent for intervals added by AddIntervals, Record, or StartInterval.
+	IntervalAdded IntervalUpdateType = "added"
+	// IntervalUpdated is sent when EndInterval changes the end of a started interval.
+	IntervalUpdated IntervalUpdateType = "updated"
+)
+
+// IntervalUpdate is a change to the intervals of a recorder.
+type IntervalUpdate struct {
+	Type IntervalUpdateType
+	// ID identifies the interval among all the intervals of the recorder.  Updates to an interval have the
+	// ID it was added with.  It is -1 in a list from a delegate that cannot tell the IDs of its intervals.
+	ID       int
+	Interval monitorapi.Interval
+}
+
+// StreamingRecorder is a monitorapi.Recorder whose interval changes can be watched as they happen.
+type StreamingRecorder interface {
+	monitorapi.Recorder
+
+	// Watch returns the changes to intervals after the call, until ctx is done.  A watcher that falls more
+	// than buffer updates behind is dropped, which closes the channel, so that watching never slows the
+	// recorder down.
+	Watch(ctx context.Context, buffer int) <-chan IntervalUpdate
+
+	// ListAndWatch returns the intervals recorded so far, as IntervalAdded updates with their IDs, together
+	// with a Watch of the changes after them.  No change is missed or seen twice between the two.  The list is
+	// in the order the intervals were recorded.
+	ListAndWatch(ctx context.Context, buffer int) ([]IntervalUpdate, <-chan IntervalUpdate)
+}
+
+// intervalsByIDRecorder is implemented by recorders that can list their intervals by the index StartInterval
+// hands out.
+type intervalsByIDRecorder interface {
+	intervalsByID() monitorapi.Intervals
+}
+
+// streamingRecorder passes every call to its delegate and sends the resulting interval changes to watchers.
+// IDs are the indexes the delegate hands out from StartInterval, so every write to the delegate must go
+// through the streamingRecorder.
+type streamingRecorder struct {
+	delegate monitorapi.Recorder
+
+	// lock serializes writes to the delegate, so that IDs and the order of updates match the delegate.
+	lock     sync.Mutex
+	next     int
+	watchers map[chan IntervalUpdate]struct{}
+}
+
+// WrapWithStreamingRecorder returns a recorder that streams the interval changes written through it.
+// Intervals the delegate already holds keep their place, new intervals are numbered after them.
+func WrapWithStreamingRecorder(delegate monitorapi.Recorder) StreamingRecorder {
+	return &streamingRecorder{
+		delegate: delegate,
+		next:     len(delegate.Intervals(time.Time{}, time.Time{})),
+		watchers: map[chan IntervalUpdate]struct{}{},
+	}
+}
+
+var _ StreamingRecorder = &streamingRecorder{}
+
+func (m *streamingRecorder) Watch(ctx context.Context, buffer int) <-chan IntervalUpdate {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.watchLocked(ctx, buffer)
+}
+
+func (m *streamingRecorder) ListAndWatch(ctx context.Context, buffer int) ([]IntervalUpdate, <-chan IntervalUpdate) {
+	// writes hold the lock, so nothing is recorded between the list and the watch
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delegate, ok := m.delegate.(intervalsByIDRecorder)
+	if !ok {
+		// the IDs of the intervals are unknown, list them in time order without one
+		intervals := m.delegate.Intervals(time.Time{}, time.Time{})
+		list := make([]IntervalUpdate, 0, len(intervals))
+		for _, interval := range intervals {
+			list = append(list, IntervalUpdate{Type: IntervalAdded, ID: -1, Interval: interval})
+		}
+		return list, m.watchLocked(ctx, buffer)
+	}
+	intervals := delegate.intervalsByID()
+	list := make([]IntervalUpdate, 0, len(intervals))
+	for id, interval := range intervals {
+		list = append(list, IntervalUpdate{Type: IntervalAdded, ID: id, Interval: interval})
+	}
+	return list, m.watchLocked(ctx, buffer)
+}
+
+// watchLocked must be called with the lock held.
+func (m *streamingRecorder) watchLocked(ctx context.Context, buffer int) <-chan IntervalUpdate {
+	ch := make(chan IntervalUpdate, buffer)
+	m.watchers[ch] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		m.stopWatching(ch)
+	}()
+	return ch
+}
+
+// stopWatching must be called with the lock held.
+func (m *streamingRecorder) stopWatching(ch chan IntervalUpdate) {
+	if _, ok := m.watchers[ch]; !ok {
+		return
+	}
+	delete(m.watchers, ch)
+	close(ch)
+}
+
+// send must be called with the lock held.
+func (m *streamingRecorder) send(update IntervalUpdate) {
+	for ch := range m.watchers {
+		select {
+		case ch <- update:
+		default:
+			m.stopWatching(ch)
+		}
+	}
+}
+
+func (m *streamingRecorder) CurrentResourceState() monitorapi.ResourcesMap {
+	return m.delegate.CurrentResourceState()
+}
+
+func (m *streamingRecorder) RecordResource(resourceType string, obj runtime.Object) {
+	m.delegate.RecordResource(resourceType, obj)
+}
+
+func (m *streamingRecorder) Record(conditions ...monitorapi.Condition) {
+	m.RecordAt(time.Now().UTC(), conditions...)
+}
+
+func (m *streamingRecorder) RecordAt(t time.Time, conditions ...monitorapi.Condition) {
+	if len(conditions) == 0 {
+		return
+	}
+	intervals := monitorapi.Intervals{}
+	for _, condition := range conditions {
+		intervals = append(intervals, monitorapi.Interval{
+			Condition: condition,
+			From:      t,
+			To:        t,
+		})
+	}
+	m.AddIntervals(intervals...)
+}
+
+func (m *streamingRecorder) AddIntervals(eventIntervals ...monitorapi.Interval) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.delegate.AddIntervals(eventIntervals...)
+	for _, interval := range eventIntervals {
+		m.send(IntervalUpdate{Type: IntervalAdded, ID: m.next, Interval: interval})
+		m.next++
+	}
+}
+
+func (m *streamingRecorder) StartInterval(interval monitorapi.Interval) int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	id := m.delegate.StartInterval(interval)
+	m.next = id + 1
+	m.send(IntervalUpdate{Type: IntervalAdded, ID: id, Interval: interval})
+	return id
+}
+
+func (m *streamingRecorder) EndInterval(startedInterval int, t time.Time) *monitorapi.Interval {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	ret := m.delegate.EndInterval(startedInterval, t)
+	if ret != nil {
+		m.send(IntervalUpdate{Type: IntervalUpdated, ID: startedInterval, Interval: *ret})
+	}
+	return ret
+}
+
+func (m *streamingRecorder) Intervals(from, to time.Time) monitorapi.Intervals {
+	return m.delegate.Intervals(from, to)
+}
@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestStreamingRecorder(t *testing.T) {
+	now := time.Now()
+	delegate := NewRecorder()
+	delegate.AddIntervals(newTestInterval("before", now))
+
+	recorder := WrapWithStreamingRecorder(delegate)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := recorder.Watch(ctx, 10)
+
+	recorder.AddIntervals(newTestInterval("added", now))
+	started := recorder.StartInterval(newTestInterval("started", now))
+	recorder.EndInterval(started, now.Add(time.Minute))
+
+	expected := []IntervalUpdate{
+		{Type: IntervalAdded, ID: 1},
+		{Type: IntervalAdded, ID: 2},
+		{Type: IntervalUpdated, ID: 2},
+	}
+	for i, want := range expected {
+		got := <-updates
+		if got.Type != want.Type || got.ID != want.ID {
+			t.Errorf("update %d: expected %s %d, got %s %d", i, want.Type, want.ID, got.Type, got.ID)
+		}
+	}
+	if started != 2 {
+		t.Errorf("expected the started interval to keep the index of the delegate, got %d", started)
+	}
+
+	// a watcher that falls behind is dropped instead of blocking the recorder
+	slow := recorder.Watch(ctx, 1)
+	recorder.AddIntervals(newTestInterval("first", now), newTestInterval("second", now))
+	<-slow
+	if _, ok := <-slow; ok {
+		t.Errorf("expected a slow watcher to be dropped")
+	}
+
+	cancel()
+	for range updates {
+	}
+}
+
+func TestStreamingRecorderListAndWatch(t *testing.T) {
+	now := time.Now()
+	recorder := WrapWithStreamingRecorder(NewRecorder())
+	recorder.AddIntervals(newTestInterval("later", now.Add(time.Minute)))
+	open := recorder.StartInterval(newTestInterval("open", now))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	list, updates := recorder.ListAndWatch(ctx, 10)
+	recorder.EndInterval(open, now.Add(2*time.Minute))
+
+	// the list is in recorded order with the IDs the intervals were added with, not in time order
+	if len(list) != 2 {
+		t.Fatalf("expected 2 listed intervals, got %d", len(list))
+	}
+	for id, message := range []string{"later", "open"} {
+		if list[id].Type != IntervalAdded || list[id].ID != id || list[id].Interval.Message.HumanMessage != message {
+			t.Errorf("listed interval %d: expected %s %d %q, got %s %d %q", id, IntervalAdded, id, message,
+				list[id].Type, list[id].ID, list[id].Interval.Message.HumanMessage)
+		}
+	}
+	// the listed intervals are not sent again, the update refers to the listed ID
+	got := <-updates
+	if got.Type != IntervalUpdated || got.ID != open {
+		t.Errorf("expected %s %d, got %s %d", IntervalUpdated, open, got.Type, got.ID)
+	}
+}
+
+func newTestInterval(message string, t time.Time) monitorapi.Interval {
+	return monitorapi.NewInterval(monitorapi.SourceE2ETest, monitorapi.Info).
+		Locator(monitorapi.NewLocator().E2ETestSuite("suite")).
+		Message(monitorapi.NewMessage().HumanMessage(message)).
+		Build(t, t)
+}
@@ -0,0 +1,371 @@
+package monitorserialization
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// CompactFileExtension is the extension of files in the compact format.
+const CompactFileExtension = ".jsonl.gz"
+
+// compactHeader is the first line of the compact format.  Anything after it in the format must stay readable by
+// older versions or get a new version.  Version 1 holds intervals of IntervalsAPIVersion monitor.openshift.io/v1.
+var compactHeader = []byte(`{"kind":"CompactIntervalList","version":1}`)
+
+// The compact format is gzipped newline delimited JSON.  After the header, every line is either
+//
+//	"a string"   appended to the string table
+//	[1,2,0,...]  an interval, made of string table indices and times
+//
+// Every string an interval refers to is defined on an earlier line, so the format can be written and read as a
+// stream.  Locators, reasons and annotations repeat a great deal across a run and are only written once.
+//
+// An interval is
+//
+//	[level, source, display, locatorType, len(keys), key, value, ..., reason, cause, humanMessage,
+//	 len(annotations), key, value, ..., from, to]
+//
+// where display is 0 or 1, a length of -1 means the map was nil, and from and to are unix seconds or null for a zero
+// time.  Times keep the precision of the JSON format, so both formats load the same intervals.
+
+// IntervalsToCompact writes intervals in the compact format, sorted the same way as IntervalsToJSON.
+func IntervalsToCompact(w io.Writer, intervals monitorapi.Intervals) error {
+	outputEvents := make([]EventInterval, 0, len(intervals))
+	for _, curr := range intervals {
+		outputEvents = append(outputEvents, monitorEventIntervalToEventInterval(curr))
+	}
+	sort.Sort(byTime(outputEvents))
+
+	zw := gzip.NewWriter(w)
+	out := bufio.NewWriter(zw)
+	enc := &compactEncoder{out: out, strings: map[string]int{}}
+	if _, err := out.Write(append(compactHeader, '\n')); err != nil {
+		return err
+	}
+	for _, curr := range outputEvents {
+		if err := enc.encode(curr); err != nil {
+			return err
+		}
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// IntervalsToCompactFile writes intervals to filename in the compact format.
+func IntervalsToCompactFile(filename string, intervals monitorapi.Intervals) error {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err := IntervalsToCompact(f, intervals); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+type compactEncoder struct {
+	out     *bufio.Writer
+	strings map[string]int
+	record  []byte
+}
+
+// index returns the position of s in the string table, writing it first if it is new.
+func (e *compactEncoder) index(s string) (int, error) {
+	if i, ok := e.strings[s]; ok {
+		return i, nil
+	}
+	encoded, err := json.Marshal(s)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := e.out.Write(append(encoded, '\n')); err != nil {
+		return 0, err
+	}
+	i := len(e.strings)
+	e.strings[s] = i
+	return i, nil
+}
+
+func (e *compactEncoder) encode(interval EventInterval) error {
+	e.record = append(e.record[:0], '[')
+	appendString := func(s string) error {
+		i, err := e.index(s)
+		if err != nil {
+			return err
+		}
+		e.appendInt(int64(i))
+		return nil
+	}
+	appendMap := func(m map[string]string) error {
+		if m == nil {
+			e.appendInt(-1)
+			return nil
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		e.appendInt(int64(len(keys)))
+		for _, k := range keys {
+			if err := appendString(k); err != nil {
+				return err
+			}
+			if err := appendString(m[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, s := range []string{interval.Level, interval.Source} {
+		if err := appendString(s); err != nil {
+			return err
+		}
+	}
+	display := int64(0)
+	if interval.Display {
+		display = 1
+	}
+	e.appendInt(display)
+	if err := appendString(string(interval.Locator.Type)); err != nil {
+		return err
+	}
+	if err := appendMap(locatorKeysToStrings(interval.Locator.Keys)); err != nil {
+		return err
+	}
+	for _, s := range []string{string(interval.Message.Reason), interval.Message.Cause, interval.Message.HumanMessage} {
+		if err := appendString(s); err != nil {
+			return err
+		}
+	}
+	if err := appendMap(annotationsToStrings(interval.Message.Annotations)); err != nil {
+		return err
+	}
+	for _, t := range []time.Time{interval.From.Time, interval.To.Time} {
+		if t.IsZero() {
+			e.appendRaw("null")
+			continue
+		}
+		e.appendInt(t.Unix())
+	}
+
+	e.record = append(e.record, ']', '\n')
+	_, err := e.out.Write(e.record)
+	return err
+}
+
+func (e *compactEncoder) appendInt(i int64) {
+	if len(e.record) > 1 {
+		e.record = append(e.record, ',')
+	}
+	e.record = strconv.AppendInt(e.record, i, 10)
+}
+
+func (e *compactEncoder) appendRaw(s string) {
+	if len(e.record) > 1 {
+		e.record = append(e.record, ',')
+	}
+	e.record = append(e.record, s...)
+}
+
+func locatorKeysToStrings(keys map[monitorapi.LocatorKey]string) map[string]string {
+	if keys == nil {
+		return nil
+	}
+	ret := make(map[string]string, len(keys))
+	for k, v := range keys {
+		ret[string(k)] = v
+	}
+	return ret
+}
+
+func annotationsToStrings(annotations map[monitorapi.AnnotationKey]string) map[string]string {
+	if annotations == nil {
+		return nil
+	}
+	ret := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		ret[string(k)] = v
+	}
+	return ret
+}
+
+// isGzip reports whether data starts with the gzip magic number.
+func isGzip(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+// isCompact reports whether data, already decompressed, is in the compact format.
+func isCompact(data []byte) bool {
+	return bytes.HasPrefix(data, compactHeader)
+}
+
+// IntervalsFromCompact reads intervals written by IntervalsToCompact.
+func IntervalsFromCompact(r io.Reader) (monitorapi.Intervals, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return intervalsFromCompactLines(zr)
+}
+
+func intervalsFromCompactLines(r io.Reader) (monitorapi.Intervals, error) {
+	scanner := bufio.NewScanner(r)
+	// human messages can be long
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("missing compact interval header")
+	}
+	if !bytes.Equal(scanner.Bytes(), compactHeader) {
+		return nil, fmt.Errorf("unsupported compact interval header %q", scanner.Text())
+	}
+
+	dec := &compactDecoder{levels: map[string]monitorapi.IntervalLevel{}}
+	intervals := monitorapi.Intervals{}
+	for line := 2; scanner.Scan(); line++ {
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		switch data[0] {
+		case '"':
+			var s string
+			if err := json.Unmarshal(data, &s); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			dec.strings = append(dec.strings, s)
+		case '[':
+			interval, err := dec.decode(data)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			intervals = append(intervals, interval)
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q", line, data[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return intervals, nil
+}
+
+type compactDecoder struct {
+	strings []string
+	levels  map[string]monitorapi.IntervalLevel
+	record  []*int64
+}
+
+func (d *compactDecoder) decode(data []byte) (monitorapi.Interval, error) {
+	d.record = d.record[:0]
+	if err := json.Unmarshal(data, &d.record); err != nil {
+		return monitorapi.Interval{}, err
+	}
+	record := d.record
+	var err error
+	next := func() (int64, bool) {
+		if err != nil {
+			return 0, false
+		}
+		if len(record) == 0 {
+			err = fmt.Errorf("interval is too short")
+			return 0, false
+		}
+		curr := record[0]
+		record = record[1:]
+		if curr == nil {
+			return 0, false
+		}
+		return *curr, true
+	}
+	nextString := func() string {
+		i, ok := next()
+		if err != nil {
+			return ""
+		}
+		if !ok || i < 0 || int(i) >= len(d.strings) {
+			err = fmt.Errorf("string %d is not defined", i)
+			return ""
+		}
+		return d.strings[i]
+	}
+	nextMap := func() map[string]string {
+		count, _ := next()
+		if err != nil || count < 0 {
+			return nil
+		}
+		ret := make(map[string]string, count)
+		for i := int64(0); i < count && err == nil; i++ {
+			k := nextString()
+			ret[k] = nextString()
+		}
+		return ret
+	}
+	nextTime := func() time.Time {
+		seconds, ok := next()
+		if !ok {
+			return time.Time{}
+		}
+		// matches how metav1.Time reads the JSON format
+		return time.Unix(seconds, 0).Local()
+	}
+
+	levelString := nextString()
+	level, ok := d.levels[levelString]
+	if !ok && err == nil {
+		if level, err = monitorapi.ConditionLevelFromString(levelString); err != nil {
+			return monitorapi.Interval{}, err
+		}
+		d.levels[levelString] = level
+	}
+	interval := monitorapi.Interval{
+		Source: monitorapi.IntervalSource(nextString()),
+	}
+	interval.Level = level
+	display, _ := next()
+	interval.Display = display == 1
+	interval.Locator.Type = monitorapi.LocatorType(nextString())
+	if keys := nextMap(); keys != nil {
+		interval.Locator.Keys = make(map[monitorapi.LocatorKey]string, len(keys))
+		for k, v := range keys {
+			interval.Locator.Keys[monitorapi.LocatorKey(k)] = v
+		}
+	}
+	interval.Message.Reason = monitorapi.IntervalReason(nextString())
+	interval.Message.Cause = nextString()
+	interval.Message.HumanMessage = nextString()
+	if annotations := nextMap(); annotations != nil {
+		interval.Message.Annotations = make(map[monitorapi.AnnotationKey]string, len(annotations))
+		for k, v := range annotations {
+			interval.Message.Annotations[monitorapi.AnnotationKey(k)] = v
+		}
+	}
+	interval.From = nextTime()
+	interval.To = nextTime()
+	if err != nil {
+		return monitorapi.Interval{}, err
+	}
+	if len(record) > 0 {
+		return monitorapi.Interval{}, fmt.Errorf("interval has %d unexpected fields", len(record))
+	}
+	return interval, nil
+}
@@ -0,0 +1,130 @@
+package monitorserialization
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func compactTestIntervals() monitorapi.Intervals {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	intervals := monitorapi.Intervals{}
+	for i := 0; i < 50; i++ {
+		// sub-second times are rounded the same way by both formats
+		from := start.Add(time.Duration(i) * 1500 * time.Millisecond)
+		intervals = append(intervals,
+			monitorapi.NewInterval(monitorapi.SourcePodMonitor, monitorapi.Info).
+				Locator(monitorapi.NewLocator().PodFromNames("ns", fmt.Sprintf("pod-%d", i%5), "uid")).
+				Message(monitorapi.NewMessage().Reason("Ready").HumanMessage("container is \"ready\"\nagain")).
+				Display().
+				Build(from, from.Add(time.Minute)),
+			monitorapi.NewInterval(monitorapi.SourceDisruption, monitorapi.Error).
+				Locator(monitorapi.NewLocator().Disruption("backend", "", "", "", "", monitorapi.NewConnectionType)).
+				Message(monitorapi.NewMessage().Reason(monitorapi.DisruptionBeganEventReason).WithAnnotation(monitorapi.AnnotationCount, "3").HumanMessage("disrupted ✗")).
+				Build(from, time.Time{}),
+		)
+	}
+	intervals = append(intervals,
+		// nil maps and empty strings
+		monitorapi.Interval{Condition: monitorapi.Condition{Level: monitorapi.Warning}, From: start},
+	)
+	return intervals
+}
+
+func TestCompactRoundTrip(t *testing.T) {
+	intervals := compactTestIntervals()
+
+	jsonData, err := IntervalsToJSON(intervals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := IntervalsFromJSON(jsonData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compactData := &bytes.Buffer{}
+	if err := IntervalsToCompact(compactData, intervals); err != nil {
+		t.Fatal(err)
+	}
+	if compactData.Len() >= len(jsonData)/4 {
+		t.Errorf("expected the compact format to be much smaller than %d bytes, got %d", len(jsonData), compactData.Len())
+	}
+
+	got, err := IntervalsFromJSON(compactData.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected\n%v\ngot\n%v", expected.Strings(), got.Strings())
+	}
+
+	got, err = IntervalsFromCompact(bytes.NewReader(compactData.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("IntervalsFromCompact: expected\n%v\ngot\n%v", expected.Strings(), got.Strings())
+	}
+
+	filename := filepath.Join(t.TempDir(), "e2e-events"+CompactFileExtension)
+	if err := IntervalsToCompactFile(filename, intervals); err != nil {
+		t.Fatal(err)
+	}
+	got, err = EventsFromFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("EventsFromFile: expected\n%v\ngot\n%v", expected.Strings(), got.Strings())
+	}
+}
+
+func TestIntervalsFromJSONGzipped(t *testing.T) {
+	intervals := compactTestIntervals()
+	jsonData, err := IntervalsToJSON(intervals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := IntervalsFromJSON(jsonData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gzipped := &bytes.Buffer{}
+	zw := gzip.NewWriter(gzipped)
+	if _, err := zw.Write(jsonData); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := IntervalsFromJSON(gzipped.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected\n%v\ngot\n%v", expected.Strings(), got.Strings())
+	}
+}
+
+func TestCompactErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"undefined string": `[0]`,
+		"short interval":   `"Info"` + "\n" + `[0,0]`,
+		"unknown level":    `"Loud"` + "\n" + `[0,0,0,0,-1,0,0,0,-1,null,null]`,
+		"extra fields":     `"Info"` + "\n" + `[0,0,0,0,-1,0,0,0,-1,null,null,1]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := IntervalsFromJSON([]byte(string(compactHeader) + "\n" + data + "\n")); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -4,16 +4,62 @@ import (
 	"archive/zip"
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/kube-openapi/pkg/util/sets"
 )
 
+var (
+	resourceScheme = runtime.NewScheme()
+
+	// resourceKinds are the kinds of the resource types recorded by the monitor tests.  Monitor tests assert the
+	// typed objects their informers record, so these are decoded back into them.
+	resourceKinds = map[string]schema.GroupVersionKind{
+		"pods":         {Version: "v1", Kind: "Pod"},
+		"events":       {Version: "v1", Kind: "Event"},
+		"namespaces":   {Version: "v1", Kind: "Namespace"},
+		"deployments":  {Group: "apps", Version: "v1", Kind: "Deployment"},
+		"daemonsets":   {Group: "apps", Version: "v1", Kind: "DaemonSet"},
+		"statefulsets": {Group: "apps", Version: "v1", Kind: "StatefulSet"},
+		"machines":     machinev1beta1.GroupVersion.WithKind("Machine"),
+	}
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(resourceScheme))
+	utilruntime.Must(machinev1beta1.Install(resourceScheme))
+}
+
+// DecodeResource returns the object of a recorded resource read back as a plain map.  Resources of the known types
+// are decoded into their typed objects, others and those that do not decode are returned as
+// *unstructured.Unstructured.
+func DecodeResource(resourceType string, object map[string]interface{}) runtime.Object {
+	unstructuredObj := &unstructured.Unstructured{Object: object}
+	gvk, ok := resourceKinds[resourceType]
+	if !ok {
+		return unstructuredObj
+	}
+	typed, err := resourceScheme.New(gvk)
+	if err != nil {
+		return unstructuredObj
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object, typed); err != nil {
+		return unstructuredObj
+	}
+	return typed
+}
+
 func InstanceMapToFile(filename string, resourceType string, instances monitorapi.InstanceMap) error {
 	namespaceToKeys := map[string][]monitorapi.InstanceKey{}
 	for key, obj := range instances {
@@ -73,3 +119,45 @@ func InstanceMapToFile(filename string, resourceType string, instances monitorap
 
 	return ioutil.WriteFile(filename, byteBuffer.Bytes(), 0644)
 }
+
+// InstanceMapFromFile reads a file written by InstanceMapToFile.  Objects are decoded with DecodeResource.
+func InstanceMapFromFile(filename string) (monitorapi.InstanceMap, error) {
+	zipReader, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer zipReader.Close()
+
+	instances := monitorapi.InstanceMap{}
+	for _, file := range zipReader.File {
+		nsReader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := ioutil.ReadAll(nsReader)
+		nsReader.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		// decode the items as plain maps, objects from typed informers usually have no kind which the
+		// unstructured decoder refuses.
+		nsList := struct {
+			Items []map[string]interface{} `json:"items"`
+		}{}
+		if err := json.Unmarshal(content, &nsList); err != nil {
+			return nil, fmt.Errorf("unable to parse %q in %q: %w", file.Name, filename, err)
+		}
+		resourceType := strings.TrimSuffix(filepath.Base(file.Name), ".json")
+		for _, item := range nsList.Items {
+			obj := &unstructured.Unstructured{Object: item}
+			instances[monitorapi.InstanceKey{
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+				UID:       string(obj.GetUID()),
+			}] = DecodeResource(resourceType, item)
+		}
+	}
+
+	return instances, nil
+}
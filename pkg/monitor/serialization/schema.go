@@ -0,0 +1,237 @@
+package monitorserialization
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// IntervalsAPIVersion is the version of the interval schema written by this package.  Readers convert older versions
+// to it, so a change to the schema needs a new version and a conversion from the previous one.
+const IntervalsAPIVersion = "monitor.openshift.io/v1"
+
+// versionedList reads the version before deciding how to read the items.
+type versionedList struct {
+	APIVersion string          `json:"apiVersion"`
+	Items      json.RawMessage `json:"items"`
+}
+
+// legacyEventInterval is an item of a file without an apiVersion.  The oldest of those have locators and messages as
+// strings, later ones added structured versions next to the strings, and the last ones only have structured locators
+// and messages.
+type legacyEventInterval struct {
+	Level      string `json:"level"`
+	Source     string `json:"source"`
+	TempSource string `json:"tempSource"`
+	Display    bool   `json:"display"`
+
+	Locator json.RawMessage `json:"locator"`
+	Message json.RawMessage `json:"message"`
+
+	TempStructuredLocator *monitorapi.Locator `json:"tempStructuredLocator"`
+	TempStructuredMessage *monitorapi.Message `json:"tempStructuredMessage"`
+
+	From metav1.Time `json:"from"`
+	To   metav1.Time `json:"to"`
+}
+
+// itemsFromJSON returns the items of a JSON intervals file converted to the current version.
+func itemsFromJSON(data []byte) ([]EventInterval, error) {
+	var list versionedList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 || string(list.Items) == "null" {
+		return nil, nil
+	}
+
+	switch list.APIVersion {
+	case IntervalsAPIVersion:
+		var items []EventInterval
+		if err := json.Unmarshal(list.Items, &items); err != nil {
+			return nil, err
+		}
+		return items, nil
+
+	case "":
+		var legacyItems []legacyEventInterval
+		if err := json.Unmarshal(list.Items, &legacyItems); err != nil {
+			return nil, err
+		}
+		items := make([]EventInterval, 0, len(legacyItems))
+		for i, legacy := range legacyItems {
+			item, err := convertLegacyEventInterval(legacy)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			items = append(items, item)
+		}
+		return items, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported intervals apiVersion %q, expected %q", list.APIVersion, IntervalsAPIVersion)
+	}
+}
+
+func convertLegacyEventInterval(legacy legacyEventInterval) (EventInterval, error) {
+	ret := EventInterval{
+		Level:   legacy.Level,
+		Source:  legacy.Source,
+		Display: legacy.Display,
+		From:    legacy.From,
+		To:      legacy.To,
+	}
+	if len(ret.Source) == 0 {
+		ret.Source = legacy.TempSource
+	}
+
+	switch {
+	case isJSONString(legacy.Locator) && legacy.TempStructuredLocator != nil && len(legacy.TempStructuredLocator.Keys) > 0:
+		ret.Locator = *legacy.TempStructuredLocator
+	case isJSONString(legacy.Locator):
+		var locator string
+		if err := json.Unmarshal(legacy.Locator, &locator); err != nil {
+			return EventInterval{}, err
+		}
+		ret.Locator = LegacyLocator(locator)
+	case len(legacy.Locator) > 0:
+		if err := json.Unmarshal(legacy.Locator, &ret.Locator); err != nil {
+			return EventInterval{}, err
+		}
+	}
+
+	switch {
+	case isJSONString(legacy.Message) && legacy.TempStructuredMessage != nil && len(legacy.TempStructuredMessage.Annotations) > 0:
+		ret.Message = *legacy.TempStructuredMessage
+	case isJSONString(legacy.Message):
+		var message string
+		if err := json.Unmarshal(legacy.Message, &message); err != nil {
+			return EventInterval{}, err
+		}
+		ret.Message = LegacyMessage(message)
+	case len(legacy.Message) > 0:
+		if err := json.Unmarshal(legacy.Message, &ret.Message); err != nil {
+			return EventInterval{}, err
+		}
+	}
+	return ret, nil
+}
+
+func isJSONString(data json.RawMessage) bool {
+	return len(data) > 0 && data[0] == '"'
+}
+
+// legacyLocatorKeys maps the keys of string locators that were renamed.
+var legacyLocatorKeys = map[string]monitorapi.LocatorKey{
+	"ns": monitorapi.LocatorNamespaceKey,
+}
+
+// LegacyLocator converts a string locator, as written by monitorapi.Locator.OldLocator and older versions, to a
+// structured one.  The type is guessed from the keys.
+func LegacyLocator(locator string) monitorapi.Locator {
+	keys := map[monitorapi.LocatorKey]string{}
+	for rest := strings.TrimSpace(locator); len(rest) > 0; rest = strings.TrimSpace(rest) {
+		key, value, _ := strings.Cut(rest, "/")
+		if strings.ContainsAny(key, " ") {
+			// not a key, keep the rest as is
+			keys[monitorapi.LocatorHmsgKey] = rest
+			break
+		}
+		rest = ""
+		if quoted, err := strconv.QuotedPrefix(value); err == nil {
+			// e2e test names are quoted because they contain spaces
+			rest = value[len(quoted):]
+			value, _ = strconv.Unquote(quoted)
+		} else if i := strings.Index(value, " "); i >= 0 {
+			value, rest = value[:i], value[i:]
+		}
+		if renamed, ok := legacyLocatorKeys[key]; ok {
+			key = string(renamed)
+		}
+		keys[monitorapi.LocatorKey(key)] = value
+	}
+	return monitorapi.Locator{Type: legacyLocatorType(keys), Keys: keys}
+}
+
+// legacyLocatorType guesses the type of a locator from its keys, most specific first.
+func legacyLocatorType(keys map[monitorapi.LocatorKey]string) monitorapi.LocatorType {
+	for _, guess := range []struct {
+		key         monitorapi.LocatorKey
+		locatorType monitorapi.LocatorType
+	}{
+		{key: monitorapi.LocatorE2ETestKey, locatorType: monitorapi.LocatorTypeE2ETest},
+		{key: monitorapi.LocatorBackendDisruptionNameKey, locatorType: monitorapi.LocatorTypeDisruption},
+		{key: monitorapi.LocatorAlertKey, locatorType: monitorapi.LocatorTypeAlert},
+		{key: monitorapi.LocatorClusterOperatorKey, locatorType: monitorapi.LocatorTypeClusterOperator},
+		{key: monitorapi.LocatorClusterVersionKey, locatorType: monitorapi.LocatorTypeClusterVersion},
+		{key: monitorapi.LocatorContainerKey, locatorType: monitorapi.LocatorTypeContainer},
+		{key: monitorapi.LocatorPodKey, locatorType: monitorapi.LocatorTypePod},
+		{key: monitorapi.LocatorMachineKey, locatorType: monitorapi.LocatorTypeMachine},
+		{key: monitorapi.LocatorNodeKey, locatorType: monitorapi.LocatorTypeNode},
+	} {
+		if _, ok := keys[guess.key]; ok {
+			return guess.locatorType
+		}
+	}
+	return ""
+}
+
+// LegacyMessage converts a string message, as written by monitorapi.Message.OldMessage, to a structured one.  The
+// message starts with key/value annotations and the rest is the human message.
+func LegacyMessage(message string) monitorapi.Message {
+	ret := monitorapi.Message{Annotations: map[monitorapi.AnnotationKey]string{}}
+	tokens := strings.Split(message, " ")
+	for i, token := range tokens {
+		key, value, ok := strings.Cut(token, "/")
+		if !ok || len(key) == 0 {
+			ret.HumanMessage = strings.Join(tokens[i:], " ")
+			break
+		}
+		ret.Annotations[monitorapi.AnnotationKey(key)] = value
+	}
+	ret.Reason = monitorapi.IntervalReason(ret.Annotations[monitorapi.AnnotationReason])
+	ret.Cause = ret.Annotations[monitorapi.AnnotationCause]
+	return ret
+}
+
+// StrictIntervalsFromJSON reads intervals like IntervalsFromJSON and rejects them if any of them fails
+// monitorapi.ValidateInterval.
+func StrictIntervalsFromJSON(data []byte) (monitorapi.Intervals, error) {
+	intervals, err := IntervalsFromJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateIntervals(intervals); err != nil {
+		return nil, err
+	}
+	return intervals, nil
+}
+
+// ValidateIntervals returns the problems monitorapi.ValidateInterval finds, once for each distinct problem so that a
+// bad reason repeated across a run is reported once.
+func ValidateIntervals(intervals monitorapi.Intervals) error {
+	counts := map[string]int{}
+	for _, interval := range intervals {
+		for _, err := range monitorapi.ValidateInterval(interval) {
+			counts[err.Error()]++
+		}
+	}
+	problems := make([]string, 0, len(counts))
+	for problem := range counts {
+		problems = append(problems, problem)
+	}
+	sort.Strings(problems)
+
+	errs := []error{}
+	for _, problem := range problems {
+		errs = append(errs, fmt.Errorf("%s in %d intervals", problem, counts[problem]))
+	}
+	return utilerrors.NewAggregate(errs)
+}
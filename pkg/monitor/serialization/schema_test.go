@@ -0,0 +1,156 @@
+package monitorserialization
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+func TestIntervalsFromJSONVersions(t *testing.T) {
+	expected := monitorapi.Intervals{
+		{
+			Condition: monitorapi.Condition{
+				Level: monitorapi.Error,
+				Locator: monitorapi.Locator{
+					Type: monitorapi.LocatorTypePod,
+					Keys: map[monitorapi.LocatorKey]string{
+						monitorapi.LocatorNamespaceKey: "openshift-etcd",
+						monitorapi.LocatorPodKey:       "etcd-0",
+					},
+				},
+				Message: monitorapi.Message{
+					Reason:       monitorapi.PodReasonNotReady,
+					HumanMessage: "pod is not ready",
+					Annotations: map[monitorapi.AnnotationKey]string{
+						monitorapi.AnnotationReason: string(monitorapi.PodReasonNotReady),
+					},
+				},
+			},
+			Source: monitorapi.SourcePodMonitor,
+			// times are read in the local time zone
+			From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Local(),
+			To:   time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC).Local(),
+		},
+	}
+	from, to := `"2024-01-01T00:00:00Z"`, `"2024-01-01T00:01:00Z"`
+
+	tests := []struct {
+		name string
+		item string
+	}{
+		{
+			name: "current",
+			item: `"source":"PodMonitor","locator":{"type":"Pod","keys":{"namespace":"openshift-etcd","pod":"etcd-0"}},
+				"message":{"reason":"PodNotReady","cause":"","humanMessage":"pod is not ready","annotations":{"reason":"PodNotReady"}}`,
+		},
+		{
+			name: "string locator and message",
+			item: `"source":"PodMonitor","locator":"ns/openshift-etcd pod/etcd-0","message":"reason/PodNotReady pod is not ready"`,
+		},
+		{
+			name: "temporary structured fields",
+			item: `"tempSource":"PodMonitor","locator":"ns/openshift-etcd pod/etcd-0 uid/ignored","message":"ignored",
+				"tempStructuredLocator":{"type":"Pod","keys":{"namespace":"openshift-etcd","pod":"etcd-0"}},
+				"tempStructuredMessage":{"reason":"PodNotReady","cause":"","humanMessage":"pod is not ready","annotations":{"reason":"PodNotReady"}}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			item := `{"level":"Error",` + test.item + `,"from":` + from + `,"to":` + to + `}`
+			for _, apiVersion := range []string{"", IntervalsAPIVersion} {
+				if apiVersion == IntervalsAPIVersion && test.name != "current" {
+					continue
+				}
+				data := `{"items":[` + item + `]}`
+				if len(apiVersion) > 0 {
+					data = `{"apiVersion":"` + apiVersion + `","items":[` + item + `]}`
+				}
+				got, err := IntervalsFromJSON([]byte(data))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(expected, got) {
+					t.Errorf("apiVersion %q: expected\n%#v\ngot\n%#v", apiVersion, expected, got)
+				}
+			}
+		})
+	}
+
+	if _, err := IntervalsFromJSON([]byte(`{"apiVersion":"monitor.openshift.io/v99","items":[]}`)); err == nil {
+		t.Error("expected an unknown apiVersion to be rejected")
+	}
+}
+
+func TestLegacyLocator(t *testing.T) {
+	tests := []struct {
+		locator  string
+		expected monitorapi.Locator
+	}{
+		{
+			locator: `node/master-0`,
+			expected: monitorapi.Locator{Type: monitorapi.LocatorTypeNode, Keys: map[monitorapi.LocatorKey]string{
+				monitorapi.LocatorNodeKey: "master-0",
+			}},
+		},
+		{
+			locator: `ns/e2e-test container/c pod/p uid/u`,
+			expected: monitorapi.Locator{Type: monitorapi.LocatorTypeContainer, Keys: map[monitorapi.LocatorKey]string{
+				monitorapi.LocatorNamespaceKey: "e2e-test",
+				monitorapi.LocatorContainerKey: "c",
+				monitorapi.LocatorPodKey:       "p",
+				monitorapi.LocatorUIDKey:       "u",
+			}},
+		},
+		{
+			locator: `e2e-test/"[sig-node] a test with/slashes" status/Passed`,
+			expected: monitorapi.Locator{Type: monitorapi.LocatorTypeE2ETest, Keys: map[monitorapi.LocatorKey]string{
+				monitorapi.LocatorE2ETestKey: "[sig-node] a test with/slashes",
+				"status":                     "Passed",
+			}},
+		},
+	}
+	for _, test := range tests {
+		if got := LegacyLocator(test.locator); !reflect.DeepEqual(test.expected, got) {
+			t.Errorf("%s: expected %#v, got %#v", test.locator, test.expected, got)
+		}
+	}
+}
+
+func TestStrictIntervalsFromJSON(t *testing.T) {
+	known, err := IntervalsToJSON(monitorapi.Intervals{
+		monitorapi.NewInterval(monitorapi.SourcePodMonitor, monitorapi.Info).
+			Locator(monitorapi.NewLocator().PodFromNames("ns", "pod", "uid")).
+			Message(monitorapi.NewMessage().Reason(monitorapi.PodReasonCreated)).
+			BuildNow(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := StrictIntervalsFromJSON(known); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	unknown := monitorapi.NewInterval(monitorapi.SourcePodMonitor, monitorapi.Info).
+		Locator(monitorapi.Locator{Type: monitorapi.LocatorTypePod, Keys: map[monitorapi.LocatorKey]string{"shoe": "left"}}).
+		Message(monitorapi.NewMessage().Reason("Dancing")).
+		BuildNow()
+	data, err := IntervalsToJSON(monitorapi.Intervals{unknown, unknown})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := IntervalsFromJSON(data); err != nil {
+		t.Errorf("expected lenient reads to accept unknown keys, got %v", err)
+	}
+	_, err = StrictIntervalsFromJSON(data)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, expected := range []string{`unknown locator key "shoe" in 2 intervals`, `unknown reason "Dancing" for source "PodMonitor" in 2 intervals`} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q in %v", expected, err)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package monitorserialization
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"sort"
 
@@ -31,7 +33,8 @@ type EventInterval struct {
 
 // EventList is not an interval.  It is an instant.  The instant removes any ambiguity about "when"
 type EventIntervalList struct {
-	Items []EventInterval `json:"items"`
+	APIVersion string          `json:"apiVersion,omitempty"`
+	Items      []EventInterval `json:"items"`
 }
 
 func EventsToFile(filename string, events monitorapi.Intervals) error {
@@ -50,13 +53,29 @@ func EventsFromFile(filename string) (monitorapi.Intervals, error) {
 	return IntervalsFromJSON(data)
 }
 
+// IntervalsFromJSON reads intervals in the JSON format or the compact format, gzipped or not.  The name predates the
+// compact format.  JSON written before IntervalsAPIVersion existed is converted.
 func IntervalsFromJSON(data []byte) (monitorapi.Intervals, error) {
-	var list EventIntervalList
-	if err := json.Unmarshal(data, &list); err != nil {
+	if isGzip(data) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, err
+		}
+	}
+	if isCompact(data) {
+		return intervalsFromCompactLines(bytes.NewReader(data))
+	}
+
+	items, err := itemsFromJSON(data)
+	if err != nil {
 		return nil, err
 	}
-	events := make(monitorapi.Intervals, 0, len(list.Items))
-	for _, interval := range list.Items {
+	events := make(monitorapi.Intervals, 0, len(items))
+	for _, interval := range items {
 		level, err := monitorapi.ConditionLevelFromString(interval.Level)
 		if err != nil {
 			return nil, err
@@ -123,7 +142,7 @@ func IntervalsToJSON(intervals monitorapi.Intervals) ([]byte, error) {
 	}
 
 	sort.Sort(byTime(outputEvents))
-	list := EventIntervalList{Items: outputEvents}
+	list := EventIntervalList{APIVersion: IntervalsAPIVersion, Items: outputEvents}
 	return json.MarshalIndent(list, "", "    ")
 }
 
@@ -148,7 +167,7 @@ func EventsIntervalsToJSON(events monitorapi.Intervals) ([]byte, error) {
 	}
 
 	sort.Sort(byTime(outputEvents))
-	list := EventIntervalList{Items: outputEvents}
+	list := EventIntervalList{APIVersion: IntervalsAPIVersion, Items: outputEvents}
 	return json.MarshalIndent(list, "", "    ")
 }
 
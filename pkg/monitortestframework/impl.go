@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +21,15 @@ import (
 
 type monitorTestRegistry struct {
 	monitorTests map[string]*monitorTesttItem
+
+	// phaseTimeouts are opt in, phases without one have no timeout.
+	phaseTimeouts map[Phase]time.Duration
+
+	timingsLock sync.Mutex
+	timings     []PhaseTiming
+	// timingsStorageDir and timingsTimeSuffix are where the timings were last written.
+	timingsStorageDir string
+	timingsTimeSuffix string
 }
 
 type monitorTesttItem struct {
@@ -27,11 +37,34 @@ type monitorTesttItem struct {
 	jiraComponent string
 
 	monitorTest MonitorTest
+
+	lock sync.Mutex
+	// timedOutPhase is the phase the monitor test was abandoned in.
+	timedOutPhase Phase
+}
+
+func (i *monitorTesttItem) getTimedOutPhase() Phase {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return i.timedOutPhase
+}
+
+func (i *monitorTesttItem) setTimedOutPhase(phase Phase) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.timedOutPhase = phase
 }
 
 func NewMonitorTestRegistry() MonitorTestRegistry {
 	return &monitorTestRegistry{
-		monitorTests: map[string]*monitorTesttItem{},
+		monitorTests:  map[string]*monitorTesttItem{},
+		phaseTimeouts: map[Phase]time.Duration{},
+	}
+}
+
+func (r *monitorTestRegistry) SetPhaseTimeouts(timeouts map[Phase]time.Duration) {
+	for phase, timeout := range timeouts {
+		r.phaseTimeouts[phase] = timeout
 	}
 }
 
@@ -57,6 +90,7 @@ func (r *monitorTestRegistry) AddMonitorTestOrDie(name, jiraComponent string, mo
 
 func (r *monitorTestRegistry) GetRegistryFor(names ...string) (MonitorTestRegistry, error) {
 	ret := NewMonitorTestRegistry().(*monitorTestRegistry)
+	ret.SetPhaseTimeouts(r.phaseTimeouts)
 
 	missingNames := []string{}
 	for _, name := range names {
@@ -91,10 +125,9 @@ func (r *monitorTestRegistry) StartCollection(ctx context.Context, adminRESTConf
 			testName := fmt.Sprintf("[Jira:%q] monitor test %v setup", invariant.jiraComponent, invariant.name)
 			logrus.Infof("  Starting %v for %v", invariant.name, invariant.jiraComponent)
 
-			start := time.Now()
-			err := startCollectionWithPanicProtection(ctx, invariant.monitorTest, adminRESTConfig, recorder)
-			end := time.Now()
-			duration := end.Sub(start)
+			_, duration, err := r.runPhase(invariant, PhaseStartCollection, func() (phaseResult, error) {
+				return phaseResult{}, startCollectionWithPanicProtection(ctx, invariant.monitorTest, adminRESTConfig, recorder)
+			})
 			if err != nil {
 				var nsErr *NotSupportedError
 				if errors.As(err, &nsErr) {
@@ -109,12 +142,10 @@ func (r *monitorTestRegistry) StartCollection(ctx context.Context, adminRESTConf
 				}
 				errCh <- err
 				junitCh <- &junitapi.JUnitTestCase{
-					Name:     testName,
-					Duration: duration.Seconds(),
-					FailureOutput: &junitapi.FailureOutput{
-						Output: fmt.Sprintf("failed during setup\n%v", err),
-					},
-					SystemOut: fmt.Sprintf("failed during setup\n%v", err),
+					Name:          testName,
+					Duration:      duration.Seconds(),
+					FailureOutput: failureOutput("setup", err),
+					SystemOut:     fmt.Sprintf("failed during setup\n%v", err),
 				}
 				var flakeErr *FlakeError
 				if !errors.As(err, &flakeErr) {
@@ -159,13 +190,13 @@ func (r *monitorTestRegistry) CollectData(ctx context.Context, storageDir string
 			defer wg.Done()
 			testName := fmt.Sprintf("[Jira:%q] monitor test %v collection", monitorTest.jiraComponent, monitorTest.name)
 
-			start := time.Now()
 			logrus.Infof("  Starting CollectData for %s", testName)
-			localIntervals, localJunits, err := collectDataWithPanicProtection(ctx, monitorTest.monitorTest, storageDir, beginning, end)
-			intervalsCh <- localIntervals
-			junitCh <- localJunits
-			end := time.Now()
-			duration := end.Sub(start)
+			result, duration, err := r.runPhase(monitorTest, PhaseCollectData, func() (phaseResult, error) {
+				localIntervals, localJunits, err := collectDataWithPanicProtection(ctx, monitorTest.monitorTest, storageDir, beginning, end)
+				return phaseResult{intervals: localIntervals, junits: localJunits}, err
+			})
+			intervalsCh <- result.intervals
+			junitCh <- result.junits
 			if err != nil {
 				var nsErr *NotSupportedError
 				if errors.As(err, &nsErr) {
@@ -183,12 +214,10 @@ func (r *monitorTestRegistry) CollectData(ctx context.Context, storageDir string
 				}
 				junitCh <- []*junitapi.JUnitTestCase{
 					{
-						Name:     testName,
-						Duration: duration.Seconds(),
-						FailureOutput: &junitapi.FailureOutput{
-							Output: fmt.Sprintf("failed during collection\n%v", err),
-						},
-						SystemOut: fmt.Sprintf("failed during collection\n%v", err),
+						Name:          testName,
+						Duration:      duration.Seconds(),
+						FailureOutput: failureOutput("collection", err),
+						SystemOut:     fmt.Sprintf("failed during collection\n%v", err),
 					},
 				}
 				var flakeErr *FlakeError
@@ -235,46 +264,62 @@ func (r *monitorTestRegistry) ConstructComputedIntervals(ctx context.Context, st
 	junits := []*junitapi.JUnitTestCase{}
 	errs := []error{}
 
+	independent, dependent := []*monitorTesttItem{}, []*monitorTesttItem{}
 	for _, monitorTest := range r.monitorTests {
-		testName := fmt.Sprintf("[Jira:%q] monitor test %v interval construction", monitorTest.jiraComponent, monitorTest.name)
+		if _, ok := monitorTest.monitorTest.(ComputedIntervalsDependent); ok {
+			dependent = append(dependent, monitorTest)
+			continue
+		}
+		independent = append(independent, monitorTest)
+	}
 
-		start := time.Now()
-		localIntervals, err := constructComputedIntervalsWithPanicProtection(ctx, monitorTest.monitorTest, startingIntervals, recordedResources, beginning, end)
-		intervals = append(intervals, localIntervals...)
-		end := time.Now()
-		duration := end.Sub(start)
-		if err != nil {
-			var nsErr *NotSupportedError
-			if errors.As(err, &nsErr) {
+	constructFrom := startingIntervals
+	for _, monitorTests := range [][]*monitorTesttItem{independent, dependent} {
+		// abandoned monitor tests may still be reading from the intervals of their pass
+		passIntervals := constructFrom
+		for _, monitorTest := range monitorTests {
+			testName := fmt.Sprintf("[Jira:%q] monitor test %v interval construction", monitorTest.jiraComponent, monitorTest.name)
+
+			result, duration, err := r.runPhase(monitorTest, PhaseConstructComputedIntervals, func() (phaseResult, error) {
+				localIntervals, err := constructComputedIntervalsWithPanicProtection(ctx, monitorTest.monitorTest, passIntervals, recordedResources, beginning, end)
+				return phaseResult{intervals: localIntervals}, err
+			})
+			intervals = append(intervals, result.intervals...)
+			if err != nil {
+				var nsErr *NotSupportedError
+				if errors.As(err, &nsErr) {
+					junits = append(junits, &junitapi.JUnitTestCase{
+						Name:     testName,
+						Duration: duration.Seconds(),
+						SkipMessage: &junitapi.SkipMessage{
+							Message: nsErr.Reason,
+						},
+					})
+					continue
+				}
+
+				errs = append(errs, err)
 				junits = append(junits, &junitapi.JUnitTestCase{
-					Name:     testName,
-					Duration: duration.Seconds(),
-					SkipMessage: &junitapi.SkipMessage{
-						Message: nsErr.Reason,
-					},
+					Name:          testName,
+					Duration:      duration.Seconds(),
+					FailureOutput: failureOutput("interval construction", err),
+					SystemOut:     fmt.Sprintf("failed during interval construction\n%v", err),
 				})
-				continue
+				var flakeErr *FlakeError
+				if !errors.As(err, &flakeErr) {
+					continue
+				}
 			}
 
-			errs = append(errs, err)
 			junits = append(junits, &junitapi.JUnitTestCase{
 				Name:     testName,
 				Duration: duration.Seconds(),
-				FailureOutput: &junitapi.FailureOutput{
-					Output: fmt.Sprintf("failed during interval construction\n%v", err),
-				},
-				SystemOut: fmt.Sprintf("failed during interval construction\n%v", err),
 			})
-			var flakeErr *FlakeError
-			if !errors.As(err, &flakeErr) {
-				continue
-			}
 		}
 
-		junits = append(junits, &junitapi.JUnitTestCase{
-			Name:     testName,
-			Duration: duration.Seconds(),
-		})
+		// the dependent monitor tests construct from what the others constructed
+		constructFrom = append(append(monitorapi.Intervals{}, startingIntervals...), intervals...)
+		sort.Sort(constructFrom)
 	}
 
 	return intervals, junits, utilerrors.NewAggregate(errs)
@@ -287,11 +332,11 @@ func (r *monitorTestRegistry) EvaluateTestsFromConstructedIntervals(ctx context.
 	for _, monitorTest := range r.monitorTests {
 		testName := fmt.Sprintf("[Jira:%q] monitor test %v test evaluation", monitorTest.jiraComponent, monitorTest.name)
 
-		start := time.Now()
-		localJunits, err := evaluateTestsFromConstructedIntervalsWithPanicProtection(ctx, monitorTest.monitorTest, finalIntervals)
-		junits = append(junits, localJunits...)
-		end := time.Now()
-		duration := end.Sub(start)
+		result, duration, err := r.runPhase(monitorTest, PhaseEvaluateTestsFromConstructedIntervals, func() (phaseResult, error) {
+			localJunits, err := evaluateTestsFromConstructedIntervalsWithPanicProtection(ctx, monitorTest.monitorTest, finalIntervals)
+			return phaseResult{junits: localJunits}, err
+		})
+		junits = append(junits, result.junits...)
 		if err != nil {
 			var nsErr *NotSupportedError
 			if errors.As(err, &nsErr) {
@@ -307,12 +352,10 @@ func (r *monitorTestRegistry) EvaluateTestsFromConstructedIntervals(ctx context.
 
 			errs = append(errs, err)
 			junits = append(junits, &junitapi.JUnitTestCase{
-				Name:     testName,
-				Duration: duration.Seconds(),
-				FailureOutput: &junitapi.FailureOutput{
-					Output: fmt.Sprintf("failed during test evaluation\n%v", err),
-				},
-				SystemOut: fmt.Sprintf("failed during test evaluation\n%v", err),
+				Name:          testName,
+				Duration:      duration.Seconds(),
+				FailureOutput: failureOutput("test evaluation", err),
+				SystemOut:     fmt.Sprintf("failed during test evaluation\n%v", err),
 			})
 			var flakeErr *FlakeError
 			if !errors.As(err, &flakeErr) {
@@ -336,8 +379,6 @@ func (r *monitorTestRegistry) WriteContentToStorage(ctx context.Context, storage
 	for _, monitorTest := range r.monitorTests {
 		testName := fmt.Sprintf("[Jira:%q] monitor test %v writing to storage", monitorTest.jiraComponent, monitorTest.name)
 
-		start := time.Now()
-
 		var finalIntervalLength = len(finalIntervals)
 		fmt.Fprintf(os.Stderr, "Processing monitorTest: %s\n", monitorTest.name)
 		fmt.Fprintf(os.Stderr, "  finalIntervals size = %d\n", finalIntervalLength)
@@ -346,9 +387,9 @@ func (r *monitorTestRegistry) WriteContentToStorage(ctx context.Context, storage
 			fmt.Fprintf(os.Stderr, "  last interval time: From = %s; To = %s\n", finalIntervals[finalIntervalLength-1].From, finalIntervals[finalIntervalLength-1].To)
 		}
 
-		err := writeContentToStorageWithPanicProtection(ctx, monitorTest.monitorTest, storageDir, timeSuffix, finalIntervals, finalResourceState)
-		end := time.Now()
-		duration := end.Sub(start)
+		_, duration, err := r.runPhase(monitorTest, PhaseWriteContentToStorage, func() (phaseResult, error) {
+			return phaseResult{}, writeContentToStorageWithPanicProtection(ctx, monitorTest.monitorTest, storageDir, timeSuffix, finalIntervals, finalResourceState)
+		})
 		if err != nil {
 			var nsErr *NotSupportedError
 			if errors.As(err, &nsErr) {
@@ -364,12 +405,10 @@ func (r *monitorTestRegistry) WriteContentToStorage(ctx context.Context, storage
 
 			errs = append(errs, err)
 			junits = append(junits, &junitapi.JUnitTestCase{
-				Name:     testName,
-				Duration: duration.Seconds(),
-				FailureOutput: &junitapi.FailureOutput{
-					Output: fmt.Sprintf("failed during test evaluation\n%v", err),
-				},
-				SystemOut: fmt.Sprintf("failed during test evaluation\n%v", err),
+				Name:          testName,
+				Duration:      duration.Seconds(),
+				FailureOutput: failureOutput("test evaluation", err),
+				SystemOut:     fmt.Sprintf("failed during test evaluation\n%v", err),
 			})
 			var flakeErr *FlakeError
 			if !errors.As(err, &flakeErr) {
@@ -383,6 +422,11 @@ func (r *monitorTestRegistry) WriteContentToStorage(ctx context.Context, storage
 		})
 	}
 
+	// the timings are not junits, failing to write them only deserves a warning
+	if err := r.writePhaseTimings(storageDir, timeSuffix); err != nil {
+		logrus.WithError(err).Warning("Unable to write monitor test timings")
+	}
+
 	return junits, utilerrors.NewAggregate(errs)
 }
 
@@ -394,11 +438,10 @@ func (r *monitorTestRegistry) Cleanup(ctx context.Context) ([]*junitapi.JUnitTes
 		testName := fmt.Sprintf("[Jira:%q] monitor test %v cleanup", monitorTest.jiraComponent, monitorTest.name)
 		log := logrus.WithField("monitorTest", monitorTest.name)
 
-		start := time.Now()
 		log.Info("beginning cleanup")
-		err := cleanupWithPanicProtection(ctx, monitorTest.monitorTest)
-		end := time.Now()
-		duration := end.Sub(start)
+		_, duration, err := r.runPhase(monitorTest, PhaseCleanup, func() (phaseResult, error) {
+			return phaseResult{}, cleanupWithPanicProtection(ctx, monitorTest.monitorTest)
+		})
 		if err != nil {
 			var nsErr *NotSupportedError
 			if errors.As(err, &nsErr) {
@@ -415,12 +458,10 @@ func (r *monitorTestRegistry) Cleanup(ctx context.Context) ([]*junitapi.JUnitTes
 			log.WithError(err).Error("failed during cleanup")
 			errs = append(errs, err)
 			junits = append(junits, &junitapi.JUnitTestCase{
-				Name:     testName,
-				Duration: duration.Seconds(),
-				FailureOutput: &junitapi.FailureOutput{
-					Output: fmt.Sprintf("failed during cleanup\n%v", err),
-				},
-				SystemOut: fmt.Sprintf("failed during cleanup\n%v", err),
+				Name:          testName,
+				Duration:      duration.Seconds(),
+				FailureOutput: failureOutput("cleanup", err),
+				SystemOut:     fmt.Sprintf("failed during cleanup\n%v", err),
 			})
 			var flakeErr *FlakeError
 			if !errors.As(err, &flakeErr) {
@@ -434,6 +475,57 @@ func (r *monitorTestRegistry) Cleanup(ctx context.Context) ([]*junitapi.JUnitTes
 		})
 	}
 
+	// cleanup may run after the content was written to storage, include its timings
+	if err := r.rewritePhaseTimings(); err != nil {
+		logrus.WithError(err).Warning("Unable to write monitor test timings")
+	}
+
+	return junits, utilerrors.NewAggregate(errs)
+}
+
+func (r *monitorTestRegistry) PrepareForOfflineEvaluation(ctx context.Context, info OfflineEvaluationInfo) ([]*junitapi.JUnitTestCase, error) {
+	junits := []*junitapi.JUnitTestCase{}
+	errs := []error{}
+
+	for _, monitorTest := range r.monitorTests {
+		offlineMonitorTest, ok := monitorTest.monitorTest.(OfflineMonitorTest)
+		if !ok {
+			continue
+		}
+		testName := fmt.Sprintf("[Jira:%q] monitor test %v offline preparation", monitorTest.jiraComponent, monitorTest.name)
+
+		_, duration, err := r.runPhase(monitorTest, PhasePrepareForOfflineEvaluation, func() (phaseResult, error) {
+			return phaseResult{}, prepareForOfflineEvaluationWithPanicProtection(ctx, offlineMonitorTest, info)
+		})
+		if err != nil {
+			var nsErr *NotSupportedError
+			if errors.As(err, &nsErr) {
+				junits = append(junits, &junitapi.JUnitTestCase{
+					Name:     testName,
+					Duration: duration.Seconds(),
+					SkipMessage: &junitapi.SkipMessage{
+						Message: nsErr.Reason,
+					},
+				})
+				continue
+			}
+
+			errs = append(errs, err)
+			junits = append(junits, &junitapi.JUnitTestCase{
+				Name:          testName,
+				Duration:      duration.Seconds(),
+				FailureOutput: failureOutput("offline preparation", err),
+				SystemOut:     fmt.Sprintf("failed during offline preparation\n%v", err),
+			})
+			continue
+		}
+
+		junits = append(junits, &junitapi.JUnitTestCase{
+			Name:     testName,
+			Duration: duration.Seconds(),
+		})
+	}
+
 	return junits, utilerrors.NewAggregate(errs)
 }
 
@@ -0,0 +1,68 @@
+package monitortestframework
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+type constructingMonitorTest struct {
+	fakeMonitorTest
+	constructed monitorapi.Intervals
+	seen        monitorapi.Intervals
+}
+
+func (c *constructingMonitorTest) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	c.seen = startingIntervals
+	return c.constructed, nil
+}
+
+type dependentMonitorTest struct {
+	constructingMonitorTest
+}
+
+func (*dependentMonitorTest) DependsOnComputedIntervals() {}
+
+func TestDependentMonitorTestsConstructFromComputedIntervals(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	interval := func(source monitorapi.IntervalSource, from time.Time) monitorapi.Interval {
+		return monitorapi.NewInterval(source, monitorapi.Info).
+			Locator(monitorapi.NewLocator().NodeFromName("master-0")).
+			Message(monitorapi.NewMessage().HumanMessage(string(source))).
+			Build(from, from.Add(time.Second))
+	}
+	raw := interval(monitorapi.SourceNodeMonitor, start.Add(2*time.Second))
+	computed := interval(monitorapi.SourceNodeState, start)
+	dependentComputed := interval(monitorapi.SourceDisruptionCause, start.Add(time.Second))
+
+	independent := &constructingMonitorTest{constructed: monitorapi.Intervals{computed}}
+	dependent := &dependentMonitorTest{constructingMonitorTest{constructed: monitorapi.Intervals{dependentComputed}}}
+	otherDependent := &dependentMonitorTest{}
+
+	registry := NewMonitorTestRegistry()
+	// registered first, still constructed after the monitor tests it depends on
+	registry.AddMonitorTestOrDie("dependent", "Test Framework", dependent)
+	registry.AddMonitorTestOrDie("other-dependent", "Test Framework", otherDependent)
+	registry.AddMonitorTestOrDie("independent", "Test Framework", independent)
+
+	intervals, _, err := registry.ConstructComputedIntervals(context.Background(), monitorapi.Intervals{raw}, nil, start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intervals) != 2 {
+		t.Errorf("expected the intervals constructed by both monitor tests, got %v", intervals.Strings())
+	}
+	if len(independent.seen) != 1 || independent.seen[0].Source != monitorapi.SourceNodeMonitor {
+		t.Errorf("expected the independent monitor test to construct from the starting intervals, got %v", independent.seen.Strings())
+	}
+	if len(dependent.seen) != 2 || dependent.seen[0].Source != monitorapi.SourceNodeState || dependent.seen[1].Source != monitorapi.SourceNodeMonitor {
+		t.Errorf("expected the dependent monitor test to construct from the sorted starting and computed intervals, got %v", dependent.seen.Strings())
+	}
+	for _, seen := range otherDependent.seen {
+		if seen.Source == monitorapi.SourceDisruptionCause {
+			t.Errorf("expected dependent monitor tests not to see the intervals constructed by each other")
+		}
+	}
+}
@@ -90,3 +90,16 @@ func cleanupWithPanicProtection(ctx context.Context, monitortest MonitorTest) (e
 	err = monitortest.Cleanup(ctx)
 	return
 }
+
+func prepareForOfflineEvaluationWithPanicProtection(ctx context.Context, monitortest OfflineMonitorTest, info OfflineEvaluationInfo) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("caught panic: %v", r)
+			logrus.WithError(err).Error("recovering from panic")
+			fmt.Print(debug.Stack())
+		}
+	}()
+
+	err = monitortest.PrepareForOfflineEvaluation(ctx, info)
+	return
+}
@@ -0,0 +1,496 @@
+// Package plugin adapts monitor tests implemented by binaries outside of origin into MonitorTests, so that
+// component teams can watch cluster invariants without landing code in origin.  See protocol.go for what a
+// plugin binary has to implement.
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/test/extensions"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+const (
+	infoTimeout = time.Minute
+	// stopTimeout is how long a plugin has to exit once its stdin is closed before it is killed.
+	stopTimeout = 30 * time.Second
+)
+
+// defaultTimeouts bound each call to a plugin.  A plugin that does not answer in time is killed, every later
+// call to it fails.
+var defaultTimeouts = map[string]time.Duration{
+	MethodStartCollection:                       5 * time.Minute,
+	MethodCollectData:                           30 * time.Minute,
+	MethodConstructComputedIntervals:            10 * time.Minute,
+	MethodEvaluateTestsFromConstructedIntervals: 10 * time.Minute,
+	MethodWriteContentToStorage:                 10 * time.Minute,
+	MethodCleanup:                               5 * time.Minute,
+}
+
+// Plugin is a MonitorTest implemented by a plugin binary.  The binary is only started on the first call, so a
+// plugin that is never called, for instance because it is filtered out, costs nothing.
+type Plugin struct {
+	path     string
+	info     Info
+	timeouts map[string]time.Duration
+	// removeBinary removes the binary if it was extracted from the payload.
+	removeBinary func()
+
+	// writeLock keeps the requests of concurrent calls from interleaving on stdin.
+	writeLock sync.Mutex
+
+	lock     sync.Mutex
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	nextID   int
+	pending  map[int]chan *Response
+	recorder monitorapi.RecorderWriter
+	// stopped is set once the plugin was told to stop, it is not started again.
+	stopped bool
+	// exited is closed once the process is gone, exitErr says why.
+	exited  chan struct{}
+	exitErr error
+}
+
+var _ monitortestframework.MonitorTest = &Plugin{}
+
+// Load returns the plugin for spec, which is either the path to a local binary, starting with / or ., or
+// <tag>:<path> to extract the binary at path from the image with tag in the release payload.
+func Load(ctx context.Context, spec string) (*Plugin, error) {
+	path, removeBinary, err := resolve(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := readInfo(ctx, path)
+	if err != nil {
+		removeBinary()
+		return nil, fmt.Errorf("monitor plugin %q: %w", spec, err)
+	}
+	return &Plugin{
+		path:         path,
+		info:         *info,
+		timeouts:     defaultTimeouts,
+		removeBinary: sync.OnceFunc(removeBinary),
+	}, nil
+}
+
+func resolve(spec string) (string, func(), error) {
+	if strings.HasPrefix(spec, "/") || strings.HasPrefix(spec, ".") {
+		if _, err := os.Stat(spec); err != nil {
+			return "", nil, fmt.Errorf("monitor plugin %q: %w", spec, err)
+		}
+		return spec, func() {}, nil
+	}
+
+	tag, binary, ok := strings.Cut(spec, ":")
+	if !ok || len(tag) == 0 || !strings.HasPrefix(binary, "/") {
+		return "", nil, fmt.Errorf("monitor plugin %q must be a local path or <tag>:<path in image>", spec)
+	}
+	testBinary, cleanup, err := extensions.ExtractPayloadBinary(tag, binary)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to extract monitor plugin %q: %w", spec, err)
+	}
+	return testBinary.BinaryPath(), cleanup, nil
+}
+
+func readInfo(ctx context.Context, path string) (*Info, error) {
+	ctx, cancel := context.WithTimeout(ctx, infoTimeout)
+	defer cancel()
+
+	stderr := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, path, "info")
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("info failed: %w\n%s", err, stderr.String())
+	}
+
+	info := &Info{}
+	if err := json.Unmarshal(out, info); err != nil {
+		return nil, fmt.Errorf("invalid info: %w", err)
+	}
+	if info.APIVersion != APIVersion {
+		return nil, fmt.Errorf("unsupported apiVersion %q, expected %q", info.APIVersion, APIVersion)
+	}
+	if len(info.Name) == 0 {
+		return nil, fmt.Errorf("info has no name")
+	}
+	if len(info.JiraComponent) == 0 {
+		return nil, fmt.Errorf("info has no jiraComponent")
+	}
+	return info, nil
+}
+
+// Name is the name the monitor test is registered with.
+func (p *Plugin) Name() string {
+	return p.info.Name
+}
+
+// JiraComponent is the component the junits of the monitor test are reported against.
+func (p *Plugin) JiraComponent() string {
+	return p.info.JiraComponent
+}
+
+func (p *Plugin) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	p.lock.Lock()
+	p.recorder = recorder
+	p.lock.Unlock()
+
+	return p.call(ctx, MethodStartCollection, StartCollectionParams{}, nil)
+}
+
+func (p *Plugin) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	result := &CollectDataResult{}
+	err := p.call(ctx, MethodCollectData, CollectDataParams{StorageDir: storageDir, Beginning: beginning, End: end}, result)
+	if err != nil {
+		return nil, nil, err
+	}
+	intervals, err := intervalsFromJSON(result.Intervals)
+	if err != nil {
+		return nil, nil, err
+	}
+	return intervals, toJUnits(result.TestCases), nil
+}
+
+func (p *Plugin) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, _ monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	intervals, err := monitorserialization.IntervalsToJSON(startingIntervals)
+	if err != nil {
+		return nil, err
+	}
+	result := &ConstructComputedIntervalsResult{}
+	err = p.call(ctx, MethodConstructComputedIntervals, ConstructComputedIntervalsParams{Intervals: intervals, Beginning: beginning, End: end}, result)
+	if err != nil {
+		return nil, err
+	}
+	return intervalsFromJSON(result.Intervals)
+}
+
+func (p *Plugin) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	intervals, err := monitorserialization.IntervalsToJSON(finalIntervals)
+	if err != nil {
+		return nil, err
+	}
+	result := &EvaluateTestsFromConstructedIntervalsResult{}
+	err = p.call(ctx, MethodEvaluateTestsFromConstructedIntervals, EvaluateTestsFromConstructedIntervalsParams{Intervals: intervals}, result)
+	if err != nil {
+		return nil, err
+	}
+	return toJUnits(result.TestCases), nil
+}
+
+func (p *Plugin) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, _ monitorapi.ResourcesMap) error {
+	intervals, err := monitorserialization.IntervalsToJSON(finalIntervals)
+	if err != nil {
+		return err
+	}
+	return p.call(ctx, MethodWriteContentToStorage, WriteContentToStorageParams{StorageDir: storageDir, TimeSuffix: timeSuffix, Intervals: intervals}, nil)
+}
+
+// Cleanup is passed on to a running plugin, which is then stopped.  A plugin that was never started, or that
+// already exited, is not started for it.  Calling Cleanup again does nothing.
+func (p *Plugin) Cleanup(ctx context.Context) error {
+	defer p.removeBinary()
+
+	p.lock.Lock()
+	running := p.cmd != nil && !p.stopped
+	if running {
+		select {
+		case <-p.exited:
+			// the failure was reported by the call that saw it
+			running = false
+		default:
+		}
+	}
+	p.lock.Unlock()
+	if !running {
+		p.stop()
+		return nil
+	}
+
+	err := p.call(ctx, MethodCleanup, CleanupParams{}, nil)
+	p.stop()
+	return err
+}
+
+// call sends a request to the plugin, starting it if needed, and decodes the result into result.
+func (p *Plugin) call(ctx context.Context, method string, params, result interface{}) error {
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	// the timeout includes sending the request, a plugin that stops reading its stdin is killed like one that
+	// stops answering
+	timeout := p.timeouts[method]
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	p.lock.Lock()
+	if err := p.startLocked(); err != nil {
+		p.lock.Unlock()
+		return err
+	}
+	id := p.nextID
+	p.nextID++
+	responseCh := make(chan *Response, 1)
+	p.pending[id] = responseCh
+	exited := p.exited
+	stdin := p.stdin
+	p.lock.Unlock()
+	defer func() {
+		p.lock.Lock()
+		delete(p.pending, id)
+		p.lock.Unlock()
+	}()
+
+	request, err := json.Marshal(Request{ID: id, Method: method, Params: rawParams})
+	if err != nil {
+		return err
+	}
+	// the write is not done with the lock held, which kill and the reader of the responses need
+	written := make(chan error, 1)
+	go func() {
+		p.writeLock.Lock()
+		defer p.writeLock.Unlock()
+		_, err := stdin.Write(append(request, '\n'))
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		if err != nil {
+			return fmt.Errorf("monitor plugin %s: unable to send %s: %w", p.info.Name, method, err)
+		}
+	case <-exited:
+		return fmt.Errorf("monitor plugin %s exited during %s: %w", p.info.Name, method, p.exitError())
+	case <-ctx.Done():
+		p.kill()
+		return fmt.Errorf("monitor plugin %s did not read %s within %v and was killed", p.info.Name, method, timeout)
+	}
+
+	select {
+	case response := <-responseCh:
+		if response.Error != nil {
+			return toError(response.Error)
+		}
+		if result == nil || len(response.Result) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(response.Result, result); err != nil {
+			return fmt.Errorf("monitor plugin %s: invalid %s result: %w", p.info.Name, method, err)
+		}
+		return nil
+
+	case <-exited:
+		return fmt.Errorf("monitor plugin %s exited during %s: %w", p.info.Name, method, p.exitError())
+
+	case <-ctx.Done():
+		p.kill()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("monitor plugin %s did not finish %s within %v and was killed", p.info.Name, method, timeout)
+		}
+		return fmt.Errorf("monitor plugin %s was killed during %s: %w", p.info.Name, method, ctx.Err())
+	}
+}
+
+// startLocked starts the plugin if it is not running yet.  It must be called with the lock held.
+func (p *Plugin) startLocked() error {
+	if p.stopped {
+		return fmt.Errorf("monitor plugin %s was already stopped", p.info.Name)
+	}
+	if p.cmd != nil {
+		select {
+		case <-p.exited:
+			return fmt.Errorf("monitor plugin %s exited earlier: %w", p.info.Name, p.exitErr)
+		default:
+			return nil
+		}
+	}
+
+	cmd := exec.Command(p.path, "serve")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("unable to start monitor plugin %s: %w", p.info.Name, err)
+	}
+	logrus.Infof("Started monitor plugin %s from %s", p.info.Name, p.path)
+
+	p.cmd = cmd
+	p.stdin = stdin
+	p.pending = map[int]chan *Response{}
+	p.exited = make(chan struct{})
+
+	logDone := make(chan struct{})
+	go func() {
+		defer close(logDone)
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			logrus.WithField("monitorPlugin", p.info.Name).Info(scanner.Text())
+		}
+	}()
+	go func() {
+		readErr := p.read(stdout)
+		<-logDone
+		waitErr := cmd.Wait()
+
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		switch {
+		case waitErr != nil:
+			p.exitErr = waitErr
+		case readErr != nil:
+			p.exitErr = readErr
+		default:
+			p.exitErr = errors.New("plugin closed its output")
+		}
+		close(p.exited)
+	}()
+	return nil
+}
+
+// read delivers responses and notifications until the plugin closes its stdout.
+func (p *Plugin) read(stdout io.Reader) error {
+	reader := bufio.NewReader(stdout)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if handleErr := p.handle(line); handleErr != nil {
+				// the plugin does not speak the protocol, there is no telling what else it will send
+				p.kill()
+				io.Copy(io.Discard, reader)
+				return handleErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *Plugin) handle(line []byte) error {
+	message := struct {
+		ID     *int            `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}{}
+	if err := json.Unmarshal(line, &message); err != nil {
+		return fmt.Errorf("invalid output %q: %w", string(line), err)
+	}
+
+	if message.ID == nil {
+		if message.Method != NotificationRecord {
+			return fmt.Errorf("unknown notification %q", message.Method)
+		}
+		params := &RecordParams{}
+		if err := json.Unmarshal(message.Params, params); err != nil {
+			return fmt.Errorf("invalid %s notification: %w", NotificationRecord, err)
+		}
+		intervals, err := intervalsFromJSON(params.Intervals)
+		if err != nil {
+			return fmt.Errorf("invalid %s notification: %w", NotificationRecord, err)
+		}
+		p.lock.Lock()
+		recorder := p.recorder
+		p.lock.Unlock()
+		if recorder != nil {
+			recorder.AddIntervals(intervals...)
+		}
+		return nil
+	}
+
+	p.lock.Lock()
+	responseCh, ok := p.pending[*message.ID]
+	p.lock.Unlock()
+	if !ok {
+		// the call already gave up on the response
+		logrus.Warningf("Ignoring unexpected response %d from monitor plugin %s", *message.ID, p.info.Name)
+		return nil
+	}
+	responseCh <- &Response{ID: *message.ID, Result: message.Result, Error: message.Error}
+	return nil
+}
+
+// stop closes the stdin of the plugin and kills it if it does not exit in time.
+func (p *Plugin) stop() {
+	p.lock.Lock()
+	if p.stopped || p.cmd == nil {
+		p.stopped = true
+		p.lock.Unlock()
+		return
+	}
+	p.stopped = true
+	p.stdin.Close()
+	exited := p.exited
+	p.lock.Unlock()
+
+	select {
+	case <-exited:
+	case <-time.After(stopTimeout):
+		p.kill()
+		<-exited
+	}
+}
+
+func (p *Plugin) kill() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.cmd != nil && p.cmd.Process != nil {
+		p.cmd.Process.Kill()
+	}
+}
+
+func (p *Plugin) exitError() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.exitErr
+}
+
+func toError(err *Error) error {
+	switch err.Reason {
+	case ErrorReasonNotSupported:
+		return &monitortestframework.NotSupportedError{Reason: err.Message}
+	case ErrorReasonFlake:
+		return &monitortestframework.FlakeError{Err: errors.New(err.Message)}
+	default:
+		return errors.New(err.Message)
+	}
+}
+
+func intervalsFromJSON(data Intervals) (monitorapi.Intervals, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	return monitorserialization.IntervalsFromJSON(data)
+}
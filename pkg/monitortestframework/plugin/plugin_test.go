@@ -0,0 +1,242 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/monitortestframework"
+)
+
+// fakePluginEnv makes the test binary act as a plugin, its value selects how the plugin behaves.
+const fakePluginEnv = "FAKE_MONITOR_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(fakePluginEnv); len(mode) > 0 {
+		fakePlugin(mode, os.Args[1])
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestPlugin(t *testing.T) {
+	ctx := context.Background()
+	p := loadFakePlugin(t, "ok")
+	if p.Name() != "fake" || p.JiraComponent() != "Test Framework" {
+		t.Fatalf("unexpected info %#v", p.info)
+	}
+
+	recorder := monitor.NewRecorder()
+	if err := p.StartCollection(ctx, nil, recorder); err != nil {
+		t.Fatal(err)
+	}
+	if intervals := recorder.Intervals(time.Time{}, time.Time{}); len(intervals) != 1 || intervals[0].Message.HumanMessage != "recorded" {
+		t.Errorf("expected the recorded interval, got %v", intervals.Strings())
+	}
+
+	intervals, junits, err := p.CollectData(ctx, t.TempDir(), time.Now(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intervals) != 1 || intervals[0].Message.HumanMessage != "collected" {
+		t.Errorf("expected the collected interval, got %v", intervals.Strings())
+	}
+	if len(junits) != 1 || junits[0].FailureOutput == nil || junits[0].FailureOutput.Output != "broken" {
+		t.Errorf("expected a failed junit, got %#v", junits)
+	}
+
+	// the intervals sent are what the plugin echoes back
+	computed, err := p.ConstructComputedIntervals(ctx, intervals, nil, time.Now(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(computed) != 1 || computed[0].Message.HumanMessage != "collected" {
+		t.Errorf("expected the intervals to be echoed, got %v", computed.Strings())
+	}
+
+	_, err = p.EvaluateTestsFromConstructedIntervals(ctx, intervals)
+	var notSupported *monitortestframework.NotSupportedError
+	if !errors.As(err, &notSupported) || notSupported.Reason != "not on this platform" {
+		t.Errorf("expected a not supported error, got %v", err)
+	}
+	var flake *monitortestframework.FlakeError
+	if err := p.WriteContentToStorage(ctx, t.TempDir(), "", intervals, nil); !errors.As(err, &flake) {
+		t.Errorf("expected a flake error, got %v", err)
+	}
+
+	if err := p.Cleanup(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Cleanup(ctx); err != nil {
+		t.Errorf("expected cleanup to be idempotent, got %v", err)
+	}
+	if err := p.StartCollection(ctx, nil, recorder); err == nil {
+		t.Errorf("expected a stopped plugin not to be started again")
+	}
+}
+
+func TestPluginFailures(t *testing.T) {
+	ctx := context.Background()
+
+	crashing := loadFakePlugin(t, "crash")
+	if err := crashing.StartCollection(ctx, nil, monitor.NewRecorder()); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := crashing.CollectData(ctx, "", time.Now(), time.Now()); err == nil || !strings.Contains(err.Error(), "exited during CollectData") {
+		t.Errorf("expected the crash to be reported, got %v", err)
+	}
+	if _, err := crashing.EvaluateTestsFromConstructedIntervals(ctx, nil); err == nil || !strings.Contains(err.Error(), "exited earlier") {
+		t.Errorf("expected calls after the crash to fail, got %v", err)
+	}
+	if err := crashing.Cleanup(ctx); err != nil {
+		t.Errorf("expected cleanup of a crashed plugin to succeed, got %v", err)
+	}
+
+	hanging := loadFakePlugin(t, "hang")
+	hanging.timeouts = map[string]time.Duration{MethodStartCollection: time.Minute, MethodCollectData: 100 * time.Millisecond}
+	if err := hanging.StartCollection(ctx, nil, monitor.NewRecorder()); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := hanging.CollectData(ctx, "", time.Now(), time.Now()); err == nil || !strings.Contains(err.Error(), "was killed") {
+		t.Errorf("expected the timeout to be reported, got %v", err)
+	}
+	select {
+	case <-hanging.exited:
+	case <-time.After(time.Minute):
+		t.Fatalf("expected the hanging plugin to be killed")
+	}
+	if err := hanging.Cleanup(ctx); err != nil {
+		t.Errorf("expected cleanup of a killed plugin to succeed, got %v", err)
+	}
+
+	deaf := loadFakePlugin(t, "deaf")
+	deaf.timeouts = map[string]time.Duration{MethodStartCollection: time.Minute, MethodConstructComputedIntervals: 100 * time.Millisecond}
+	if err := deaf.StartCollection(ctx, nil, monitor.NewRecorder()); err != nil {
+		t.Fatal(err)
+	}
+	// more than fits in the pipe to the plugin
+	manyIntervals := monitorapi.Intervals{}
+	for i := 0; i < 2000; i++ {
+		manyIntervals = append(manyIntervals, monitorapi.NewInterval(monitorapi.SourceE2ETest, monitorapi.Info).
+			Locator(monitorapi.NewLocator().E2ETestSuite("suite")).
+			Message(monitorapi.NewMessage().HumanMessagef("interval %d", i)).
+			BuildNow())
+	}
+	if _, err := deaf.ConstructComputedIntervals(ctx, manyIntervals, nil, time.Now(), time.Now()); err == nil || !strings.Contains(err.Error(), "was killed") {
+		t.Errorf("expected the timeout sending the request to be reported, got %v", err)
+	}
+	select {
+	case <-deaf.exited:
+	case <-time.After(time.Minute):
+		t.Fatalf("expected the plugin that does not read to be killed")
+	}
+}
+
+func TestLoadInvalidSpec(t *testing.T) {
+	for _, spec := range []string{"./does-not-exist", "tests", "tests:relative/path"} {
+		if _, err := Load(context.Background(), spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
+
+func loadFakePlugin(t *testing.T, mode string) *Plugin {
+	t.Setenv(fakePluginEnv, mode)
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := Load(context.Background(), executable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { p.Cleanup(context.Background()) })
+	return p
+}
+
+// fakePlugin implements the plugin protocol in the given mode.
+func fakePlugin(mode, command string) {
+	if command == "info" {
+		json.NewEncoder(os.Stdout).Encode(Info{APIVersion: APIVersion, Name: "fake", JiraComponent: "Test Framework"})
+		return
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		request := Request{}
+		if err := json.Unmarshal(line, &request); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid request: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "handling %s\n", request.Method)
+
+		response := Response{ID: request.ID, Result: json.RawMessage("{}")}
+		switch request.Method {
+		case MethodStartCollection:
+			encoder.Encode(Notification{Method: NotificationRecord, Params: mustMarshal(RecordParams{Intervals: fakeIntervals("recorded")})})
+		case MethodCollectData:
+			switch mode {
+			case "crash":
+				os.Exit(3)
+			case "hang":
+				time.Sleep(time.Hour)
+			}
+			response.Result = mustMarshal(CollectDataResult{
+				Intervals: fakeIntervals("collected"),
+				TestCases: []*TestCase{{Name: "fake test", FailureOutput: "broken"}},
+			})
+		case MethodConstructComputedIntervals:
+			params := ConstructComputedIntervalsParams{}
+			json.Unmarshal(request.Params, &params)
+			response.Result = mustMarshal(ConstructComputedIntervalsResult{Intervals: params.Intervals})
+		case MethodEvaluateTestsFromConstructedIntervals:
+			response.Result = nil
+			response.Error = &Error{Message: "not on this platform", Reason: ErrorReasonNotSupported}
+		case MethodWriteContentToStorage:
+			response.Result = nil
+			response.Error = &Error{Message: "unstable", Reason: ErrorReasonFlake}
+		}
+		encoder.Encode(response)
+		if mode == "deaf" {
+			// stop reading requests
+			time.Sleep(time.Hour)
+		}
+	}
+}
+
+func fakeIntervals(message string) Intervals {
+	now := time.Now()
+	intervals := monitorapi.Intervals{
+		monitorapi.NewInterval(monitorapi.SourceE2ETest, monitorapi.Info).
+			Locator(monitorapi.NewLocator().E2ETestSuite("suite")).
+			Message(monitorapi.NewMessage().HumanMessage(message)).
+			Build(now, now),
+	}
+	data, err := monitorserialization.IntervalsToJSON(intervals)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func mustMarshal(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
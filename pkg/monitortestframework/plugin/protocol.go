@@ -0,0 +1,154 @@
+package plugin
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// The protocol between openshift-tests and a monitor test plugin.
+//
+// "<plugin> info" prints an Info to stdout and exits.
+//
+// "<plugin> serve" reads one Request per line from stdin and writes one Response per line to stdout, in any
+// order, for as long as stdin is open.  While collecting, the plugin may also write Notifications to stdout
+// to record intervals as they happen.  Anything written to stderr is logged.  The plugin inherits the
+// environment of openshift-tests, including KUBECONFIG.
+
+const (
+	// APIVersion is the version of the protocol described here.
+	APIVersion = "v1"
+
+	MethodStartCollection                       = "StartCollection"
+	MethodCollectData                           = "CollectData"
+	MethodConstructComputedIntervals            = "ConstructComputedIntervals"
+	MethodEvaluateTestsFromConstructedIntervals = "EvaluateTestsFromConstructedIntervals"
+	MethodWriteContentToStorage                 = "WriteContentToStorage"
+	MethodCleanup                               = "Cleanup"
+
+	// NotificationRecord carries RecordParams.
+	NotificationRecord = "Record"
+
+	// ErrorReasonNotSupported reports that the monitor test does not apply to the cluster, which is not a failure.
+	ErrorReasonNotSupported = "NotSupported"
+	// ErrorReasonFlake reports a failure that is only recorded as a flake.
+	ErrorReasonFlake = "Flake"
+)
+
+// Info describes a plugin.
+type Info struct {
+	APIVersion string `json:"apiVersion"`
+	// Name is the name the monitor test is registered with.
+	Name string `json:"name"`
+	// JiraComponent is forced into every junit the monitor test reports.
+	JiraComponent string `json:"jiraComponent"`
+}
+
+type Request struct {
+	ID     int             `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type Response struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// Notification is written by the plugin without being asked for, it has no ID.
+type Notification struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type Error struct {
+	Message string `json:"message"`
+	// Reason is ErrorReasonNotSupported, ErrorReasonFlake, or empty for a failure.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Intervals are serialized the same way as the intervals written to the artifacts of a run, as a list with
+// an items field.
+type Intervals = json.RawMessage
+
+type StartCollectionParams struct{}
+
+type CollectDataParams struct {
+	StorageDir string    `json:"storageDir"`
+	Beginning  time.Time `json:"beginning"`
+	End        time.Time `json:"end"`
+}
+
+type CollectDataResult struct {
+	Intervals Intervals   `json:"intervals,omitempty"`
+	TestCases []*TestCase `json:"testCases,omitempty"`
+}
+
+// ConstructComputedIntervalsParams does not include the recorded resources, plugins that need them have to
+// read the cluster themselves.
+type ConstructComputedIntervalsParams struct {
+	Intervals Intervals `json:"intervals"`
+	Beginning time.Time `json:"beginning"`
+	End       time.Time `json:"end"`
+}
+
+type ConstructComputedIntervalsResult struct {
+	Intervals Intervals `json:"intervals,omitempty"`
+}
+
+type EvaluateTestsFromConstructedIntervalsParams struct {
+	Intervals Intervals `json:"intervals"`
+}
+
+type EvaluateTestsFromConstructedIntervalsResult struct {
+	TestCases []*TestCase `json:"testCases,omitempty"`
+}
+
+type WriteContentToStorageParams struct {
+	StorageDir string    `json:"storageDir"`
+	TimeSuffix string    `json:"timeSuffix"`
+	Intervals  Intervals `json:"intervals"`
+}
+
+type CleanupParams struct{}
+
+type RecordParams struct {
+	Intervals Intervals `json:"intervals"`
+}
+
+// TestCase is a junit test case.  A test case with neither a skip nor a failure message passed.
+type TestCase struct {
+	Name           string  `json:"name"`
+	Duration       float64 `json:"duration,omitempty"`
+	SkipMessage    string  `json:"skipMessage,omitempty"`
+	FailureMessage string  `json:"failureMessage,omitempty"`
+	FailureOutput  string  `json:"failureOutput,omitempty"`
+	SystemOut      string  `json:"systemOut,omitempty"`
+}
+
+func (t *TestCase) toJUnit() *junitapi.JUnitTestCase {
+	junit := &junitapi.JUnitTestCase{
+		Name:      t.Name,
+		Duration:  t.Duration,
+		SystemOut: t.SystemOut,
+	}
+	if len(t.SkipMessage) > 0 {
+		junit.SkipMessage = &junitapi.SkipMessage{Message: t.SkipMessage}
+	}
+	if len(t.FailureMessage) > 0 || len(t.FailureOutput) > 0 {
+		junit.FailureOutput = &junitapi.FailureOutput{Message: t.FailureMessage, Output: t.FailureOutput}
+	}
+	return junit
+}
+
+func toJUnits(testCases []*TestCase) []*junitapi.JUnitTestCase {
+	var junits []*junitapi.JUnitTestCase
+	for _, testCase := range testCases {
+		if testCase != nil {
+			junits = append(junits, testCase.toJUnit())
+		}
+	}
+	return junits
+}
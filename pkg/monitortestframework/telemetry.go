@@ -0,0 +1,267 @@
+package monitortestframework
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/origin/pkg/dataloader"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// Phase is a step of the MonitorTest lifecycle.
+type Phase string
+
+const (
+	PhaseStartCollection                       Phase = "StartCollection"
+	PhaseCollectData                           Phase = "CollectData"
+	PhaseConstructComputedIntervals            Phase = "ConstructComputedIntervals"
+	PhaseEvaluateTestsFromConstructedIntervals Phase = "EvaluateTestsFromConstructedIntervals"
+	PhaseWriteContentToStorage                 Phase = "WriteContentToStorage"
+	PhaseCleanup                               Phase = "Cleanup"
+	PhasePrepareForOfflineEvaluation           Phase = "PrepareForOfflineEvaluation"
+)
+
+// phases are the phases a timeout can be given for.
+var phases = []Phase{
+	PhaseStartCollection,
+	PhaseCollectData,
+	PhaseConstructComputedIntervals,
+	PhaseEvaluateTestsFromConstructedIntervals,
+	PhaseWriteContentToStorage,
+	PhaseCleanup,
+	PhasePrepareForOfflineEvaluation,
+}
+
+// ParsePhaseTimeouts parses phase=duration pairs, as given on the command line, for instance CollectData=90m.
+func ParsePhaseTimeouts(values map[string]string) (map[Phase]time.Duration, error) {
+	timeouts := map[Phase]time.Duration{}
+	for phase, value := range values {
+		if !slices.Contains(phases, Phase(phase)) {
+			return nil, fmt.Errorf("unknown monitor test phase %q", phase)
+		}
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout for monitor test phase %s: %w", phase, err)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("timeout for monitor test phase %s must be positive", phase)
+		}
+		timeouts[Phase(phase)] = timeout
+	}
+	return timeouts, nil
+}
+
+// PhaseTiming is what one monitor test cost during one phase.
+type PhaseTiming struct {
+	MonitorTest   string    `json:"monitorTest"`
+	JiraComponent string    `json:"jiraComponent"`
+	Phase         Phase     `json:"phase"`
+	Start         time.Time `json:"start"`
+	Duration      float64   `json:"durationSeconds"`
+
+	// Intervals and JUnits are how many the monitor test returned.
+	Intervals int `json:"intervals"`
+	JUnits    int `json:"junits"`
+
+	// HeapBytesBefore and HeapBytesAfter are the heap of the whole process when the phase started and ended, not
+	// of the monitor test.  Other monitor tests run concurrently during StartCollection and CollectData, the
+	// collectors keep running in the background and the garbage collector runs whenever it wants, so their
+	// difference is only a rough hint of what the monitor test allocated.
+	HeapBytesBefore uint64 `json:"heapBytesBefore"`
+	HeapBytesAfter  uint64 `json:"heapBytesAfter"`
+	// GoroutinesBefore and GoroutinesAfter are counted for the whole process as well, so they are only a rough
+	// hint of the goroutines the monitor test started or leaked.
+	GoroutinesBefore int `json:"goroutinesBefore"`
+	GoroutinesAfter  int `json:"goroutinesAfter"`
+
+	TimedOut bool   `json:"timedOut,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type phaseResult struct {
+	intervals monitorapi.Intervals
+	junits    []*junitapi.JUnitTestCase
+}
+
+// runPhase runs fn, which must already be protected from panics, and records what it cost.  fn runs in its own
+// goroutine so that a monitor test that exceeds the timeout of the phase, if one was set, is abandoned instead of
+// hanging the run.  A monitor test that timed out is not called again, except to clean up, and fails every phase
+// it is not called for.
+func (r *monitorTestRegistry) runPhase(monitorTest *monitorTesttItem, phase Phase, fn func() (phaseResult, error)) (phaseResult, time.Duration, error) {
+	if timedOutPhase := monitorTest.getTimedOutPhase(); len(timedOutPhase) > 0 && phase != PhaseCleanup {
+		return phaseResult{}, 0, fmt.Errorf("not run because %s timed out during %s", monitorTest.name, timedOutPhase)
+	}
+
+	timing := PhaseTiming{
+		MonitorTest:   monitorTest.name,
+		JiraComponent: monitorTest.jiraComponent,
+		Phase:         phase,
+	}
+	timing.HeapBytesBefore, timing.GoroutinesBefore = processResources()
+	timing.Start = time.Now()
+
+	type outcome struct {
+		result phaseResult
+		err    error
+	}
+	outcomeCh := make(chan outcome, 1)
+	go func() {
+		result, err := fn()
+		outcomeCh <- outcome{result: result, err: err}
+	}()
+
+	var result phaseResult
+	var err error
+	timeout, ok := r.phaseTimeouts[phase]
+	if !ok {
+		// phases without a timeout wait for the monitor test however long it takes
+		curr := <-outcomeCh
+		result, err = curr.result, curr.err
+	} else {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case curr := <-outcomeCh:
+			result, err = curr.result, curr.err
+		case <-timer.C:
+			err = &PhaseTimeoutError{Phase: phase, Timeout: timeout}
+			timing.TimedOut = true
+			monitorTest.setTimedOutPhase(phase)
+			logrus.WithField("monitorTest", monitorTest.name).Errorf("abandoning %s after %v", phase, timeout)
+		}
+	}
+
+	duration := time.Since(timing.Start)
+	timing.Duration = duration.Seconds()
+	timing.Intervals = len(result.intervals)
+	timing.JUnits = len(result.junits)
+	timing.HeapBytesAfter, timing.GoroutinesAfter = processResources()
+	if err != nil {
+		timing.Error = err.Error()
+	}
+
+	r.timingsLock.Lock()
+	r.timings = append(r.timings, timing)
+	r.timingsLock.Unlock()
+
+	return result, duration, err
+}
+
+func processResources() (uint64, int) {
+	memStats := runtime.MemStats{}
+	runtime.ReadMemStats(&memStats)
+	return memStats.HeapAlloc, runtime.NumGoroutine()
+}
+
+// failureOutput describes a failed phase, a timeout is called out so that it is not mistaken for the monitor
+// test finding a problem.
+func failureOutput(action string, err error) *junitapi.FailureOutput {
+	var timeoutErr *PhaseTimeoutError
+	if errors.As(err, &timeoutErr) {
+		return &junitapi.FailureOutput{
+			Message: timeoutErr.Error(),
+			Output:  fmt.Sprintf("timed out during %s\n%v", action, err),
+		}
+	}
+	return &junitapi.FailureOutput{
+		Output: fmt.Sprintf("failed during %s\n%v", action, err),
+	}
+}
+
+func (r *monitorTestRegistry) PhaseTimings() []PhaseTiming {
+	r.timingsLock.Lock()
+	defer r.timingsLock.Unlock()
+
+	timings := make([]PhaseTiming, len(r.timings))
+	copy(timings, r.timings)
+	sort.SliceStable(timings, func(i, j int) bool {
+		return timings[i].Start.Before(timings[j].Start)
+	})
+	return timings
+}
+
+// writePhaseTimings writes the timings recorded so far as json for people and as an autodl file for bigquery.  The
+// location is remembered, so that the timings can be written again once more phases ran.
+func (r *monitorTestRegistry) writePhaseTimings(storageDir, timeSuffix string) error {
+	r.timingsLock.Lock()
+	r.timingsStorageDir, r.timingsTimeSuffix = storageDir, timeSuffix
+	r.timingsLock.Unlock()
+	timings := r.PhaseTimings()
+
+	data, err := json.MarshalIndent(timings, "", "    ")
+	if err != nil {
+		return err
+	}
+	timingsFile := filepath.Join(storageDir, fmt.Sprintf("monitor-test-timings%s.json", timeSuffix))
+	if err := os.WriteFile(timingsFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %v: %w", timingsFile, err)
+	}
+
+	// the heap and goroutines are measured for the whole process, see PhaseTiming
+	rows := make([]map[string]string, 0, len(timings))
+	for _, timing := range timings {
+		rows = append(rows, map[string]string{
+			"MonitorTest":      timing.MonitorTest,
+			"JiraComponent":    timing.JiraComponent,
+			"Phase":            string(timing.Phase),
+			"StartTime":        timing.Start.UTC().Format(time.RFC3339),
+			"DurationSeconds":  strconv.FormatFloat(timing.Duration, 'f', -1, 64),
+			"Intervals":        strconv.Itoa(timing.Intervals),
+			"JUnits":           strconv.Itoa(timing.JUnits),
+			"HeapBytesDelta":   strconv.FormatInt(int64(timing.HeapBytesAfter)-int64(timing.HeapBytesBefore), 10),
+			"GoroutinesBefore": strconv.Itoa(timing.GoroutinesBefore),
+			"GoroutinesAfter":  strconv.Itoa(timing.GoroutinesAfter),
+			"TimedOut":         strconv.FormatBool(timing.TimedOut),
+			"Error":            truncate(timing.Error, 1000),
+		})
+	}
+	dataFile := dataloader.DataFile{
+		TableName: "monitor_test_timings",
+		Schema: map[string]dataloader.DataType{
+			"MonitorTest":      dataloader.DataTypeString,
+			"JiraComponent":    dataloader.DataTypeString,
+			"Phase":            dataloader.DataTypeString,
+			"StartTime":        dataloader.DataTypeTimestamp,
+			"DurationSeconds":  dataloader.DataTypeFloat64,
+			"Intervals":        dataloader.DataTypeInteger,
+			"JUnits":           dataloader.DataTypeInteger,
+			"HeapBytesDelta":   dataloader.DataTypeInteger,
+			"GoroutinesBefore": dataloader.DataTypeInteger,
+			"GoroutinesAfter":  dataloader.DataTypeInteger,
+			"TimedOut":         dataloader.DataTypeBool,
+			"Error":            dataloader.DataTypeString,
+		},
+		Rows: rows,
+	}
+	return dataloader.WriteDataFile(filepath.Join(storageDir, fmt.Sprintf("monitor-test-timings%s-%s", timeSuffix, dataloader.AutoDataLoaderSuffix)), dataFile)
+}
+
+// rewritePhaseTimings writes the timings again where they were last written, if they were.
+func (r *monitorTestRegistry) rewritePhaseTimings() error {
+	r.timingsLock.Lock()
+	storageDir, timeSuffix := r.timingsStorageDir, r.timingsTimeSuffix
+	r.timingsLock.Unlock()
+	if len(storageDir) == 0 {
+		return nil
+	}
+	return r.writePhaseTimings(storageDir, timeSuffix)
+}
+
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	return strings.TrimSpace(s[:length]) + "..."
+}
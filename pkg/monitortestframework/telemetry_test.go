@@ -0,0 +1,177 @@
+package monitortestframework
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+type fakeMonitorTest struct {
+	hang      chan struct{}
+	intervals monitorapi.Intervals
+	cleanedUp bool
+}
+
+func (f *fakeMonitorTest) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	return nil
+}
+
+func (f *fakeMonitorTest) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	if f.hang != nil {
+		<-f.hang
+	}
+	return f.intervals, nil, nil
+}
+
+func (f *fakeMonitorTest) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, nil
+}
+
+func (f *fakeMonitorTest) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	return nil, nil
+}
+
+func (f *fakeMonitorTest) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return nil
+}
+
+func (f *fakeMonitorTest) Cleanup(ctx context.Context) error {
+	f.cleanedUp = true
+	return nil
+}
+
+func TestPhasesWithoutTimeoutWait(t *testing.T) {
+	ctx := context.Background()
+	hang := make(chan struct{})
+	slow := &fakeMonitorTest{hang: hang, intervals: monitorapi.Intervals{{}}}
+	registry := NewMonitorTestRegistry()
+	registry.AddMonitorTestOrDie("slow", "Test Framework", slow)
+	// only the phases given a timeout have one
+	registry.SetPhaseTimeouts(map[Phase]time.Duration{PhaseCleanup: time.Minute})
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		close(hang)
+	}()
+	intervals, _, err := registry.CollectData(ctx, "", time.Now(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intervals) != 1 {
+		t.Errorf("expected the intervals of the slow monitor test, got %d", len(intervals))
+	}
+	for _, timing := range registry.PhaseTimings() {
+		if timing.TimedOut {
+			t.Errorf("expected no timeout, got %#v", timing)
+		}
+	}
+}
+
+func TestPhaseTimeoutsAndTimings(t *testing.T) {
+	ctx := context.Background()
+	hang := make(chan struct{})
+	defer close(hang)
+	hanging := &fakeMonitorTest{hang: hang}
+	healthy := &fakeMonitorTest{intervals: monitorapi.Intervals{{}, {}}}
+
+	registry := NewMonitorTestRegistry()
+	registry.AddMonitorTestOrDie("hanging", "Test Framework", hanging)
+	registry.AddMonitorTestOrDie("healthy", "Test Framework", healthy)
+	registry.SetPhaseTimeouts(map[Phase]time.Duration{PhaseCollectData: 100 * time.Millisecond})
+
+	if _, err := registry.StartCollection(ctx, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	intervals, junits, err := registry.CollectData(ctx, "", time.Now(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intervals) != 2 {
+		t.Errorf("expected the intervals of the healthy monitor test, got %d", len(intervals))
+	}
+	timedOut := findJUnit(junits, `[Jira:"Test Framework"] monitor test hanging collection`)
+	if timedOut == nil || timedOut.FailureOutput == nil || !strings.Contains(timedOut.FailureOutput.Message, "did not finish CollectData") {
+		t.Errorf("expected the hanging monitor test to fail collection with a timeout, got %#v", timedOut)
+	}
+
+	// later phases fail the abandoned monitor test, but still clean it up
+	junits, err = registry.EvaluateTestsFromConstructedIntervals(ctx, intervals)
+	if err == nil {
+		t.Errorf("expected evaluation to fail for the hanging monitor test")
+	}
+	notRun := findJUnit(junits, `[Jira:"Test Framework"] monitor test hanging test evaluation`)
+	if notRun == nil || notRun.FailureOutput == nil || notRun.SkipMessage != nil {
+		t.Errorf("expected evaluation of the hanging monitor test to fail, got %#v", notRun)
+	}
+	if healthyEvaluation := findJUnit(junits, `[Jira:"Test Framework"] monitor test healthy test evaluation`); healthyEvaluation == nil || healthyEvaluation.FailureOutput != nil {
+		t.Errorf("expected evaluation of the healthy monitor test to pass, got %#v", healthyEvaluation)
+	}
+
+	storageDir := t.TempDir()
+	if _, err := registry.WriteContentToStorage(ctx, storageDir, "_suffix", intervals, nil); err == nil {
+		t.Errorf("expected writing to storage to fail for the hanging monitor test")
+	}
+	if _, err := registry.Cleanup(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !hanging.cleanedUp || !healthy.cleanedUp {
+		t.Errorf("expected both monitor tests to be cleaned up")
+	}
+	for _, name := range []string{"monitor-test-timings_suffix.json", "monitor-test-timings_suffix-autodl.json"} {
+		if _, err := os.Stat(filepath.Join(storageDir, name)); err != nil {
+			t.Errorf("expected %s to be written: %v", name, err)
+		}
+	}
+	var writtenTimings []PhaseTiming
+	data, err := os.ReadFile(filepath.Join(storageDir, "monitor-test-timings_suffix.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &writtenTimings); err != nil {
+		t.Fatal(err)
+	}
+	cleanupWritten := false
+	for _, timing := range writtenTimings {
+		if timing.Phase == PhaseCleanup {
+			cleanupWritten = true
+		}
+	}
+	if !cleanupWritten {
+		t.Errorf("expected the timings written to storage to include cleanup")
+	}
+
+	timings := map[string]PhaseTiming{}
+	for _, timing := range registry.PhaseTimings() {
+		timings[timing.MonitorTest+"/"+string(timing.Phase)] = timing
+	}
+	if timing := timings["hanging/CollectData"]; !timing.TimedOut {
+		t.Errorf("expected the timeout to be recorded, got %#v", timing)
+	}
+	if timing := timings["healthy/CollectData"]; timing.Intervals != 2 || timing.TimedOut {
+		t.Errorf("expected the intervals of the healthy monitor test to be counted, got %#v", timing)
+	}
+	if _, ok := timings["hanging/EvaluateTestsFromConstructedIntervals"]; ok {
+		t.Errorf("expected no timing for a phase that was not run")
+	}
+	if _, ok := timings["hanging/Cleanup"]; !ok {
+		t.Errorf("expected a timing for the cleanup of the hanging monitor test")
+	}
+}
+
+func findJUnit(junits []*junitapi.JUnitTestCase, name string) *junitapi.JUnitTestCase {
+	for _, junit := range junits {
+		if junit.Name == name {
+			return junit
+		}
+	}
+	return nil
+}
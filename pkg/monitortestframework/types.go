@@ -9,6 +9,7 @@ import (
 	"k8s.io/client-go/rest"
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionpolicy"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 )
 
@@ -34,6 +35,22 @@ type MonitorTestInitializationInfo struct {
 
 	// DisableMonitorTests will remove any monitor tests contained in the provided list
 	DisableMonitorTests []string
+
+	// MonitorPlugins are added as monitor tests, see the plugin package for the format.
+	MonitorPlugins []string
+
+	// PhaseTimeouts are how long a single monitor test may take for a phase.  Phases without one have no timeout.
+	PhaseTimeouts map[Phase]time.Duration
+
+	// ChaosSchedule is a file of faults injected during Disruptive runs, see the chaos package.
+	ChaosSchedule string
+
+	// DisruptionBackends is a file of backends sampled from inside the cluster, see the inclusterdisruption package.
+	DisruptionBackends string
+
+	// DisruptionPolicy holds the disruption budgets the availability tests apply before historical data, nil when
+	// there is none.
+	DisruptionPolicy *disruptionpolicy.Policy
 }
 
 type MonitorTest interface {
@@ -48,7 +65,8 @@ type MonitorTest interface {
 	CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error)
 
 	// ConstructComputedIntervals is called after all InvariantTests have produced raw Intervals.
-	// Order of ConstructComputedIntervals across different InvariantTests is not guaranteed.
+	// Order of ConstructComputedIntervals across different InvariantTests is not guaranteed, except for those
+	// implementing ComputedIntervalsDependent.
 	// Return *only* the constructed intervals.
 	// Errors reported will be indicated as junit test failure and will cause job runs to fail.
 	ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (constructedIntervals monitorapi.Intervals, err error)
@@ -74,6 +92,29 @@ type MonitorTest interface {
 	Cleanup(ctx context.Context) error
 }
 
+// OfflineEvaluationInfo describes a saved run that is being replayed without a cluster.
+type OfflineEvaluationInfo struct {
+	// ArtifactDir is the directory holding the artifacts written by the original run, for instance cluster-data_*.json.
+	ArtifactDir string
+}
+
+// OfflineMonitorTest is optionally implemented by MonitorTests that use the cluster after StartCollection, usually
+// during EvaluateTestsFromConstructedIntervals.  When saved data is replayed, StartCollection and CollectData
+// are never called.  PrepareForOfflineEvaluation is called instead and the MonitorTest must not contact the
+// cluster in any later phase.  MonitorTests that do not implement this are run as-is during a replay.
+type OfflineMonitorTest interface {
+	PrepareForOfflineEvaluation(ctx context.Context, info OfflineEvaluationInfo) error
+}
+
+// ComputedIntervalsDependent is optionally implemented by MonitorTests that construct intervals from the intervals
+// constructed by other MonitorTests.  Their ConstructComputedIntervals is called after that of all the other
+// MonitorTests, with the constructed intervals added to the starting intervals.  They do not see the intervals
+// constructed by each other.
+type ComputedIntervalsDependent interface {
+	// DependsOnComputedIntervals only marks the MonitorTest.
+	DependsOnComputedIntervals()
+}
+
 type MonitorTestRegistry interface {
 	AddRegistryOrDie(registry MonitorTestRegistry)
 
@@ -86,6 +127,15 @@ type MonitorTestRegistry interface {
 	GetRegistryFor(names ...string) (MonitorTestRegistry, error)
 	ListMonitorTests() sets.String
 
+	// SetPhaseTimeouts sets how long a single monitor test may take for a phase, phases without one have no timeout.
+	// A monitor test that exceeds the timeout of a phase is reported as failing that phase and is abandoned, it is
+	// only called again to clean up.  It fails the later phases as well.
+	SetPhaseTimeouts(timeouts map[Phase]time.Duration)
+
+	// PhaseTimings returns how long each monitor test took in each phase so far, and what it cost.  They are also
+	// written by WriteContentToStorage, and written again by a later Cleanup.
+	PhaseTimings() []PhaseTiming
+
 	// StartCollection is responsible for setting up all resources required for collection of data on the cluster.
 	// An error will not stop execution, but will cause a junit failure that will cause the job run to fail.
 	// This allows us to know when setups fail.
@@ -96,7 +146,8 @@ type MonitorTestRegistry interface {
 	CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error)
 
 	// ConstructComputedIntervals is called after all InvariantTests have produced raw Intervals.
-	// Order of ConstructComputedIntervals across different InvariantTests is not guaranteed.
+	// Order of ConstructComputedIntervals across different InvariantTests is not guaranteed, except for those
+	// implementing ComputedIntervalsDependent.
 	// Return *only* the constructed intervals.
 	// Errors reported will be indicated as junit test failure and will cause job runs to fail.
 	ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error)
@@ -120,5 +171,9 @@ type MonitorTestRegistry interface {
 	// Errors reported will cause job runs to fail to ensure cleanup functions work reliably.
 	Cleanup(ctx context.Context) ([]*junitapi.JUnitTestCase, error)
 
+	// PrepareForOfflineEvaluation is called instead of StartCollection and CollectData when saved data is replayed.
+	// Only MonitorTests implementing OfflineMonitorTest are called.
+	PrepareForOfflineEvaluation(ctx context.Context, info OfflineEvaluationInfo) ([]*junitapi.JUnitTestCase, error)
+
 	getMonitorTests() map[string]*monitorTesttItem
 }
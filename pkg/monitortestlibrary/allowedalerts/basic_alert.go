@@ -191,6 +191,16 @@ func (a *basicAlertTest) AlertState() AlertState {
 	return a.alertState
 }
 
+// historicalDataKey is the key the historical data of the alert is looked up by.
+func (a *basicAlertTest) historicalDataKey() historicaldata.AlertDataKey {
+	return historicaldata.AlertDataKey{
+		AlertName:      a.alertName,
+		AlertLevel:     string(a.alertState),
+		AlertNamespace: a.namespace,
+		JobType:        *a.jobType,
+	}
+}
+
 type testState int
 
 const (
@@ -227,12 +237,7 @@ func (a *basicAlertTest) failOrFlake(firingIntervals, pendingIntervals monitorap
 	firingDuration := firingIntervals.Duration(1 * time.Second)
 	pendingDuration := pendingIntervals.Duration(1 * time.Second)
 
-	dataKey := historicaldata.AlertDataKey{
-		AlertName:      a.alertName,
-		AlertLevel:     string(a.alertState),
-		AlertNamespace: a.namespace,
-		JobType:        *a.jobType,
-	}
+	dataKey := a.historicalDataKey()
 
 	failAfter, err := a.allowanceCalculator.FailAfter(dataKey)
 	if err != nil {
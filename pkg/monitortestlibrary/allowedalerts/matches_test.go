@@ -1,6 +1,7 @@
 package allowedalerts
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetClosestP99Value(t *testing.T) {
@@ -150,6 +152,31 @@ func TestGetClosestP99Value(t *testing.T) {
 	}
 }
 
+// TestComputedAlertDataMatches ensures alert data computed from job runs, whose alerts files capitalize the level, is
+// found by the key the alert tests look it up by.
+func TestComputedAlertDataMatches(t *testing.T) {
+	jobType := platformidentification.JobType{Release: "4.18", FromRelease: "4.18", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	jobRuns := []historicaldata.JobRunData{}
+	for i := 0; i < 100; i++ {
+		jobRuns = append(jobRuns, historicaldata.JobRunData{
+			JobType: jobType,
+			Alerts: map[historicaldata.AlertDataKey]time.Duration{
+				{AlertName: "etcdGRPCRequestsSlow", AlertLevel: "Warning", JobType: jobType}: 10 * time.Second,
+			},
+		})
+	}
+	data, err := json.Marshal(historicaldata.ComputeAlertData(jobRuns))
+	require.NoError(t, err)
+	matcher, err := historicaldata.NewAlertMatcher(data)
+	require.NoError(t, err)
+
+	alertTest := newAlertTest("etcd", "etcdGRPCRequestsSlow", &jobType).warning().toTests()[0].(*basicAlertTest)
+	p99, _, err := matcher.BestMatchP99(alertTest.historicalDataKey())
+	require.NoError(t, err)
+	require.NotNil(t, p99, "no historical data found for the alert")
+	assert.Equal(t, 10*time.Second, *p99)
+}
+
 // TestAlertDataFileParsing uses the actual query_results.json data file we populate weekly
 // from bigquery and commit into origin. Test ensures we can parse it and the data looks sane.
 func TestAlertDataFileParsing(t *testing.T) {
@@ -0,0 +1,139 @@
+package chaos
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	faultLabel    = "chaos.openshift.io/fault"
+	containerName = "inject"
+)
+
+// shellQuote quotes s for bash so that no part of it is interpreted.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// undoAfter schedules command to run on the node once the fault has lasted long enough.  It is a transient systemd
+// timer and not the pod that sleeps, so that the fault is undone even if the pod or the run goes away.
+func undoAfter(fault Fault, command string) string {
+	return fmt.Sprintf("systemd-run --on-active=%d /bin/sh -c %s", int64(fault.Duration.Seconds()), shellQuote(command))
+}
+
+// script is run in the root of the node.
+func (f *Fault) script(nodeName string) string {
+	lines := []string{"set -euo pipefail"}
+	switch f.Type {
+	case FaultKillStaticPod:
+		podName := fmt.Sprintf("%s-%s", f.Pod, nodeName)
+		lines = append(lines,
+			fmt.Sprintf("pods=$(crictl pods --namespace %s --name %s --state ready -q)", shellQuote(f.Namespace), shellQuote("^"+podName+"$")),
+			fmt.Sprintf(`if [[ -z "${pods}" ]]; then echo "no ready pod %s" >&2; exit 1; fi`, podName),
+			`echo "stopping pod sandbox ${pods}"`,
+			"crictl stopp ${pods}",
+		)
+	case FaultRebootNode:
+		// delayed so that the pod is reported successful
+		reboot := "systemctl reboot"
+		if f.Force {
+			reboot = "systemctl reboot --force --force"
+		}
+		lines = append(lines,
+			"echo 'reboot in 10 seconds'",
+			fmt.Sprintf("systemd-run --on-active=10 /bin/sh -c %s", shellQuote(reboot)),
+		)
+	case FaultDropTraffic:
+		rule := fmt.Sprintf("INPUT -p %s --dport %d -j DROP", f.Protocol, f.Port)
+		lines = append(lines,
+			undoAfter(*f, "iptables -D "+rule),
+			"iptables -I "+rule,
+			fmt.Sprintf("echo 'dropping %s traffic to port %d'", f.Protocol, f.Port),
+		)
+	case FaultPauseEtcdMember:
+		lines = append(lines,
+			"id=$(crictl ps --name '^etcd$' --state running -q | head -n 1)",
+			`if [[ -z "${id}" ]]; then echo "no running etcd container" >&2; exit 1; fi`,
+			`pid=$(crictl inspect --output go-template --template '{{.info.pid}}' "${id}")`,
+			fmt.Sprintf("systemd-run --on-active=%d /bin/kill -CONT \"${pid}\"", int64(f.Duration.Seconds())),
+			`kill -STOP "${pid}"`,
+			`echo "paused etcd process ${pid}"`,
+		)
+	case FaultFillDisk:
+		file := path.Join(f.Path, "chaos-"+f.Name)
+		lines = append(lines,
+			undoAfter(*f, "rm -f "+shellQuote(file)),
+			fmt.Sprintf("fallocate -l %d %s", f.Size.Value(), shellQuote(file)),
+			"echo "+shellQuote(fmt.Sprintf("allocated %s at %s", f.Size.String(), file)),
+		)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// injectorPod runs the script of the fault in the root of the node, with the network and processes of the host.
+func injectorPod(fault Fault, nodeName, image string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("chaos-%s-", fault.Name),
+			Labels:       map[string]string{faultLabel: fault.Name},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      nodeName,
+			HostPID:       true,
+			HostNetwork:   true,
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    containerName,
+					Image:   image,
+					Command: []string{"chroot", "/host", "/bin/bash", "-c", fault.script(nodeName)},
+					SecurityContext: &corev1.SecurityContext{
+						RunAsUser:  ptr.To[int64](0),
+						Privileged: ptr.To(true),
+					},
+					ImagePullPolicy:          corev1.PullIfNotPresent,
+					TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "host", MountPath: "/host"},
+					},
+				},
+			},
+			// faults are injected on nodes that are tainted, or becoming so
+			Tolerations: []corev1.Toleration{
+				{Operator: corev1.TolerationOpExists},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "host",
+					VolumeSource: corev1.VolumeSource{
+						HostPath: &corev1.HostPathVolumeSource{Path: "/"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func injectorNamespace() *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "e2e-chaos-",
+			Labels: map[string]string{
+				"pod-security.kubernetes.io/enforce": "privileged",
+				"pod-security.kubernetes.io/audit":   "privileged",
+				"pod-security.kubernetes.io/warn":    "privileged",
+				// bypass SCC rather than waiting for a binding to be reflected, like the pod network pollers do.
+				"security.openshift.io/disable-securitycontextconstraints": "true",
+				"security.openshift.io/scc.podSecurityLabelSync":           "false",
+			},
+			Annotations: map[string]string{
+				"workload.openshift.io/allowed": "management",
+			},
+		},
+	}
+}
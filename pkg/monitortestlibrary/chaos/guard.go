@@ -0,0 +1,27 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AllowedLabel must be set to "true" on the ClusterVersion of a cluster before faults are injected in it:
+//
+//	oc label clusterversion/version chaos.openshift.io/allowed=true
+const AllowedLabel = "chaos.openshift.io/allowed"
+
+// EnsureAllowed refuses to inject faults in clusters that are not labelled for chaos, so that a schedule pointed at
+// the wrong kubeconfig does not reboot somebody's cluster.
+func EnsureAllowed(ctx context.Context, configClient configv1client.Interface) error {
+	clusterVersion, err := configClient.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to check whether the cluster allows chaos: %w", err)
+	}
+	if clusterVersion.Labels[AllowedLabel] != "true" {
+		return fmt.Errorf("refusing to inject faults: clusterversion/%s is not labelled %s=true", clusterVersion.Name, AllowedLabel)
+	}
+	return nil
+}
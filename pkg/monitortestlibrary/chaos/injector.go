@@ -0,0 +1,258 @@
+package chaos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// Result is what happened to a fault of the schedule.
+type Result struct {
+	Fault    Fault
+	NodeName string
+	// InjectedAt is when the injector container started, zero if the fault was not injected.
+	InjectedAt time.Time
+	// Output is the log of the injector pod.
+	Output string
+	Err    error
+}
+
+// Injector injects the faults of a schedule in the cluster, each from a privileged pod on the node of the fault, and
+// records every one as an interval of SourceChaos so that the disruption they induce can be told from organic
+// disruption.  The cluster must have been checked with EnsureAllowed.
+type Injector struct {
+	kubeClient kubernetes.Interface
+	image      string
+	schedule   *Schedule
+	recorder   monitorapi.RecorderWriter
+
+	// injectionTimeout is how long the pod injecting a fault may take to complete.
+	injectionTimeout time.Duration
+	pollInterval     time.Duration
+
+	namespaceName string
+
+	lock    sync.Mutex
+	results []Result
+}
+
+// NewInjector returns an injector of the faults of the schedule that runs the image, which must have bash and the
+// node root must have crictl, iptables and systemd-run.
+func NewInjector(kubeClient kubernetes.Interface, image string, schedule *Schedule, recorder monitorapi.RecorderWriter) *Injector {
+	return &Injector{
+		kubeClient: kubeClient,
+		image:      image,
+		schedule:   schedule,
+		recorder:   recorder,
+
+		injectionTimeout: 5 * time.Minute,
+		pollInterval:     5 * time.Second,
+	}
+}
+
+// Setup creates the namespace of the injector pods.
+func (i *Injector) Setup(ctx context.Context) error {
+	actualNamespace, err := i.kubeClient.CoreV1().Namespaces().Create(ctx, injectorNamespace(), metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	i.namespaceName = actualNamespace.Name
+	return nil
+}
+
+// Run injects the faults when they are due, counting from start, until all are injected or ctx is done.  Faults are
+// injected one at a time.
+func (i *Injector) Run(ctx context.Context, start time.Time) {
+	faults := append([]Fault{}, i.schedule.Faults...)
+	sort.SliceStable(faults, func(a, b int) bool {
+		return faults[a].After.Duration < faults[b].After.Duration
+	})
+	for _, fault := range faults {
+		select {
+		case <-time.After(time.Until(start.Add(fault.After.Duration))):
+		case <-ctx.Done():
+			return
+		}
+		result := i.Inject(ctx, fault)
+		if result.Err != nil {
+			klog.Errorf("Failed to inject fault %q: %v", fault.Name, result.Err)
+		}
+
+		i.lock.Lock()
+		i.results = append(i.results, result)
+		i.lock.Unlock()
+	}
+}
+
+// Results are the faults that were due, in the order they were injected.
+func (i *Injector) Results() []Result {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return append([]Result{}, i.results...)
+}
+
+// Inject injects the fault now and records it.
+func (i *Injector) Inject(ctx context.Context, fault Fault) Result {
+	result := Result{Fault: fault}
+	var startedAt time.Time
+	result.NodeName, result.Err = i.pickNode(ctx, fault)
+	if result.Err == nil {
+		startedAt, result.Output, result.Err = i.runInjectorPod(ctx, fault, result.NodeName)
+	}
+	if result.Err != nil {
+		i.recorder.AddIntervals(
+			monitorapi.NewInterval(monitorapi.SourceChaos, monitorapi.Warning).
+				Locator(monitorapi.NewLocator().NodeFromName(result.NodeName)).
+				Message(monitorapi.NewMessage().Reason(monitorapi.ChaosFaultFailed).
+					WithAnnotation(monitorapi.AnnotationChaosFault, string(fault.Type)).
+					HumanMessagef("%v fault %q was not injected: %v", fault.Type, fault.Name, result.Err)).
+				BuildNow(),
+		)
+		return result
+	}
+
+	// the fault is in place, and the disruption it causes may start, before the pod is seen to complete
+	result.InjectedAt = startedAt
+	klog.Infof("CHAOS: injected %v fault %q on node/%s", fault.Type, fault.Name, result.NodeName)
+	i.recorder.AddIntervals(
+		monitorapi.NewInterval(monitorapi.SourceChaos, monitorapi.Info).
+			Locator(monitorapi.NewLocator().NodeFromName(result.NodeName)).
+			Message(monitorapi.NewMessage().Reason(monitorapi.ChaosFaultInjected).
+				WithAnnotation(monitorapi.AnnotationChaosFault, string(fault.Type)).
+				HumanMessagef("%v fault %q injected", fault.Type, fault.Name)).
+			Display().
+			Build(result.InjectedAt, result.InjectedAt.Add(fault.Duration.Duration)),
+	)
+	return result
+}
+
+// pickNode returns the node of the fault, or a random node matching its selector.
+func (i *Injector) pickNode(ctx context.Context, fault Fault) (string, error) {
+	if len(fault.Node) > 0 {
+		return fault.Node, nil
+	}
+	nodes, err := i.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(fault.NodeSelector).String(),
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(nodes.Items) == 0 {
+		return "", fmt.Errorf("no nodes match %v", labels.SelectorFromSet(fault.NodeSelector))
+	}
+	return nodes.Items[rand.Intn(len(nodes.Items))].Name, nil
+}
+
+// runInjectorPod runs the script of the fault on the node, waits for it to complete and returns when the script
+// started.  That is when the injector container started or, if the pod does not tell, when the pod was created.
+func (i *Injector) runInjectorPod(ctx context.Context, fault Fault, nodeName string) (time.Time, string, error) {
+	if len(i.namespaceName) == 0 {
+		return time.Time{}, "", fmt.Errorf("the chaos injector was not set up")
+	}
+	startedAt := time.Now()
+	pod, err := i.kubeClient.CoreV1().Pods(i.namespaceName).Create(ctx, injectorPod(fault, nodeName, i.image), metav1.CreateOptions{})
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	var phase corev1.PodPhase
+	err = wait.PollUntilContextTimeout(ctx, i.pollInterval, i.injectionTimeout, true, func(ctx context.Context) (bool, error) {
+		current, err := i.kubeClient.CoreV1().Pods(i.namespaceName).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		phase = current.Status.Phase
+		if containerStartedAt := injectorStartedAt(current); !containerStartedAt.IsZero() {
+			startedAt = containerStartedAt
+		}
+		return phase == corev1.PodSucceeded || phase == corev1.PodFailed, nil
+	})
+	output := i.podLog(ctx, pod.Name)
+	switch {
+	case err != nil:
+		return time.Time{}, output, fmt.Errorf("pod/%s did not complete: %w", pod.Name, err)
+	case phase == corev1.PodFailed:
+		return time.Time{}, output, fmt.Errorf("pod/%s failed: %s", pod.Name, output)
+	}
+	return startedAt, output, nil
+}
+
+// injectorStartedAt returns when the injector container of the pod started, zero if it has not.
+func injectorStartedAt(pod *corev1.Pod) time.Time {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != containerName {
+			continue
+		}
+		switch {
+		case status.State.Terminated != nil:
+			return status.State.Terminated.StartedAt.Time
+		case status.State.Running != nil:
+			return status.State.Running.StartedAt.Time
+		}
+	}
+	return time.Time{}
+}
+
+func (i *Injector) podLog(ctx context.Context, podName string) string {
+	logStream, err := i.kubeClient.CoreV1().Pods(i.namespaceName).GetLogs(podName, &corev1.PodLogOptions{
+		Container: containerName,
+	}).Stream(ctx)
+	if err != nil {
+		return fmt.Sprintf("unable to read log: %v", err)
+	}
+	defer logStream.Close()
+	buf := &bytes.Buffer{}
+	if _, err := io.Copy(buf, logStream); err != nil {
+		fmt.Fprintf(buf, "\nunable to read log: %v", err)
+	}
+	return buf.String()
+}
+
+func (i *Injector) namespaceDeleted(ctx context.Context) (bool, error) {
+	_, err := i.kubeClient.CoreV1().Namespaces().Get(ctx, i.namespaceName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+
+	if err != nil {
+		klog.Errorf("Error checking for deleted namespace: %s, %s", i.namespaceName, err.Error())
+		return false, err
+	}
+
+	return false, nil
+}
+
+// Cleanup deletes the namespace of the injector pods and waits for it to be gone.  Faults that last are undone on
+// their nodes, not by Cleanup.
+func (i *Injector) Cleanup(ctx context.Context) error {
+	if len(i.namespaceName) == 0 {
+		return nil
+	}
+	if err := i.kubeClient.CoreV1().Namespaces().Delete(ctx, i.namespaceName, metav1.DeleteOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	startTime := time.Now()
+	if err := wait.PollUntilContextTimeout(ctx, 15*time.Second, 20*time.Minute, true, i.namespaceDeleted); err != nil {
+		return err
+	}
+	klog.Infof("Deleting namespace: %s took %.2f seconds", i.namespaceName, time.Since(startTime).Seconds())
+	return nil
+}
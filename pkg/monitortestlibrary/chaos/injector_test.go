@@ -0,0 +1,154 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestEnsureAllowed(t *testing.T) {
+	clusterVersion := &configv1.ClusterVersion{ObjectMeta: metav1.ObjectMeta{Name: "version"}}
+	err := EnsureAllowed(context.Background(), configfake.NewSimpleClientset(clusterVersion))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "refusing to inject faults")
+
+	clusterVersion.Labels = map[string]string{AllowedLabel: "true"}
+	assert.NoError(t, EnsureAllowed(context.Background(), configfake.NewSimpleClientset(clusterVersion)))
+}
+
+// containerStartedAt is when the injector containers of fakeInjectorClient started.
+var containerStartedAt = time.Now().Add(-time.Minute).Truncate(time.Second)
+
+// fakeInjectorClient completes the injector pods in the phase.
+func fakeInjectorClient(phase corev1.PodPhase, objects ...runtime.Object) *fake.Clientset {
+	kubeClient := fake.NewSimpleClientset(objects...)
+	// the fake client does not generate names
+	kubeClient.PrependReactor("create", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		obj := action.(clienttesting.CreateAction).GetObject().(metav1.Object)
+		if len(obj.GetName()) == 0 {
+			obj.SetName(obj.GetGenerateName() + "test")
+		}
+		if pod, ok := obj.(*corev1.Pod); ok {
+			pod.Status.Phase = phase
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+				{
+					Name: containerName,
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{StartedAt: metav1.NewTime(containerStartedAt)},
+					},
+				},
+			}
+		}
+		return false, nil, nil
+	})
+	return kubeClient
+}
+
+func TestInject(t *testing.T) {
+	schedule, err := ParseSchedule([]byte(testSchedule))
+	require.NoError(t, err)
+	master := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "master-0", Labels: map[string]string{masterNodeRoleLabel: ""}}}
+	worker := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}}
+
+	recorder := monitor.NewRecorder()
+	kubeClient := fakeInjectorClient(corev1.PodSucceeded, master, worker)
+	injector := NewInjector(kubeClient, "tools", schedule, recorder)
+	injector.pollInterval = time.Millisecond
+	ctx := context.Background()
+	require.NoError(t, injector.Setup(ctx))
+
+	result := injector.Inject(ctx, schedule.Faults[1])
+	require.NoError(t, result.Err)
+	assert.Equal(t, "master-1", result.NodeName)
+	assert.Equal(t, containerStartedAt, result.InjectedAt, "faults are injected when the container starts, not when the pod is seen to complete")
+
+	result = injector.Inject(ctx, schedule.Faults[0])
+	require.NoError(t, result.Err)
+	assert.Equal(t, "master-0", result.NodeName, "static pods are killed on masters")
+
+	pod, err := kubeClient.CoreV1().Pods("e2e-chaos-test").Get(ctx, "chaos-kill-apiserver-test", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "master-0", pod.Spec.NodeName)
+	assert.True(t, pod.Spec.HostNetwork)
+	assert.True(t, *pod.Spec.Containers[0].SecurityContext.Privileged)
+
+	intervals := recorder.Intervals(time.Time{}, time.Time{})
+	require.Len(t, intervals, 2)
+	for _, interval := range intervals {
+		assert.Equal(t, monitorapi.SourceChaos, interval.Source)
+		assert.Equal(t, monitorapi.Info, interval.Level)
+		assert.Equal(t, monitorapi.ChaosFaultInjected, interval.Message.Reason)
+		assert.Empty(t, monitorapi.ValidateInterval(interval))
+	}
+	drop := intervals.Filter(func(interval monitorapi.Interval) bool {
+		return interval.Message.Annotations[monitorapi.AnnotationChaosFault] == string(FaultDropTraffic)
+	})
+	require.Len(t, drop, 1)
+	assert.Equal(t, "master-1", drop[0].Locator.Keys[monitorapi.LocatorNodeKey])
+	assert.Equal(t, containerStartedAt, drop[0].From)
+	assert.Equal(t, 90*time.Second, drop[0].To.Sub(drop[0].From))
+}
+
+func TestInjectFailure(t *testing.T) {
+	schedule, err := ParseSchedule([]byte(testSchedule))
+	require.NoError(t, err)
+
+	recorder := monitor.NewRecorder()
+	injector := NewInjector(fakeInjectorClient(corev1.PodFailed), "tools", schedule, recorder)
+	injector.pollInterval = time.Millisecond
+	ctx := context.Background()
+	require.NoError(t, injector.Setup(ctx))
+
+	result := injector.Inject(ctx, schedule.Faults[0])
+	require.Error(t, result.Err)
+	assert.Contains(t, result.Err.Error(), "no nodes match")
+
+	result = injector.Inject(ctx, schedule.Faults[1])
+	require.Error(t, result.Err)
+	assert.Contains(t, result.Err.Error(), "failed")
+	assert.True(t, result.InjectedAt.IsZero())
+
+	intervals := recorder.Intervals(time.Time{}, time.Time{})
+	require.Len(t, intervals, 2)
+	for _, interval := range intervals {
+		assert.Equal(t, monitorapi.Warning, interval.Level)
+		assert.Equal(t, monitorapi.ChaosFaultFailed, interval.Message.Reason)
+	}
+}
+
+func TestRun(t *testing.T) {
+	schedule, err := ParseSchedule([]byte(testSchedule))
+	require.NoError(t, err)
+
+	injector := NewInjector(fakeInjectorClient(corev1.PodSucceeded), "tools", schedule, monitor.NewRecorder())
+	injector.pollInterval = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, injector.Setup(ctx))
+
+	// only the drop traffic fault is due
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		injector.Run(ctx, time.Now().Add(-25*time.Minute))
+	}()
+	assert.Eventually(t, func() bool { return len(injector.Results()) == 2 }, 10*time.Second, time.Millisecond)
+	cancel()
+	<-done
+
+	results := injector.Results()
+	require.Len(t, results, 2)
+	assert.Equal(t, "kill-apiserver", results[0].Fault.Name)
+	assert.Equal(t, "drop-apiserver-traffic", results[1].Fault.Name)
+}
@@ -0,0 +1,198 @@
+package chaos
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// FaultType is what is done to the cluster.
+type FaultType string
+
+const (
+	// FaultKillStaticPod stops the sandbox of a static pod, the kubelet starts it again.
+	FaultKillStaticPod FaultType = "KillStaticPod"
+	// FaultRebootNode reboots a node, gracefully unless forced.
+	FaultRebootNode FaultType = "RebootNode"
+	// FaultDropTraffic drops the traffic a node receives on a port for the duration.
+	FaultDropTraffic FaultType = "DropTraffic"
+	// FaultPauseEtcdMember stops the etcd process of a node for the duration.
+	FaultPauseEtcdMember FaultType = "PauseEtcdMember"
+	// FaultFillDisk allocates a file on a node for the duration.
+	FaultFillDisk FaultType = "FillDisk"
+)
+
+const masterNodeRoleLabel = "node-role.kubernetes.io/master"
+
+// Schedule is the list of faults injected during a run.
+//
+//	faults:
+//	- name: drop-apiserver-traffic
+//	  type: DropTraffic
+//	  after: 20m
+//	  duration: 90s
+//	  nodeSelector:
+//	    node-role.kubernetes.io/master: ""
+//	  port: 6443
+type Schedule struct {
+	Faults []Fault `json:"faults"`
+
+	// Source is the file the schedule was read from.
+	Source string `json:"-"`
+}
+
+// Fault is injected once, on a single node.
+type Fault struct {
+	Name string    `json:"name"`
+	Type FaultType `json:"type"`
+
+	// After is when the fault is injected, from the start of the run.
+	After metav1.Duration `json:"after"`
+	// Duration is how long DropTraffic, PauseEtcdMember and FillDisk last.  The end is scheduled on the node before
+	// the fault is injected so that the cluster recovers even if the run is interrupted.
+	Duration metav1.Duration `json:"duration,omitempty"`
+
+	// Node is the node of the fault, otherwise one of the nodes matching NodeSelector is picked at random.
+	// KillStaticPod and PauseEtcdMember default to the master nodes.
+	Node         string            `json:"node,omitempty"`
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Namespace and Pod name the static pod stopped by KillStaticPod, the pod on the node is named <pod>-<node>.
+	Namespace string `json:"namespace,omitempty"`
+	Pod       string `json:"pod,omitempty"`
+
+	// Force reboots without a clean shutdown.
+	Force bool `json:"force,omitempty"`
+
+	// Port and Protocol are the traffic dropped, tcp by default.
+	Port     int    `json:"port,omitempty"`
+	Protocol string `json:"protocol,omitempty"`
+
+	// Size is how much of the disk is filled at Path, /var/tmp by default.
+	Size *resource.Quantity `json:"size,omitempty"`
+	Path string             `json:"path,omitempty"`
+}
+
+// ReadSchedule reads and validates a schedule file.
+func ReadSchedule(filename string) (*Schedule, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := ParseSchedule(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	schedule.Source = filename
+	return schedule, nil
+}
+
+// ParseSchedule reads a json or yaml schedule, sets the defaults of its faults and validates them.  Unknown fields
+// are errors so that a misspelled fault is not silently ignored.
+func ParseSchedule(data []byte) (*Schedule, error) {
+	schedule := &Schedule{}
+	if err := yaml.UnmarshalStrict(data, schedule); err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for i := range schedule.Faults {
+		schedule.Faults[i].SetDefaults()
+		if err := schedule.Faults[i].Validate(); err != nil {
+			return nil, err
+		}
+		if names[schedule.Faults[i].Name] {
+			return nil, fmt.Errorf("fault %q is declared twice", schedule.Faults[i].Name)
+		}
+		names[schedule.Faults[i].Name] = true
+	}
+	return schedule, nil
+}
+
+// SetDefaults fills in the fields that were not set.
+func (f *Fault) SetDefaults() {
+	if len(f.Node) == 0 && len(f.NodeSelector) == 0 && (f.Type == FaultKillStaticPod || f.Type == FaultPauseEtcdMember) {
+		f.NodeSelector = map[string]string{masterNodeRoleLabel: ""}
+	}
+	if f.Type == FaultDropTraffic && len(f.Protocol) == 0 {
+		f.Protocol = "tcp"
+	}
+	if f.Type == FaultFillDisk && len(f.Path) == 0 {
+		f.Path = "/var/tmp"
+	}
+}
+
+// diskPath matches the paths a disk can be filled at, they are run in a shell as root on the node.
+var diskPath = regexp.MustCompile(`^[A-Za-z0-9/._-]+$`)
+
+// Validate checks that the fault can be injected and ends.
+func (f *Fault) Validate() error {
+	if len(f.Name) == 0 {
+		return fmt.Errorf("fault has no name")
+	}
+	// the name is part of the name of the pod injecting the fault
+	if errs := validation.IsDNS1123Label(f.Name); len(errs) > 0 {
+		return fmt.Errorf("fault %q: invalid name: %v", f.Name, errs)
+	}
+	if f.After.Duration < 0 {
+		return fmt.Errorf("fault %q: after must not be negative", f.Name)
+	}
+
+	switch f.Type {
+	case FaultKillStaticPod:
+		if len(f.Namespace) == 0 || len(f.Pod) == 0 {
+			return fmt.Errorf("fault %q: namespace and pod of the static pod are required", f.Name)
+		}
+		if errs := validation.IsDNS1123Label(f.Namespace); len(errs) > 0 {
+			return fmt.Errorf("fault %q: invalid namespace: %v", f.Name, errs)
+		}
+		if errs := validation.IsDNS1123Subdomain(f.Pod); len(errs) > 0 {
+			return fmt.Errorf("fault %q: invalid pod: %v", f.Name, errs)
+		}
+	case FaultRebootNode:
+	case FaultDropTraffic:
+		if f.Port <= 0 || f.Port > 65535 {
+			return fmt.Errorf("fault %q: port must be between 1 and 65535", f.Name)
+		}
+		if f.Protocol != "tcp" && f.Protocol != "udp" {
+			return fmt.Errorf("fault %q: protocol must be tcp or udp, not %q", f.Name, f.Protocol)
+		}
+	case FaultPauseEtcdMember:
+	case FaultFillDisk:
+		if f.Size == nil || f.Size.Sign() <= 0 {
+			return fmt.Errorf("fault %q: size must be positive", f.Name)
+		}
+		if !path.IsAbs(f.Path) {
+			return fmt.Errorf("fault %q: path must be absolute, not %q", f.Name, f.Path)
+		}
+		if !diskPath.MatchString(f.Path) {
+			return fmt.Errorf("fault %q: path must only contain letters, digits, '/', '.', '_' and '-', not %q", f.Name, f.Path)
+		}
+	default:
+		return fmt.Errorf("fault %q: unknown type %q", f.Name, f.Type)
+	}
+
+	if f.hasDuration() {
+		if f.Duration.Duration < time.Second {
+			return fmt.Errorf("fault %q: %v faults must last at least a second", f.Name, f.Type)
+		}
+	} else if f.Duration.Duration != 0 {
+		return fmt.Errorf("fault %q: %v faults do not have a duration", f.Name, f.Type)
+	}
+	return nil
+}
+
+// hasDuration is true for the faults that are undone after their duration, the others end when the cluster recovers.
+func (f *Fault) hasDuration() bool {
+	switch f.Type {
+	case FaultDropTraffic, FaultPauseEtcdMember, FaultFillDisk:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package chaos
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchedule = `
+faults:
+- name: kill-apiserver
+  type: KillStaticPod
+  after: 10m
+  namespace: openshift-kube-apiserver
+  pod: kube-apiserver
+- name: drop-apiserver-traffic
+  type: DropTraffic
+  after: 20m
+  duration: 90s
+  node: master-1
+  port: 6443
+- name: fill-disk
+  type: FillDisk
+  after: 30m
+  duration: 5m
+  size: 10Gi
+`
+
+func TestParseSchedule(t *testing.T) {
+	schedule, err := ParseSchedule([]byte(testSchedule))
+	require.NoError(t, err)
+	require.Len(t, schedule.Faults, 3)
+
+	kill := schedule.Faults[0]
+	assert.Equal(t, 10*time.Minute, kill.After.Duration)
+	assert.Equal(t, map[string]string{masterNodeRoleLabel: ""}, kill.NodeSelector, "static pods default to the masters")
+
+	drop := schedule.Faults[1]
+	assert.Equal(t, "tcp", drop.Protocol)
+	assert.Empty(t, drop.NodeSelector)
+
+	fill := schedule.Faults[2]
+	assert.Equal(t, "/var/tmp", fill.Path)
+	assert.Equal(t, int64(10*1024*1024*1024), fill.Size.Value())
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		expected string
+	}{
+		{
+			name:     "unknown field",
+			schedule: "faults:\n- name: a\n  type: RebootNode\n  forced: true\n",
+			expected: `unknown field "forced"`,
+		},
+		{
+			name:     "unknown type",
+			schedule: "faults:\n- name: a\n  type: DeleteCluster\n",
+			expected: `unknown type "DeleteCluster"`,
+		},
+		{
+			name:     "duplicate",
+			schedule: "faults:\n- name: a\n  type: RebootNode\n- name: a\n  type: RebootNode\n",
+			expected: `fault "a" is declared twice`,
+		},
+		{
+			name:     "lasting fault without duration",
+			schedule: "faults:\n- name: a\n  type: PauseEtcdMember\n",
+			expected: "must last at least a second",
+		},
+		{
+			name:     "reboot with duration",
+			schedule: "faults:\n- name: a\n  type: RebootNode\n  duration: 1m\n",
+			expected: "do not have a duration",
+		},
+		{
+			name:     "port",
+			schedule: "faults:\n- name: a\n  type: DropTraffic\n  duration: 1m\n",
+			expected: "port must be between 1 and 65535",
+		},
+		{
+			name:     "static pod",
+			schedule: "faults:\n- name: a\n  type: KillStaticPod\n  namespace: openshift-etcd\n  pod: \"etcd; reboot\"\n",
+			expected: "invalid pod",
+		},
+		{
+			name:     "relative path",
+			schedule: "faults:\n- name: a\n  type: FillDisk\n  duration: 1m\n  size: 1Gi\n  path: tmp\n",
+			expected: "path must be absolute",
+		},
+		{
+			name:     "path with shell metacharacters",
+			schedule: "faults:\n- name: a\n  type: FillDisk\n  duration: 1m\n  size: 1Gi\n  path: \"/var/tmp'; reboot; '\"\n",
+			expected: "path must only contain",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSchedule([]byte(tt.schedule))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestScript(t *testing.T) {
+	schedule, err := ParseSchedule([]byte(testSchedule))
+	require.NoError(t, err)
+
+	kill := schedule.Faults[0].script("master-0")
+	assert.Contains(t, kill, "crictl pods --namespace 'openshift-kube-apiserver' --name '^kube-apiserver-master-0$'")
+
+	drop := schedule.Faults[1].script("master-1")
+	assert.Contains(t, drop, "systemd-run --on-active=90 /bin/sh -c 'iptables -D INPUT -p tcp --dport 6443 -j DROP'")
+	assert.Less(t, strings.Index(drop, "systemd-run"), strings.Index(drop, "iptables -I"), "the fault must be undone before it is injected")
+
+	fill := schedule.Faults[2].script("worker-0")
+	assert.Contains(t, fill, `/bin/sh -c 'rm -f '\''/var/tmp/chaos-fill-disk'\'''`)
+	assert.Contains(t, fill, "fallocate -l 10737418240 '/var/tmp/chaos-fill-disk'")
+	assert.Contains(t, fill, "echo 'allocated 10Gi at /var/tmp/chaos-fill-disk'")
+}
@@ -0,0 +1,306 @@
+package disruptioncause
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// Cause is the category of what likely made a backend unavailable.
+type Cause string
+
+const (
+	CauseAPIServerGracefulShutdown  Cause = "APIServerGracefulShutdown"
+	CauseFaultyLoadBalancer         Cause = "FaultyLoadBalancer"
+	CauseLoadBalancerHealthCheckLag Cause = "LoadBalancerHealthCheckLag"
+	CauseNodeReboot                 Cause = "NodeReboot"
+	CauseNodeNotReady               Cause = "NodeNotReady"
+	CauseEtcdLeaderChange           Cause = "EtcdLeaderChange"
+	CauseStaticPodInstall           Cause = "StaticPodInstall"
+	CauseDNS                        Cause = "DNS"
+	CauseChaosInjection             Cause = "ChaosInjection"
+	CauseUnknown                    Cause = "Unknown"
+)
+
+const (
+	// sameNodeScore is added when the disruption was seen from the node the candidate is about, for the samplers that
+	// run on every node.
+	sameNodeScore = 10
+)
+
+// rule scores the intervals that may have caused disruption.  A disruption that starts within the window of a matching
+// interval scores the weight of the rule, one that only overlaps it half of that.
+type rule struct {
+	cause   Cause
+	weight  int
+	matches func(monitorapi.Interval) bool
+
+	// lead and lag widen the window of the interval, disruption is often seen a little before or after what caused it.
+	lead, lag time.Duration
+	// atStart only uses the start of the interval, for intervals that last until the next change.
+	atStart bool
+}
+
+// rules are ordered by precedence, the first of equal scores wins.
+var rules = []rule{
+	{
+		// the fault was injected on purpose, whatever it then caused is induced and not organic disruption.  Faults
+		// without a duration, like reboots, take a while to cause disruption.
+		cause:   CauseChaosInjection,
+		weight:  45,
+		matches: isChaosFault,
+		lag:     2 * time.Minute,
+	},
+	{
+		// the on-prem load balancer kept sending connections to a kube-apiserver that was down until it noticed.
+		cause:   CauseLoadBalancerHealthCheckLag,
+		weight:  40,
+		matches: isHaproxyDetectsDown,
+		lag:     5 * time.Second,
+	},
+	{
+		// clients could not reach the kube-apiserver through a load balancer, during a graceful shutdown this means
+		// the load balancer did not take the instance out of rotation in time.
+		cause:   CauseFaultyLoadBalancer,
+		weight:  35,
+		matches: isAPIUnreachableFromClient,
+		lag:     5 * time.Second,
+	},
+	{
+		cause:   CauseAPIServerGracefulShutdown,
+		weight:  30,
+		matches: isAPIServerGracefulShutdown,
+		lag:     5 * time.Second,
+	},
+	{
+		cause:   CauseNodeReboot,
+		weight:  25,
+		matches: isNodeReboot,
+		lag:     30 * time.Second,
+	},
+	{
+		cause:   CauseNodeNotReady,
+		weight:  20,
+		matches: isNodeNotReady,
+		lag:     30 * time.Second,
+	},
+	{
+		cause:   CauseStaticPodInstall,
+		weight:  20,
+		matches: isStaticPodInstall,
+		lag:     10 * time.Second,
+	},
+	{
+		// leadership intervals last for the whole term, only the election may disrupt.
+		cause:   CauseEtcdLeaderChange,
+		weight:  15,
+		matches: isEtcdLeadership,
+		lead:    5 * time.Second,
+		lag:     15 * time.Second,
+		atStart: true,
+	},
+}
+
+// messageRules attribute disruption from the errors of the disruption itself, the sampler knows better than any
+// concurrent interval.
+var messageRules = []struct {
+	cause     Cause
+	weight    int
+	fragments []string
+}{
+	{cause: CauseFaultyLoadBalancer, weight: 100, fragments: []string{"category: FaultyLoadBalancer"}},
+	{cause: CauseDNS, weight: 50, fragments: []string{"category: DNSError", "no such host", "server misbehaving"}},
+}
+
+func isChaosFault(interval monitorapi.Interval) bool {
+	return interval.Source == monitorapi.SourceChaos && interval.Message.Reason == monitorapi.ChaosFaultInjected
+}
+
+func isHaproxyDetectsDown(interval monitorapi.Interval) bool {
+	return interval.Source == monitorapi.SourceHaproxyMonitor && interval.Message.Reason == monitorapi.OnPremHaproxyDetectsDown
+}
+
+func isAPIUnreachableFromClient(interval monitorapi.Interval) bool {
+	return interval.Source == monitorapi.SourceAPIUnreachableFromClient
+}
+
+func isAPIServerGracefulShutdown(interval monitorapi.Interval) bool {
+	return interval.Source == monitorapi.APIServerGracefulShutdown
+}
+
+func isNodeReboot(interval monitorapi.Interval) bool {
+	return interval.Source == monitorapi.SourceNodeState && interval.Message.Annotations[monitorapi.AnnotationPhase] == "Reboot"
+}
+
+func isNodeNotReady(interval monitorapi.Interval) bool {
+	return interval.Source == monitorapi.SourceNodeState && interval.Message.Reason == monitorapi.NodeNotReadyReason
+}
+
+func isStaticPodInstall(interval monitorapi.Interval) bool {
+	return interval.Source == monitorapi.SourceStaticPodInstallMonitor
+}
+
+func isEtcdLeadership(interval monitorapi.Interval) bool {
+	return interval.Source == monitorapi.SourceEtcdLeadership
+}
+
+// IsDisruption matches the intervals that count as disruption of a backend.
+func IsDisruption(interval monitorapi.Interval) bool {
+	return monitorapi.IsDisruptionEvent(interval) && monitorapi.IsErrorEvent(interval)
+}
+
+// Attribution is the likely cause of a disruption interval.
+type Attribution struct {
+	Cause Cause
+	Score int
+	// Evidence describes what the cause was attributed from.
+	Evidence string
+}
+
+// Attributor attributes disruption to the intervals of a run.
+type Attributor struct {
+	candidates map[Cause]monitorapi.Intervals
+}
+
+// NewAttributor keeps the intervals that may cause disruption.
+func NewAttributor(intervals monitorapi.Intervals) *Attributor {
+	ret := &Attributor{candidates: map[Cause]monitorapi.Intervals{}}
+	for _, interval := range intervals {
+		for _, r := range rules {
+			if r.matches(interval) {
+				ret.candidates[r.cause] = append(ret.candidates[r.cause], interval)
+			}
+		}
+	}
+	return ret
+}
+
+// Attribute scores every candidate cause of the disruption and returns the best, or CauseUnknown if nothing explains
+// it.
+func (a *Attributor) Attribute(disruption monitorapi.Interval) Attribution {
+	best := Attribution{Cause: CauseUnknown}
+	message := disruption.Message.HumanMessage
+	for _, r := range messageRules {
+		for _, fragment := range r.fragments {
+			if strings.Contains(message, fragment) && r.weight > best.Score {
+				best = Attribution{Cause: r.cause, Score: r.weight, Evidence: fmt.Sprintf("disruption error contains %q", fragment)}
+			}
+		}
+	}
+
+	disruptionNode := nodeOf(disruption)
+	for _, r := range rules {
+		for _, candidate := range a.candidates[r.cause] {
+			score := r.score(disruption, candidate)
+			if score == 0 {
+				continue
+			}
+			if len(disruptionNode) > 0 && nodeOf(candidate) == disruptionNode {
+				score += sameNodeScore
+			}
+			if score > best.Score {
+				best = Attribution{Cause: r.cause, Score: score, Evidence: candidate.String()}
+			}
+		}
+	}
+	return best
+}
+
+func (r rule) score(disruption, candidate monitorapi.Interval) int {
+	from := candidate.From.Add(-r.lead)
+	to := candidate.To
+	if r.atStart || to.IsZero() {
+		to = candidate.From
+	}
+	to = to.Add(r.lag)
+
+	switch {
+	case !disruption.From.Before(from) && !disruption.From.After(to):
+		return r.weight
+	case disruption.From.Before(from) && (disruption.To.IsZero() || disruption.To.After(from)):
+		return r.weight / 2
+	}
+	return 0
+}
+
+func nodeOf(interval monitorapi.Interval) string {
+	if node := interval.Locator.Keys[monitorapi.LocatorNodeKey]; len(node) > 0 {
+		return node
+	}
+	return interval.Message.Annotations[monitorapi.AnnotationNode]
+}
+
+// CauseIntervals returns an interval of SourceDisruptionCause for every disruption interval, with the same locator
+// and span, that carries its likely cause so that the cause is on the timeline next to the disruption.
+func (a *Attributor) CauseIntervals(disrupted monitorapi.Intervals) monitorapi.Intervals {
+	ret := monitorapi.Intervals{}
+	for _, disruption := range disrupted {
+		attribution := a.Attribute(disruption)
+		message := monitorapi.NewMessage().
+			Reason(monitorapi.DisruptionAttributed).
+			Cause(string(attribution.Cause))
+		if len(attribution.Evidence) > 0 {
+			message = message.HumanMessagef("disruption likely caused by %s: %s", attribution.Cause, attribution.Evidence)
+		} else {
+			message = message.HumanMessage("disruption has no likely cause")
+		}
+		ret = append(ret,
+			monitorapi.NewInterval(monitorapi.SourceDisruptionCause, monitorapi.Info).
+				Locator(disruption.Locator).
+				Message(message).
+				Display().
+				Build(disruption.From, disruption.To),
+		)
+	}
+	return ret
+}
+
+// IsCause matches the intervals returned by CauseIntervals.
+func IsCause(interval monitorapi.Interval) bool {
+	return interval.Source == monitorapi.SourceDisruptionCause
+}
+
+// CauseOf returns the cause a cause interval was attributed to.
+func CauseOf(interval monitorapi.Interval) Cause {
+	if cause := interval.Message.Annotations[monitorapi.AnnotationCause]; len(cause) > 0 {
+		return Cause(cause)
+	}
+	return CauseUnknown
+}
+
+// Totals sums the disruption of the cause intervals by cause, counting at least a second for every interval like the
+// disruption of a backend is counted.
+func Totals(causes monitorapi.Intervals) map[Cause]time.Duration {
+	byCause := map[Cause]monitorapi.Intervals{}
+	for _, interval := range causes {
+		cause := CauseOf(interval)
+		byCause[cause] = append(byCause[cause], interval)
+	}
+	ret := map[Cause]time.Duration{}
+	for cause, intervals := range byCause {
+		ret[cause] = intervals.Duration(1 * time.Second).Round(time.Second)
+	}
+	return ret
+}
+
+// Summary lists the totals by cause, the longest first.
+func Summary(totals map[Cause]time.Duration) string {
+	causes := []Cause{}
+	for cause := range totals {
+		causes = append(causes, cause)
+	}
+	sort.Slice(causes, func(i, j int) bool {
+		if totals[causes[i]] != totals[causes[j]] {
+			return totals[causes[i]] > totals[causes[j]]
+		}
+		return causes[i] < causes[j]
+	})
+	lines := []string{}
+	for _, cause := range causes {
+		lines = append(lines, fmt.Sprintf("%s: %s", cause, totals[cause]))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,168 @@
+package disruptioncause
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+var start = time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+func disruptionAt(from, to time.Time, message, node string) monitorapi.Interval {
+	interval := monitorapi.NewInterval(monitorapi.SourceDisruption, monitorapi.Error).
+		Locator(monitorapi.NewLocator().LocateDisruptionCheck("kube-api-new-connections", "", monitorapi.NewConnectionType)).
+		Message(monitorapi.NewMessage().Reason(monitorapi.DisruptionBeganEventReason).HumanMessage(message)).
+		Build(from, to)
+	if len(node) > 0 {
+		interval.Message.Annotations[monitorapi.AnnotationNode] = node
+	}
+	return interval
+}
+
+func nodeState(node string, reason monitorapi.IntervalReason, phase string, from, to time.Time) monitorapi.Interval {
+	message := monitorapi.NewMessage().Reason(reason).HumanMessage("node state")
+	if len(phase) > 0 {
+		message = message.WithAnnotation(monitorapi.AnnotationPhase, phase)
+	}
+	return monitorapi.NewInterval(monitorapi.SourceNodeState, monitorapi.Warning).
+		Locator(monitorapi.NewLocator().NodeFromName(node)).
+		Message(message).
+		Build(from, to)
+}
+
+func TestAttribute(t *testing.T) {
+	gracefulShutdown := monitorapi.NewInterval(monitorapi.APIServerGracefulShutdown, monitorapi.Info).
+		Locator(monitorapi.NewLocator().NodeFromName("master-0")).
+		Message(monitorapi.NewMessage().HumanMessage("graceful shutdown")).
+		Build(start, start.Add(70*time.Second))
+	etcdLeadership := monitorapi.NewInterval(monitorapi.SourceEtcdLeadership, monitorapi.Info).
+		Locator(monitorapi.NewLocator().NodeFromName("master-1")).
+		Message(monitorapi.NewMessage().HumanMessage("elected leader")).
+		Build(start.Add(10*time.Minute), start.Add(time.Hour))
+
+	tests := []struct {
+		name       string
+		intervals  monitorapi.Intervals
+		disruption monitorapi.Interval
+		expected   Cause
+		score      int
+	}{
+		{
+			name:       "nothing explains it",
+			intervals:  monitorapi.Intervals{gracefulShutdown},
+			disruption: disruptionAt(start.Add(5*time.Minute), start.Add(5*time.Minute+time.Second), "timeout", ""),
+			expected:   CauseUnknown,
+		},
+		{
+			name:       "during graceful shutdown",
+			intervals:  monitorapi.Intervals{gracefulShutdown},
+			disruption: disruptionAt(start.Add(30*time.Second), start.Add(32*time.Second), "timeout", ""),
+			expected:   CauseAPIServerGracefulShutdown,
+			score:      30,
+		},
+		{
+			name:       "started before graceful shutdown",
+			intervals:  monitorapi.Intervals{gracefulShutdown},
+			disruption: disruptionAt(start.Add(-2*time.Second), start.Add(2*time.Second), "timeout", ""),
+			expected:   CauseAPIServerGracefulShutdown,
+			score:      15,
+		},
+		{
+			name:       "error of the disruption wins",
+			intervals:  monitorapi.Intervals{gracefulShutdown},
+			disruption: disruptionAt(start.Add(30*time.Second), start.Add(32*time.Second), "dial tcp: lookup api: no such host", ""),
+			expected:   CauseDNS,
+			score:      50,
+		},
+		{
+			name: "the node the disruption was seen from",
+			intervals: monitorapi.Intervals{
+				nodeState("worker-0", monitorapi.NodeNotReadyReason, "", start, start.Add(time.Minute)),
+				nodeState("worker-1", "", "Reboot", start, start.Add(time.Minute)),
+			},
+			disruption: disruptionAt(start.Add(10*time.Second), start.Add(12*time.Second), "timeout", "worker-0"),
+			expected:   CauseNodeNotReady,
+			score:      30,
+		},
+		{
+			name: "node reboot before not ready",
+			intervals: monitorapi.Intervals{
+				nodeState("worker-0", monitorapi.NodeNotReadyReason, "", start, start.Add(time.Minute)),
+				nodeState("worker-0", "", "Reboot", start, start.Add(time.Minute)),
+			},
+			disruption: disruptionAt(start.Add(10*time.Second), start.Add(12*time.Second), "timeout", ""),
+			expected:   CauseNodeReboot,
+			score:      25,
+		},
+		{
+			name: "injected fault before graceful shutdown",
+			intervals: monitorapi.Intervals{
+				gracefulShutdown,
+				monitorapi.NewInterval(monitorapi.SourceChaos, monitorapi.Info).
+					Locator(monitorapi.NewLocator().NodeFromName("master-0")).
+					Message(monitorapi.NewMessage().Reason(monitorapi.ChaosFaultInjected).HumanMessage("RebootNode fault injected")).
+					Build(start.Add(-time.Minute), start.Add(-time.Minute)),
+			},
+			disruption: disruptionAt(start.Add(30*time.Second), start.Add(32*time.Second), "timeout", ""),
+			expected:   CauseChaosInjection,
+			score:      45,
+		},
+		{
+			name:       "etcd leader election",
+			intervals:  monitorapi.Intervals{etcdLeadership},
+			disruption: disruptionAt(start.Add(10*time.Minute-3*time.Second), start.Add(10*time.Minute), "timeout", ""),
+			expected:   CauseEtcdLeaderChange,
+			score:      15,
+		},
+		{
+			name:       "during etcd leadership term",
+			intervals:  monitorapi.Intervals{etcdLeadership},
+			disruption: disruptionAt(start.Add(30*time.Minute), start.Add(30*time.Minute+time.Second), "timeout", ""),
+			expected:   CauseUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := NewAttributor(tt.intervals).Attribute(tt.disruption)
+			assert.Equal(t, tt.expected, actual.Cause)
+			assert.Equal(t, tt.score, actual.Score)
+		})
+	}
+}
+
+func TestCauseIntervalsAndTotals(t *testing.T) {
+	gracefulShutdown := monitorapi.NewInterval(monitorapi.APIServerGracefulShutdown, monitorapi.Info).
+		Locator(monitorapi.NewLocator().NodeFromName("master-0")).
+		Message(monitorapi.NewMessage().HumanMessage("graceful shutdown")).
+		Build(start, start.Add(70*time.Second))
+	disrupted := monitorapi.Intervals{
+		disruptionAt(start.Add(10*time.Second), start.Add(13*time.Second), "timeout", ""),
+		disruptionAt(start.Add(20*time.Second), start.Add(20*time.Second+200*time.Millisecond), "timeout", ""),
+		disruptionAt(start.Add(10*time.Minute), start.Add(10*time.Minute+5*time.Second), "timeout", ""),
+	}
+
+	causes := NewAttributor(monitorapi.Intervals{gracefulShutdown}).CauseIntervals(disrupted)
+	if assert.Len(t, causes, 3) {
+		assert.Equal(t, string(CauseAPIServerGracefulShutdown), causes[0].Message.Cause)
+		assert.Equal(t, CauseAPIServerGracefulShutdown, CauseOf(causes[1]))
+		assert.Equal(t, CauseUnknown, CauseOf(causes[2]))
+		for i := range causes {
+			assert.True(t, IsCause(causes[i]))
+			assert.Equal(t, disrupted[i].Locator, causes[i].Locator)
+			assert.Equal(t, disrupted[i].From, causes[i].From)
+			assert.Equal(t, disrupted[i].To, causes[i].To)
+			// the cause intervals do not count as disruption
+			assert.False(t, IsDisruption(causes[i]))
+		}
+	}
+
+	totals := Totals(causes)
+	assert.Equal(t, map[Cause]time.Duration{
+		CauseAPIServerGracefulShutdown: 4 * time.Second,
+		CauseUnknown:                   5 * time.Second,
+	}, totals)
+	assert.Equal(t, "Unknown: 5s\nAPIServerGracefulShutdown: 4s", Summary(totals))
+}
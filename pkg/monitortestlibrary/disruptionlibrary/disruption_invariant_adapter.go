@@ -9,7 +9,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/openshift/origin/pkg/monitortestframework"
 	"github.com/openshift/origin/pkg/monitortestlibrary/allowedbackenddisruption"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptioncause"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionpolicy"
 	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
 
 	"github.com/openshift/origin/pkg/monitor/backenddisruption"
@@ -17,8 +20,27 @@ import (
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/events"
 )
 
+// Sampler is a disruption test of a single backend and connection type.  It is implemented by
+// backenddisruption.BackendSampler for HTTP backends and by the samplers of pkg/disruption/ci, including the
+// ones that probe TCP, DNS and gRPC backends.
+type Sampler interface {
+	GetDisruptionBackendName() string
+	GetLocator() monitorapi.Locator
+	StartEndpointMonitoring(ctx context.Context, m monitorapi.RecorderWriter, eventRecorder events.EventRecorder) error
+	Stop()
+}
+
+// latencySampler is implemented by samplers that record degraded intervals when the backend responds slower than its
+// latency SLO.
+type latencySampler interface {
+	GetLatencySLO() backenddisruption.LatencySLO
+	// HasLatencySLO is false when GetLatencySLO is the default, which is only indicative.
+	HasLatencySLO() bool
+}
+
 type Availability struct {
 	newConnectionTestName    string
 	reusedConnectionTestName string
@@ -28,27 +50,125 @@ type Availability struct {
 	// which will include any upgrade versions
 	adminRESTConfig *rest.Config
 
-	newConnectionDisruptionSampler    *backenddisruption.BackendSampler
-	reusedConnectionDisruptionSampler *backenddisruption.BackendSampler
+	// the length of the run, for disruption policy budgets relative to it
+	startTime time.Time
+	endTime   time.Time
+
+	newConnectionDisruptionSampler    Sampler
+	reusedConnectionDisruptionSampler Sampler
+
+	// policy holds the disruption budgets applied before historical data, nil when there is none
+	policy *disruptionpolicy.Policy
+
+	// offlineJobType is read from the saved cluster data when a run is replayed, it is used instead of asking the
+	// cluster.
+	offlineJobType *platformidentification.JobType
 }
 
+// NewAvailabilityInvariant checks the disruption of both samplers against policy, which may be nil, or the historical
+// data of their backends.
 func NewAvailabilityInvariant(
 	newConnectionTestName, reusedConnectionTestName string,
-	newConnectionDisruptionSampler, reusedConnectionDisruptionSampler *backenddisruption.BackendSampler) *Availability {
+	newConnectionDisruptionSampler, reusedConnectionDisruptionSampler Sampler,
+	policy *disruptionpolicy.Policy) *Availability {
 	return &Availability{
 		newConnectionTestName:             newConnectionTestName,
 		reusedConnectionTestName:          reusedConnectionTestName,
 		newConnectionDisruptionSampler:    newConnectionDisruptionSampler,
 		reusedConnectionDisruptionSampler: reusedConnectionDisruptionSampler,
+		policy:                            policy,
 	}
 }
 
+// NewOfflineAvailabilityInvariant checks the disruption of two backends sampled by a replayed run, by their disruption
+// backend names, like NewAvailabilityInvariant does during a run.  The samplers are not recreated, the intervals they
+// recorded are found by backend name, and the tests are skipped for a backend that recorded none.  Latency is not
+// evaluated, the SLO of the samplers is not saved.
+func NewOfflineAvailabilityInvariant(
+	newConnectionTestName, reusedConnectionTestName string,
+	newConnectionBackendName, reusedConnectionBackendName string,
+	policy *disruptionpolicy.Policy) *Availability {
+	return NewAvailabilityInvariant(
+		newConnectionTestName, reusedConnectionTestName,
+		&recordedSampler{backendName: newConnectionBackendName},
+		&recordedSampler{backendName: reusedConnectionBackendName},
+		policy,
+	)
+}
+
+// recordedSampler stands in for a sampler of a replayed run.  Its locator is that of the intervals recorded for its
+// backend, it is only known once they are.
+type recordedSampler struct {
+	backendName string
+	locator     monitorapi.Locator
+	recorded    bool
+}
+
+func (s *recordedSampler) GetDisruptionBackendName() string {
+	return s.backendName
+}
+
+func (s *recordedSampler) GetLocator() monitorapi.Locator {
+	return s.locator
+}
+
+func (s *recordedSampler) StartEndpointMonitoring(ctx context.Context, m monitorapi.RecorderWriter, eventRecorder events.EventRecorder) error {
+	return fmt.Errorf("%s was sampled by a replayed run and cannot be sampled again", s.backendName)
+}
+
+func (s *recordedSampler) Stop() {}
+
+// findRecorded takes the locator from the first interval recorded for the backend.
+func (s *recordedSampler) findRecorded(finalIntervals monitorapi.Intervals) {
+	if interval, ok := firstRecorded(finalIntervals, s.backendName); ok {
+		s.locator, s.recorded = interval.Locator, true
+	}
+}
+
+// WasSampled reports whether intervals were recorded for the disruption backend, a replayed run may not have sampled
+// every backend that can be checked offline.
+func WasSampled(finalIntervals monitorapi.Intervals, backendName string) bool {
+	_, ok := firstRecorded(finalIntervals, backendName)
+	return ok
+}
+
+func firstRecorded(finalIntervals monitorapi.Intervals, backendName string) (monitorapi.Interval, bool) {
+	for _, interval := range finalIntervals {
+		if monitorapi.BackendDisruptionNameFromLocator(interval.Locator) == backendName {
+			return interval, true
+		}
+	}
+	return monitorapi.Interval{}, false
+}
+
+// OfflineJobType reads the job type of a replayed run from the cluster data it saved.  The error is a
+// NotSupportedError, disruption cannot be judged without the job type.
+func OfflineJobType(info monitortestframework.OfflineEvaluationInfo) (*platformidentification.JobType, error) {
+	clusterData, err := platformidentification.ReadClusterDataFromDir(info.ArtifactDir)
+	if err != nil {
+		return nil, &monitortestframework.NotSupportedError{Reason: fmt.Sprintf("disruption tests require the job type: %v", err)}
+	}
+	return &clusterData.JobType, nil
+}
+
+// PrepareForOfflineEvaluation reads the job type of the replayed run, so that EvaluateTestsFromConstructedIntervals
+// does not ask the cluster for it.
+func (w *Availability) PrepareForOfflineEvaluation(ctx context.Context, info monitortestframework.OfflineEvaluationInfo) error {
+	jobType, err := OfflineJobType(info)
+	if err != nil {
+		return err
+	}
+	w.offlineJobType = jobType
+	return nil
+}
+
 func (w *Availability) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
 	if w == nil {
 		return fmt.Errorf("unable to start collection because instance is nil")
 	}
 
 	w.adminRESTConfig = adminRESTConfig
+	w.startTime = time.Now()
 
 	if err := w.newConnectionDisruptionSampler.StartEndpointMonitoring(ctx, recorder, nil); err != nil {
 		return err
@@ -65,6 +185,8 @@ func (w *Availability) CollectData(ctx context.Context) (monitorapi.Intervals, [
 		return nil, nil, fmt.Errorf("unable to collected data because instance is nil")
 	}
 
+	w.endTime = time.Now()
+
 	// when it is time to collect data, we need to stop the collectors.  they both  have to drain, so stop in parallel
 	wg := sync.WaitGroup{}
 
@@ -186,16 +308,22 @@ func (w *Availability) junitForNewConnections(ctx context.Context, finalInterval
 	if err != nil {
 		return nil, fmt.Errorf("unable to get new allowed disruption: %w", err)
 	}
-	return createDisruptionJunit(
+	disruptedIntervals := finalIntervals.Filter(
+		monitorapi.And(
+			monitorapi.IsEventForLocator(w.newConnectionDisruptionSampler.GetLocator()),
+			monitorapi.IsErrorEvent,
+		),
+	)
+	if policyJunit := policyDisruptionJunit(
+		w.policy, w.newConnectionTestName, w.newConnectionDisruptionSampler.GetDisruptionBackendName(), w.newConnectionDisruptionSampler.GetLocator(),
+		newConnectionAllowed != nil, disruptedIntervals, jobType, w.runDuration()); policyJunit != nil {
+		return addDisruptionCauses(policyJunit, monitorapi.IsEventForLocator(w.newConnectionDisruptionSampler.GetLocator()), finalIntervals), nil
+	}
+	return addDisruptionCauses(createDisruptionJunit(
 			w.newConnectionTestName, newConnectionAllowed, newConnectionDisruptionDetails, w.newConnectionDisruptionSampler.GetLocator(),
-			finalIntervals.Filter(
-				monitorapi.And(
-					monitorapi.IsEventForLocator(w.newConnectionDisruptionSampler.GetLocator()),
-					monitorapi.IsErrorEvent,
-				),
-			),
+			disruptedIntervals,
 			jobType,
-		),
+		), monitorapi.IsEventForLocator(w.newConnectionDisruptionSampler.GetLocator()), finalIntervals),
 		nil
 }
 
@@ -204,43 +332,253 @@ func (w *Availability) junitForReusedConnections(ctx context.Context, finalInter
 	if err != nil {
 		return nil, fmt.Errorf("unable to get reused allowed disruption: %w", err)
 	}
-	return createDisruptionJunit(
+	disruptedIntervals := finalIntervals.Filter(
+		monitorapi.And(
+			monitorapi.IsEventForLocator(w.reusedConnectionDisruptionSampler.GetLocator()),
+			monitorapi.IsErrorEvent,
+		),
+	)
+	if policyJunit := policyDisruptionJunit(
+		w.policy, w.reusedConnectionTestName, w.reusedConnectionDisruptionSampler.GetDisruptionBackendName(), w.reusedConnectionDisruptionSampler.GetLocator(),
+		reusedConnectionAllowed != nil, disruptedIntervals, jobType, w.runDuration()); policyJunit != nil {
+		return addDisruptionCauses(policyJunit, monitorapi.IsEventForLocator(w.reusedConnectionDisruptionSampler.GetLocator()), finalIntervals), nil
+	}
+	return addDisruptionCauses(createDisruptionJunit(
 			w.reusedConnectionTestName, reusedConnectionAllowed, reusedConnectionDisruptionDetails, w.reusedConnectionDisruptionSampler.GetLocator(),
-			finalIntervals.Filter(
-				monitorapi.And(
-					monitorapi.IsEventForLocator(w.reusedConnectionDisruptionSampler.GetLocator()),
-					monitorapi.IsErrorEvent,
-				),
-			),
+			disruptedIntervals,
 			jobType,
-		),
+		), monitorapi.IsEventForLocator(w.reusedConnectionDisruptionSampler.GetLocator()), finalIntervals),
 		nil
 }
 
-func historicalAllowedDisruption(ctx context.Context, backend *backenddisruption.BackendSampler, jobType *platformidentification.JobType) (*time.Duration, string, error) {
+// addDisruptionCauses appends the disruption of the backend by likely cause to the output of its junit, so that trends
+// can be tracked by cause and not only by backend.  The causes are the intervals of the backend constructed by the
+// disruption summary serializer.
+func addDisruptionCauses(junit *junitapi.JUnitTestCase, isBackend monitorapi.EventIntervalMatchesFunc, finalIntervals monitorapi.Intervals) *junitapi.JUnitTestCase {
+	if junit == nil || junit.SkipMessage != nil {
+		return junit
+	}
+	causeIntervals := finalIntervals.Filter(monitorapi.And(isBackend, disruptioncause.IsCause))
+	if len(causeIntervals) == 0 {
+		return junit
+	}
+	causes := fmt.Sprintf("disruption by likely cause:\n%s", disruptioncause.Summary(disruptioncause.Totals(causeIntervals)))
+	if len(junit.SystemOut) > 0 {
+		junit.SystemOut += "\n\n"
+	}
+	junit.SystemOut += causes
+	if junit.FailureOutput != nil {
+		junit.FailureOutput.Output += "\n\n" + causes
+	}
+	return junit
+}
+
+// runDuration is the length of the run, or 0 if it is not known yet.
+func (w *Availability) runDuration() time.Duration {
+	if w.startTime.IsZero() || !w.endTime.After(w.startTime) {
+		return 0
+	}
+	return w.endTime.Sub(w.startTime)
+}
+
+// policyDisruptionJunit returns the result of the budget of policy for backendName, or nil if there is no policy, no
+// budget for the backend or the policy leaves it to historical data.  Budgets relative to the run are skipped when
+// runDuration is 0.
+func policyDisruptionJunit(
+	policy *disruptionpolicy.Policy,
+	testName, backendName string,
+	locator monitorapi.Locator,
+	hasHistoricalData bool,
+	disruptedIntervals monitorapi.Intervals,
+	jobType *platformidentification.JobType,
+	runDuration time.Duration) *junitapi.JUnitTestCase {
+
+	budget := policy.BudgetFor(backendName, *jobType)
+	if budget == nil || !policy.Applies(hasHistoricalData) {
+		return nil
+	}
+
+	violations := budget.Evaluate(disruptedIntervals, runDuration)
+	if len(violations) == 0 {
+		return &junitapi.JUnitTestCase{
+			Name:      testName,
+			SystemOut: fmt.Sprintf("allowed disruption from %s", policy.Provenance(budget)),
+		}
+	}
+
+	failureMessage := fmt.Sprintf("%v was unreachable during disruption beyond %s:\n%s\n\n%s",
+		locator.OldLocator(), policy.Provenance(budget),
+		strings.Join(violations, "\n"),
+		strings.Join(disruptedIntervals.Strings(), "\n"))
+	return &junitapi.JUnitTestCase{
+		Name: testName,
+		FailureOutput: &junitapi.FailureOutput{
+			Output: failureMessage,
+		},
+		SystemOut: failureMessage,
+	}
+}
+
+func historicalAllowedDisruption(ctx context.Context, backend Sampler, jobType *platformidentification.JobType) (*time.Duration, string, error) {
 	return allowedbackenddisruption.GetAllowedDisruption(backend.GetDisruptionBackendName(), *jobType)
 }
 
+// BackendAvailabilityJunit evaluates the disruption of a backend that is not sampled by an Availability, such as one
+// sampled by pods inside the cluster, against policy, which may be nil, or the historical data of its backend, like an
+// Availability does.  Overlapping disruption of the backend is merged before it is totaled.  runDuration is the
+// length of the run, for the budgets relative to it.
+func BackendAvailabilityJunit(
+	policy *disruptionpolicy.Policy,
+	testName, backendName string,
+	locator monitorapi.Locator,
+	finalIntervals monitorapi.Intervals,
+	jobType *platformidentification.JobType,
+	runDuration time.Duration) (*junitapi.JUnitTestCase, error) {
+
+	allowed, disruptionDetails, err := allowedbackenddisruption.GetAllowedDisruption(backendName, *jobType)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get allowed disruption for %s: %w", backendName, err)
+	}
+	// the samplers on every node record to the same backend, an outage they all saw is only counted once
+	disruptedIntervals := finalIntervals.Filter(
+		monitorapi.And(
+			monitorapi.IsEventForBackendDisruptionName(backendName),
+			monitorapi.IsErrorEvent,
+		),
+	).Union()
+	if policyJunit := policyDisruptionJunit(policy, testName, backendName, locator, allowed != nil, disruptedIntervals, jobType, runDuration); policyJunit != nil {
+		return addDisruptionCauses(policyJunit, monitorapi.IsEventForBackendDisruptionName(backendName), finalIntervals), nil
+	}
+	return addDisruptionCauses(createDisruptionJunit(
+			testName, allowed, disruptionDetails, locator,
+			disruptedIntervals,
+			jobType,
+		), monitorapi.IsEventForBackendDisruptionName(backendName), finalIntervals),
+		nil
+}
+
 func (w *Availability) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
 	if w == nil {
 		return nil, fmt.Errorf("unable to evaluate tests because instance is nil")
 	}
 
+	jobType := w.offlineJobType
+	if jobType == nil {
+		if w.adminRESTConfig == nil {
+			return nil, &monitortestframework.NotSupportedError{Reason: "the job type is not known without a cluster or saved cluster data"}
+		}
+		var err error
+		if jobType, err = platformidentification.GetJobType(ctx, w.adminRESTConfig); err != nil {
+			return nil, err
+		}
+	}
+	if w.startTime.IsZero() {
+		// a replayed run, budgets relative to the run use the span of its intervals
+		w.startTime, w.endTime = intervalBounds(finalIntervals)
+	}
+
 	var err error
-	jobType, err := platformidentification.GetJobType(ctx, w.adminRESTConfig)
-	if err != nil {
-		return nil, err
+	newConnectionJunit := notSampledJunit(w.newConnectionTestName, w.newConnectionDisruptionSampler, finalIntervals)
+	if newConnectionJunit == nil {
+		if newConnectionJunit, err = w.junitForNewConnections(ctx, finalIntervals, jobType); err != nil {
+			return nil, err
+		}
 	}
 
-	newConnectionJunit, err := w.junitForNewConnections(ctx, finalIntervals, jobType)
-	if err != nil {
-		return nil, err
+	reusedConnectionJunit := notSampledJunit(w.reusedConnectionTestName, w.reusedConnectionDisruptionSampler, finalIntervals)
+	if reusedConnectionJunit == nil {
+		if reusedConnectionJunit, err = w.junitForReusedConnections(ctx, finalIntervals, jobType); err != nil {
+			return nil, err
+		}
 	}
 
-	reusedConnectionJunit, err := w.junitForReusedConnections(ctx, finalIntervals, jobType)
-	if err != nil {
-		return nil, err
+	ret := []*junitapi.JUnitTestCase{newConnectionJunit, reusedConnectionJunit}
+	ret = append(ret, latencyJunits(w.newConnectionTestName, w.newConnectionDisruptionSampler, finalIntervals, jobType)...)
+	ret = append(ret, latencyJunits(w.reusedConnectionTestName, w.reusedConnectionDisruptionSampler, finalIntervals, jobType)...)
+	return ret, nil
+}
+
+// notSampledJunit finds the intervals of a backend of a replayed run.  It returns a skipped test if the backend
+// recorded none, because it was not sampled during the run, and nil otherwise.
+func notSampledJunit(testName string, sampler Sampler, finalIntervals monitorapi.Intervals) *junitapi.JUnitTestCase {
+	recorded, ok := sampler.(*recordedSampler)
+	if !ok {
+		return nil
+	}
+	if recorded.findRecorded(finalIntervals); recorded.recorded {
+		return nil
+	}
+	return NotSampledJunit(testName, recorded.backendName)
+}
+
+// NotSampledJunit skips the test of a backend that WasSampled reports no intervals for.
+func NotSampledJunit(testName, backendName string) *junitapi.JUnitTestCase {
+	return &junitapi.JUnitTestCase{
+		Name: testName,
+		SkipMessage: &junitapi.SkipMessage{
+			Message: fmt.Sprintf("%s was not sampled during the replayed run", backendName),
+		},
+	}
+}
+
+// intervalBounds returns the earliest start and the latest end of intervals.
+func intervalBounds(intervals monitorapi.Intervals) (time.Time, time.Time) {
+	var beginning, end time.Time
+	for _, interval := range intervals {
+		if !interval.From.IsZero() && (beginning.IsZero() || interval.From.Before(beginning)) {
+			beginning = interval.From
+		}
+		if interval.To.After(end) {
+			end = interval.To
+		}
+	}
+	return beginning, end
+}
+
+// latencyJunits returns the result of the latency SLO of the backend of sampler, or nothing if the sampler does not
+// track latency.  Degraded latency flakes the test, except during upgrades where being degraded for longer than an
+// SLO set for the sampler allows fails it.  The default SLO is not a commitment of the backend and only ever flakes.
+func latencyJunits(availabilityTestName string, sampler Sampler, finalIntervals monitorapi.Intervals, jobType *platformidentification.JobType) []*junitapi.JUnitTestCase {
+	withSLO, ok := sampler.(latencySampler)
+	if !ok {
+		return nil
+	}
+	slo := withSLO.GetLatencySLO()
+	testName := latencyTestName(availabilityTestName)
+
+	degradedIntervals := finalIntervals.Filter(
+		monitorapi.And(
+			monitorapi.IsEventForLocator(sampler.GetLocator()),
+			backenddisruption.IsLatencyDegraded,
+		),
+	)
+	if len(degradedIntervals) == 0 {
+		return []*junitapi.JUnitTestCase{{Name: testName}}
 	}
 
-	return []*junitapi.JUnitTestCase{newConnectionJunit, reusedConnectionJunit}, nil
+	degradedDuration := degradedIntervals.Duration(1 * time.Second).Round(time.Second)
+	isUpgrade := len(jobType.FromRelease) > 0
+	failureMessage := fmt.Sprintf("%v responded slower than %s for at least %s during %d periods of %s or more (maxAllowedDuringUpgrade=%s):\n%s",
+		sampler.GetLocator().OldLocator(), slo.Threshold, degradedDuration, len(degradedIntervals), slo.SustainedFor,
+		slo.MaxDegradedDuringUpgrade, strings.Join(degradedIntervals.Strings(), "\n"))
+	failure := &junitapi.JUnitTestCase{
+		Name: testName,
+		FailureOutput: &junitapi.FailureOutput{
+			Output: failureMessage,
+		},
+		SystemOut: failureMessage,
+	}
+	if isUpgrade && withSLO.HasLatencySLO() && degradedDuration > slo.MaxDegradedDuringUpgrade {
+		return []*junitapi.JUnitTestCase{failure}
+	}
+	// flake
+	return []*junitapi.JUnitTestCase{failure, {Name: testName}}
+}
+
+// latencyTestName names the latency test after the availability test of the same sampler.
+func latencyTestName(availabilityTestName string) string {
+	if prefix, ok := strings.CutSuffix(availabilityTestName, "should be available throughout the test"); ok {
+		return prefix + "should respond within its latency SLO throughout the test"
+	}
+	return availabilityTestName + " within its latency SLO"
 }
@@ -0,0 +1,99 @@
+package disruptionlibrary
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionpolicy"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+)
+
+func TestBackendAvailabilityJunitAppliesPolicy(t *testing.T) {
+	policy, err := disruptionpolicy.ParsePolicy([]byte(`
+precedence: Override
+budgets:
+- name: echo
+  backends: ["echo-*"]
+  maxSingleOutageSeconds: 5
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	beginning := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	outage := func(backendName string, seconds int) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceDisruption, monitorapi.Error).
+			Locator(monitorapi.NewLocator().LocateDisruptionCheck(backendName, "node-1", monitorapi.NewConnectionType)).
+			Message(monitorapi.NewMessage().Reason(monitorapi.DisruptionBeganEventReason).HumanMessage("stopped responding")).
+			Build(beginning, beginning.Add(time.Duration(seconds)*time.Second))
+	}
+	jobType := &platformidentification.JobType{Release: "4.18", Platform: "metal", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+
+	tests := []struct {
+		name          string
+		outageSeconds int
+		expectFailure bool
+	}{
+		{name: "within budget", outageSeconds: 3},
+		{name: "over budget", outageSeconds: 10, expectFailure: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			locator := monitorapi.NewLocator().LocateDisruptionCheck("echo-new-connections", "", monitorapi.NewConnectionType)
+			finalIntervals := monitorapi.Intervals{
+				outage("echo-new-connections", test.outageSeconds),
+				// another backend does not count
+				outage("other-new-connections", 60),
+			}
+			junit, err := BackendAvailabilityJunit(policy, "echo should be available", "echo-new-connections", locator, finalIntervals, jobType, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if junit.SkipMessage != nil {
+				t.Fatalf("expected the policy to apply without historical data, got skipped: %s", junit.SkipMessage.Message)
+			}
+			if failed := junit.FailureOutput != nil; failed != test.expectFailure {
+				t.Fatalf("expected failure %v, got %v: %s", test.expectFailure, failed, junit.SystemOut)
+			}
+			if !strings.Contains(junit.SystemOut, `budget "echo" of disruption policy`) {
+				t.Errorf("expected the provenance of the budget in the output, got %q", junit.SystemOut)
+			}
+		})
+	}
+}
+
+func TestBackendAvailabilityJunitCountsOverlappingOutagesOnce(t *testing.T) {
+	policy, err := disruptionpolicy.ParsePolicy([]byte(`
+precedence: Override
+budgets:
+- name: echo
+  backends: ["echo-*"]
+  maxSeconds: 15
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	beginning := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	finalIntervals := monitorapi.Intervals{}
+	// the same 10s outage seen from three nodes, each a little later than the last
+	for i, node := range []string{"node-1", "node-2", "node-3"} {
+		from := beginning.Add(time.Duration(i) * time.Second)
+		finalIntervals = append(finalIntervals, monitorapi.NewInterval(monitorapi.SourceDisruption, monitorapi.Error).
+			Locator(monitorapi.NewLocator().LocateDisruptionCheck("echo-new-connections", node, monitorapi.NewConnectionType)).
+			Message(monitorapi.NewMessage().Reason(monitorapi.DisruptionBeganEventReason).HumanMessage("stopped responding")).
+			Build(from, from.Add(10*time.Second)))
+	}
+	jobType := &platformidentification.JobType{Release: "4.18", Platform: "metal", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+
+	locator := monitorapi.NewLocator().LocateDisruptionCheck("echo-new-connections", "", monitorapi.NewConnectionType)
+	junit, err := BackendAvailabilityJunit(policy, "echo should be available", "echo-new-connections", locator, finalIntervals, jobType, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if junit.FailureOutput != nil {
+		t.Fatalf("expected the 12s the backend was down to be within the budget, got: %s", junit.FailureOutput.Output)
+	}
+}
@@ -0,0 +1,214 @@
+package disruptionpolicy
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"sigs.k8s.io/yaml"
+)
+
+// Precedence decides between a budget of the policy and historical data for the same backend.
+type Precedence string
+
+const (
+	// PrecedenceOverride uses the budget even when there is historical data.
+	PrecedenceOverride Precedence = "Override"
+	// PrecedenceSupplement only uses the budget when there is not enough historical data.
+	PrecedenceSupplement Precedence = "Supplement"
+)
+
+// Policy declares how much disruption backends may see in a run, for clusters that do not have historical data or
+// should not be held to it.
+//
+//	precedence: Supplement
+//	budgets:
+//	- name: fast-api
+//	  backends: ["kube-api-*", "openshift-api-*"]
+//	  jobType:
+//	    platform: metal
+//	  maxSeconds: 10
+//	  maxSingleOutageSeconds: 3
+type Policy struct {
+	Precedence Precedence `json:"precedence"`
+	// Budgets are matched in order and the first one that matches a backend and job type is used, so list specific
+	// budgets before general ones.
+	Budgets []Budget `json:"budgets"`
+
+	// Source is the file the policy was read from.
+	Source string `json:"-"`
+}
+
+// Budget is the disruption allowed for some backends.  Every limit that is set must hold.
+type Budget struct {
+	Name string `json:"name"`
+	// Backends are disruption backend names.  A trailing * matches names with the prefix, no backends match all of
+	// them.
+	Backends []string     `json:"backends,omitempty"`
+	JobType  JobTypeMatch `json:"jobType,omitempty"`
+
+	// MaxSeconds limits the total disruption of the run.
+	MaxSeconds *float64 `json:"maxSeconds,omitempty"`
+	// MaxPercentOfRun limits the total disruption relative to the length of the run.
+	MaxPercentOfRun *float64 `json:"maxPercentOfRun,omitempty"`
+	// MaxSingleOutageSeconds limits the longest disruption.
+	MaxSingleOutageSeconds *float64 `json:"maxSingleOutageSeconds,omitempty"`
+}
+
+// JobTypeMatch selects job types.  Empty fields match everything.
+type JobTypeMatch struct {
+	Release      string `json:"release,omitempty"`
+	FromRelease  string `json:"fromRelease,omitempty"`
+	Platform     string `json:"platform,omitempty"`
+	Architecture string `json:"architecture,omitempty"`
+	Network      string `json:"network,omitempty"`
+	Topology     string `json:"topology,omitempty"`
+}
+
+// ReadPolicy reads and validates a policy file.
+func ReadPolicy(filename string) (*Policy, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := ParsePolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	policy.Source = filename
+	return policy, nil
+}
+
+// ParsePolicy reads and validates a policy.  Unknown fields are errors so that a misspelled limit is not ignored.
+func ParsePolicy(data []byte) (*Policy, error) {
+	policy := &Policy{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, err
+	}
+	if len(policy.Precedence) == 0 {
+		policy.Precedence = PrecedenceSupplement
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Validate checks the precedence and that every budget has a name and a limit.
+func (p *Policy) Validate() error {
+	if p.Precedence != PrecedenceOverride && p.Precedence != PrecedenceSupplement {
+		return fmt.Errorf("unknown precedence %q, expected %q or %q", p.Precedence, PrecedenceOverride, PrecedenceSupplement)
+	}
+	names := map[string]bool{}
+	for i, budget := range p.Budgets {
+		if len(budget.Name) == 0 {
+			return fmt.Errorf("budget %d has no name", i)
+		}
+		if names[budget.Name] {
+			return fmt.Errorf("budget %q is declared twice", budget.Name)
+		}
+		names[budget.Name] = true
+		if budget.MaxSeconds == nil && budget.MaxPercentOfRun == nil && budget.MaxSingleOutageSeconds == nil {
+			return fmt.Errorf("budget %q has no limit", budget.Name)
+		}
+		for _, limit := range []*float64{budget.MaxSeconds, budget.MaxPercentOfRun, budget.MaxSingleOutageSeconds} {
+			if limit != nil && *limit < 0 {
+				return fmt.Errorf("budget %q has a negative limit", budget.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// BudgetFor returns the first budget that matches the backend and job type, or nil.  A nil policy has no budgets.
+func (p *Policy) BudgetFor(backendName string, jobType platformidentification.JobType) *Budget {
+	if p == nil {
+		return nil
+	}
+	for i := range p.Budgets {
+		if p.Budgets[i].matches(backendName, jobType) {
+			return &p.Budgets[i]
+		}
+	}
+	return nil
+}
+
+// Applies returns true if a budget of the policy is used rather than historical data.
+func (p *Policy) Applies(hasHistoricalData bool) bool {
+	return p.Precedence == PrecedenceOverride || !hasHistoricalData
+}
+
+// Provenance describes where a budget comes from for junit messages.
+func (p *Policy) Provenance(budget *Budget) string {
+	source := p.Source
+	if len(source) == 0 {
+		source = "inline"
+	}
+	return fmt.Sprintf("budget %q of disruption policy %s (precedence %s)", budget.Name, source, p.Precedence)
+}
+
+func (b *Budget) matches(backendName string, jobType platformidentification.JobType) bool {
+	if !b.JobType.matches(jobType) {
+		return false
+	}
+	if len(b.Backends) == 0 {
+		return true
+	}
+	for _, backend := range b.Backends {
+		if prefix, ok := strings.CutSuffix(backend, "*"); ok && strings.HasPrefix(backendName, prefix) {
+			return true
+		}
+		if backend == backendName {
+			return true
+		}
+	}
+	return false
+}
+
+func (m JobTypeMatch) matches(jobType platformidentification.JobType) bool {
+	for _, field := range []struct{ want, actual string }{
+		{m.Release, jobType.Release},
+		{m.FromRelease, jobType.FromRelease},
+		{m.Platform, jobType.Platform},
+		{m.Architecture, jobType.Architecture},
+		{m.Network, jobType.Network},
+		{m.Topology, jobType.Topology},
+	} {
+		if len(field.want) > 0 && field.want != field.actual {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluate returns the limits of the budget that disrupted, the error intervals of a backend, exceeds.  Disruption is
+// counted the way the historical data counts it, at least a second for every interval.  The percent limit is only
+// checked when the length of the run is known.
+func (b *Budget) Evaluate(disrupted monitorapi.Intervals, runDuration time.Duration) []string {
+	violations := []string{}
+	total := disrupted.Duration(1 * time.Second).Round(time.Second)
+	if b.MaxSeconds != nil && total.Seconds() > *b.MaxSeconds {
+		violations = append(violations, fmt.Sprintf("disrupted for %s, more than the budget of %vs", total, *b.MaxSeconds))
+	}
+	if b.MaxPercentOfRun != nil && runDuration > 0 {
+		percent := 100 * total.Seconds() / runDuration.Seconds()
+		if percent > *b.MaxPercentOfRun {
+			violations = append(violations, fmt.Sprintf("disrupted for %.2f%% of the %s run, more than the budget of %v%%", percent, runDuration.Round(time.Second), *b.MaxPercentOfRun))
+		}
+	}
+	if b.MaxSingleOutageSeconds != nil {
+		longest := time.Duration(0)
+		for _, interval := range disrupted {
+			if curr := interval.To.Sub(interval.From); curr > longest {
+				longest = curr
+			}
+		}
+		if longest.Round(time.Second).Seconds() > *b.MaxSingleOutageSeconds {
+			violations = append(violations, fmt.Sprintf("longest outage was %s, more than the budget of %vs", longest.Round(time.Second), *b.MaxSingleOutageSeconds))
+		}
+	}
+	return violations
+}
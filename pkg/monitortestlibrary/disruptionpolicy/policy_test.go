@@ -0,0 +1,108 @@
+package disruptionpolicy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+precedence: Override
+budgets:
+- name: metal-api
+  backends: ["kube-api-*", "openshift-api-new-connections"]
+  jobType:
+    platform: metal
+  maxSeconds: 10
+  maxSingleOutageSeconds: 3
+- name: everything-else
+  maxPercentOfRun: 1
+`
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicy))
+	require.NoError(t, err)
+	assert.Equal(t, PrecedenceOverride, policy.Precedence)
+	require.Len(t, policy.Budgets, 2)
+
+	for _, invalid := range []struct {
+		policy string
+		err    string
+	}{
+		{policy: "precedence: Sometimes", err: `unknown precedence "Sometimes"`},
+		{policy: "budgets:\n- name: a\n  maxSeconds: 1\n- name: a\n  maxSeconds: 1", err: `budget "a" is declared twice`},
+		{policy: "budgets:\n- name: a", err: `budget "a" has no limit`},
+		{policy: "budgets:\n- name: a\n  maxSecond: 1", err: `unknown field "maxSecond"`},
+	} {
+		_, err := ParsePolicy([]byte(invalid.policy))
+		if assert.Error(t, err, invalid.policy) {
+			assert.Contains(t, err.Error(), invalid.err)
+		}
+	}
+
+	policy, err = ParsePolicy([]byte("budgets: []"))
+	require.NoError(t, err)
+	assert.Equal(t, PrecedenceSupplement, policy.Precedence, "default precedence")
+	assert.True(t, policy.Applies(false))
+	assert.False(t, policy.Applies(true))
+}
+
+func TestBudgetFor(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicy))
+	require.NoError(t, err)
+
+	metal := platformidentification.JobType{Platform: "metal", Topology: "ha"}
+	aws := platformidentification.JobType{Platform: "aws", Topology: "ha"}
+	tests := []struct {
+		backend  string
+		jobType  platformidentification.JobType
+		expected string
+	}{
+		{backend: "kube-api-new-connections", jobType: metal, expected: "metal-api"},
+		{backend: "openshift-api-new-connections", jobType: metal, expected: "metal-api"},
+		{backend: "openshift-api-reused-connections", jobType: metal, expected: "everything-else"},
+		{backend: "kube-api-new-connections", jobType: aws, expected: "everything-else"},
+	}
+	for _, test := range tests {
+		budget := policy.BudgetFor(test.backend, test.jobType)
+		if assert.NotNil(t, budget, test.backend) {
+			assert.Equal(t, test.expected, budget.Name, test.backend)
+		}
+	}
+
+	var noPolicy *Policy
+	assert.Nil(t, noPolicy.BudgetFor("kube-api-new-connections", metal))
+}
+
+func TestEvaluate(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicy))
+	require.NoError(t, err)
+	metalAPI, everythingElse := &policy.Budgets[0], &policy.Budgets[1]
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	outage := func(from, seconds int) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceDisruption, monitorapi.Error).
+			Locator(monitorapi.NewLocator().DisruptionRequiredOnly("kube-api-new-connections", "kube-api-new-connections")).
+			Message(monitorapi.NewMessage().Reason(monitorapi.DisruptionBeganEventReason)).
+			Build(start.Add(time.Duration(from)*time.Second), start.Add(time.Duration(from+seconds)*time.Second))
+	}
+
+	assert.Empty(t, metalAPI.Evaluate(monitorapi.Intervals{outage(0, 3), outage(10, 3)}, time.Hour))
+	assert.Equal(t, []string{
+		"disrupted for 11s, more than the budget of 10s",
+		"longest outage was 4s, more than the budget of 3s",
+	}, metalAPI.Evaluate(monitorapi.Intervals{outage(0, 4), outage(10, 3), outage(20, 4)}, time.Hour))
+
+	assert.Empty(t, everythingElse.Evaluate(monitorapi.Intervals{outage(0, 30)}, time.Hour))
+	violations := everythingElse.Evaluate(monitorapi.Intervals{outage(0, 60)}, time.Hour)
+	require.Len(t, violations, 1)
+	assert.True(t, strings.HasPrefix(violations[0], "disrupted for 1.67% of the 1h0m0s run"), violations[0])
+	assert.Empty(t, everythingElse.Evaluate(monitorapi.Intervals{outage(0, 60)}, 0), "unknown run length")
+
+	assert.Equal(t, `budget "metal-api" of disruption policy inline (precedence Override)`, policy.Provenance(metalAPI))
+}
@@ -0,0 +1,295 @@
+package historicaldata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// JobRunData is what one job run contributes to historical data, read from the artifacts it wrote.
+type JobRunData struct {
+	Dir                string
+	JobType            platformidentification.JobType
+	MasterNodesUpdated string
+
+	// Disruption is the disruption of the run by backend name.
+	Disruption map[string]time.Duration
+	// Alerts is how long each alert fired during the run.
+	Alerts map[AlertDataKey]time.Duration
+}
+
+// DisruptionQueryResult is an entry of the disruption query results read by NewDisruptionMatcher.
+type DisruptionQueryResult struct {
+	DataKey            `json:",inline"`
+	MasterNodesUpdated string
+	JobRuns            int64
+	P95                string
+	P99                string
+	P75                string
+	P50                string
+}
+
+// AlertQueryResult is an entry of the alert query results read by NewAlertMatcher.
+type AlertQueryResult struct {
+	AlertDataKey `json:",inline"`
+	JobRuns      int64
+	P95          string
+	P99          string
+	P75          string
+	P50          string
+}
+
+// these mirror the files written by the disruption and alert serializer monitor tests, which cannot be imported here.
+type backendDisruptionFile struct {
+	BackendDisruptions map[string]*struct {
+		BackendName       string
+		DisruptedDuration metav1.Duration
+	}
+}
+
+type alertsFile struct {
+	Alerts []struct {
+		Name      string
+		Namespace string
+		Level     string
+		Duration  metav1.Duration
+	}
+}
+
+// ReadJobRunDataFromDirs finds the job runs under root.  A job run is a directory with a cluster-data_*.json, its
+// backend-disruption_*.json and alerts_*.json are read from the same directory and summed if there are several.
+func ReadJobRunDataFromDirs(root string) ([]JobRunData, error) {
+	dirs := []string{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		matches, err := filepath.Glob(filepath.Join(path, "cluster-data*.json"))
+		if err != nil {
+			return err
+		}
+		if len(matches) > 0 {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []JobRunData{}
+	for _, dir := range dirs {
+		jobRun, err := ReadJobRunDataFromDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, *jobRun)
+	}
+	return ret, nil
+}
+
+// ReadJobRunDataFromDir reads the artifacts of the job run in dir.
+func ReadJobRunDataFromDir(dir string) (*JobRunData, error) {
+	clusterData, err := platformidentification.ReadClusterDataFromDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	ret := &JobRunData{
+		Dir:                dir,
+		JobType:            clusterData.JobType,
+		MasterNodesUpdated: clusterData.MasterNodesUpdated,
+		Disruption:         map[string]time.Duration{},
+		Alerts:             map[AlertDataKey]time.Duration{},
+	}
+
+	disruptionFiles, err := filepath.Glob(filepath.Join(dir, "backend-disruption*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, filename := range disruptionFiles {
+		disruption := &backendDisruptionFile{}
+		if err := readJSONFile(filename, disruption); err != nil {
+			return nil, err
+		}
+		for name, backend := range disruption.BackendDisruptions {
+			if backend == nil {
+				continue
+			}
+			ret.Disruption[name] += backend.DisruptedDuration.Duration
+		}
+	}
+
+	alertFiles, err := filepath.Glob(filepath.Join(dir, "alerts*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, filename := range alertFiles {
+		alerts := &alertsFile{}
+		if err := readJSONFile(filename, alerts); err != nil {
+			return nil, err
+		}
+		for _, alert := range alerts.Alerts {
+			key := AlertDataKey{
+				AlertName:      alert.Name,
+				AlertNamespace: alert.Namespace,
+				AlertLevel:     alert.Level,
+				JobType:        clusterData.JobType,
+			}
+			ret.Alerts[key] += alert.Duration.Duration
+		}
+	}
+	return ret, nil
+}
+
+func readJSONFile(filename string, into interface{}) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, into); err != nil {
+		return fmt.Errorf("unable to parse %q: %w", filename, err)
+	}
+	return nil
+}
+
+// ComputeDisruptionData returns the percentiles of the disruption of every backend and job type in jobRuns, sorted by
+// key.  Like the query results, runs that updated master nodes and runs that did not are in separate rows.
+func ComputeDisruptionData(jobRuns []JobRunData) []DisruptionQueryResult {
+	type groupKey struct {
+		DataKey
+		masterNodesUpdated string
+	}
+	seconds := map[groupKey][]float64{}
+	for _, jobRun := range jobRuns {
+		for backendName, disruption := range jobRun.Disruption {
+			key := groupKey{
+				DataKey:            DataKey{BackendName: backendName, JobType: jobRun.JobType},
+				masterNodesUpdated: jobRun.MasterNodesUpdated,
+			}
+			seconds[key] = append(seconds[key], disruption.Seconds())
+		}
+	}
+
+	ret := []DisruptionQueryResult{}
+	for key, values := range seconds {
+		p := percentiles(values)
+		ret = append(ret, DisruptionQueryResult{
+			DataKey:            key.DataKey,
+			MasterNodesUpdated: key.masterNodesUpdated,
+			JobRuns:            int64(len(values)),
+			P95:                p[95],
+			P99:                p[99],
+			P75:                p[75],
+			P50:                p[50],
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].DataKey != ret[j].DataKey {
+			return lessJobType(ret[i].BackendName, ret[i].JobType, ret[j].BackendName, ret[j].JobType)
+		}
+		return ret[i].MasterNodesUpdated < ret[j].MasterNodesUpdated
+	})
+	return ret
+}
+
+// ComputeAlertData returns the percentiles of how long every alert fired by job type in jobRuns, sorted by key.  An
+// alert that fired in some runs of a job type counts as firing for 0s in the runs of that job type it did not fire in,
+// so that the percentiles are over every run of the job type, like those of the queries.
+// Alert levels are lowercased, the alerts files hold "Warning" and "Critical" while the alert tests look up "warning"
+// and "critical".
+func ComputeAlertData(jobRuns []JobRunData) []AlertQueryResult {
+	jobTypeRuns := map[platformidentification.JobType][]map[AlertDataKey]time.Duration{}
+	for _, jobRun := range jobRuns {
+		alerts := map[AlertDataKey]time.Duration{}
+		for key, duration := range jobRun.Alerts {
+			key.AlertLevel = strings.ToLower(key.AlertLevel)
+			alerts[key] += duration
+		}
+		jobTypeRuns[jobRun.JobType] = append(jobTypeRuns[jobRun.JobType], alerts)
+	}
+
+	seconds := map[AlertDataKey][]float64{}
+	for _, runs := range jobTypeRuns {
+		keys := map[AlertDataKey]bool{}
+		for _, alerts := range runs {
+			for key := range alerts {
+				keys[key] = true
+			}
+		}
+		for key := range keys {
+			for _, alerts := range runs {
+				// missing alerts are 0
+				seconds[key] = append(seconds[key], alerts[key].Seconds())
+			}
+		}
+	}
+
+	ret := []AlertQueryResult{}
+	for key, values := range seconds {
+		p := percentiles(values)
+		ret = append(ret, AlertQueryResult{
+			AlertDataKey: key,
+			JobRuns:      int64(len(values)),
+			P95:          p[95],
+			P99:          p[99],
+			P75:          p[75],
+			P50:          p[50],
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		iName := ret[i].AlertName + "/" + ret[i].AlertNamespace + "/" + ret[i].AlertLevel
+		jName := ret[j].AlertName + "/" + ret[j].AlertNamespace + "/" + ret[j].AlertLevel
+		return lessJobType(iName, ret[i].JobType, jName, ret[j].JobType)
+	})
+	return ret
+}
+
+func lessJobType(iName string, i platformidentification.JobType, jName string, j platformidentification.JobType) bool {
+	iKey := []string{iName, i.Release, i.FromRelease, i.Platform, i.Architecture, i.Network, i.Topology}
+	jKey := []string{jName, j.Release, j.FromRelease, j.Platform, j.Architecture, j.Network, j.Topology}
+	for k := range iKey {
+		if iKey[k] != jKey[k] {
+			return iKey[k] < jKey[k]
+		}
+	}
+	return false
+}
+
+// percentiles returns the 50th, 75th, 95th and 99th percentiles of values, interpolated the way the PERCENTILE_CONT
+// of the queries that produce the historical data does, formatted the way the query results have them to the
+// millisecond.
+func percentiles(values []float64) map[int]string {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	ret := map[int]string{}
+	for _, p := range []int{50, 75, 95, 99} {
+		ret[p] = strconv.FormatFloat(math.Round(percentileCont(sorted, float64(p)/100)*1000)/1000, 'f', -1, 64)
+	}
+	return ret
+}
+
+func percentileCont(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	position := p * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+}
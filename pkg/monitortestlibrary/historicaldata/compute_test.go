@@ -0,0 +1,103 @@
+package historicaldata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeHistoricalData(t *testing.T) {
+	root := t.TempDir()
+	aws := platformidentification.JobType{Release: "4.18", Platform: "aws", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+	gcp := platformidentification.JobType{Release: "4.18", Platform: "gcp", Architecture: "amd64", Network: "ovn", Topology: "ha"}
+
+	writeRun := func(name string, jobType platformidentification.JobType, masterNodesUpdated string, disruptionSeconds, alertSeconds int) {
+		dir := filepath.Join(root, name, "artifacts", "junit")
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		writeJSON := func(filename string, content interface{}) {
+			data, err := json.Marshal(content)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, filename), data, 0644))
+		}
+		writeJSON("cluster-data_20240101-000000.json", platformidentification.ClusterData{JobType: jobType, MasterNodesUpdated: masterNodesUpdated})
+		writeJSON("backend-disruption_20240101-000000.json", map[string]interface{}{
+			"BackendDisruptions": map[string]interface{}{
+				"kube-api-new-connections": map[string]interface{}{
+					"BackendName":       "kube-api-new-connections",
+					"DisruptedDuration": fmt.Sprintf("%ds", disruptionSeconds),
+				},
+			},
+		})
+		alerts := []interface{}{}
+		if alertSeconds >= 0 {
+			alerts = append(alerts, map[string]interface{}{"Name": "KubeAPIErrorBudgetBurn", "Namespace": "openshift-kube-apiserver", "Level": "Warning", "Duration": fmt.Sprintf("%ds", alertSeconds)})
+		}
+		writeJSON("alerts_20240101-000000.json", map[string]interface{}{"Alerts": alerts})
+	}
+	for i := 1; i <= 5; i++ {
+		writeRun(fmt.Sprintf("aws-%d", i), aws, "N", i, 10*i)
+	}
+	// the alert did not fire
+	writeRun("aws-6", aws, "N", 6, -1)
+	// updating master nodes is counted apart
+	writeRun("aws-7", aws, "Y", 60, 0)
+	writeRun("gcp-1", gcp, "N", 7, 0)
+	// not a job run
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "other"), 0755))
+
+	jobRuns, err := ReadJobRunDataFromDirs(root)
+	require.NoError(t, err)
+	require.Len(t, jobRuns, 8)
+
+	disruption := ComputeDisruptionData(jobRuns)
+	require.Len(t, disruption, 3)
+	assert.Equal(t, DisruptionQueryResult{
+		DataKey:            DataKey{BackendName: "kube-api-new-connections", JobType: aws},
+		MasterNodesUpdated: "N",
+		JobRuns:            6,
+		P50:                "3.5",
+		P75:                "4.75",
+		P95:                "5.75",
+		P99:                "5.95",
+	}, disruption[0])
+	assert.Equal(t, DisruptionQueryResult{
+		DataKey:            DataKey{BackendName: "kube-api-new-connections", JobType: aws},
+		MasterNodesUpdated: "Y",
+		JobRuns:            1,
+		P50:                "60",
+		P75:                "60",
+		P95:                "60",
+		P99:                "60",
+	}, disruption[1])
+
+	data, err := json.Marshal(disruption)
+	require.NoError(t, err)
+	matcher, err := NewDisruptionMatcher(data)
+	require.NoError(t, err)
+	assert.Len(t, matcher.HistoricalData, 2)
+	assert.Equal(t, int64(1), matcher.HistoricalData[DataKey{BackendName: "kube-api-new-connections", JobType: gcp}].JobRuns)
+
+	alerts := ComputeAlertData(jobRuns)
+	require.Len(t, alerts, 2)
+	data, err = json.Marshal(alerts)
+	require.NoError(t, err)
+	alertMatcher, err := NewAlertMatcher(data)
+	require.NoError(t, err)
+	// the level is lowercased, like the alert tests look it up
+	key := AlertDataKey{AlertName: "KubeAPIErrorBudgetBurn", AlertNamespace: "openshift-kube-apiserver", AlertLevel: "warning", JobType: aws}
+	assert.Equal(t, 49.4, alertMatcher.HistoricalData[key].P99)
+	assert.Equal(t, int64(7), alertMatcher.HistoricalData[key].JobRuns)
+}
+
+func TestPercentileCont(t *testing.T) {
+	assert.Equal(t, 0.0, percentileCont(nil, 0.99))
+	assert.Equal(t, 7.0, percentileCont([]float64{7}, 0.99))
+	assert.Equal(t, 1.5, percentileCont([]float64{1, 2}, 0.5))
+	assert.InDelta(t, 9.91, percentileCont([]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 0.99), 0.0001)
+}
@@ -0,0 +1,263 @@
+package inclusterdisruption
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// Deployer runs the samplers of backends inside the cluster, in a namespace of their own, and collects the intervals
+// they recorded.  It is driven by a monitor test: Deploy when collection starts, CollectData when it ends and Cleanup.
+type Deployer struct {
+	kubeClient kubernetes.Interface
+	image      string
+	backends   []BackendSpec
+
+	// stopGracePeriod is how long the sampler pods get to write their last intervals after being told to stop.
+	stopGracePeriod time.Duration
+
+	namespaceName string
+}
+
+// NewDeployer returns a deployer of sampler pods for the backends that run the openshift-tests image.  The backends
+// must have been validated.
+func NewDeployer(kubeClient kubernetes.Interface, image string, backends []BackendSpec) *Deployer {
+	return &Deployer{
+		kubeClient: kubeClient,
+		image:      image,
+		backends:   backends,
+
+		// the 30s is just a guess, like for the pod network pollers
+		stopGracePeriod: 30 * time.Second,
+	}
+}
+
+// NamespaceName is the namespace of the sampler pods once they are deployed.
+func (d *Deployer) NamespaceName() string {
+	return d.namespaceName
+}
+
+// Deploy creates the namespace, the RBAC and configuration of the sampler pods and a DaemonSet or Deployment for every
+// backend, depending on its placement.
+func (d *Deployer) Deploy(ctx context.Context) error {
+	actualNamespace, err := d.kubeClient.CoreV1().Namespaces().Create(ctx, samplerNamespace(), metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	d.namespaceName = actualNamespace.Name
+
+	if _, err := d.kubeClient.RbacV1().Roles(d.namespaceName).Create(ctx, samplerRole(), metav1.CreateOptions{}); err != nil {
+		return err
+	}
+	if _, err := d.kubeClient.RbacV1().RoleBindings(d.namespaceName).Create(ctx, samplerRoleBinding(), metav1.CreateOptions{}); err != nil {
+		return err
+	}
+	configMap, err := backendsConfigMap(d.backends)
+	if err != nil {
+		return err
+	}
+	if _, err := d.kubeClient.CoreV1().ConfigMaps(d.namespaceName).Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+
+	for _, spec := range d.backends {
+		switch spec.Placement.Spread {
+		case SpreadPerNode:
+			if _, err := d.kubeClient.AppsV1().DaemonSets(d.namespaceName).Create(ctx, samplerDaemonSet(spec, d.image), metav1.CreateOptions{}); err != nil {
+				return err
+			}
+		case SpreadPerZone:
+			zones, err := d.countZones(ctx, spec.Placement.NodeSelector)
+			if err != nil {
+				return err
+			}
+			if _, err := d.kubeClient.AppsV1().Deployments(d.namespaceName).Create(ctx, samplerDeployment(spec, d.image, zones), metav1.CreateOptions{}); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("backend %q: unknown spread %q", spec.Name, spec.Placement.Spread)
+		}
+	}
+	return nil
+}
+
+// countZones returns the number of zones of the nodes the sampler pods may be scheduled to.
+func (d *Deployer) countZones(ctx context.Context, nodeSelector map[string]string) (int32, error) {
+	nodes, err := d.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(nodeSelector).String(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	zones := sets.New[string]()
+	for _, node := range nodes.Items {
+		if zone := node.Labels[corev1.LabelTopologyZone]; len(zone) > 0 {
+			zones.Insert(zone)
+		}
+	}
+	return int32(zones.Len()), nil
+}
+
+// CollectData stops the sampler pods and reads the intervals from their logs.  There is a junit for every backend
+// that fails if a sampler pod did not record any intervals.
+func (d *Deployer) CollectData(ctx context.Context) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	if len(d.namespaceName) == 0 {
+		return nil, nil, fmt.Errorf("the disruption samplers were not deployed")
+	}
+
+	if _, err := d.kubeClient.CoreV1().ConfigMaps(d.namespaceName).Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: StopConfigMapName},
+	}, metav1.CreateOptions{}); err != nil {
+		return nil, nil, err
+	}
+
+	select {
+	case <-time.After(d.stopGracePeriod):
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+
+	retIntervals := monitorapi.Intervals{}
+	junits := []*junitapi.JUnitTestCase{}
+	errs := []error{}
+	for _, spec := range d.backends {
+		localIntervals, localJunit, localErrs := d.collectBackend(ctx, spec)
+		retIntervals = append(retIntervals, localIntervals...)
+		junits = append(junits, localJunit)
+		errs = append(errs, localErrs...)
+	}
+	return retIntervals, junits, utilerrors.NewAggregate(errs)
+}
+
+func (d *Deployer) collectBackend(ctx context.Context, spec BackendSpec) (monitorapi.Intervals, *junitapi.JUnitTestCase, []error) {
+	logJunit := &junitapi.JUnitTestCase{
+		Name: fmt.Sprintf("[sig-network] can collect %v disruption sampler pod logs", spec.Name),
+	}
+	samplerPods, err := d.kubeClient.CoreV1().Pods(d.namespaceName).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(samplerLabels(spec)).String(),
+	})
+	if err != nil {
+		logJunit.FailureOutput = &junitapi.FailureOutput{Output: err.Error()}
+		return nil, logJunit, []error{err}
+	}
+
+	retIntervals := monitorapi.Intervals{}
+	errs := []error{}
+	buf := &bytes.Buffer{}
+	podsWithoutIntervals := []string{}
+	for _, samplerPod := range samplerPods.Items {
+		fmt.Fprintf(buf, "\n\nLogs for -n %v pod/%v on node/%v\n", samplerPod.Namespace, samplerPod.Name, samplerPod.Spec.NodeName)
+		logStream, err := d.kubeClient.CoreV1().Pods(d.namespaceName).GetLogs(samplerPod.Name, &corev1.PodLogOptions{
+			Container: containerName,
+		}).Stream(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		podIntervals, err := IntervalsFromLog(logStream, samplerPod.Spec.NodeName, buf)
+		logStream.Close()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if len(podIntervals) == 0 {
+			podsWithoutIntervals = append(podsWithoutIntervals, samplerPod.Name)
+		}
+		retIntervals = append(retIntervals, podIntervals...)
+	}
+
+	failures := []string{}
+	if len(podsWithoutIntervals) > 0 {
+		failures = append(failures, fmt.Sprintf("%d pods lacked sampler output: [%v]", len(podsWithoutIntervals), strings.Join(podsWithoutIntervals, ", ")))
+	}
+	if len(samplerPods.Items) == 0 {
+		failures = append(failures, fmt.Sprintf("no pods found for sampler %q", spec.Name))
+	}
+	logJunit.SystemOut = buf.String()
+	if len(failures) > 0 {
+		logJunit.FailureOutput = &junitapi.FailureOutput{
+			Output: strings.Join(failures, "\n"),
+		}
+	}
+	return retIntervals, logJunit, errs
+}
+
+// maxLogLineBytes is the longest sampler log line read, intervals with long messages exceed the default of
+// bufio.Scanner.
+const maxLogLineBytes = 16 * 1024 * 1024
+
+// IntervalsFromLog reads the intervals in the log of a sampler pod that ran on nodeName and copies the log to out.
+// Not all lines are intervals, others are ignored.  The intervals are annotated with the node since the samplers of
+// a backend on every node record to the same disruption backend.
+func IntervalsFromLog(log io.Reader, nodeName string, out io.Writer) (monitorapi.Intervals, error) {
+	ret := monitorapi.Intervals{}
+	scanner := bufio.NewScanner(log)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineBytes)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		fmt.Fprintf(out, "%s\n", line)
+		if len(line) == 0 {
+			continue
+		}
+
+		currInterval, err := monitorserialization.IntervalFromJSON(line)
+		if err != nil {
+			continue
+		}
+		if len(nodeName) > 0 {
+			if currInterval.Message.Annotations == nil {
+				currInterval.Message.Annotations = map[monitorapi.AnnotationKey]string{}
+			}
+			currInterval.Message.Annotations[monitorapi.AnnotationNode] = nodeName
+		}
+		ret = append(ret, *currInterval)
+	}
+	return ret, scanner.Err()
+}
+
+func (d *Deployer) namespaceDeleted(ctx context.Context) (bool, error) {
+	_, err := d.kubeClient.CoreV1().Namespaces().Get(ctx, d.namespaceName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+
+	if err != nil {
+		klog.Errorf("Error checking for deleted namespace: %s, %s", d.namespaceName, err.Error())
+		return false, err
+	}
+
+	return false, nil
+}
+
+// Cleanup deletes the namespace of the sampler pods and waits for it to be gone.
+func (d *Deployer) Cleanup(ctx context.Context) error {
+	if len(d.namespaceName) == 0 {
+		return nil
+	}
+	if err := d.kubeClient.CoreV1().Namespaces().Delete(ctx, d.namespaceName, metav1.DeleteOptions{}); err != nil {
+		return err
+	}
+
+	startTime := time.Now()
+	if err := wait.PollUntilContextTimeout(ctx, 15*time.Second, 20*time.Minute, true, d.namespaceDeleted); err != nil {
+		return err
+	}
+	klog.Infof("Deleting namespace: %s took %.2f seconds", d.namespaceName, time.Since(startTime).Seconds())
+	return nil
+}
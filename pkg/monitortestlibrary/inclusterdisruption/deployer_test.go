@@ -0,0 +1,117 @@
+package inclusterdisruption
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func zonedNode(name, zone string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   name,
+		Labels: map[string]string{corev1.LabelTopologyZone: zone},
+	}}
+}
+
+func TestDeploy(t *testing.T) {
+	specs, err := ParseBackendSpecs([]byte(testBackends))
+	require.NoError(t, err)
+
+	kubeClient := fake.NewSimpleClientset(zonedNode("a", "zone-1"), zonedNode("b", "zone-1"), zonedNode("c", "zone-2"))
+	// the fake client does not generate names
+	kubeClient.PrependReactor("create", "namespaces", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		namespace := action.(clienttesting.CreateAction).GetObject().(*corev1.Namespace)
+		if len(namespace.Name) == 0 {
+			namespace.Name = namespace.GenerateName + "test"
+		}
+		return false, nil, nil
+	})
+
+	deployer := NewDeployer(kubeClient, "openshift-tests-image", specs)
+	deployer.stopGracePeriod = 0
+	ctx := context.Background()
+	require.NoError(t, deployer.Deploy(ctx))
+	namespace := deployer.NamespaceName()
+	assert.Equal(t, "e2e-disruption-sampler-test", namespace)
+
+	_, err = kubeClient.RbacV1().RoleBindings(namespace).Get(ctx, "disruption-sampler", metav1.GetOptions{})
+	assert.NoError(t, err)
+	configMap, err := kubeClient.CoreV1().ConfigMaps(namespace).Get(ctx, backendsConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	deployed, err := ParseBackendSpecs([]byte(configMap.Data[backendsFileName]))
+	require.NoError(t, err)
+	assert.Equal(t, specs, deployed, "the sampler pods read the backends from the configmap")
+
+	daemonSet, err := kubeClient.AppsV1().DaemonSets(namespace).Get(ctx, "pod-to-host-disruption-sampler", metav1.GetOptions{})
+	require.NoError(t, err)
+	podSpec := daemonSet.Spec.Template.Spec
+	assert.Equal(t, "openshift-tests-image", podSpec.Containers[0].Image)
+	assert.Contains(t, podSpec.Containers[0].Command, "--backend=pod-to-host")
+	assert.False(t, podSpec.HostNetwork)
+
+	deployment, err := kubeClient.AppsV1().Deployments(namespace).Get(ctx, "pod-to-dns-disruption-sampler", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *deployment.Spec.Replicas, "a pod in every zone")
+	podSpec = deployment.Spec.Template.Spec
+	assert.True(t, podSpec.HostNetwork)
+	require.Len(t, podSpec.TopologySpreadConstraints, 1)
+	assert.Equal(t, corev1.LabelTopologyZone, podSpec.TopologySpreadConstraints[0].TopologyKey)
+
+	_, junits, err := deployer.CollectData(ctx)
+	assert.NoError(t, err)
+	_, err = kubeClient.CoreV1().ConfigMaps(namespace).Get(ctx, StopConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err, "the samplers are told to stop")
+	require.Len(t, junits, 2)
+	assert.Equal(t, "[sig-network] can collect pod-to-host disruption sampler pod logs", junits[0].Name)
+	if assert.NotNil(t, junits[0].FailureOutput) {
+		assert.Contains(t, junits[0].FailureOutput.Output, `no pods found for sampler "pod-to-host"`)
+	}
+}
+
+func TestIntervalsFromLog(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	interval := monitorapi.NewInterval(monitorapi.SourceDisruption, monitorapi.Error).
+		Locator(monitorapi.NewLocator().LocateDisruptionCheck("pod-to-host-new-connections", "pod-to-host-from-node-a", monitorapi.NewConnectionType)).
+		Message(monitorapi.NewMessage().Reason(monitorapi.DisruptionBeganEventReason).HumanMessage("stopped responding")).
+		Build(from, from.Add(5*time.Second))
+	intervalJSON, err := monitorserialization.IntervalToOneLineJSON(interval)
+	require.NoError(t, err)
+
+	log := strings.Join([]string{
+		"Initializing to sample 1 backends",
+		string(intervalJSON),
+		"",
+		"Exiting...",
+	}, "\n")
+	out := &bytes.Buffer{}
+	intervals, err := IntervalsFromLog(strings.NewReader(log), "worker-a", out)
+	require.NoError(t, err)
+	require.Len(t, intervals, 1)
+	assert.Equal(t, "worker-a", intervals[0].Message.Annotations[monitorapi.AnnotationNode])
+	assert.Equal(t, monitorapi.DisruptionBeganEventReason, intervals[0].Message.Reason)
+	assert.Equal(t, "pod-to-host-new-connections", monitorapi.BackendDisruptionNameFromLocator(intervals[0].Locator))
+	assert.Contains(t, out.String(), "Exiting...", "the whole log is kept")
+
+	// lines longer than bufio.Scanner reads by default
+	long := monitorapi.NewInterval(monitorapi.SourceDisruption, monitorapi.Error).
+		Locator(monitorapi.NewLocator().LocateDisruptionCheck("pod-to-host-new-connections", "pod-to-host-from-node-a", monitorapi.NewConnectionType)).
+		Message(monitorapi.NewMessage().Reason(monitorapi.DisruptionBeganEventReason).HumanMessage(strings.Repeat("x", 100*1024))).
+		Build(from, from.Add(5*time.Second))
+	longJSON, err := monitorserialization.IntervalToOneLineJSON(long)
+	require.NoError(t, err)
+	intervals, err = IntervalsFromLog(strings.NewReader(string(longJSON)+"\n"+string(intervalJSON)), "worker-a", &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Len(t, intervals, 2)
+}
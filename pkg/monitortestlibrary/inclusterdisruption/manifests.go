@@ -0,0 +1,197 @@
+package inclusterdisruption
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// StopConfigMapName is created in the namespace of the sampler pods to stop sampling.
+	StopConfigMapName = "stop-collecting"
+
+	backendsConfigMapName = "disruption-backends"
+	backendsFileName      = "backends.json"
+	backendsMountPath     = "/etc/disruption-backends"
+	outputMountPath       = "/var/log/disruption"
+
+	samplerLabel   = "disruption.openshift.io/sampler"
+	workloadSuffix = "-disruption-sampler"
+	containerName  = "disruption-sampler"
+)
+
+func samplerNamespace() *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "e2e-disruption-sampler-",
+			Labels: map[string]string{
+				// host network samplers need a privileged namespace
+				"pod-security.kubernetes.io/enforce": "privileged",
+				"pod-security.kubernetes.io/audit":   "privileged",
+				"pod-security.kubernetes.io/warn":    "privileged",
+				// bypass SCC rather than waiting for a binding to be reflected, like the pod network pollers do.
+				"security.openshift.io/disable-securitycontextconstraints": "true",
+				"security.openshift.io/scc.podSecurityLabelSync":           "false",
+			},
+			Annotations: map[string]string{
+				"workload.openshift.io/allowed": "management",
+			},
+		},
+	}
+}
+
+// samplerRole lets the sampler pods watch for the stop configmap.
+func samplerRole() *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{Name: "disruption-sampler"},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"configmaps"},
+				Verbs:     []string{"get", "list", "watch"},
+			},
+		},
+	}
+}
+
+func samplerRoleBinding() *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "disruption-sampler"},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     "disruption-sampler",
+		},
+		Subjects: []rbacv1.Subject{
+			{Kind: rbacv1.ServiceAccountKind, Name: "default"},
+		},
+	}
+}
+
+func backendsConfigMap(backends []BackendSpec) (*corev1.ConfigMap, error) {
+	data, err := json.Marshal(backends)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: backendsConfigMapName},
+		Data: map[string]string{
+			backendsFileName: string(data),
+		},
+	}, nil
+}
+
+func samplerLabels(spec BackendSpec) map[string]string {
+	return map[string]string{samplerLabel: spec.Name}
+}
+
+// samplerDaemonSet runs a sampler pod for the backend on every node.
+func samplerDaemonSet(spec BackendSpec, image string) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   spec.Name + workloadSuffix,
+			Labels: samplerLabels(spec),
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: samplerLabels(spec)},
+			Template: samplerPodTemplate(spec, image),
+		},
+	}
+}
+
+// samplerDeployment runs a sampler pod for the backend in every one of the zones.  Without zones there is a single pod.
+func samplerDeployment(spec BackendSpec, image string, zones int32) *appsv1.Deployment {
+	template := samplerPodTemplate(spec, image)
+	replicas := int32(1)
+	if zones > 0 {
+		replicas = zones
+		template.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{
+			{
+				MaxSkew:           1,
+				TopologyKey:       corev1.LabelTopologyZone,
+				WhenUnsatisfiable: corev1.DoNotSchedule,
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: samplerLabels(spec)},
+			},
+		}
+	}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   spec.Name + workloadSuffix,
+			Labels: samplerLabels(spec),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: samplerLabels(spec)},
+			Template: template,
+		},
+	}
+}
+
+func samplerPodTemplate(spec BackendSpec, image string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: samplerLabels(spec)},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  containerName,
+					Image: image,
+					Command: []string{
+						"/usr/bin/openshift-tests",
+						"disruption",
+						"sample-backends",
+						"--backends-file=" + path.Join(backendsMountPath, backendsFileName),
+						"--backend=" + spec.Name,
+						"--stop-configmap=" + StopConfigMapName,
+						"--my-node-name=$(MY_NODE_NAME)",
+						// the output survives restarts of the container, its content is logged again when it starts
+						fmt.Sprintf("--output-file=%s/%s.jsonl", outputMountPath, spec.Name),
+					},
+					ImagePullPolicy:          corev1.PullIfNotPresent,
+					TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+					Env: []corev1.EnvVar{
+						{
+							Name: "MY_NODE_NAME",
+							ValueFrom: &corev1.EnvVarSource{
+								FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"},
+							},
+						},
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "backends", MountPath: backendsMountPath, ReadOnly: true},
+						{Name: "output", MountPath: outputMountPath},
+					},
+				},
+			},
+			RestartPolicy:                 corev1.RestartPolicyAlways,
+			HostNetwork:                   spec.Placement.HostNetwork,
+			NodeSelector:                  spec.Placement.NodeSelector,
+			TerminationGracePeriodSeconds: ptr.To[int64](70),
+			Tolerations: []corev1.Toleration{
+				// Ensure pod can be scheduled on master nodes
+				{Key: "node-role.kubernetes.io/master", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+				// Ensure pod can be scheduled on edge nodes
+				{Key: "node-role.kubernetes.io/edge", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "backends",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: backendsConfigMapName},
+						},
+					},
+				},
+				{
+					Name:         "output",
+					VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+				},
+			},
+		},
+	}
+}
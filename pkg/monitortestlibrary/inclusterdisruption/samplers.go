@@ -0,0 +1,78 @@
+package inclusterdisruption
+
+import (
+	"fmt"
+
+	"github.com/openshift/origin/pkg/disruption/backend"
+	"github.com/openshift/origin/pkg/disruption/ci"
+	"github.com/openshift/origin/pkg/monitor/backenddisruption"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+)
+
+// NewSamplers returns a sampler for every connection type of the backend, run by the sampler pod on nodeName.
+func NewSamplers(spec BackendSpec, nodeName string) ([]disruptionlibrary.Sampler, error) {
+	ret := []disruptionlibrary.Sampler{}
+	for _, connectionType := range spec.ConnectionTypes {
+		sampler, err := newSampler(spec, nodeName, connectionType)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, sampler)
+	}
+	return ret, nil
+}
+
+func newSampler(spec BackendSpec, nodeName string, connectionType monitorapi.BackendConnectionType) (disruptionlibrary.Sampler, error) {
+	if spec.Protocol == ProtocolHTTP {
+		// the interval locator is unique for every pod sampling the backend, but the backend is per connection type
+		locator := monitorapi.NewLocator().LocateDisruptionCheck(
+			spec.DisruptionBackendName(connectionType),
+			fmt.Sprintf("%s-from-node-%s", spec.Name, nodeName),
+			connectionType,
+		)
+		sampler := backenddisruption.NewSimpleBackendWithLocator(locator, spec.URL, "", connectionType).
+			WithSampleInterval(spec.Interval.Duration).
+			WithTimeout(spec.Timeout.Duration)
+		if spec.ExpectedStatusCode != 0 {
+			sampler = sampler.WithExpectedStatusCode(spec.ExpectedStatusCode)
+		}
+		if len(spec.ExpectedBody) > 0 {
+			sampler = sampler.WithExpectedBody(spec.ExpectedBody)
+		}
+		return sampler, nil
+	}
+
+	config := ci.TestConfiguration{
+		TestDescriptor: testDescriptor(spec, connectionType),
+		Path:           spec.Target,
+		Timeout:        spec.Timeout.Duration,
+		SampleInterval: spec.Interval.Duration,
+	}
+	switch spec.Protocol {
+	case ProtocolTCP:
+		return ci.NewTCPSampler(config, spec.Address, spec.Echo)
+	case ProtocolDNS:
+		return ci.NewDNSSampler(config, spec.Address, spec.Target)
+	case ProtocolGRPC:
+		return ci.NewGRPCSampler(config, spec.Address)
+	default:
+		return nil, fmt.Errorf("backend %q: unknown protocol %q", spec.Name, spec.Protocol)
+	}
+}
+
+func testDescriptor(spec BackendSpec, connectionType monitorapi.BackendConnectionType) ci.TestDescriptor {
+	return ci.TestDescriptor{
+		TargetServer:     ci.ServerNameType(spec.Name),
+		LoadBalancerType: backend.ServiceNetworkType,
+		ConnectionType:   connectionType,
+		Protocol:         backend.ProtocolType(spec.Protocol),
+		BackendName:      spec.DisruptionBackendName(connectionType),
+	}
+}
+
+// ProbeLocator is the locator of the intervals recorded by the tcp, dns and grpc samplers of the backend for the
+// connection type, it is the same on every node.
+func ProbeLocator(spec BackendSpec, connectionType monitorapi.BackendConnectionType) monitorapi.Locator {
+	return testDescriptor(spec, connectionType).DisruptionLocator()
+}
@@ -0,0 +1,198 @@
+package inclusterdisruption
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// Protocol is how a backend is sampled.
+type Protocol string
+
+const (
+	// ProtocolHTTP sends GET requests to the URL of the backend.
+	ProtocolHTTP Protocol = "http"
+	// ProtocolTCP connects to the address of the backend.
+	ProtocolTCP Protocol = "tcp"
+	// ProtocolDNS resolves the target using the address of the backend as the DNS server.
+	ProtocolDNS Protocol = "dns"
+	// ProtocolGRPC calls the gRPC health service at the address of the backend for the target service.
+	ProtocolGRPC Protocol = "grpc"
+)
+
+// Spread is how many sampler pods are run for a backend.
+type Spread string
+
+const (
+	// SpreadPerNode runs a sampler pod on every node.
+	SpreadPerNode Spread = "PerNode"
+	// SpreadPerZone runs a sampler pod in every zone.
+	SpreadPerZone Spread = "PerZone"
+)
+
+const (
+	defaultSampleInterval = 1 * time.Second
+	defaultTimeout        = 20 * time.Second
+)
+
+// BackendSpec describes a backend sampled from inside the cluster.  The disruption backends are named
+// <name>-new-connections and <name>-reused-connections, like those of the pollers.  A file of backend specs is a list:
+//
+//	# sample cluster DNS from a pod in every zone
+//	- name: pod-to-dns
+//	  protocol: dns
+//	  address: 172.30.0.10:53
+//	  target: kubernetes.default.svc.cluster.local
+//	  placement:
+//	    spread: PerZone
+type BackendSpec struct {
+	Name     string   `json:"name"`
+	Protocol Protocol `json:"protocol,omitempty"`
+
+	// URL is sampled by http backends.
+	URL string `json:"url,omitempty"`
+	// Address is the host:port of tcp, dns and grpc backends.
+	Address string `json:"address,omitempty"`
+	// Target is the name resolved by dns backends and the service checked by grpc backends.
+	Target string `json:"target,omitempty"`
+	// Echo expects tcp backends to echo what is written to them.
+	Echo bool `json:"echo,omitempty"`
+
+	// ExpectedStatusCode and ExpectedBody are checked for http backends, by default any 2xx or 3xx response is
+	// available.
+	ExpectedStatusCode int    `json:"expectedStatusCode,omitempty"`
+	ExpectedBody       string `json:"expectedBody,omitempty"`
+
+	// Interval is the time between samples, a second by default.
+	Interval metav1.Duration `json:"interval,omitempty"`
+	// Timeout of every sample, 20 seconds by default.
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+
+	// ConnectionTypes defaults to new and reused connections, dns backends always use new connections.
+	ConnectionTypes []monitorapi.BackendConnectionType `json:"connectionTypes,omitempty"`
+
+	Placement Placement `json:"placement,omitempty"`
+}
+
+// Placement is where the sampler pods of a backend run.
+type Placement struct {
+	Spread       Spread            `json:"spread,omitempty"`
+	HostNetwork  bool              `json:"hostNetwork,omitempty"`
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+// ReadBackendSpecs reads and validates a file of backend specs.
+func ReadBackendSpecs(filename string) ([]BackendSpec, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	specs, err := ParseBackendSpecs(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return specs, nil
+}
+
+// ParseBackendSpecs reads a json or yaml list of backend specs, sets their defaults and validates them.  Unknown fields
+// are errors so that a misspelled expectation is not ignored.
+func ParseBackendSpecs(data []byte) ([]BackendSpec, error) {
+	specs := []BackendSpec{}
+	if err := yaml.UnmarshalStrict(data, &specs); err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for i := range specs {
+		specs[i].SetDefaults()
+		if err := specs[i].Validate(); err != nil {
+			return nil, err
+		}
+		if names[specs[i].Name] {
+			return nil, fmt.Errorf("backend %q is declared twice", specs[i].Name)
+		}
+		names[specs[i].Name] = true
+	}
+	return specs, nil
+}
+
+// SetDefaults fills in the fields that were not set.
+func (s *BackendSpec) SetDefaults() {
+	if len(s.Protocol) == 0 {
+		s.Protocol = ProtocolHTTP
+	}
+	if s.Interval.Duration == 0 {
+		s.Interval.Duration = defaultSampleInterval
+	}
+	if s.Timeout.Duration == 0 {
+		s.Timeout.Duration = defaultTimeout
+	}
+	if len(s.ConnectionTypes) == 0 {
+		s.ConnectionTypes = []monitorapi.BackendConnectionType{monitorapi.NewConnectionType, monitorapi.ReusedConnectionType}
+		if s.Protocol == ProtocolDNS {
+			s.ConnectionTypes = []monitorapi.BackendConnectionType{monitorapi.NewConnectionType}
+		}
+	}
+	if len(s.Placement.Spread) == 0 {
+		s.Placement.Spread = SpreadPerNode
+	}
+}
+
+// Validate checks that the backend can be sampled and its name can be used for the names of the sampler resources.
+func (s *BackendSpec) Validate() error {
+	if len(s.Name) == 0 {
+		return fmt.Errorf("backend has no name")
+	}
+	// the name is the prefix of the workload and its pods
+	if errs := validation.IsDNS1123Label(s.Name + workloadSuffix); len(errs) > 0 {
+		return fmt.Errorf("backend %q: invalid name: %v", s.Name, errs)
+	}
+
+	switch s.Protocol {
+	case ProtocolHTTP:
+		u, err := url.Parse(s.URL)
+		if err != nil {
+			return fmt.Errorf("backend %q: invalid url: %w", s.Name, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("backend %q: url must be http or https, not %q", s.Name, s.URL)
+		}
+	case ProtocolTCP, ProtocolDNS, ProtocolGRPC:
+		if _, _, err := net.SplitHostPort(s.Address); err != nil {
+			return fmt.Errorf("backend %q: address must be host:port: %w", s.Name, err)
+		}
+		if s.Protocol == ProtocolDNS && len(s.Target) == 0 {
+			return fmt.Errorf("backend %q: dns backends must have a target to resolve", s.Name)
+		}
+	default:
+		return fmt.Errorf("backend %q: unknown protocol %q", s.Name, s.Protocol)
+	}
+
+	for _, connectionType := range s.ConnectionTypes {
+		switch connectionType {
+		case monitorapi.NewConnectionType:
+		case monitorapi.ReusedConnectionType:
+			if s.Protocol == ProtocolDNS {
+				return fmt.Errorf("backend %q: dns queries are sent over UDP and cannot reuse connections", s.Name)
+			}
+		default:
+			return fmt.Errorf("backend %q: unknown connection type %q", s.Name, connectionType)
+		}
+	}
+
+	if s.Placement.Spread != SpreadPerNode && s.Placement.Spread != SpreadPerZone {
+		return fmt.Errorf("backend %q: unknown spread %q, expected %q or %q", s.Name, s.Placement.Spread, SpreadPerNode, SpreadPerZone)
+	}
+	return nil
+}
+
+// DisruptionBackendName is the name of the disruption backend for the connection type.
+func (s *BackendSpec) DisruptionBackendName(connectionType monitorapi.BackendConnectionType) string {
+	return fmt.Sprintf("%s-%v-connections", s.Name, connectionType)
+}
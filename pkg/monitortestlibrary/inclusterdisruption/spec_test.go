@@ -0,0 +1,82 @@
+package inclusterdisruption
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBackends = `
+- name: pod-to-host
+  url: https://10.0.0.1:10250/healthz
+  expectedStatusCode: 401
+- name: pod-to-dns
+  protocol: dns
+  address: 172.30.0.10:53
+  target: kubernetes.default.svc.cluster.local
+  interval: 5s
+  placement:
+    spread: PerZone
+    hostNetwork: true
+`
+
+func TestParseBackendSpecs(t *testing.T) {
+	specs, err := ParseBackendSpecs([]byte(testBackends))
+	require.NoError(t, err)
+	require.Len(t, specs, 2)
+
+	http := specs[0]
+	assert.Equal(t, ProtocolHTTP, http.Protocol)
+	assert.Equal(t, time.Second, http.Interval.Duration)
+	assert.Equal(t, defaultTimeout, http.Timeout.Duration)
+	assert.Equal(t, []monitorapi.BackendConnectionType{monitorapi.NewConnectionType, monitorapi.ReusedConnectionType}, http.ConnectionTypes)
+	assert.Equal(t, SpreadPerNode, http.Placement.Spread)
+	assert.Equal(t, "pod-to-host-reused-connections", http.DisruptionBackendName(monitorapi.ReusedConnectionType))
+
+	dns := specs[1]
+	assert.Equal(t, 5*time.Second, dns.Interval.Duration)
+	assert.Equal(t, []monitorapi.BackendConnectionType{monitorapi.NewConnectionType}, dns.ConnectionTypes, "dns only uses new connections")
+	assert.Equal(t, SpreadPerZone, dns.Placement.Spread)
+	assert.True(t, dns.Placement.HostNetwork)
+
+	for _, invalid := range []struct {
+		backends string
+		err      string
+	}{
+		{backends: "- url: http://a", err: "backend has no name"},
+		{backends: "- name: Upper\n  url: http://a", err: `backend "Upper": invalid name`},
+		{backends: "- name: a\n  url: http://a\n- name: a\n  url: http://b", err: `backend "a" is declared twice`},
+		{backends: "- name: a\n  url: ftp://a", err: "url must be http or https"},
+		{backends: "- name: a\n  protocol: tcp\n  address: a", err: "address must be host:port"},
+		{backends: "- name: a\n  protocol: dns\n  address: a:53", err: "must have a target"},
+		{backends: "- name: a\n  protocol: dns\n  address: a:53\n  target: b\n  connectionTypes: [reused]", err: "cannot reuse connections"},
+		{backends: "- name: a\n  protocol: udp\n  address: a:53", err: `unknown protocol "udp"`},
+		{backends: "- name: a\n  url: http://a\n  placement:\n    spread: PerRack", err: `unknown spread "PerRack"`},
+		{backends: "- name: a\n  url: http://a\n  expectedStatus: 401", err: `unknown field "expectedStatus"`},
+	} {
+		_, err := ParseBackendSpecs([]byte(invalid.backends))
+		if assert.Error(t, err, invalid.backends) {
+			assert.Contains(t, err.Error(), invalid.err)
+		}
+	}
+}
+
+func TestNewSamplers(t *testing.T) {
+	specs, err := ParseBackendSpecs([]byte(testBackends))
+	require.NoError(t, err)
+
+	samplers, err := NewSamplers(specs[0], "worker-a")
+	require.NoError(t, err)
+	require.Len(t, samplers, 2)
+	assert.Equal(t, "pod-to-host-new-connections", samplers[0].GetDisruptionBackendName())
+	assert.Equal(t, "pod-to-host-reused-connections", samplers[1].GetDisruptionBackendName())
+	assert.Contains(t, samplers[0].GetLocator().OldLocator(), "worker-a", "every node samples to its own locator")
+
+	samplers, err = NewSamplers(specs[1], "worker-a")
+	require.NoError(t, err)
+	require.Len(t, samplers, 1)
+	assert.Equal(t, "pod-to-dns-new-connections", samplers[0].GetDisruptionBackendName())
+}
@@ -65,6 +65,28 @@ func TestDuplicatedEventForStableSystem(events monitorapi.Intervals, clientConfi
 	return tests
 }
 
+// SkipDuplicatedEventTests reports the tests run by TestDuplicatedEventForUpgrade and TestDuplicatedEventForStableSystem
+// as skipped, for when they cannot run, like when saved data is replayed without a cluster.
+func SkipDuplicatedEventTests(reason string) []*junitapi.JUnitTestCase {
+	tests := []*junitapi.JUnitTestCase{}
+	for _, namespace := range getNamespacesForJUnits().List() {
+		tests = append(tests, &junitapi.JUnitTestCase{
+			Name:        getJUnitName(duplicatedCoreNamespaceEventsTestName, namespace),
+			SkipMessage: &junitapi.SkipMessage{Message: reason},
+		})
+	}
+	tests = append(tests, &junitapi.JUnitTestCase{
+		Name:        duplicatedE2ENamespaceEventsTestName,
+		SkipMessage: &junitapi.SkipMessage{Message: reason},
+	})
+	return tests
+}
+
+const (
+	duplicatedCoreNamespaceEventsTestName = "[sig-arch] events should not repeat pathologically"
+	duplicatedE2ENamespaceEventsTestName  = "[sig-arch] events should not repeat pathologically in e2e namespaces"
+)
+
 type duplicateEventsEvaluator struct {
 	registry *AllowedPathologicalEventRegistry
 
@@ -80,9 +102,7 @@ type duplicateEventsEvaluator struct {
 // is easier to author, but less complete in its view.
 // I hate regexes, so I only do this because I really have to.
 func (d *duplicateEventsEvaluator) testDuplicatedCoreNamespaceEvents(events monitorapi.Intervals, kubeClientConfig *rest.Config) []*junitapi.JUnitTestCase {
-	const testName = "[sig-arch] events should not repeat pathologically"
-
-	return d.testDuplicatedEvents(testName, false, events.Filter(monitorapi.Not(monitorapi.IsInE2ENamespace)), kubeClientConfig, false)
+	return d.testDuplicatedEvents(duplicatedCoreNamespaceEventsTestName, false, events.Filter(monitorapi.Not(monitorapi.IsInE2ENamespace)), kubeClientConfig, false)
 }
 
 // we want to identify events based on the monitor because it is (currently) our only spot that tracks events over time
@@ -90,9 +110,7 @@ func (d *duplicateEventsEvaluator) testDuplicatedCoreNamespaceEvents(events moni
 // is easier to author, but less complete in its view.
 // I hate regexes, so I only do this because I really have to.
 func (d *duplicateEventsEvaluator) testDuplicatedE2ENamespaceEvents(events monitorapi.Intervals, kubeClientConfig *rest.Config) []*junitapi.JUnitTestCase {
-	const testName = "[sig-arch] events should not repeat pathologically in e2e namespaces"
-
-	return d.testDuplicatedEvents(testName, true, events.Filter(monitorapi.IsInE2ENamespace), kubeClientConfig, true)
+	return d.testDuplicatedEvents(duplicatedE2ENamespaceEventsTestName, true, events.Filter(monitorapi.IsInE2ENamespace), kubeClientConfig, true)
 }
 
 // appendToFirstLine appends add to the end of the first line of s
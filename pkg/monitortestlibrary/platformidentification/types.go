@@ -2,7 +2,12 @@ package platformidentification
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -36,6 +41,8 @@ type ClusterData struct {
 	CloudZone             string
 	ClusterVersionHistory []string
 	MasterNodesUpdated    string
+	// FeatureSet is the feature set of the cluster, it is nil in the cluster data written before it was recorded.
+	FeatureSet *configv1.FeatureSet `json:",omitempty"`
 }
 
 const (
@@ -114,6 +121,13 @@ func BuildClusterData(ctx context.Context, clientConfig *rest.Config) (ClusterDa
 		clusterData.ClusterVersionHistory = getClusterVersions(clusterVersions)
 	}
 
+	featureGate, err := configClient.FeatureGates().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		errors = append(errors, err)
+	} else {
+		clusterData.FeatureSet = &featureGate.Spec.FeatureSet
+	}
+
 	kubeClient, err := kubernetes.NewForConfig(clientConfig)
 	if err != nil {
 		errors = append(errors, err)
@@ -133,6 +147,29 @@ func BuildClusterData(ctx context.Context, clientConfig *rest.Config) (ClusterDa
 	return clusterData, &errors
 }
 
+// ReadClusterDataFromDir loads the cluster-data_*.json written by a previous run into dir.  It is used when replaying
+// saved data, where there is no cluster to ask.
+func ReadClusterDataFromDir(dir string) (*ClusterData, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "cluster-data*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no cluster-data*.json found in %q", dir)
+	}
+	sort.Strings(matches)
+
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		return nil, err
+	}
+	clusterData := &ClusterData{}
+	if err := json.Unmarshal(data, clusterData); err != nil {
+		return nil, fmt.Errorf("unable to parse %q: %w", matches[0], err)
+	}
+	return clusterData, nil
+}
+
 func getClusterVersions(versions *configv1.ClusterVersionList) []string {
 	if versions == nil {
 		return nil
@@ -0,0 +1,219 @@
+package statetracker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+// Machine declares the states a locator can be in and the intervals that move it between them.  It replaces the loop
+// over intervals that monitor tests otherwise write around a state tracker: every interval is matched against the
+// transitions, the first one that matches closes and opens states, and states still open at the end are closed with
+// the end of the run.
+type Machine struct {
+	ConstructedBy monitorapi.ConstructionOwner
+	// Source is the source of the intervals built for the states.
+	Source monitorapi.IntervalSource
+
+	// Locate returns the locator whose states interval changes, or false to ignore interval.
+	Locate func(interval monitorapi.Interval) (monitorapi.Locator, bool)
+	// LocatorAnnotations, if set, returns annotations to remember for the locator of interval.  The latest ones are
+	// passed to messages in Change.Annotations and added to the intervals of states that never completed.
+	LocatorAnnotations func(interval monitorapi.Interval) map[monitorapi.AnnotationKey]string
+
+	States      []MachineState
+	Transitions []Transition
+}
+
+// MachineState is a state of a Machine and the interval built when it closes.
+type MachineState struct {
+	Name string
+	// Row, if set, is added to the locator of the intervals under the row key, so that states of a locator can be shown
+	// on separate rows.
+	Row    string
+	Reason monitorapi.IntervalReason
+	Level  monitorapi.IntervalLevel
+
+	// HeldFromBeginning means that closing a state that was never opened builds an interval from the beginning of the
+	// run, because the locator must have been in the state all along.  Otherwise such closes are ignored.
+	HeldFromBeginning bool
+
+	// Message builds the message of the interval when a transition closes the state.
+	Message func(change Change) *monitorapi.MessageBuilder
+	// Unfinished, if set, builds the message of the interval when the state is still open at the end of the run.  By
+	// default these intervals are warnings that the state never completed.
+	Unfinished func(change Change) *monitorapi.MessageBuilder
+}
+
+// Transition closes and then opens states of the locator of the intervals it matches.
+type Transition struct {
+	Match Match
+	Close []string
+	Open  []string
+}
+
+// Match selects intervals.  Empty fields match everything, the fields that are set must all match.
+type Match struct {
+	Reasons     []monitorapi.IntervalReason
+	Sources     []monitorapi.IntervalSource
+	Annotations map[monitorapi.AnnotationKey]string
+	// Func, if set, must return true as well.
+	Func func(interval monitorapi.Interval) bool
+}
+
+// Change is what the message of a closed state is built from.
+type Change struct {
+	State   string
+	Locator monitorapi.Locator
+	// Opened is the interval that opened the state, nil when the state was held from the beginning.
+	Opened *monitorapi.Interval
+	// Closed is the interval that closed the state, nil when the state was still open at the end of the run.
+	Closed *monitorapi.Interval
+	// Annotations are the latest LocatorAnnotations of the locator.
+	Annotations map[monitorapi.AnnotationKey]string
+}
+
+func (m Match) matches(interval monitorapi.Interval) bool {
+	if len(m.Reasons) > 0 && !contains(m.Reasons, interval.Message.Reason) {
+		return false
+	}
+	if len(m.Sources) > 0 && !contains(m.Sources, interval.Source) {
+		return false
+	}
+	for k, v := range m.Annotations {
+		if actual, ok := interval.Message.Annotations[k]; !ok || actual != v {
+			return false
+		}
+	}
+	return m.Func == nil || m.Func(interval)
+}
+
+func contains[T comparable](values []T, value T) bool {
+	for _, curr := range values {
+		if curr == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the machine can locate intervals and that transitions only refer to declared states.
+func (m *Machine) Validate() error {
+	if m.Locate == nil {
+		return fmt.Errorf("machine for %q has no Locate", m.Source)
+	}
+	states := map[string]bool{}
+	for _, state := range m.States {
+		if states[state.Name] {
+			return fmt.Errorf("state %q is declared twice", state.Name)
+		}
+		if state.Message == nil {
+			return fmt.Errorf("state %q has no Message", state.Name)
+		}
+		states[state.Name] = true
+	}
+	for i, transition := range m.Transitions {
+		for _, name := range append(append([]string{}, transition.Close...), transition.Open...) {
+			if !states[name] {
+				return fmt.Errorf("transition %d refers to undeclared state %q", i, name)
+			}
+		}
+	}
+	return nil
+}
+
+// ConstructComputedIntervals has the signature of the monitor test method, so a monitor test built on a machine can
+// return it directly.
+func (m *Machine) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m.Intervals(startingIntervals, beginning, end), nil
+}
+
+// Intervals runs intervals through the machine and returns the intervals of the states they went through.  The
+// machine must be valid.
+func (m *Machine) Intervals(intervals monitorapi.Intervals, beginning, end time.Time) monitorapi.Intervals {
+	tracker := NewStateTracker(m.ConstructedBy, m.Source, beginning)
+	states := map[string]MachineState{}
+	for _, state := range m.States {
+		states[state.Name] = state
+	}
+	stateInfo := func(state MachineState) StateInfo {
+		return State(state.Name, state.Row, state.Reason)
+	}
+
+	type openState struct {
+		locatorKey string
+		state      string
+	}
+	opened := map[openState]monitorapi.Interval{}
+	locatorAnnotations := map[string]map[monitorapi.AnnotationKey]string{}
+
+	ret := monitorapi.Intervals{}
+	for i := range intervals {
+		interval := intervals[i]
+		locator, ok := m.Locate(interval)
+		if !ok {
+			continue
+		}
+		locatorKey := locator.OldLocator()
+		if m.LocatorAnnotations != nil {
+			if locatorAnnotations[locatorKey] == nil {
+				locatorAnnotations[locatorKey] = map[monitorapi.AnnotationKey]string{}
+			}
+			for k, v := range m.LocatorAnnotations(interval) {
+				locatorAnnotations[locatorKey][k] = v
+			}
+		}
+
+		for _, transition := range m.Transitions {
+			if !transition.Match.matches(interval) {
+				continue
+			}
+			for _, name := range transition.Close {
+				state := states[name]
+				change := Change{State: name, Locator: locator, Closed: &interval, Annotations: locatorAnnotations[locatorKey]}
+				if opener, ok := opened[openState{locatorKey: locatorKey, state: name}]; ok {
+					change.Opened = &opener
+				}
+				creator := SimpleInterval(m.Source, state.Level, state.Message(change))
+				if state.HeldFromBeginning {
+					ret = append(ret, tracker.CloseInterval(locator, stateInfo(state), creator, interval.From)...)
+				} else {
+					ret = append(ret, tracker.CloseIfOpenedInterval(locator, stateInfo(state), creator, interval.From)...)
+				}
+				delete(opened, openState{locatorKey: locatorKey, state: name})
+			}
+			for _, name := range transition.Open {
+				if alreadyOpen := tracker.OpenInterval(locator, stateInfo(states[name]), interval.From); !alreadyOpen {
+					opened[openState{locatorKey: locatorKey, state: name}] = interval
+				}
+			}
+			break
+		}
+	}
+
+	defaultAnnotations := map[string]map[string]string{}
+	for locatorKey, annotations := range locatorAnnotations {
+		defaultAnnotations[locatorKey] = map[string]string{}
+		for k, v := range annotations {
+			defaultAnnotations[locatorKey][string(k)] = v
+		}
+	}
+	ret = append(ret, tracker.closeAll(end, func(locatorKey string, info StateInfo) intervalCreationFunc {
+		state := states[info.stateName]
+		if state.Unfinished == nil {
+			return tracker.unfinishedInterval(defaultAnnotations[locatorKey], info)
+		}
+		change := Change{State: state.Name, Locator: tracker.locators[locatorKey], Annotations: locatorAnnotations[locatorKey]}
+		if opener, ok := opened[openState{locatorKey: locatorKey, state: state.Name}]; ok {
+			change.Opened = &opener
+		}
+		return SimpleInterval(m.Source, state.Level, state.Unfinished(change))
+	})...)
+
+	return ret
+}
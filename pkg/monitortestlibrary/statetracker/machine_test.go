@@ -0,0 +1,194 @@
+package statetracker
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+)
+
+var (
+	machineBeginning = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	machineEnd       = machineBeginning.Add(time.Hour)
+)
+
+// nodeInterval is a node event with reason at minute.
+func nodeInterval(reason monitorapi.IntervalReason, minute int) monitorapi.Interval {
+	return monitorapi.NewInterval(monitorapi.SourceNodeMonitor, monitorapi.Info).
+		Locator(monitorapi.NewLocator().NodeFromName("master-0")).
+		Message(monitorapi.NewMessage().Reason(reason).HumanMessage(string(reason))).
+		Build(machineBeginning.Add(time.Duration(minute)*time.Minute), machineBeginning.Add(time.Duration(minute)*time.Minute))
+}
+
+// downMachine tracks a node being down, between NodeNotReady and NodeReady.
+func downMachine(down MachineState) *Machine {
+	down.Name = "Down"
+	down.Reason = monitorapi.NodeNotReadyReason
+	down.Level = monitorapi.Error
+	down.Message = func(change Change) *monitorapi.MessageBuilder {
+		if change.Opened == nil {
+			return monitorapi.NewMessage().HumanMessage("down from the beginning")
+		}
+		return monitorapi.NewMessage().HumanMessage(fmt.Sprintf("down since %s", change.Opened.From.Format("15:04")))
+	}
+	return &Machine{
+		ConstructedBy: monitorapi.ConstructionOwnerNodeLifecycle,
+		Source:        monitorapi.SourceNodeState,
+		Locate: func(interval monitorapi.Interval) (monitorapi.Locator, bool) {
+			return interval.Locator, interval.Source == monitorapi.SourceNodeMonitor
+		},
+		States: []MachineState{down},
+		Transitions: []Transition{
+			{
+				Match: Match{Reasons: []monitorapi.IntervalReason{monitorapi.NodeNotReadyReason}},
+				Open:  []string{"Down"},
+			},
+			{
+				Match: Match{Reasons: []monitorapi.IntervalReason{"NodeReady"}},
+				Close: []string{"Down"},
+			},
+		},
+	}
+}
+
+func summarizeMachineIntervals(intervals monitorapi.Intervals) []string {
+	ret := []string{}
+	for _, interval := range intervals {
+		ret = append(ret, fmt.Sprintf("%s-%s %v %s",
+			interval.From.Format("15:04"), interval.To.Format("15:04"), interval.Level, interval.Message.HumanMessage))
+	}
+	return ret
+}
+
+func TestMachineHeldFromBeginning(t *testing.T) {
+	tests := []struct {
+		name              string
+		heldFromBeginning bool
+		intervals         monitorapi.Intervals
+		expected          []string
+	}{
+		{
+			name:              "held, closed without being opened",
+			heldFromBeginning: true,
+			intervals:         monitorapi.Intervals{nodeInterval("NodeReady", 2)},
+			expected:          []string{"00:00-00:02 Error down from the beginning"},
+		},
+		{
+			name:      "not held, closed without being opened",
+			intervals: monitorapi.Intervals{nodeInterval("NodeReady", 2)},
+			expected:  []string{},
+		},
+		{
+			name:              "held, opened then closed",
+			heldFromBeginning: true,
+			intervals:         monitorapi.Intervals{nodeInterval(monitorapi.NodeNotReadyReason, 1), nodeInterval("NodeReady", 2)},
+			expected:          []string{"00:01-00:02 Error down since 00:01"},
+		},
+		{
+			name:      "not held, opened then closed",
+			intervals: monitorapi.Intervals{nodeInterval(monitorapi.NodeNotReadyReason, 1), nodeInterval("NodeReady", 2)},
+			expected:  []string{"00:01-00:02 Error down since 00:01"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			machine := downMachine(MachineState{HeldFromBeginning: test.heldFromBeginning})
+			require.NoError(t, machine.Validate())
+			actual := machine.Intervals(test.intervals, machineBeginning, machineEnd)
+			assert.Equal(t, test.expected, summarizeMachineIntervals(actual))
+		})
+	}
+}
+
+func TestMachineUnfinished(t *testing.T) {
+	tests := []struct {
+		name       string
+		unfinished func(change Change) *monitorapi.MessageBuilder
+		intervals  monitorapi.Intervals
+		expected   []string
+	}{
+		{
+			name:      "default warning",
+			intervals: monitorapi.Intervals{nodeInterval(monitorapi.NodeNotReadyReason, 1)},
+			expected:  []string{"00:01-01:00 Warning never completed"},
+		},
+		{
+			name: "built from the interval that opened the state",
+			unfinished: func(change Change) *monitorapi.MessageBuilder {
+				return monitorapi.NewMessage().HumanMessage(fmt.Sprintf("still down since %s", change.Opened.From.Format("15:04")))
+			},
+			intervals: monitorapi.Intervals{nodeInterval(monitorapi.NodeNotReadyReason, 1)},
+			expected:  []string{"00:01-01:00 Error still down since 00:01"},
+		},
+		{
+			name: "not used for closed states",
+			unfinished: func(change Change) *monitorapi.MessageBuilder {
+				return monitorapi.NewMessage().HumanMessage("still down")
+			},
+			intervals: monitorapi.Intervals{nodeInterval(monitorapi.NodeNotReadyReason, 1), nodeInterval("NodeReady", 2)},
+			expected:  []string{"00:01-00:02 Error down since 00:01"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			machine := downMachine(MachineState{Unfinished: test.unfinished})
+			require.NoError(t, machine.Validate())
+			actual := machine.Intervals(test.intervals, machineBeginning, machineEnd)
+			assert.Equal(t, test.expected, summarizeMachineIntervals(actual))
+		})
+	}
+}
+
+func TestMachineValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		mutate      func(machine *Machine)
+		expectedErr string
+	}{
+		{
+			name:   "valid",
+			mutate: func(machine *Machine) {},
+		},
+		{
+			name:        "no locate",
+			mutate:      func(machine *Machine) { machine.Locate = nil },
+			expectedErr: `machine for "NodeState" has no Locate`,
+		},
+		{
+			name:        "state declared twice",
+			mutate:      func(machine *Machine) { machine.States = append(machine.States, machine.States[0]) },
+			expectedErr: `state "Down" is declared twice`,
+		},
+		{
+			name:        "state without message",
+			mutate:      func(machine *Machine) { machine.States[0].Message = nil },
+			expectedErr: `state "Down" has no Message`,
+		},
+		{
+			name:        "transition opens an undeclared state",
+			mutate:      func(machine *Machine) { machine.Transitions[0].Open = []string{"Gone"} },
+			expectedErr: `transition 0 refers to undeclared state "Gone"`,
+		},
+		{
+			name:        "transition closes an undeclared state",
+			mutate:      func(machine *Machine) { machine.Transitions[1].Close = []string{"Down", "Gone"} },
+			expectedErr: `transition 1 refers to undeclared state "Gone"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			machine := downMachine(MachineState{})
+			test.mutate(machine)
+			err := machine.Validate()
+			if len(test.expectedErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, test.expectedErr)
+		})
+	}
+}
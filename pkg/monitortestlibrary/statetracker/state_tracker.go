@@ -154,19 +154,30 @@ func (t *stateTracker) CloseInterval(locator monitorapi.Locator, state StateInfo
 }
 
 func (t *stateTracker) CloseAllIntervals(locatorToMessageAnnotations map[string]map[string]string, end time.Time) []monitorapi.Interval {
+	return t.closeAll(end, func(locatorKey string, state StateInfo) intervalCreationFunc {
+		return t.unfinishedInterval(locatorToMessageAnnotations[locatorKey], state)
+	})
+}
+
+// unfinishedInterval warns that state never completed.
+func (t *stateTracker) unfinishedInterval(messageAnnotations map[string]string, state StateInfo) intervalCreationFunc {
+	annotations := map[monitorapi.AnnotationKey]string{}
+	for k, v := range messageAnnotations {
+		annotations[monitorapi.AnnotationKey(k)] = v
+	}
+	annotations[monitorapi.AnnotationState] = state.stateName
+	annotations[monitorapi.AnnotationConstructed] = string(t.constructedBy)
+	mb := monitorapi.NewMessage().WithAnnotations(annotations).HumanMessage("never completed").Reason(state.reason)
+	return SimpleInterval(t.intervalSource, monitorapi.Warning, mb)
+}
+
+// closeAll closes every open state at end, with the intervals intervalCreator returns for them.
+func (t *stateTracker) closeAll(end time.Time, intervalCreator func(locatorKey string, state StateInfo) intervalCreationFunc) []monitorapi.Interval {
 	ret := []monitorapi.Interval{}
 	for locator, states := range t.locatorToStateMap {
-		annotations := map[monitorapi.AnnotationKey]string{}
-		for k, v := range locatorToMessageAnnotations[locator] {
-			annotations[monitorapi.AnnotationKey(k)] = v
-		}
-
 		l := t.locators[locator]
 		for state := range states {
-			annotations[monitorapi.AnnotationState] = state.stateName
-			annotations[monitorapi.AnnotationConstructed] = string(t.constructedBy)
-			mb := monitorapi.NewMessage().WithAnnotations(annotations).HumanMessage("never completed").Reason(state.reason)
-			ret = append(ret, t.CloseInterval(l, state, SimpleInterval(t.intervalSource, monitorapi.Warning, mb), end)...)
+			ret = append(ret, t.CloseInterval(l, state, intervalCreator(locator, state), end)...)
 		}
 	}
 
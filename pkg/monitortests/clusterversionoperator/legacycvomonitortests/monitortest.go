@@ -14,6 +14,10 @@ import (
 
 type legacyMonitorTests struct {
 	adminRESTConfig *rest.Config
+
+	// offline is set when replaying saved data. All of the operator state and OS update
+	// tests are skipped then, their exceptions are looked up in the live cluster.
+	offline bool
 }
 
 func NewLegacyTests() monitortestframework.MonitorTest {
@@ -25,6 +29,11 @@ func (w *legacyMonitorTests) StartCollection(ctx context.Context, adminRESTConfi
 	return nil
 }
 
+func (w *legacyMonitorTests) PrepareForOfflineEvaluation(ctx context.Context, info monitortestframework.OfflineEvaluationInfo) error {
+	w.offline = true
+	return nil
+}
+
 func (w *legacyMonitorTests) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
 	return nil, nil, nil
 }
@@ -34,6 +43,10 @@ func (*legacyMonitorTests) ConstructComputedIntervals(ctx context.Context, start
 }
 
 func (w *legacyMonitorTests) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	if w.offline {
+		return nil, &monitortestframework.NotSupportedError{Reason: "operator state exceptions are determined from the live cluster"}
+	}
+
 	junits := []*junitapi.JUnitTestCase{}
 	junits = append(junits, testOperatorOSUpdateStaged(finalIntervals, w.adminRESTConfig)...)
 	junits = append(junits, testOperatorOSUpdateStartedEventRecorded(finalIntervals, w.adminRESTConfig)...)
@@ -1,113 +1,84 @@
 package operatorstateanalyzer
 
 import (
-	"fmt"
 	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
-	"github.com/sirupsen/logrus"
+	"github.com/openshift/origin/pkg/monitortestlibrary/statetracker"
+)
+
+var (
+	operatorAvailableMachine   = operatorStatusMachine(configv1.OperatorAvailable, configv1.ConditionTrue, monitorapi.Error)
+	operatorProgressingMachine = operatorStatusMachine(configv1.OperatorProgressing, configv1.ConditionFalse, monitorapi.Warning)
+	operatorDegradedMachine    = operatorStatusMachine(configv1.OperatorDegraded, configv1.ConditionFalse, monitorapi.Error)
 )
 
 func intervalsFromEvents_OperatorAvailable(intervals monitorapi.Intervals, _ monitorapi.ResourcesMap, beginning, end time.Time) monitorapi.Intervals {
-	return intervalsFromEvents_OperatorStatus(intervals, beginning, end, configv1.OperatorAvailable, configv1.ConditionTrue, monitorapi.Error)
+	return operatorAvailableMachine.Intervals(intervals, beginning, end)
 }
 
 func intervalsFromEvents_OperatorProgressing(intervals monitorapi.Intervals, _ monitorapi.ResourcesMap, beginning, end time.Time) monitorapi.Intervals {
-	return intervalsFromEvents_OperatorStatus(intervals, beginning, end, configv1.OperatorProgressing, configv1.ConditionFalse, monitorapi.Warning)
+	return operatorProgressingMachine.Intervals(intervals, beginning, end)
 }
 
 func intervalsFromEvents_OperatorDegraded(intervals monitorapi.Intervals, _ monitorapi.ResourcesMap, beginning, end time.Time) monitorapi.Intervals {
-	return intervalsFromEvents_OperatorStatus(intervals, beginning, end, configv1.OperatorDegraded, configv1.ConditionFalse, monitorapi.Error)
+	return operatorDegradedMachine.Intervals(intervals, beginning, end)
 }
 
-func intervalsFromEvents_OperatorStatus(intervals monitorapi.Intervals, beginning, end time.Time, conditionType configv1.ClusterStatusConditionType, conditionGoodState configv1.ConditionStatus, level monitorapi.IntervalLevel) monitorapi.Intervals {
-	ret := monitorapi.Intervals{}
-	operatorToInterestingBadCondition := map[string]*configv1.ClusterOperatorStatusCondition{}
-
-	for _, event := range intervals {
-		if event.Source != monitorapi.SourceClusterOperatorMonitor {
-			continue
-		}
-		operatorName := event.Locator.Keys[monitorapi.LocatorClusterOperatorKey]
-		logrus.WithField("event", event).WithField("operator", operatorName).
-			Infof("operator status: processing event")
-
-		currentCondition := monitorapi.GetOperatorConditionStatus(event)
-		if currentCondition == nil {
-			logrus.Info("currentCondition came back nil, event does not appear to be a condition")
-			continue
-		}
-		logrus.Infof("checking if currentCondition.Type %v != %v", currentCondition.Type, conditionType)
-		if currentCondition.Type != conditionType {
-			logrus.Info("condition types not equal")
-			continue
-		}
-
-		lastCondition := operatorToInterestingBadCondition[operatorName]
-		if lastCondition != nil && lastCondition.Status == currentCondition.Status {
-			// if the status didn't actually change (imagine degraded just changing reasons)
-			// don't count as the interval
-			continue
-		}
-		if currentCondition.Status != conditionGoodState {
-			// don't overwrite a previous condition in a bad State
-			if lastCondition == nil {
-				// force the last transition time, since we think we just transitioned at this instant
-				currentCondition.LastTransitionTime.Time = event.From
-				operatorToInterestingBadCondition[operatorName] = currentCondition
-				logrus.WithField("currentCondition", currentCondition).
-					WithField("operatorName", operatorName).
-					Info("mapped to bad condition")
-
-			}
-			continue
-		}
-
-		// at this point we have transitioned to a good State.  Remove the previous "bad" State
-		delete(operatorToInterestingBadCondition, operatorName)
-		logrus.WithField("operatorName", operatorName).Info("cleared bad condition")
-
-		from := beginning
-		lastStatus := "Unknown"
-		lastMessage := "Unknown"
-		lastReason := "Unknown"
-		if lastCondition != nil {
-			from = lastCondition.LastTransitionTime.Time
-			lastStatus = fmt.Sprintf("%v", lastCondition.Status)
-			lastMessage = lastCondition.Message
-			lastReason = lastCondition.Reason
-		} else {
-			// if we're in a good State now, then we were probably in a bad State before.  Let's start by assuming that anyway
-			if conditionGoodState == configv1.ConditionTrue {
-				lastStatus = "False"
-			} else {
-				lastStatus = "True"
-			}
-		}
-		ret = append(ret, monitorapi.NewInterval(monitorapi.SourceOperatorState, level).
-			Locator(event.Locator).
-			Message(monitorapi.NewMessage().Reason(monitorapi.IntervalReason(lastReason)).
-					HumanMessage(lastMessage).
-					WithAnnotation(monitorapi.AnnotationCondition, string(conditionType)).
-					WithAnnotation(monitorapi.AnnotationStatus, lastStatus)).
-			Display(). // this is the variant of interval we want to chart
-			Build(from, event.From),
-		)
+// operatorStatusMachine charts the time each operator spends with conditionType in a status other than
+// conditionGoodState.  The interval describes the condition that started the bad state.
+func operatorStatusMachine(conditionType configv1.ClusterStatusConditionType, conditionGoodState configv1.ConditionStatus, level monitorapi.IntervalLevel) *statetracker.Machine {
+	badState := "Not" + string(conditionGoodState) + string(conditionType)
+	conditionMatch := map[monitorapi.AnnotationKey]string{monitorapi.AnnotationCondition: string(conditionType)}
+	goodMatch := map[monitorapi.AnnotationKey]string{
+		monitorapi.AnnotationCondition: string(conditionType),
+		monitorapi.AnnotationStatus:    string(conditionGoodState),
 	}
 
-	for operatorName, lastCondition := range operatorToInterestingBadCondition {
-		ret = append(ret, monitorapi.NewInterval(monitorapi.SourceOperatorState, level).
-			Locator(monitorapi.NewLocator().ClusterOperator(operatorName)).
-			Message(monitorapi.NewMessage().Reason(monitorapi.IntervalReason(lastCondition.Reason)).
-					HumanMessage(lastCondition.Message).
-					WithAnnotation(monitorapi.AnnotationCondition, string(conditionType)).
-					WithAnnotation(monitorapi.AnnotationStatus, string(lastCondition.Status)).HumanMessage(lastCondition.Message)).
-			Display(). // this is the variant of interval we want to chart
-			Build(lastCondition.LastTransitionTime.Time, end),
-		)
-		fmt.Printf("from: %s, to: %s\n", lastCondition.LastTransitionTime, end)
+	message := func(lastCondition *configv1.ClusterOperatorStatusCondition) *monitorapi.MessageBuilder {
+		return monitorapi.NewMessage().Reason(monitorapi.IntervalReason(lastCondition.Reason)).
+			HumanMessage(lastCondition.Message).
+			WithAnnotation(monitorapi.AnnotationCondition, string(conditionType)).
+			WithAnnotation(monitorapi.AnnotationStatus, string(lastCondition.Status))
 	}
 
-	return ret
+	return &statetracker.Machine{
+		Source: monitorapi.SourceOperatorState,
+		Locate: func(interval monitorapi.Interval) (monitorapi.Locator, bool) {
+			return interval.Locator, interval.Source == monitorapi.SourceClusterOperatorMonitor
+		},
+		States: []statetracker.MachineState{
+			{
+				Name:  badState,
+				Level: level,
+				// if we're in a good State now, then we were probably in a bad State before.  Let's start by assuming that anyway
+				HeldFromBeginning: true,
+				Message: func(change statetracker.Change) *monitorapi.MessageBuilder {
+					if change.Opened != nil {
+						return message(monitorapi.GetOperatorConditionStatus(*change.Opened))
+					}
+					lastStatus := configv1.ConditionTrue
+					if conditionGoodState == configv1.ConditionTrue {
+						lastStatus = configv1.ConditionFalse
+					}
+					return message(&configv1.ClusterOperatorStatusCondition{Status: lastStatus, Reason: "Unknown", Message: "Unknown"})
+				},
+				Unfinished: func(change statetracker.Change) *monitorapi.MessageBuilder {
+					return message(monitorapi.GetOperatorConditionStatus(*change.Opened))
+				},
+			},
+		},
+		Transitions: []statetracker.Transition{
+			{
+				Match: statetracker.Match{Annotations: goodMatch},
+				Close: []string{badState},
+			},
+			{
+				// changes between bad states, like Unknown after False, keep the condition that started the bad state
+				Match: statetracker.Match{Annotations: conditionMatch},
+				Open:  []string{badState},
+			},
+		},
+	}
 }
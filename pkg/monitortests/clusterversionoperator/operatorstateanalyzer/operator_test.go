@@ -1,6 +1,8 @@
 package operatorstateanalyzer
 
 import (
+	"fmt"
+	"sort"
 	"testing"
 	"time"
 
@@ -125,3 +127,116 @@ func TestIntervalsFromEvents_OperatorProgressing2(t *testing.T) {
 	actual := intervalsFromEvents_OperatorProgressing(intervals, nil, time.Time{}, time.Time{})
 	assert.Equal(t, 1, len(actual))
 }
+
+func conditionInterval(operator, condition, status, reason, message string, at string) monitorapi.Interval {
+	mb := monitorapi.NewMessage().
+		WithAnnotation(monitorapi.AnnotationCondition, condition).
+		WithAnnotation(monitorapi.AnnotationStatus, status).
+		HumanMessage(message)
+	if len(reason) > 0 {
+		mb = mb.Reason(monitorapi.IntervalReason(reason))
+	}
+	return monitorapi.NewInterval(monitorapi.SourceClusterOperatorMonitor, monitorapi.Warning).
+		Locator(monitorapi.NewLocator().ClusterOperator(operator)).
+		Message(mb).
+		Build(timeFor(at), timeFor(at))
+}
+
+func summarize(intervals monitorapi.Intervals) []string {
+	ret := []string{}
+	for _, interval := range intervals {
+		ret = append(ret, fmt.Sprintf("%s display=%v %s", interval.Source, interval.Display, interval.String()))
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// TestOperatorStatusIntervals runs the operator state machines through the cases the hand written loop they replaced
+// was checked against.  They produce the same intervals, including for states still open at the end.
+func TestOperatorStatusIntervals(t *testing.T) {
+	tests := []struct {
+		name      string
+		construct func(monitorapi.Intervals, monitorapi.ResourcesMap, time.Time, time.Time) monitorapi.Intervals
+		intervals monitorapi.Intervals
+		expected  []string
+	}{
+		{
+			name:      "unavailable then available",
+			construct: intervalsFromEvents_OperatorAvailable,
+			intervals: monitorapi.Intervals{
+				conditionInterval("etcd", "Available", "False", "NoQuorum", "quorum lost", "2024-01-01T00:01:00Z"),
+				conditionInterval("etcd", "Available", "True", "AsExpected", "", "2024-01-01T00:02:00Z"),
+			},
+			expected: []string{
+				"OperatorState display=true Jan 01 00:01:00.000 - 60s   E clusteroperator/etcd condition/Available reason/NoQuorum status/False quorum lost",
+			},
+		},
+		{
+			name:      "available from the start",
+			construct: intervalsFromEvents_OperatorAvailable,
+			intervals: monitorapi.Intervals{
+				conditionInterval("etcd", "Available", "True", "AsExpected", "", "2024-01-01T00:02:00Z"),
+			},
+			expected: []string{
+				"OperatorState display=true Jan 01 00:00:00.000 - 120s  E clusteroperator/etcd condition/Available reason/Unknown status/False Unknown",
+			},
+		},
+		{
+			name:      "never available again",
+			construct: intervalsFromEvents_OperatorAvailable,
+			intervals: monitorapi.Intervals{
+				conditionInterval("etcd", "Available", "False", "NoQuorum", "quorum lost", "2024-01-01T00:01:00Z"),
+			},
+			expected: []string{
+				"OperatorState display=true Jan 01 00:01:00.000 - 3540s E clusteroperator/etcd condition/Available reason/NoQuorum status/False quorum lost",
+			},
+		},
+		{
+			name:      "unknown keeps the first bad condition",
+			construct: intervalsFromEvents_OperatorAvailable,
+			intervals: monitorapi.Intervals{
+				conditionInterval("etcd", "Available", "False", "NoQuorum", "quorum lost", "2024-01-01T00:01:00Z"),
+				conditionInterval("etcd", "Available", "Unknown", "", "no idea", "2024-01-01T00:01:30Z"),
+				conditionInterval("etcd", "Available", "True", "AsExpected", "", "2024-01-01T00:02:00Z"),
+			},
+			expected: []string{
+				"OperatorState display=true Jan 01 00:01:00.000 - 60s   E clusteroperator/etcd condition/Available reason/NoQuorum status/False quorum lost",
+			},
+		},
+		{
+			name:      "operators and conditions are separate",
+			construct: intervalsFromEvents_OperatorDegraded,
+			intervals: monitorapi.Intervals{
+				conditionInterval("etcd", "Degraded", "True", "Broken", "etcd is broken", "2024-01-01T00:01:00Z"),
+				conditionInterval("dns", "Degraded", "True", "Broken", "dns is broken", "2024-01-01T00:01:10Z"),
+				conditionInterval("etcd", "Available", "False", "NoQuorum", "quorum lost", "2024-01-01T00:01:20Z"),
+				conditionInterval("etcd", "Degraded", "False", "", "", "2024-01-01T00:02:00Z"),
+				conditionInterval("dns", "Degraded", "True", "StillBroken", "dns is still broken", "2024-01-01T00:02:10Z"),
+			},
+			expected: []string{
+				"OperatorState display=true Jan 01 00:01:00.000 - 60s   E clusteroperator/etcd condition/Degraded reason/Broken status/True etcd is broken",
+				"OperatorState display=true Jan 01 00:01:10.000 - 3530s E clusteroperator/dns condition/Degraded reason/Broken status/True dns is broken",
+			},
+		},
+		{
+			name:      "progressing repeatedly",
+			construct: intervalsFromEvents_OperatorProgressing,
+			intervals: monitorapi.Intervals{
+				conditionInterval("network", "Progressing", "True", "Deploying", "rolling out", "2024-01-01T00:01:00Z"),
+				conditionInterval("network", "Progressing", "False", "", "", "2024-01-01T00:02:00Z"),
+				conditionInterval("network", "Progressing", "True", "Deploying", "rolling out again", "2024-01-01T00:03:00Z"),
+				conditionInterval("network", "Progressing", "False", "", "", "2024-01-01T00:04:00Z"),
+			},
+			expected: []string{
+				"OperatorState display=true Jan 01 00:01:00.000 - 60s   W clusteroperator/network condition/Progressing reason/Deploying status/True rolling out",
+				"OperatorState display=true Jan 01 00:03:00.000 - 60s   W clusteroperator/network condition/Progressing reason/Deploying status/True rolling out again",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := test.construct(test.intervals, nil, timeFor("2024-01-01T00:00:00Z"), timeFor("2024-01-01T01:00:00Z"))
+			assert.Equal(t, test.expected, summarize(actual))
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/openshift/origin/pkg/monitortestframework"
 
 	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionpolicy"
 
 	routev1 "github.com/openshift/api/route/v1"
 	routeclient "github.com/openshift/client-go/route/clientset/versioned"
@@ -39,10 +40,14 @@ type availability struct {
 	disruptionChecker  *disruptionlibrary.Availability
 	notSupportedReason error
 	suppressJunit      bool
+
+	disruptionPolicy *disruptionpolicy.Policy
 }
 
-func NewAvailabilityInvariant() monitortestframework.MonitorTest {
-	return &availability{}
+func NewAvailabilityInvariant(info monitortestframework.MonitorTestInitializationInfo) monitortestframework.MonitorTest {
+	return &availability{
+		disruptionPolicy: info.DisruptionPolicy,
+	}
 }
 
 func NewRecordAvailabilityOnly() monitortestframework.MonitorTest {
@@ -51,6 +56,15 @@ func NewRecordAvailabilityOnly() monitortestframework.MonitorTest {
 	}
 }
 
+func (w *availability) PrepareForOfflineEvaluation(ctx context.Context, info monitortestframework.OfflineEvaluationInfo) error {
+	w.disruptionChecker = disruptionlibrary.NewOfflineAvailabilityInvariant(
+		newConnectionTestName, reusedConnectionTestName,
+		"image-registry-new-connections", "image-registry-reused-connections",
+		w.disruptionPolicy,
+	)
+	return w.disruptionChecker.PrepareForOfflineEvaluation(ctx, info)
+}
+
 func (w *availability) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
 	var err error
 
@@ -109,6 +123,7 @@ func (w *availability) StartCollection(ctx context.Context, adminRESTConfig *res
 	w.disruptionChecker = disruptionlibrary.NewAvailabilityInvariant(
 		newConnectionTestName, reusedConnectionTestName,
 		newConnectionDisruptionSampler, reusedConnectionDisruptionSampler,
+		w.disruptionPolicy,
 	)
 	if err := w.disruptionChecker.StartCollection(ctx, adminRESTConfig, recorder); err != nil {
 		return err
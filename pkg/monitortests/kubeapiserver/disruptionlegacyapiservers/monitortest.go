@@ -16,6 +16,7 @@ import (
 
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionpolicy"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 )
 
@@ -24,10 +25,14 @@ type availability struct {
 
 	notSupportedReason error
 	suppressJunit      bool
+
+	disruptionPolicy *disruptionpolicy.Policy
 }
 
-func NewAvailabilityInvariant() monitortestframework.MonitorTest {
-	return &availability{}
+func NewAvailabilityInvariant(info monitortestframework.MonitorTestInitializationInfo) monitortestframework.MonitorTest {
+	return &availability{
+		disruptionPolicy: info.DisruptionPolicy,
+	}
 }
 
 func NewRecordAvailabilityOnly() monitortestframework.MonitorTest {
@@ -40,7 +45,7 @@ func testNames(owner, disruptionBackendName string) (string, string) {
 		fmt.Sprintf("[%s] disruption/%s connection/reused should be available throughout the test", owner, disruptionBackendName)
 }
 
-func newDisruptionCheckerForKubeAPI(adminRESTConfig *rest.Config) (*disruptionlibrary.Availability, error) {
+func newDisruptionCheckerForKubeAPI(adminRESTConfig *rest.Config, disruptionPolicy *disruptionpolicy.Policy) (*disruptionlibrary.Availability, error) {
 	disruptionBackedName := "kube-api"
 	newConnectionTestName, reusedConnectionTestName := testNames("sig-api-machinery", disruptionBackedName)
 	newConnections, err := createAPIServerBackendSampler(adminRESTConfig, disruptionBackedName, "/api/v1/namespaces/default", monitorapi.NewConnectionType)
@@ -54,10 +59,11 @@ func newDisruptionCheckerForKubeAPI(adminRESTConfig *rest.Config) (*disruptionli
 	return disruptionlibrary.NewAvailabilityInvariant(
 		newConnectionTestName, reusedConnectionTestName,
 		newConnections, reusedConnections,
+		disruptionPolicy,
 	), nil
 }
 
-func newDisruptionCheckerForKubeAPICached(adminRESTConfig *rest.Config) (*disruptionlibrary.Availability, error) {
+func newDisruptionCheckerForKubeAPICached(adminRESTConfig *rest.Config, disruptionPolicy *disruptionpolicy.Policy) (*disruptionlibrary.Availability, error) {
 	// by setting resourceVersion="0" we instruct the server to get the data from the memory cache and avoid contacting with the etcd.
 
 	disruptionBackedName := "cache-kube-api"
@@ -73,10 +79,11 @@ func newDisruptionCheckerForKubeAPICached(adminRESTConfig *rest.Config) (*disrup
 	return disruptionlibrary.NewAvailabilityInvariant(
 		newConnectionTestName, reusedConnectionTestName,
 		newConnections, reusedConnections,
+		disruptionPolicy,
 	), nil
 }
 
-func newDisruptionCheckerForOpenshiftAPI(adminRESTConfig *rest.Config) (*disruptionlibrary.Availability, error) {
+func newDisruptionCheckerForOpenshiftAPI(adminRESTConfig *rest.Config, disruptionPolicy *disruptionpolicy.Policy) (*disruptionlibrary.Availability, error) {
 	disruptionBackedName := "openshift-api"
 	newConnectionTestName, reusedConnectionTestName := testNames("sig-api-machinery", disruptionBackedName)
 	newConnections, err := createAPIServerBackendSampler(adminRESTConfig, disruptionBackedName, "/apis/image.openshift.io/v1/namespaces/default/imagestreams", monitorapi.NewConnectionType)
@@ -90,10 +97,11 @@ func newDisruptionCheckerForOpenshiftAPI(adminRESTConfig *rest.Config) (*disrupt
 	return disruptionlibrary.NewAvailabilityInvariant(
 		newConnectionTestName, reusedConnectionTestName,
 		newConnections, reusedConnections,
+		disruptionPolicy,
 	), nil
 }
 
-func newDisruptionCheckerForOpenshiftAPICached(adminRESTConfig *rest.Config) (*disruptionlibrary.Availability, error) {
+func newDisruptionCheckerForOpenshiftAPICached(adminRESTConfig *rest.Config, disruptionPolicy *disruptionpolicy.Policy) (*disruptionlibrary.Availability, error) {
 	// by setting resourceVersion="0" we instruct the server to get the data from the memory cache and avoid contacting with the etcd.
 
 	disruptionBackedName := "cache-openshift-api"
@@ -109,10 +117,11 @@ func newDisruptionCheckerForOpenshiftAPICached(adminRESTConfig *rest.Config) (*d
 	return disruptionlibrary.NewAvailabilityInvariant(
 		newConnectionTestName, reusedConnectionTestName,
 		newConnections, reusedConnections,
+		disruptionPolicy,
 	), nil
 }
 
-func newDisruptionCheckerForOAuthAPI(adminRESTConfig *rest.Config) (*disruptionlibrary.Availability, error) {
+func newDisruptionCheckerForOAuthAPI(adminRESTConfig *rest.Config, disruptionPolicy *disruptionpolicy.Policy) (*disruptionlibrary.Availability, error) {
 	disruptionBackedName := "oauth-api"
 	newConnectionTestName, reusedConnectionTestName := testNames("sig-api-machinery", disruptionBackedName)
 	newConnections, err := createAPIServerBackendSampler(adminRESTConfig, disruptionBackedName, "/apis/oauth.openshift.io/v1/oauthclients", monitorapi.NewConnectionType)
@@ -126,10 +135,11 @@ func newDisruptionCheckerForOAuthAPI(adminRESTConfig *rest.Config) (*disruptionl
 	return disruptionlibrary.NewAvailabilityInvariant(
 		newConnectionTestName, reusedConnectionTestName,
 		newConnections, reusedConnections,
+		disruptionPolicy,
 	), nil
 }
 
-func newDisruptionCheckerForOAuthCached(adminRESTConfig *rest.Config) (*disruptionlibrary.Availability, error) {
+func newDisruptionCheckerForOAuthCached(adminRESTConfig *rest.Config, disruptionPolicy *disruptionpolicy.Policy) (*disruptionlibrary.Availability, error) {
 	// by setting resourceVersion="0" we instruct the server to get the data from the memory cache and avoid contacting with the etcd.
 
 	disruptionBackedName := "cache-oauth-api"
@@ -145,9 +155,32 @@ func newDisruptionCheckerForOAuthCached(adminRESTConfig *rest.Config) (*disrupti
 	return disruptionlibrary.NewAvailabilityInvariant(
 		newConnectionTestName, reusedConnectionTestName,
 		newConnections, reusedConnections,
+		disruptionPolicy,
 	), nil
 }
 
+// newOfflineDisruptionChecker checks a backend sampled by a replayed run.
+func newOfflineDisruptionChecker(disruptionBackedName string, disruptionPolicy *disruptionpolicy.Policy) *disruptionlibrary.Availability {
+	newConnectionTestName, reusedConnectionTestName := testNames("sig-api-machinery", disruptionBackedName)
+	return disruptionlibrary.NewOfflineAvailabilityInvariant(
+		newConnectionTestName, reusedConnectionTestName,
+		fmt.Sprintf("%s-%v-connections", disruptionBackedName, monitorapi.NewConnectionType),
+		fmt.Sprintf("%s-%v-connections", disruptionBackedName, monitorapi.ReusedConnectionType),
+		disruptionPolicy,
+	)
+}
+
+func (w *availability) PrepareForOfflineEvaluation(ctx context.Context, info monitortestframework.OfflineEvaluationInfo) error {
+	for _, disruptionBackedName := range []string{"kube-api", "cache-kube-api", "openshift-api", "cache-openshift-api", "oauth-api", "cache-oauth-api"} {
+		curr := newOfflineDisruptionChecker(disruptionBackedName, w.disruptionPolicy)
+		if err := curr.PrepareForOfflineEvaluation(ctx, info); err != nil {
+			return err
+		}
+		w.disruptionCheckers = append(w.disruptionCheckers, curr)
+	}
+	return nil
+}
+
 func (w *availability) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
 	var err error
 
@@ -179,34 +212,34 @@ func (w *availability) StartCollection(ctx context.Context, adminRESTConfig *res
 
 	var curr *disruptionlibrary.Availability
 
-	curr, err = newDisruptionCheckerForKubeAPI(adminRESTConfig)
+	curr, err = newDisruptionCheckerForKubeAPI(adminRESTConfig, w.disruptionPolicy)
 	if err != nil {
 		return err
 	}
 	w.disruptionCheckers = append(w.disruptionCheckers, curr)
-	curr, err = newDisruptionCheckerForKubeAPICached(adminRESTConfig)
+	curr, err = newDisruptionCheckerForKubeAPICached(adminRESTConfig, w.disruptionPolicy)
 	if err != nil {
 		return err
 	}
 	w.disruptionCheckers = append(w.disruptionCheckers, curr)
 
-	curr, err = newDisruptionCheckerForOpenshiftAPI(adminRESTConfig)
+	curr, err = newDisruptionCheckerForOpenshiftAPI(adminRESTConfig, w.disruptionPolicy)
 	if err != nil {
 		return err
 	}
 	w.disruptionCheckers = append(w.disruptionCheckers, curr)
-	curr, err = newDisruptionCheckerForOpenshiftAPICached(adminRESTConfig)
+	curr, err = newDisruptionCheckerForOpenshiftAPICached(adminRESTConfig, w.disruptionPolicy)
 	if err != nil {
 		return err
 	}
 	w.disruptionCheckers = append(w.disruptionCheckers, curr)
 
-	curr, err = newDisruptionCheckerForOAuthAPI(adminRESTConfig)
+	curr, err = newDisruptionCheckerForOAuthAPI(adminRESTConfig, w.disruptionPolicy)
 	if err != nil {
 		return err
 	}
 	w.disruptionCheckers = append(w.disruptionCheckers, curr)
-	curr, err = newDisruptionCheckerForOAuthCached(adminRESTConfig)
+	curr, err = newDisruptionCheckerForOAuthCached(adminRESTConfig, w.disruptionPolicy)
 	if err != nil {
 		return err
 	}
@@ -13,6 +13,11 @@ import (
 
 type legacyMonitorTests struct {
 	adminRESTConfig *rest.Config
+
+	// offline is set when replaying saved data. The static pod lifecycle test is skipped
+	// then, it lists the static pods and their events from the cluster. So are the early
+	// E2E disruption tests, they ask the cluster whether it is single node.
+	offline bool
 }
 
 func NewLegacyTests() monitortestframework.MonitorTest {
@@ -24,6 +29,11 @@ func (w *legacyMonitorTests) StartCollection(ctx context.Context, adminRESTConfi
 	return nil
 }
 
+func (w *legacyMonitorTests) PrepareForOfflineEvaluation(ctx context.Context, info monitortestframework.OfflineEvaluationInfo) error {
+	w.offline = true
+	return nil
+}
+
 func (w *legacyMonitorTests) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
 	return nil, nil, nil
 }
@@ -35,9 +45,17 @@ func (*legacyMonitorTests) ConstructComputedIntervals(ctx context.Context, start
 func (w *legacyMonitorTests) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
 	junits := []*junitapi.JUnitTestCase{}
 	junits = append(junits, testPodNodeNameIsImmutable(finalIntervals)...)
-	junits = append(junits, testStaticPodLifecycleFailure(finalIntervals, w.adminRESTConfig)...)
 	junits = append(junits, testAPIServerIPTablesAccessDisruption(finalIntervals)...)
-	junits = append(junits, testEarlyE2EAPIServerDisruption(finalIntervals, w.adminRESTConfig)...)
+	if w.offline {
+		junits = append(junits, &junitapi.JUnitTestCase{
+			Name:        staticPodLifecycleTestName,
+			SkipMessage: &junitapi.SkipMessage{Message: "static pods and their events are listed from the cluster, it is not run on saved data"},
+		})
+		junits = append(junits, earlyE2ESkips("the topology of the cluster is needed, it is not run on saved data")...)
+	} else {
+		junits = append(junits, testStaticPodLifecycleFailure(finalIntervals, w.adminRESTConfig)...)
+		junits = append(junits, testEarlyE2EAPIServerDisruption(finalIntervals, w.adminRESTConfig)...)
+	}
 
 	return junits, nil
 }
@@ -43,9 +43,11 @@ func staticPodFailureFromMessage(message string) (*staticPodFailure, error) {
 	}, nil
 }
 
+const staticPodLifecycleTestName = `[sig-node] static pods should start after being created`
+
 func testStaticPodLifecycleFailure(events monitorapi.Intervals, kubeClientConfig *rest.Config) []*junitapi.JUnitTestCase {
 	ctx := context.TODO()
-	const testName = `[sig-node] static pods should start after being created`
+	const testName = staticPodLifecycleTestName
 	failures := []string{}
 
 	kubeClient, err := kubernetes.NewForConfig(kubeClientConfig)
@@ -14,6 +14,7 @@ import (
 	"github.com/openshift/origin/pkg/monitor/backenddisruption"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionpolicy"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 	exutil "github.com/openshift/origin/test/extended/util"
 )
@@ -26,10 +27,14 @@ const (
 type availability struct {
 	disruptionChecker  *disruptionlibrary.Availability
 	notSupportedReason error
+
+	disruptionPolicy *disruptionpolicy.Policy
 }
 
-func NewAvailabilityInvariant() monitortestframework.MonitorTest {
-	return &availability{}
+func NewAvailabilityInvariant(info monitortestframework.MonitorTestInitializationInfo) monitortestframework.MonitorTest {
+	return &availability{
+		disruptionPolicy: info.DisruptionPolicy,
+	}
 }
 
 func createBackendSampler(clusterConfig *rest.Config, disruptionBackendName, url string, connectionType monitorapi.BackendConnectionType) (*backenddisruption.BackendSampler, error) {
@@ -87,6 +92,7 @@ func (w *availability) StartCollection(ctx context.Context, adminRESTConfig *res
 	w.disruptionChecker = disruptionlibrary.NewAvailabilityInvariant(
 		newConnectionTestName, reusedConnectionTestName,
 		newConnections, reusedConnections,
+		w.disruptionPolicy,
 	)
 
 	if err := w.disruptionChecker.StartCollection(ctx, adminRESTConfig, recorder); err != nil {
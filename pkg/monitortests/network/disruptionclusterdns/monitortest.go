@@ -0,0 +1,188 @@
+package disruptionclusterdns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/openshift/origin/pkg/disruption/ci"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionpolicy"
+	"github.com/openshift/origin/pkg/monitortestlibrary/inclusterdisruption"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/monitortests/network/disruptionpodnetwork"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"github.com/openshift/origin/test/extended/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	dnsNamespace   = "openshift-dns"
+	dnsServiceName = "dns-default"
+	// resolvedName is always served by the cluster DNS, whatever the state of the workloads.
+	resolvedName = "kubernetes.default.svc.cluster.local"
+
+	newConnectionTestName = "[sig-network] disruption/cluster-dns connection/new should be available throughout the test"
+)
+
+type clusterDNSAvailability struct {
+	payloadImagePullSpec string
+	disruptionPolicy     *disruptionpolicy.Policy
+
+	adminRESTConfig    *rest.Config
+	backend            inclusterdisruption.BackendSpec
+	notSupportedReason error
+	deployer           *inclusterdisruption.Deployer
+	// offlineJobType is read from the saved cluster data when a run is replayed
+	offlineJobType *platformidentification.JobType
+	// runDuration is the length of the run, for disruption policy budgets relative to it
+	runDuration time.Duration
+}
+
+// NewAvailabilityInvariant resolves a name with the cluster DNS service from a pod on every node.  Queries are sent
+// over UDP, so there are only new connections.
+func NewAvailabilityInvariant(info monitortestframework.MonitorTestInitializationInfo) monitortestframework.MonitorTest {
+	return &clusterDNSAvailability{
+		payloadImagePullSpec: info.UpgradeTargetPayloadImagePullSpec,
+		disruptionPolicy:     info.DisruptionPolicy,
+	}
+}
+
+// newBackend is the backend sampled by the pods, address is only needed to sample it.
+func newBackend(address string) inclusterdisruption.BackendSpec {
+	backend := inclusterdisruption.BackendSpec{
+		Name:     string(ci.ClusterDNS),
+		Protocol: inclusterdisruption.ProtocolDNS,
+		Address:  address,
+		Target:   resolvedName,
+		Placement: inclusterdisruption.Placement{
+			Spread: inclusterdisruption.SpreadPerNode,
+		},
+	}
+	backend.SetDefaults()
+	return backend
+}
+
+func (w *clusterDNSAvailability) PrepareForOfflineEvaluation(ctx context.Context, info monitortestframework.OfflineEvaluationInfo) error {
+	jobType, err := disruptionlibrary.OfflineJobType(info)
+	if err != nil {
+		return err
+	}
+	w.offlineJobType = jobType
+	w.backend = newBackend("")
+	return nil
+}
+
+func (w *clusterDNSAvailability) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	w.adminRESTConfig = adminRESTConfig
+	kubeClient, err := kubernetes.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+
+	dnsService, err := kubeClient.CoreV1().Services(dnsNamespace).Get(ctx, dnsServiceName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		w.notSupportedReason = &monitortestframework.NotSupportedError{Reason: fmt.Sprintf("service/%s is not in namespace/%s", dnsServiceName, dnsNamespace)}
+		return w.notSupportedReason
+	case err != nil:
+		return err
+	}
+
+	// the sampler pods are unschedulable on ROSA like those of the pod network, TRT-1869
+	isManagedServiceCluster, err := util.IsManagedServiceCluster(ctx, kubeClient)
+	if err != nil {
+		return err
+	}
+	if isManagedServiceCluster {
+		w.notSupportedReason = &monitortestframework.NotSupportedError{Reason: "cluster DNS samplers are unschedulable on ROSA TRT-1869"}
+		return w.notSupportedReason
+	}
+
+	openshiftTestsImagePullSpec, err := disruptionpodnetwork.GetOpenshiftTestsImagePullSpec(ctx, adminRESTConfig, w.payloadImagePullSpec, nil)
+	if err != nil {
+		w.notSupportedReason = &monitortestframework.NotSupportedError{Reason: fmt.Sprintf("unable to determine openshift-tests image: %v", err)}
+		return w.notSupportedReason
+	}
+
+	w.backend = newBackend(net.JoinHostPort(dnsService.Spec.ClusterIP, "53"))
+	if err := w.backend.Validate(); err != nil {
+		return err
+	}
+
+	w.deployer = inclusterdisruption.NewDeployer(kubeClient, openshiftTestsImagePullSpec, []inclusterdisruption.BackendSpec{w.backend})
+	return w.deployer.Deploy(ctx)
+}
+
+func (w *clusterDNSAvailability) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	if w.notSupportedReason != nil {
+		return nil, nil, w.notSupportedReason
+	}
+	if w.deployer == nil {
+		return nil, nil, nil
+	}
+	w.runDuration = end.Sub(beginning)
+	return w.deployer.CollectData(ctx)
+}
+
+func (w *clusterDNSAvailability) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	if w.offlineJobType != nil {
+		// a replayed run is not collected
+		w.runDuration = end.Sub(beginning)
+	}
+	return nil, w.notSupportedReason
+}
+
+func (w *clusterDNSAvailability) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	if w.notSupportedReason != nil {
+		return nil, w.notSupportedReason
+	}
+	jobType := w.offlineJobType
+	switch {
+	case jobType != nil:
+		backendName := w.backend.DisruptionBackendName(monitorapi.NewConnectionType)
+		if !disruptionlibrary.WasSampled(finalIntervals, backendName) {
+			return []*junitapi.JUnitTestCase{disruptionlibrary.NotSampledJunit(newConnectionTestName, backendName)}, nil
+		}
+	case w.deployer == nil:
+		return nil, nil
+	default:
+		var err error
+		if jobType, err = platformidentification.GetJobType(ctx, w.adminRESTConfig); err != nil {
+			return nil, err
+		}
+	}
+	newConnectionJunit, err := disruptionlibrary.BackendAvailabilityJunit(
+		w.disruptionPolicy,
+		newConnectionTestName,
+		w.backend.DisruptionBackendName(monitorapi.NewConnectionType),
+		inclusterdisruption.ProbeLocator(w.backend, monitorapi.NewConnectionType),
+		finalIntervals,
+		jobType,
+		w.runDuration,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return []*junitapi.JUnitTestCase{newConnectionJunit}, nil
+}
+
+func (w *clusterDNSAvailability) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return w.notSupportedReason
+}
+
+func (w *clusterDNSAvailability) Cleanup(ctx context.Context) error {
+	if w.notSupportedReason != nil {
+		return w.notSupportedReason
+	}
+	if w.deployer == nil {
+		return nil
+	}
+	return w.deployer.Cleanup(ctx)
+}
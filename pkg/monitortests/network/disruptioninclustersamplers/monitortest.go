@@ -0,0 +1,159 @@
+package disruptioninclustersamplers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionpolicy"
+	"github.com/openshift/origin/pkg/monitortestlibrary/inclusterdisruption"
+	"github.com/openshift/origin/pkg/monitortestlibrary/platformidentification"
+	"github.com/openshift/origin/pkg/monitortests/network/disruptionpodnetwork"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// MonitorName is only registered when a file of backend specs is given.
+const MonitorName = "in-cluster-disruption-samplers"
+
+type inClusterSamplers struct {
+	backendsFile         string
+	payloadImagePullSpec string
+	disruptionPolicy     *disruptionpolicy.Policy
+
+	adminRESTConfig    *rest.Config
+	backends           []inclusterdisruption.BackendSpec
+	notSupportedReason error
+	deployer           *inclusterdisruption.Deployer
+	// offlineJobType is read from the saved cluster data when a run is replayed
+	offlineJobType *platformidentification.JobType
+	// runDuration is the length of the run, for disruption policy budgets relative to it
+	runDuration time.Duration
+}
+
+// NewInClusterSamplersInvariant samples the backends of the spec file from sampler pods inside the cluster.
+func NewInClusterSamplersInvariant(info monitortestframework.MonitorTestInitializationInfo) monitortestframework.MonitorTest {
+	return &inClusterSamplers{
+		backendsFile:         info.DisruptionBackends,
+		payloadImagePullSpec: info.UpgradeTargetPayloadImagePullSpec,
+		disruptionPolicy:     info.DisruptionPolicy,
+	}
+}
+
+func (w *inClusterSamplers) PrepareForOfflineEvaluation(ctx context.Context, info monitortestframework.OfflineEvaluationInfo) error {
+	jobType, err := disruptionlibrary.OfflineJobType(info)
+	if err != nil {
+		return err
+	}
+	backends, err := inclusterdisruption.ReadBackendSpecs(w.backendsFile)
+	if err != nil {
+		return err
+	}
+	w.offlineJobType = jobType
+	w.backends = backends
+	return nil
+}
+
+func (w *inClusterSamplers) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	w.adminRESTConfig = adminRESTConfig
+	backends, err := inclusterdisruption.ReadBackendSpecs(w.backendsFile)
+	if err != nil {
+		return err
+	}
+	w.backends = backends
+
+	openshiftTestsImagePullSpec, err := disruptionpodnetwork.GetOpenshiftTestsImagePullSpec(ctx, adminRESTConfig, w.payloadImagePullSpec, nil)
+	if err != nil {
+		w.notSupportedReason = &monitortestframework.NotSupportedError{Reason: fmt.Sprintf("unable to determine openshift-tests image: %v", err)}
+		return w.notSupportedReason
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+	w.deployer = inclusterdisruption.NewDeployer(kubeClient, openshiftTestsImagePullSpec, backends)
+	return w.deployer.Deploy(ctx)
+}
+
+func (w *inClusterSamplers) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	if w.notSupportedReason != nil {
+		return nil, nil, w.notSupportedReason
+	}
+	if w.deployer == nil {
+		return nil, nil, nil
+	}
+	w.runDuration = end.Sub(beginning)
+	return w.deployer.CollectData(ctx)
+}
+
+func (w *inClusterSamplers) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	if w.offlineJobType != nil {
+		// a replayed run is not collected
+		w.runDuration = end.Sub(beginning)
+	}
+	return nil, w.notSupportedReason
+}
+
+// EvaluateTestsFromConstructedIntervals returns the availability of every backend and connection type sampled.  When a
+// run is replayed, the tests of a backend it did not sample are skipped.
+func (w *inClusterSamplers) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	if w.notSupportedReason != nil {
+		return nil, w.notSupportedReason
+	}
+	jobType := w.offlineJobType
+	if jobType == nil {
+		if w.deployer == nil {
+			return nil, nil
+		}
+		var err error
+		if jobType, err = platformidentification.GetJobType(ctx, w.adminRESTConfig); err != nil {
+			return nil, err
+		}
+	}
+	junits := []*junitapi.JUnitTestCase{}
+	for _, backend := range w.backends {
+		for _, connectionType := range backend.ConnectionTypes {
+			if w.offlineJobType != nil && !disruptionlibrary.WasSampled(finalIntervals, backend.DisruptionBackendName(connectionType)) {
+				junits = append(junits, disruptionlibrary.NotSampledJunit(availabilityTestName(backend, connectionType), backend.DisruptionBackendName(connectionType)))
+				continue
+			}
+			junit, err := disruptionlibrary.BackendAvailabilityJunit(
+				w.disruptionPolicy,
+				availabilityTestName(backend, connectionType),
+				backend.DisruptionBackendName(connectionType),
+				inclusterdisruption.ProbeLocator(backend, connectionType),
+				finalIntervals,
+				jobType,
+				w.runDuration,
+			)
+			if err != nil {
+				return nil, err
+			}
+			junits = append(junits, junit)
+		}
+	}
+	return junits, nil
+}
+
+func availabilityTestName(backend inclusterdisruption.BackendSpec, connectionType monitorapi.BackendConnectionType) string {
+	return fmt.Sprintf("[sig-network] disruption/%s connection/%v should be available throughout the test", backend.Name, connectionType)
+}
+
+func (w *inClusterSamplers) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return w.notSupportedReason
+}
+
+func (w *inClusterSamplers) Cleanup(ctx context.Context) error {
+	if w.notSupportedReason != nil {
+		return w.notSupportedReason
+	}
+	if w.deployer == nil {
+		return nil
+	}
+	return w.deployer.Cleanup(ctx)
+}
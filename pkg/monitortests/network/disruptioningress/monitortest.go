@@ -19,6 +19,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionpolicy"
 
 	routeclient "github.com/openshift/client-go/route/clientset/versioned"
 	"github.com/openshift/origin/pkg/monitor/backenddisruption"
@@ -30,10 +31,14 @@ import (
 type availability struct {
 	disruptionCheckers []*disruptionlibrary.Availability
 	suppressJunit      bool
+
+	disruptionPolicy *disruptionpolicy.Policy
 }
 
-func NewAvailabilityInvariant() monitortestframework.MonitorTest {
-	return &availability{}
+func NewAvailabilityInvariant(info monitortestframework.MonitorTestInitializationInfo) monitortestframework.MonitorTest {
+	return &availability{
+		disruptionPolicy: info.DisruptionPolicy,
+	}
 }
 
 func NewRecordAvailabilityOnly() monitortestframework.MonitorTest {
@@ -42,6 +47,31 @@ func NewRecordAvailabilityOnly() monitortestframework.MonitorTest {
 	}
 }
 
+// PrepareForOfflineEvaluation checks both routes, the tests of the console route are skipped if the replayed run did
+// not sample it.
+func (w *availability) PrepareForOfflineEvaluation(ctx context.Context, info monitortestframework.OfflineEvaluationInfo) error {
+	w.disruptionCheckers = []*disruptionlibrary.Availability{
+		disruptionlibrary.NewOfflineAvailabilityInvariant(
+			"[sig-network-edge] ns/openshift-authentication route/oauth-openshift disruption/ingress-to-oauth-server connection/new should be available throughout the test",
+			"[sig-network-edge] ns/openshift-authentication route/oauth-openshift disruption/ingress-to-oauth-server connection/reused should be available throughout the test",
+			"ingress-to-oauth-server-new-connections", "ingress-to-oauth-server-reused-connections",
+			w.disruptionPolicy,
+		),
+		disruptionlibrary.NewOfflineAvailabilityInvariant(
+			"[sig-network-edge] ns/openshift-console route/console disruption/ingress-to-console connection/new should be available throughout the test",
+			"[sig-network-edge] ns/openshift-console route/console disruption/ingress-to-console connection/reused should be available throughout the test",
+			"ingress-to-console-new-connections", "ingress-to-console-reused-connections",
+			w.disruptionPolicy,
+		),
+	}
+	for i := range w.disruptionCheckers {
+		if err := w.disruptionCheckers[i].PrepareForOfflineEvaluation(ctx, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (w *availability) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
 	var err error
 
@@ -65,6 +95,7 @@ func (w *availability) StartCollection(ctx context.Context, adminRESTConfig *res
 		disruptionChecker := disruptionlibrary.NewAvailabilityInvariant(
 			newConnectionTestName, reusedConnectionTestName,
 			newConnectionDisruptionSampler, reusedConnectionDisruptionSampler,
+			w.disruptionPolicy,
 		)
 		w.disruptionCheckers = append(w.disruptionCheckers, disruptionChecker)
 	}
@@ -95,6 +126,7 @@ func (w *availability) StartCollection(ctx context.Context, adminRESTConfig *res
 			disruptionChecker := disruptionlibrary.NewAvailabilityInvariant(
 				newConnectionTestName, reusedConnectionTestName,
 				newConnectionDisruptionSampler, reusedConnectionDisruptionSampler,
+				w.disruptionPolicy,
 			)
 			w.disruptionCheckers = append(w.disruptionCheckers, disruptionChecker)
 
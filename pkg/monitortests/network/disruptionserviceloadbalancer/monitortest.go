@@ -31,6 +31,7 @@ import (
 	"github.com/openshift/origin/pkg/monitor/backenddisruption"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionpolicy"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 	exutil "github.com/openshift/origin/test/extended/util"
 	"github.com/openshift/origin/test/extended/util/image"
@@ -61,6 +62,8 @@ type availability struct {
 
 	disruptionChecker *disruptionlibrary.Availability
 	suppressJunit     bool
+
+	disruptionPolicy *disruptionpolicy.Policy
 }
 
 // isNotSupportedForPlatformExternal if platform type is external, this checks to see if the
@@ -83,8 +86,10 @@ func isNotSupportedForPlatformExternal(infra *configv1.Infrastructure) bool {
 	return notSupported
 }
 
-func NewAvailabilityInvariant() monitortestframework.MonitorTest {
-	return &availability{}
+func NewAvailabilityInvariant(info monitortestframework.MonitorTestInitializationInfo) monitortestframework.MonitorTest {
+	return &availability{
+		disruptionPolicy: info.DisruptionPolicy,
+	}
 }
 
 func NewRecordAvailabilityOnly() monitortestframework.MonitorTest {
@@ -93,6 +98,15 @@ func NewRecordAvailabilityOnly() monitortestframework.MonitorTest {
 	}
 }
 
+func (w *availability) PrepareForOfflineEvaluation(ctx context.Context, info monitortestframework.OfflineEvaluationInfo) error {
+	w.disruptionChecker = disruptionlibrary.NewOfflineAvailabilityInvariant(
+		newConnectionTestName, reusedConnectionTestName,
+		"service-load-balancer-with-pdb-new-connections", "service-load-balancer-with-pdb-reused-connections",
+		w.disruptionPolicy,
+	)
+	return w.disruptionChecker.PrepareForOfflineEvaluation(ctx, info)
+}
+
 func (w *availability) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
 	var err error
 
@@ -262,6 +276,7 @@ func (w *availability) StartCollection(ctx context.Context, adminRESTConfig *res
 	w.disruptionChecker = disruptionlibrary.NewAvailabilityInvariant(
 		newConnectionTestName, reusedConnectionTestName,
 		newConnectionDisruptionSampler, reusedConnectionDisruptionSampler,
+		w.disruptionPolicy,
 	)
 	if err := w.disruptionChecker.StartCollection(ctx, adminRESTConfig, recorder); err != nil {
 		return err
@@ -14,6 +14,12 @@ import (
 type legacyMonitorTests struct {
 	adminRESTConfig *rest.Config
 	duration        time.Duration
+
+	// offline is set when replaying saved data. The pod sandbox creation tests are skipped
+	// then, their allowances depend on the platform read from the cluster infrastructure.
+	// So is the ovnkube-node readiness probe test, it asks the cluster when the install
+	// completed and would ignore every repeated probe failure without it.
+	offline bool
 }
 
 func NewLegacyTests() monitortestframework.MonitorTest {
@@ -25,6 +31,11 @@ func (w *legacyMonitorTests) StartCollection(ctx context.Context, adminRESTConfi
 	return nil
 }
 
+func (w *legacyMonitorTests) PrepareForOfflineEvaluation(ctx context.Context, info monitortestframework.OfflineEvaluationInfo) error {
+	w.offline = true
+	return nil
+}
+
 func (w *legacyMonitorTests) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
 	w.duration = end.Sub(beginning)
 	return nil, nil, nil
@@ -36,8 +47,16 @@ func (*legacyMonitorTests) ConstructComputedIntervals(ctx context.Context, start
 
 func (w *legacyMonitorTests) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
 	junits := []*junitapi.JUnitTestCase{}
-	junits = append(junits, testPodSandboxCreation(finalIntervals, w.adminRESTConfig)...)
-	junits = append(junits, testOvnNodeReadinessProbe(finalIntervals, w.adminRESTConfig)...)
+	if w.offline {
+		junits = append(junits, podSandboxCreationSkips("pod sandbox creation allowances need the platform of the cluster, they are not run on saved data")...)
+		junits = append(junits, &junitapi.JUnitTestCase{
+			Name:        ovnNodeReadinessProbeTestName,
+			SkipMessage: &junitapi.SkipMessage{Message: "the install completion time is read from the cluster, it is not run on saved data"},
+		})
+	} else {
+		junits = append(junits, testPodSandboxCreation(finalIntervals, w.adminRESTConfig)...)
+		junits = append(junits, testOvnNodeReadinessProbe(finalIntervals, w.adminRESTConfig)...)
+	}
 	junits = append(junits, testNoDNSLookupErrorsInDisruptionSamplers(finalIntervals)...)
 	junits = append(junits, testNoOVSVswitchdUnreasonablyLongPollIntervals(finalIntervals)...)
 	junits = append(junits, testPodIPReuse(finalIntervals)...)
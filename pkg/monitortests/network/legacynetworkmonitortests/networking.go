@@ -50,26 +50,42 @@ func getPlatformType(clientConfig *rest.Config) (configv1.PlatformType, error) {
 	return infra.Status.PlatformStatus.Type, nil
 }
 
-func testPodSandboxCreation(events monitorapi.Intervals, clientConfig *rest.Config) []*junitapi.JUnitTestCase {
-	const testName = "[sig-network] pods should successfully create sandboxes"
-	// we can further refine this signal by subdividing different failure modes if it is pertinent.  Right now I'm seeing
-	// 1. error reading container (probably exited) json message: EOF
-	// 2. dial tcp 10.0.76.225:6443: i/o timeout
-	// 3. Path:"" ERRORED: error configuring pod [openshift-kube-apiserver/revision-pruner-10-master-1] networking: Multus: [openshift-kube-apiserver/revision-pruner-10-master-1/72c4e0fa-fcf2-47f7-a9ff-4efdb1dc55c5]: error waiting for pod: pod "revision-pruner-10-master-1" not found
-	// 4. write child: broken pipe
-	bySubStrings := []testCategorizer{
-		{by: " by reading container", substring: "error reading container (probably exited) json message: EOF"},
-		{by: " by pinging container registry", substring: "pinging container registry"}, // likely combined with i/o timeout but separate test for visibility
-		{by: " by not timing out", substring: "i/o timeout"},
-		{by: " by writing network status", substring: "error setting the networks status"},
-		{by: " by getting pod", substring: " error waiting for pod: pod"},
-		{by: " by writing child", substring: "write child: broken pipe"},
-		{by: " by ovn default network ready", substring: "have you checked that your default network is ready? still waiting for readinessindicatorfile"},
-		{by: " by adding pod to network", substring: "failed (add)"},
-		{by: " by initializing docker source", substring: `can't talk to a V1 container registry`},
-		{by: " by binding hostport", substring: "failed to add hostport"},
-		{by: " by other", substring: " "}, // always matches
+const podSandboxCreationTestName = "[sig-network] pods should successfully create sandboxes"
+
+// we can further refine this signal by subdividing different failure modes if it is pertinent.  Right now I'm seeing
+// 1. error reading container (probably exited) json message: EOF
+// 2. dial tcp 10.0.76.225:6443: i/o timeout
+// 3. Path:"" ERRORED: error configuring pod [openshift-kube-apiserver/revision-pruner-10-master-1] networking: Multus: [openshift-kube-apiserver/revision-pruner-10-master-1/72c4e0fa-fcf2-47f7-a9ff-4efdb1dc55c5]: error waiting for pod: pod "revision-pruner-10-master-1" not found
+// 4. write child: broken pipe
+var podSandboxCreationCategories = []testCategorizer{
+	{by: " by reading container", substring: "error reading container (probably exited) json message: EOF"},
+	{by: " by pinging container registry", substring: "pinging container registry"}, // likely combined with i/o timeout but separate test for visibility
+	{by: " by not timing out", substring: "i/o timeout"},
+	{by: " by writing network status", substring: "error setting the networks status"},
+	{by: " by getting pod", substring: " error waiting for pod: pod"},
+	{by: " by writing child", substring: "write child: broken pipe"},
+	{by: " by ovn default network ready", substring: "have you checked that your default network is ready? still waiting for readinessindicatorfile"},
+	{by: " by adding pod to network", substring: "failed (add)"},
+	{by: " by initializing docker source", substring: `can't talk to a V1 container registry`},
+	{by: " by binding hostport", substring: "failed to add hostport"},
+	{by: " by other", substring: " "}, // always matches
+}
+
+// podSandboxCreationSkips reports every pod sandbox creation test as skipped for reason.
+func podSandboxCreationSkips(reason string) []*junitapi.JUnitTestCase {
+	skips := []*junitapi.JUnitTestCase{}
+	for _, by := range podSandboxCreationCategories {
+		skips = append(skips, &junitapi.JUnitTestCase{
+			Name:        podSandboxCreationTestName + by.by,
+			SkipMessage: &junitapi.SkipMessage{Message: reason},
+		})
 	}
+	return skips
+}
+
+func testPodSandboxCreation(events monitorapi.Intervals, clientConfig *rest.Config) []*junitapi.JUnitTestCase {
+	const testName = podSandboxCreationTestName
+	bySubStrings := podSandboxCreationCategories
 
 	failures := []string{}
 	flakes := []string{}
@@ -318,8 +334,10 @@ func getPodDeletionTime(events monitorapi.Intervals, podLocator monitorapi.Locat
 
 // bug is tracked here: https://bugzilla.redhat.com/show_bug.cgi?id=2057181
 // It was closed working as designed.
+const ovnNodeReadinessProbeTestName = "[bz-networking] ovnkube-node readiness probe should not fail repeatedly"
+
 func testOvnNodeReadinessProbe(events monitorapi.Intervals, kubeClientConfig *rest.Config) []*junitapi.JUnitTestCase {
-	const testName = "[bz-networking] ovnkube-node readiness probe should not fail repeatedly"
+	const testName = ovnNodeReadinessProbeTestName
 	var tests []*junitapi.JUnitTestCase
 	var failureOutput string
 	msgMap := map[string]bool{}
@@ -15,6 +15,9 @@ import (
 
 type legacyMonitorTests struct {
 	adminRESTConfig *rest.Config
+
+	// offlineClusterData is set when replaying saved data, in which case the cluster must not be contacted.
+	offlineClusterData *platformidentification.ClusterData
 }
 
 func NewLegacyTests() monitortestframework.MonitorTest {
@@ -26,6 +29,16 @@ func (w *legacyMonitorTests) StartCollection(ctx context.Context, adminRESTConfi
 	return nil
 }
 
+func (w *legacyMonitorTests) PrepareForOfflineEvaluation(ctx context.Context, info monitortestframework.OfflineEvaluationInfo) error {
+	clusterData, err := platformidentification.ReadClusterDataFromDir(info.ArtifactDir)
+	if err != nil {
+		// the cluster data only refines a single test, carry on without it.
+		clusterData = &platformidentification.ClusterData{}
+	}
+	w.offlineClusterData = clusterData
+	return nil
+}
+
 func (w *legacyMonitorTests) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
 	return nil, nil, nil
 }
@@ -36,14 +49,20 @@ func (*legacyMonitorTests) ConstructComputedIntervals(ctx context.Context, start
 
 func (w *legacyMonitorTests) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
 
-	clusterData, _ := platformidentification.BuildClusterData(context.Background(), w.adminRESTConfig)
-
-	containerFailures, err := testContainerFailures(w.adminRESTConfig, finalIntervals)
-	if err != nil {
-		return nil, err
-	}
 	junits := []*junitapi.JUnitTestCase{}
-	junits = append(junits, containerFailures...)
+	var clusterData platformidentification.ClusterData
+	if w.offlineClusterData != nil {
+		// container failure checks look up live pods, which we cannot do offline.
+		clusterData = *w.offlineClusterData
+	} else {
+		clusterData, _ = platformidentification.BuildClusterData(context.Background(), w.adminRESTConfig)
+
+		containerFailures, err := testContainerFailures(w.adminRESTConfig, finalIntervals)
+		if err != nil {
+			return nil, err
+		}
+		junits = append(junits, containerFailures...)
+	}
 	junits = append(junits, testDeleteGracePeriodZero(finalIntervals)...)
 	junits = append(junits, testKubeApiserverProcessOverlap(finalIntervals)...)
 	junits = append(junits, testKubeAPIServerGracefulTermination(finalIntervals)...)
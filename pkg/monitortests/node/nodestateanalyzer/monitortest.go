@@ -27,10 +27,7 @@ func (w *nodeStateAnalyzer) CollectData(ctx context.Context, storageDir string,
 }
 
 func (*nodeStateAnalyzer) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
-	ret := monitorapi.Intervals{}
-	ret = append(ret, intervalsFromEvents_NodeChanges(startingIntervals, nil, beginning, end)...)
-
-	return ret, nil
+	return nodeStateMachine.ConstructComputedIntervals(ctx, startingIntervals, recordedResources, beginning, end)
 }
 
 func (*nodeStateAnalyzer) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
@@ -3,6 +3,7 @@ package nodestateanalyzer
 import (
 	"embed"
 	"fmt"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -129,3 +130,108 @@ func nodeStringOrDie(name string) string {
 
 	return string(ret)
 }
+
+func nodeInterval(node string, source monitorapi.IntervalSource, reason, message, at string) monitorapi.Interval {
+	from, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		panic(err)
+	}
+	return monitorapi.NewInterval(source, monitorapi.Info).
+		Locator(monitorapi.NewLocator().NodeFromName(node)).
+		Message(monitorapi.NewMessage().Reason(monitorapi.IntervalReason(reason)).HumanMessage(message).WithAnnotation(monitorapi.AnnotationRoles, "worker")).
+		Build(from, from)
+}
+
+func summarize(intervals monitorapi.Intervals) []string {
+	ret := []string{}
+	for _, interval := range intervals {
+		ret = append(ret, fmt.Sprintf("%s display=%v %s", interval.Source, interval.Display, interval.String()))
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// TestNodeStateMachine runs the node state machine through the cases the hand written loop it replaced was checked
+// against.
+func TestNodeStateMachine(t *testing.T) {
+	if err := nodeStateMachine.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	beginning, end := "2024-01-01T00:00:00Z", "2024-01-01T01:00:00Z"
+	tests := []struct {
+		name      string
+		intervals monitorapi.Intervals
+		expected  []string
+	}{
+		{
+			name: "update",
+			intervals: monitorapi.Intervals{
+				nodeInterval("a", monitorapi.SourceNodeMonitor, "MachineConfigChange", "updating", "2024-01-01T00:01:00Z"),
+				nodeInterval("a", "", "Cordon", "", "2024-01-01T00:02:00Z"),
+				nodeInterval("a", "", "Drain", "", "2024-01-01T00:02:10Z"),
+				nodeInterval("a", "", "OSUpdateStarted", "", "2024-01-01T00:03:00Z"),
+				nodeInterval("a", "", "Reboot", "", "2024-01-01T00:04:00Z"),
+				nodeInterval("a", monitorapi.SourceNodeMonitor, "NotReady", "", "2024-01-01T00:04:30Z"),
+				nodeInterval("a", "", "Starting", "", "2024-01-01T00:05:00Z"),
+				nodeInterval("a", monitorapi.SourceNodeMonitor, "Ready", "", "2024-01-01T00:05:30Z"),
+				nodeInterval("a", monitorapi.SourceNodeMonitor, "MachineConfigReached", "reached rendered-worker-2", "2024-01-01T00:06:00Z"),
+			},
+			expected: []string{
+				"NodeState display=true Jan 01 00:01:00.000 - 300s  I node/a row/NodeUpdate constructed/node-lifecycle-constructor phase/Update reason/NodeUpdate roles/worker reached rendered-worker-2",
+				"NodeState display=true Jan 01 00:02:00.000 - 60s   I node/a row/NodeUpdatePhases constructed/node-lifecycle-constructor phase/Drain reason/NodeUpdate roles/worker drained node",
+				"NodeState display=true Jan 01 00:03:00.000 - 60s   I node/a row/NodeUpdatePhases constructed/node-lifecycle-constructor phase/OperatingSystemUpdate reason/NodeUpdate roles/worker updated operating system",
+				"NodeState display=true Jan 01 00:04:00.000 - 60s   I node/a row/NodeUpdatePhases constructed/node-lifecycle-constructor phase/Reboot reason/NodeUpdate roles/worker rebooted and kubelet started",
+				"NodeState display=true Jan 01 00:04:30.000 - 60s   W node/a row/NodeNotReady constructed/node-lifecycle-constructor reason/NotReady roles/worker node is not ready",
+			},
+		},
+		{
+			name: "never ready again",
+			intervals: monitorapi.Intervals{
+				nodeInterval("a", monitorapi.SourceNodeMonitor, "NotReady", "", "2024-01-01T00:04:30Z"),
+				nodeInterval("b", monitorapi.SourceNodeMonitor, "MachineConfigChange", "updating", "2024-01-01T00:05:00Z"),
+			},
+			expected: []string{
+				"NodeState display=true Jan 01 00:04:30.000 - 3330s W node/a row/NodeNotReady constructed/node-lifecycle-constructor reason/NotReady roles/worker state/NotReady never completed",
+				"NodeState display=true Jan 01 00:05:00.000 - 3300s W node/b row/NodeUpdate constructed/node-lifecycle-constructor reason/NodeUpdate roles/worker state/Update never completed",
+			},
+		},
+		{
+			name: "closes without opens and other sources are ignored",
+			intervals: monitorapi.Intervals{
+				nodeInterval("a", monitorapi.SourceNodeMonitor, "Ready", "", "2024-01-01T00:01:00Z"),
+				nodeInterval("a", "", "Starting", "", "2024-01-01T00:02:00Z"),
+				nodeInterval("a", monitorapi.SourcePodMonitor, "NotReady", "", "2024-01-01T00:03:00Z"),
+				nodeInterval("a", monitorapi.SourcePodMonitor, "Ready", "", "2024-01-01T00:04:00Z"),
+			},
+			expected: []string{},
+		},
+		{
+			name: "nodes are separate",
+			intervals: monitorapi.Intervals{
+				nodeInterval("a", monitorapi.SourceNodeMonitor, "NotReady", "", "2024-01-01T00:01:00Z"),
+				nodeInterval("b", monitorapi.SourceNodeMonitor, "NotReady", "", "2024-01-01T00:01:30Z"),
+				nodeInterval("b", monitorapi.SourceNodeMonitor, "NotReady", "", "2024-01-01T00:01:40Z"),
+				nodeInterval("a", monitorapi.SourceNodeMonitor, "Ready", "", "2024-01-01T00:02:00Z"),
+				nodeInterval("b", monitorapi.SourceNodeMonitor, "Ready", "", "2024-01-01T00:03:00Z"),
+			},
+			expected: []string{
+				"NodeState display=true Jan 01 00:01:00.000 - 60s   W node/a row/NodeNotReady constructed/node-lifecycle-constructor reason/NotReady roles/worker node is not ready",
+				"NodeState display=true Jan 01 00:01:30.000 - 90s   W node/b row/NodeNotReady constructed/node-lifecycle-constructor reason/NotReady roles/worker node is not ready",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := intervalsFromEvents_NodeChanges(test.intervals, nil, timeFor(beginning), timeFor(end))
+			assert.Equal(t, test.expected, summarize(actual))
+		})
+	}
+}
+
+func timeFor(asString string) time.Time {
+	ret, err := time.Parse(time.RFC3339, asString)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
@@ -5,6 +5,7 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -49,6 +50,11 @@ func TestPodIntervalCreation(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			test.test(t)
 		})
+		// replayed runs read the pods back from the resource file written by the original run
+		test.replayed = true
+		t.Run(name+"-replayed", func(t *testing.T) {
+			test.test(t)
+		})
 	}
 }
 
@@ -58,6 +64,7 @@ type podIntervalTest struct {
 	startTime string
 	endTime   string
 	podData   [][]byte
+	replayed  bool
 }
 
 func (p podIntervalTest) test(t *testing.T) {
@@ -84,6 +91,18 @@ func (p podIntervalTest) test(t *testing.T) {
 		resourceMap["pods"] = podMap
 	}
 
+	if p.replayed {
+		resourceFile := filepath.Join(t.TempDir(), "resource-pods.zip")
+		if err := monitorserialization.InstanceMapToFile(resourceFile, "pods", resourceMap["pods"]); err != nil {
+			t.Fatal(err)
+		}
+		pods, err := monitorserialization.InstanceMapFromFile(resourceFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resourceMap["pods"] = pods
+	}
+
 	inputIntervals, err := monitorserialization.IntervalsFromJSON(p.events)
 	if err != nil {
 		t.Fatal(err)
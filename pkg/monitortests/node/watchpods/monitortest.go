@@ -17,12 +17,21 @@ import (
 type podWatcher struct {
 	kubeClient  kubernetes.Interface
 	podInformer coreinformers.PodInformer
+	// offline is set when a run is replayed
+	offline bool
 }
 
 func NewPodWatcher() monitortestframework.MonitorTest {
 	return &podWatcher{}
 }
 
+// PrepareForOfflineEvaluation keeps constructing the pod intervals of a replayed run, only the informer cache cannot be
+// checked without the cluster.
+func (w *podWatcher) PrepareForOfflineEvaluation(ctx context.Context, info monitortestframework.OfflineEvaluationInfo) error {
+	w.offline = true
+	return nil
+}
+
 func (w *podWatcher) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
 	var err error
 	w.kubeClient, err = kubernetes.NewForConfig(adminRESTConfig)
@@ -49,6 +58,9 @@ func (*podWatcher) ConstructComputedIntervals(ctx context.Context, startingInter
 }
 
 func (w *podWatcher) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	if w.offline {
+		return nil, &monitortestframework.NotSupportedError{Reason: "the pod informer cache can only be checked against the cluster"}
+	}
 	if w.podInformer == nil {
 		return nil, nil
 	}
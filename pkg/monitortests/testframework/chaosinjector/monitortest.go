@@ -0,0 +1,142 @@
+package chaosinjector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/chaos"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"github.com/openshift/origin/test/extended/util/image"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// MonitorName is only registered for Disruptive suites that were given a schedule.
+const MonitorName = "chaos-injector"
+
+type chaosInjector struct {
+	scheduleFile string
+
+	schedule *chaos.Schedule
+	injector *chaos.Injector
+	// cancel stops injecting and done is closed once the injector stopped.
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	stopOnce sync.Once
+}
+
+// NewChaosInjector injects the faults of the schedule file during the run.
+func NewChaosInjector(scheduleFile string) monitortestframework.MonitorTest {
+	return &chaosInjector{
+		scheduleFile: scheduleFile,
+	}
+}
+
+func (w *chaosInjector) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	schedule, err := chaos.ReadSchedule(w.scheduleFile)
+	if err != nil {
+		return err
+	}
+	configClient, err := configclient.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+	if err := chaos.EnsureAllowed(ctx, configClient); err != nil {
+		return err
+	}
+	kubeClient, err := kubernetes.NewForConfig(adminRESTConfig)
+	if err != nil {
+		return err
+	}
+
+	injector := chaos.NewInjector(kubeClient, image.ShellImage(), schedule, recorder)
+	if err := injector.Setup(ctx); err != nil {
+		return err
+	}
+	w.schedule = schedule
+	w.injector = injector
+
+	// faults are injected for the whole run, not only while collection starts
+	injectCtx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+	start := time.Now()
+	go func() {
+		defer close(w.done)
+		injector.Run(injectCtx, start)
+	}()
+	return nil
+}
+
+// stop waits for the fault being injected, faults that are not due yet are not injected.
+func (w *chaosInjector) stop() {
+	if w.cancel == nil {
+		return
+	}
+	w.stopOnce.Do(func() {
+		w.cancel()
+		<-w.done
+	})
+}
+
+func (w *chaosInjector) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	if w.injector == nil {
+		return nil, nil, nil
+	}
+	w.stop()
+
+	results := map[string]chaos.Result{}
+	for _, result := range w.injector.Results() {
+		results[result.Fault.Name] = result
+	}
+	junits := []*junitapi.JUnitTestCase{}
+	for _, fault := range w.schedule.Faults {
+		junit := &junitapi.JUnitTestCase{
+			Name: fmt.Sprintf("[sig-arch] chaos fault %s is injected", fault.Name),
+		}
+		result, ok := results[fault.Name]
+		switch {
+		case !ok:
+			junit.SkipMessage = &junitapi.SkipMessage{
+				Message: fmt.Sprintf("the run ended before the fault was due after %v", fault.After.Duration),
+			}
+		case result.Err != nil:
+			junit.FailureOutput = &junitapi.FailureOutput{
+				Output: fmt.Sprintf("%v fault was not injected on node/%s: %v", fault.Type, result.NodeName, result.Err),
+			}
+			junit.SystemOut = result.Output
+		default:
+			junit.SystemOut = strings.TrimSpace(fmt.Sprintf("injected on node/%s at %v\n%s",
+				result.NodeName, result.InjectedAt.UTC().Format(time.RFC3339), result.Output))
+		}
+		junits = append(junits, junit)
+	}
+	return nil, junits, nil
+}
+
+func (*chaosInjector) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, nil
+}
+
+func (*chaosInjector) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	return nil, nil
+}
+
+func (*chaosInjector) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	return nil
+}
+
+func (w *chaosInjector) Cleanup(ctx context.Context) error {
+	if w.injector == nil {
+		return nil
+	}
+	w.stop()
+	return w.injector.Cleanup(ctx)
+}
@@ -21,6 +21,9 @@ import (
 
 type clusterInfoSerializer struct {
 	adminRESTConfig *rest.Config
+
+	// offlineClusterData is set when replaying saved data, in which case it is written back out as-is.
+	offlineClusterData *platformidentification.ClusterData
 }
 
 func NewClusterInfoSerializer() monitortestframework.MonitorTest {
@@ -32,6 +35,15 @@ func (w *clusterInfoSerializer) StartCollection(ctx context.Context, adminRESTCo
 	return nil
 }
 
+func (w *clusterInfoSerializer) PrepareForOfflineEvaluation(ctx context.Context, info monitortestframework.OfflineEvaluationInfo) error {
+	clusterData, err := platformidentification.ReadClusterDataFromDir(info.ArtifactDir)
+	if err != nil {
+		return &monitortestframework.NotSupportedError{Reason: err.Error()}
+	}
+	w.offlineClusterData = clusterData
+	return nil
+}
+
 func (w *clusterInfoSerializer) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
 	return nil, nil, nil
 }
@@ -45,6 +57,9 @@ func (*clusterInfoSerializer) EvaluateTestsFromConstructedIntervals(ctx context.
 }
 
 func (w *clusterInfoSerializer) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	if w.offlineClusterData != nil {
+		return writeClusterData(filepath.Join(storageDir, fmt.Sprintf("cluster-data%s.json", timeSuffix)), *w.offlineClusterData)
+	}
 	return writeClusterData(
 		filepath.Join(storageDir, fmt.Sprintf("cluster-data%s.json", timeSuffix)),
 		w.collectClusterData(clusterinfo.WasMasterNodeUpdated(finalIntervals)),
@@ -92,6 +92,7 @@ func (w *cloudAvailability) StartCollection(ctx context.Context, adminRESTConfig
 	w.disruptionChecker = disruptionlibrary.NewAvailabilityInvariant(
 		newCloudConnectionTestName, reusedCloudConnectionTestName,
 		newConnectionDisruptionSampler, reusedConnectionDisruptionSampler,
+		nil,
 	)
 	if err := w.disruptionChecker.StartCollection(ctx, adminRESTConfig, recorder); err != nil {
 		return err
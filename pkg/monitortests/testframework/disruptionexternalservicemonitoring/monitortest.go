@@ -7,6 +7,7 @@ import (
 
 	"github.com/openshift/origin/pkg/monitortestframework"
 	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionlibrary"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionpolicy"
 
 	"k8s.io/client-go/rest"
 
@@ -26,10 +27,14 @@ type availability struct {
 	disruptionChecker  *disruptionlibrary.Availability
 	notSupportedReason error
 	suppressJunit      bool
+
+	disruptionPolicy *disruptionpolicy.Policy
 }
 
-func NewAvailabilityInvariant() monitortestframework.MonitorTest {
-	return &availability{}
+func NewAvailabilityInvariant(info monitortestframework.MonitorTestInitializationInfo) monitortestframework.MonitorTest {
+	return &availability{
+		disruptionPolicy: info.DisruptionPolicy,
+	}
 }
 
 func NewRecordAvailabilityOnly() monitortestframework.MonitorTest {
@@ -54,6 +59,7 @@ func (w *availability) StartCollection(ctx context.Context, adminRESTConfig *res
 	w.disruptionChecker = disruptionlibrary.NewAvailabilityInvariant(
 		newConnectionTestName, reusedConnectionTestName,
 		newConnectionDisruptionSampler, reusedConnectionDisruptionSampler,
+		w.disruptionPolicy,
 	)
 	if err := w.disruptionChecker.StartCollection(ctx, adminRESTConfig, recorder); err != nil {
 		return err
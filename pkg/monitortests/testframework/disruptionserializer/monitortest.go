@@ -9,8 +9,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/openshift/origin/pkg/monitor/disruptionlatency"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptioncause"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
@@ -31,8 +33,12 @@ func (w *disruptionSummarySerializer) CollectData(ctx context.Context, storageDi
 	return nil, nil, nil
 }
 
+// DependsOnComputedIntervals since disruption is attributed to intervals constructed by other monitor tests, like the
+// node states and the kube-apiserver graceful shutdowns.
+func (*disruptionSummarySerializer) DependsOnComputedIntervals() {}
+
 func (*disruptionSummarySerializer) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
-	return nil, nil
+	return disruptioncause.NewAttributor(startingIntervals).CauseIntervals(startingIntervals.Filter(disruptioncause.IsDisruption)), nil
 }
 
 func (*disruptionSummarySerializer) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
@@ -41,7 +47,18 @@ func (*disruptionSummarySerializer) EvaluateTestsFromConstructedIntervals(ctx co
 
 func (*disruptionSummarySerializer) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
 	backendDisruption := computeDisruptionData(finalIntervals)
-	return writeDisruptionData(filepath.Join(storageDir, fmt.Sprintf("backend-disruption%s.json", timeSuffix)), backendDisruption)
+	if err := writeDisruptionData(filepath.Join(storageDir, fmt.Sprintf("backend-disruption%s.json", timeSuffix)), backendDisruption); err != nil {
+		return err
+	}
+
+	backendLatency, err := computeLatencyData(finalIntervals)
+	if err != nil {
+		return err
+	}
+	if len(backendLatency.BackendLatencies) == 0 {
+		return nil
+	}
+	return writeLatencyData(filepath.Join(storageDir, fmt.Sprintf("backend-latency%s.json", timeSuffix)), backendLatency)
 }
 
 func (*disruptionSummarySerializer) Cleanup(ctx context.Context) error {
@@ -52,6 +69,9 @@ func (*disruptionSummarySerializer) Cleanup(ctx context.Context) error {
 type BackendDisruptionList struct {
 	// BackendDisruptions is keyed by name to make the consumption easier
 	BackendDisruptions map[string]*BackendDisruption
+
+	// DisruptedDurationByCause is the disruption of all backends by likely cause.
+	DisruptedDurationByCause map[disruptioncause.Cause]metav1.Duration `json:",omitempty"`
 }
 
 type BackendDisruption struct {
@@ -64,6 +84,8 @@ type BackendDisruption struct {
 
 	DisruptedDuration  metav1.Duration
 	DisruptionMessages []string
+	// DisruptedDurationByCause splits the disruption by what likely caused it.
+	DisruptedDurationByCause map[disruptioncause.Cause]metav1.Duration `json:",omitempty"`
 
 	// New disruption test framework is introducing these fields, for
 	// previous version of the test, these fields will default:
@@ -83,12 +105,48 @@ func writeDisruptionData(filename string, disruption *BackendDisruptionList) err
 	return ioutil.WriteFile(filename, jsonContent, 0644)
 }
 
+type BackendLatencyList struct {
+	// BackendLatencies is keyed by the same name as BackendDisruptions, only successful samples are counted.
+	BackendLatencies map[string]*disruptionlatency.BackendLatency
+}
+
+// computeLatencyData reads the latency back from the intervals that every sampler records when it stops, the
+// samplers of the same backend are added up.
+func computeLatencyData(eventIntervals monitorapi.Intervals) (*BackendLatencyList, error) {
+	ret := &BackendLatencyList{
+		BackendLatencies: map[string]*disruptionlatency.BackendLatency{},
+	}
+	for _, interval := range eventIntervals.Filter(disruptionlatency.IsLatencySummary) {
+		latency, err := disruptionlatency.FromSummaryInterval(interval)
+		if err != nil {
+			return nil, err
+		}
+		backendName := monitorapi.BackendDisruptionNameFromLocator(interval.Locator)
+		if existing, ok := ret.BackendLatencies[backendName]; ok {
+			if err := existing.Add(latency); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		ret.BackendLatencies[backendName] = latency
+	}
+	return ret, nil
+}
+
+func writeLatencyData(filename string, latency *BackendLatencyList) error {
+	jsonContent, err := json.MarshalIndent(latency, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, jsonContent, 0644)
+}
+
 func computeDisruptionData(eventIntervals monitorapi.Intervals) *BackendDisruptionList {
 	ret := &BackendDisruptionList{
 		BackendDisruptions: map[string]*BackendDisruption{},
 	}
 
-	backendDisruptionNamesToConnectionType := map[string]monitorapi.BackendConnectionType{}
+	backendDisruptionNamesToLocator := map[string]monitorapi.Locator{}
 	allDisruptionEventsIntervals := eventIntervals.Filter(
 		monitorapi.And(
 			monitorapi.IsDisruptionEvent,
@@ -100,29 +158,46 @@ func computeDisruptionData(eventIntervals monitorapi.Intervals) *BackendDisrupti
 	)
 	for _, eventInterval := range allDisruptionEventsIntervals {
 		backendDisruptionName := monitorapi.BackendDisruptionNameFromLocator(eventInterval.Locator)
-		connectionType := eventInterval.Locator.Keys[monitorapi.LocatorConnectionKey]
-
-		backendDisruptionNamesToConnectionType[backendDisruptionName] = monitorapi.BackendConnectionType(connectionType)
+		backendDisruptionNamesToLocator[backendDisruptionName] = eventInterval.Locator
 	}
 
-	for backendDisruptionName, connectionType := range backendDisruptionNamesToConnectionType {
+	// the likely causes of the disruption were constructed by ConstructComputedIntervals
+	allCauses := eventIntervals.Filter(disruptioncause.IsCause)
+
+	for backendDisruptionName, locator := range backendDisruptionNamesToLocator {
 		disruptionDuration, disruptionMessages :=
 			monitorapi.BackendDisruptionSeconds(backendDisruptionName, allDisruptionEventsIntervals)
 
 		bs := &BackendDisruption{
 			Name:               backendDisruptionName,
 			BackendName:        backendDisruptionName,
-			ConnectionType:     strings.Title(string(connectionType)),
+			ConnectionType:     strings.Title(locator.Keys[monitorapi.LocatorConnectionKey]),
 			DisruptedDuration:  metav1.Duration{Duration: disruptionDuration},
 			DisruptionMessages: disruptionMessages,
-			LoadBalancerType:   "",
-			Protocol:           "",
-			// for existing disruption test, the 'disruption' locator
-			// part closely resembles the api being tested.
-			TargetAPI: "",
+			// only the disruption tests of pkg/disruption set these in the
+			// locator, they tell apart the backends of a server that are
+			// sampled over different protocols (http1, http2, tcp, dns, grpc).
+			LoadBalancerType: locator.Keys[monitorapi.LocatorLoadBalancerKey],
+			Protocol:         locator.Keys[monitorapi.LocatorProtocolKey],
+			TargetAPI:        locator.Keys[monitorapi.LocatorTargetKey],
+
+			DisruptedDurationByCause: durationsByCause(allCauses.Filter(monitorapi.IsEventForBackendDisruptionName(backendDisruptionName))),
 		}
 		ret.BackendDisruptions[backendDisruptionName] = bs
 	}
+	ret.DisruptedDurationByCause = durationsByCause(allCauses)
 
 	return ret
 }
+
+func durationsByCause(causes monitorapi.Intervals) map[disruptioncause.Cause]metav1.Duration {
+	totals := disruptioncause.Totals(causes)
+	if len(totals) == 0 {
+		return nil
+	}
+	ret := map[disruptioncause.Cause]metav1.Duration{}
+	for cause, duration := range totals {
+		ret[cause] = metav1.Duration{Duration: duration}
+	}
+	return ret
+}
@@ -1,12 +1,15 @@
 package disruptionserializer
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/openshift/origin/pkg/monitor/disruptionlatency"
 	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptioncause"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -436,6 +439,57 @@ func TestComputeDisruptionData(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "probe backends keep their protocol",
+			intervals: []monitorapi.Interval{
+				{
+					Condition: monitorapi.Condition{
+						Level:   monitorapi.Error,
+						Locator: monitorapi.NewLocator().Disruption("cluster-dns-dns-service-network-new-connections", "cluster-dns-dns-service-network", "service-network", "dns", "cluster-dns", monitorapi.NewConnectionType),
+						Message: monitorapi.Message{
+							Reason:       monitorapi.DisruptionBeganEventReason,
+							HumanMessage: "disruption/cluster-dns-dns-service-network-new-connections stopped responding",
+						},
+					},
+					From:   time.Now().Add(-5 * time.Minute),
+					To:     time.Now().Add(-4 * time.Minute),
+					Source: monitorapi.SourceDisruption,
+				},
+				{
+					Condition: monitorapi.Condition{
+						Level:   monitorapi.Info,
+						Locator: monitorapi.NewLocator().Disruption("cluster-dns-tcp-service-network-new-connections", "cluster-dns-tcp-service-network", "service-network", "tcp", "cluster-dns", monitorapi.NewConnectionType),
+						Message: monitorapi.Message{
+							Reason:       monitorapi.DisruptionEndedEventReason,
+							HumanMessage: "disruption/cluster-dns-tcp-service-network-new-connections started responding",
+						},
+					},
+					From:   time.Now().Add(-5 * time.Minute),
+					To:     time.Now().Add(-4 * time.Minute),
+					Source: monitorapi.SourceDisruption,
+				},
+			},
+			expected: map[string]BackendDisruption{
+				"cluster-dns-dns-service-network-new-connections": {
+					Name:              "cluster-dns-dns-service-network-new-connections",
+					BackendName:       "cluster-dns-dns-service-network-new-connections",
+					ConnectionType:    "New",
+					DisruptedDuration: metav1.Duration{Duration: 1 * time.Minute},
+					LoadBalancerType:  "service-network",
+					Protocol:          "dns",
+					TargetAPI:         "cluster-dns",
+				},
+				"cluster-dns-tcp-service-network-new-connections": {
+					Name:              "cluster-dns-tcp-service-network-new-connections",
+					BackendName:       "cluster-dns-tcp-service-network-new-connections",
+					ConnectionType:    "New",
+					DisruptedDuration: metav1.Duration{Duration: 0},
+					LoadBalancerType:  "service-network",
+					Protocol:          "tcp",
+					TargetAPI:         "cluster-dns",
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -449,8 +503,71 @@ func TestComputeDisruptionData(t *testing.T) {
 				assert.Equal(t, expectedDisruption.BackendName, ad.BackendName)
 				assert.Equal(t, expectedDisruption.ConnectionType, ad.ConnectionType)
 				assert.Equal(t, expectedDisruption.DisruptedDuration, ad.DisruptedDuration)
+				assert.Equal(t, expectedDisruption.LoadBalancerType, ad.LoadBalancerType)
+				assert.Equal(t, expectedDisruption.Protocol, ad.Protocol)
+				assert.Equal(t, expectedDisruption.TargetAPI, ad.TargetAPI)
 				// NOTE: not checking the actual disruption messages, embedded timestamps make it cumbersome
 			}
 		})
 	}
 }
+
+func TestComputeDisruptionDataByCause(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	disruption := func(backend string, from, to time.Time) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceDisruption, monitorapi.Error).
+			Locator(monitorapi.NewLocator().LocateDisruptionCheck(backend, "", monitorapi.NewConnectionType)).
+			Message(monitorapi.NewMessage().Reason(monitorapi.DisruptionBeganEventReason).
+				HumanMessage("stopped responding to GET requests over new connections")).
+			Build(from, to)
+	}
+	intervals := monitorapi.Intervals{
+		monitorapi.NewInterval(monitorapi.APIServerGracefulShutdown, monitorapi.Info).
+			Locator(monitorapi.NewLocator().NodeFromName("master-0")).
+			Message(monitorapi.NewMessage().HumanMessage("graceful shutdown")).
+			Build(start, start.Add(70*time.Second)),
+		disruption("kube-api-new-connections", start.Add(10*time.Second), start.Add(13*time.Second)),
+		disruption("oauth-api-new-connections", start.Add(20*time.Second), start.Add(22*time.Second)),
+		disruption("oauth-api-new-connections", start.Add(10*time.Minute), start.Add(10*time.Minute+4*time.Second)),
+	}
+
+	causes, err := (&disruptionSummarySerializer{}).ConstructComputedIntervals(context.TODO(), intervals, nil, start, start.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, causes, 3)
+
+	disruptions := computeDisruptionData(append(intervals, causes...))
+	assert.Equal(t, map[disruptioncause.Cause]metav1.Duration{
+		disruptioncause.CauseAPIServerGracefulShutdown: {Duration: 3 * time.Second},
+	}, disruptions.BackendDisruptions["kube-api-new-connections"].DisruptedDurationByCause)
+	assert.Equal(t, map[disruptioncause.Cause]metav1.Duration{
+		disruptioncause.CauseAPIServerGracefulShutdown: {Duration: 2 * time.Second},
+		disruptioncause.CauseUnknown:                   {Duration: 4 * time.Second},
+	}, disruptions.BackendDisruptions["oauth-api-new-connections"].DisruptedDurationByCause)
+	assert.Equal(t, map[disruptioncause.Cause]metav1.Duration{
+		disruptioncause.CauseAPIServerGracefulShutdown: {Duration: 5 * time.Second},
+		disruptioncause.CauseUnknown:                   {Duration: 4 * time.Second},
+	}, disruptions.DisruptedDurationByCause)
+}
+
+func TestComputeLatencyData(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	summary := func(node string, total time.Duration) monitorapi.Interval {
+		locator := monitorapi.NewLocator().LocateDisruptionCheck("echo-new-connections", "echo-from-node-"+node, monitorapi.NewConnectionType)
+		latency := disruptionlatency.NewBackendLatency("echo-new-connections", monitorapi.NewConnectionType)
+		latency.Observe(disruptionlatency.SampleLatency{Total: total})
+		interval, err := latency.SummaryInterval(locator, start, start.Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return interval
+	}
+
+	// the samplers of the backend on every node are added up
+	actual, err := computeLatencyData(monitorapi.Intervals{summary("worker-0", 40*time.Millisecond), summary("worker-1", 3*time.Second)})
+	if !assert.NoError(t, err) || !assert.Len(t, actual.BackendLatencies, 1) {
+		return
+	}
+	latency := actual.BackendLatencies["echo-new-connections"]
+	assert.Equal(t, int64(2), latency.Total.Count)
+	assert.Equal(t, 3*time.Second, latency.Total.Max.Duration)
+}
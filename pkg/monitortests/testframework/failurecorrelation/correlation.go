@@ -0,0 +1,311 @@
+package failurecorrelation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// maxFailureOutputIntervals limits the intervals listed in a junit failure, the artifact has all of them.
+	maxFailureOutputIntervals = 20
+
+	sameNamespaceScore   = 10
+	sameNodeScore        = 5
+	usedBackendScore     = 5
+	errorScore           = 2
+	warningScore         = 1
+	failureOutputHeading = "Concurrent cluster events"
+)
+
+// Report lists, for every failed e2e test run, the Error and Warning intervals that overlapped it.
+type Report struct {
+	Tests []FailedTest `json:"tests"`
+}
+
+// FailedTest is one failed run of an e2e test.
+type FailedTest struct {
+	Name   string    `json:"name"`
+	Status string    `json:"status"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+
+	// Namespaces are the e2e namespaces that first appeared while the test ran, assuming each belongs to the most
+	// recently started test at the time.  Nodes are the nodes their pods were on.
+	Namespaces []string `json:"namespaces,omitempty"`
+	Nodes      []string `json:"nodes,omitempty"`
+
+	// Intervals are ordered by score, the most relevant first.
+	Intervals []CorrelatedInterval `json:"intervals"`
+	// Annotation is the "Concurrent cluster events" section added to the junit failure of the run.
+	Annotation string `json:"annotation"`
+}
+
+// CorrelatedInterval is an interval that overlapped a failed test and why it may be relevant.
+type CorrelatedInterval struct {
+	Score     int      `json:"score"`
+	Relevance []string `json:"relevance,omitempty"`
+
+	Level   string    `json:"level"`
+	Source  string    `json:"source"`
+	Locator string    `json:"locator"`
+	Message string    `json:"message"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+}
+
+// Correlate finds the failed e2e test runs in intervals, as built by the e2e test analyzer, and ranks the Error and
+// Warning intervals that overlapped each of them.  Intervals in the namespaces of the test rank highest, then those on
+// the nodes of the test and disruption of backends the test likely used.  All other overlapping intervals are listed
+// too, ranked by level.
+func Correlate(intervals monitorapi.Intervals) *Report {
+	testRuns := intervals.Filter(isTestRun)
+	sort.SliceStable(testRuns, func(i, j int) bool {
+		return testRuns[i].From.Before(testRuns[j].From)
+	})
+	namespacesByRun := e2eNamespacesByTestRun(intervals, testRuns)
+
+	ret := &Report{Tests: []FailedTest{}}
+	for i, run := range testRuns {
+		status := run.Message.Annotations[monitorapi.AnnotationStatus]
+		if status != "Failed" && status != "Flaked" {
+			continue
+		}
+		testName, _ := monitorapi.E2ETestFromLocator(run.Locator)
+		namespaces := namespacesByRun[i]
+		nodes := nodesForNamespaces(intervals, namespaces)
+
+		failed := FailedTest{
+			Name:       testName,
+			Status:     status,
+			From:       run.From,
+			To:         run.To,
+			Namespaces: sets.List(namespaces),
+			Nodes:      sets.List(nodes),
+			Intervals:  []CorrelatedInterval{},
+		}
+		for _, interval := range intervals {
+			if interval.Source == monitorapi.SourceE2ETest || !overlaps(interval, run) {
+				continue
+			}
+			if interval.Level != monitorapi.Error && interval.Level != monitorapi.Warning {
+				continue
+			}
+			failed.Intervals = append(failed.Intervals, correlate(testName, namespaces, nodes, interval))
+		}
+		sort.SliceStable(failed.Intervals, func(i, j int) bool {
+			if failed.Intervals[i].Score != failed.Intervals[j].Score {
+				return failed.Intervals[i].Score > failed.Intervals[j].Score
+			}
+			return failed.Intervals[i].From.Before(failed.Intervals[j].From)
+		})
+		failed.Annotation = failed.annotation()
+		ret.Tests = append(ret.Tests, failed)
+	}
+	return ret
+}
+
+// isTestRun matches the intervals the e2e test analyzer builds from the start to the end of a test.
+func isTestRun(interval monitorapi.Interval) bool {
+	if interval.Source != monitorapi.SourceE2ETest || !monitorapi.IsE2ETest(interval.Locator) {
+		return false
+	}
+	if interval.Message.Reason == monitorapi.E2ETestStarted || interval.Message.Reason == monitorapi.E2ETestFinished {
+		return false
+	}
+	_, ok := interval.Message.Annotations[monitorapi.AnnotationStatus]
+	return ok
+}
+
+func overlaps(interval, run monitorapi.Interval) bool {
+	if interval.From.After(run.To) {
+		return false
+	}
+	return interval.To.IsZero() || !interval.To.Before(run.From)
+}
+
+// e2eNamespacesByTestRun assigns every e2e namespace to the test run that started last before the namespace was first
+// seen.  Tests run in parallel, so this is a guess, but the framework creates the namespace of a test as it starts.
+func e2eNamespacesByTestRun(intervals, testRuns monitorapi.Intervals) map[int]sets.Set[string] {
+	firstSeen := map[string]time.Time{}
+	for _, interval := range intervals.Filter(monitorapi.IsInE2ENamespace) {
+		namespace := monitorapi.NamespaceFromLocator(interval.Locator)
+		if seen, ok := firstSeen[namespace]; !ok || interval.From.Before(seen) {
+			firstSeen[namespace] = interval.From
+		}
+	}
+
+	ret := map[int]sets.Set[string]{}
+	for namespace, seen := range firstSeen {
+		owner := -1
+		for i, run := range testRuns {
+			if run.From.After(seen) {
+				break
+			}
+			if run.To.Before(seen) {
+				continue
+			}
+			owner = i
+		}
+		if owner < 0 {
+			continue
+		}
+		if ret[owner] == nil {
+			ret[owner] = sets.New[string]()
+		}
+		ret[owner].Insert(namespace)
+	}
+	return ret
+}
+
+func nodesForNamespaces(intervals monitorapi.Intervals, namespaces sets.Set[string]) sets.Set[string] {
+	ret := sets.New[string]()
+	for _, interval := range intervals {
+		if !namespaces.Has(monitorapi.NamespaceFromLocator(interval.Locator)) {
+			continue
+		}
+		if node := nodeOf(interval); len(node) > 0 {
+			ret.Insert(node)
+		}
+	}
+	return ret
+}
+
+func nodeOf(interval monitorapi.Interval) string {
+	if node := interval.Locator.Keys[monitorapi.LocatorNodeKey]; len(node) > 0 {
+		return node
+	}
+	return interval.Message.Annotations[monitorapi.AnnotationNode]
+}
+
+func correlate(testName string, namespaces, nodes sets.Set[string], interval monitorapi.Interval) CorrelatedInterval {
+	ret := CorrelatedInterval{
+		Level:   interval.Level.String(),
+		Source:  string(interval.Source),
+		Locator: interval.Locator.OldLocator(),
+		Message: interval.Message.OldMessage(),
+		From:    interval.From,
+		To:      interval.To,
+	}
+	switch interval.Level {
+	case monitorapi.Error:
+		ret.Score += errorScore
+	case monitorapi.Warning:
+		ret.Score += warningScore
+	}
+	if namespace := monitorapi.NamespaceFromLocator(interval.Locator); namespaces.Has(namespace) {
+		ret.Score += sameNamespaceScore
+		ret.Relevance = append(ret.Relevance, fmt.Sprintf("in test namespace %s", namespace))
+	}
+	if node := nodeOf(interval); nodes.Has(node) {
+		ret.Score += sameNodeScore
+		ret.Relevance = append(ret.Relevance, fmt.Sprintf("on test node %s", node))
+	}
+	if backend := monitorapi.BackendDisruptionNameFromLocator(interval.Locator); len(backend) > 0 && monitorapi.IsDisruptionEvent(interval) && usesBackend(testName, backend) {
+		ret.Score += usedBackendScore
+		ret.Relevance = append(ret.Relevance, fmt.Sprintf("disruption of %s used by the test", backend))
+	}
+	return ret
+}
+
+// backendUsers guesses from the name of a test whether it uses a disruption backend.  Backends are matched by prefix
+// and the first match wins.
+var backendUsers = []struct {
+	backendPrefix string
+	testNameParts []string
+}{
+	// every test talks to the apiservers
+	{backendPrefix: "kube-api"},
+	{backendPrefix: "openshift-api"},
+	{backendPrefix: "oauth-api"},
+	{backendPrefix: "ingress-", testNameParts: []string{"[sig-network-edge]", "route", "ingress"}},
+	{backendPrefix: "image-registry", testNameParts: []string{"[sig-imageregistry]", "image"}},
+	{backendPrefix: "service-load-balancer", testNameParts: []string{"loadbalancer", "load balancer"}},
+	{backendPrefix: "", testNameParts: []string{"[sig-network]"}},
+}
+
+func usesBackend(testName, backend string) bool {
+	backend = strings.TrimPrefix(backend, "cache-")
+	testName = strings.ToLower(testName)
+	for _, users := range backendUsers {
+		if !strings.HasPrefix(backend, users.backendPrefix) {
+			continue
+		}
+		if len(users.testNameParts) == 0 {
+			return true
+		}
+		for _, part := range users.testNameParts {
+			if strings.Contains(testName, part) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// annotation describes the intervals concurrent with a failed test for a junit failure message.
+func (t FailedTest) annotation() string {
+	out := &strings.Builder{}
+	fmt.Fprintf(out, "%s from %s to %s:\n", failureOutputHeading, t.From.UTC().Format(time.RFC3339), t.To.UTC().Format(time.RFC3339))
+	if len(t.Intervals) == 0 {
+		fmt.Fprintf(out, "  none\n")
+		return out.String()
+	}
+	for i, interval := range t.Intervals {
+		if i == maxFailureOutputIntervals {
+			fmt.Fprintf(out, "  ... and %d more in failure-correlation_*.json\n", len(t.Intervals)-i)
+			break
+		}
+		fmt.Fprintf(out, "  %s %s %s - %s %s %s", interval.Level, interval.Source,
+			interval.From.UTC().Format(time.TimeOnly), interval.To.UTC().Format(time.TimeOnly), interval.Locator, interval.Message)
+		if len(interval.Relevance) > 0 {
+			fmt.Fprintf(out, " (%s)", strings.Join(interval.Relevance, ", "))
+		}
+		fmt.Fprintln(out)
+	}
+	return out.String()
+}
+
+// AnnotationFor returns the annotation of the failed run of testName between start and end, or "" if there is none.
+// Retries of a test run one after the other, so the run of every attempt of a test gets its own annotation.
+func (r *Report) AnnotationFor(testName string, start, end time.Time) string {
+	annotations := []string{}
+	for _, test := range r.Tests {
+		if test.Name != testName || test.From.After(end) || test.To.Before(start) {
+			continue
+		}
+		annotations = append(annotations, test.Annotation)
+	}
+	return strings.Join(annotations, "\n\n")
+}
+
+// ReportFileName is the artifact the failure correlation monitor test writes the report of a run to.
+func ReportFileName(timeSuffix string) string {
+	return fmt.Sprintf("failure-correlation%s.json", timeSuffix)
+}
+
+// ReadReport reads the report the failure correlation monitor test wrote to storageDir, or returns nil if it did
+// not write one, because it was disabled for instance.
+func ReadReport(storageDir, timeSuffix string) (*Report, error) {
+	content, err := os.ReadFile(filepath.Join(storageDir, ReportFileName(timeSuffix)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{}
+	if err := json.Unmarshal(content, report); err != nil {
+		return nil, fmt.Errorf("unable to read failure correlation report: %w", err)
+	}
+	return report, nil
+}
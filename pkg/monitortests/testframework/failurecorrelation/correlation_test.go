@@ -0,0 +1,86 @@
+package failurecorrelation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time {
+		return start.Add(time.Duration(minutes) * time.Minute)
+	}
+	testRun := func(name, status string, from, to int) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourceE2ETest, monitorapi.Info).
+			Locator(monitorapi.NewLocator().E2ETest(name)).
+			Message(monitorapi.NewMessage().HumanMessagef("e2e test finished As %q", status).
+				WithAnnotation(monitorapi.AnnotationStatus, status)).
+			Display().
+			Build(at(from), at(to))
+	}
+	podInterval := func(level monitorapi.IntervalLevel, namespace, node string, from, to int) monitorapi.Interval {
+		return monitorapi.NewInterval(monitorapi.SourcePodMonitor, level).
+			Locator(monitorapi.NewLocator().PodFromNames(namespace, "pod", "uid")).
+			Message(monitorapi.NewMessage().Reason(monitorapi.PodReasonCreated).
+				WithAnnotation(monitorapi.AnnotationNode, node).
+				HumanMessage(namespace)).
+			Build(at(from), at(to))
+	}
+
+	intervals := monitorapi.Intervals{
+		testRun("[sig-other] passing", "Passed", -1, 10),
+		testRun("[sig-apps] failing", "Failed", 0, 5),
+		// the namespaces of the passing test and the failing test
+		podInterval(monitorapi.Info, "e2e-failing", "worker-1", 1, 1),
+		podInterval(monitorapi.Info, "e2e-passing", "worker-2", -1, -1),
+		podInterval(monitorapi.Warning, "e2e-passing", "worker-2", 2, 3),
+		podInterval(monitorapi.Error, "e2e-failing", "worker-1", 2, 3),
+		monitorapi.NewInterval(monitorapi.SourceNodeState, monitorapi.Warning).
+			Locator(monitorapi.NewLocator().NodeFromName("worker-1")).
+			Message(monitorapi.NewMessage().Reason(monitorapi.NodeNotReadyReason).HumanMessage("not ready")).
+			Build(at(4), at(8)),
+		monitorapi.NewInterval(monitorapi.SourceDisruption, monitorapi.Error).
+			Locator(monitorapi.NewLocator().DisruptionRequiredOnly("kube-api-new-connections", "kube-api-new-connections")).
+			Message(monitorapi.NewMessage().Reason(monitorapi.DisruptionBeganEventReason).HumanMessage("disrupted")).
+			Build(at(3), at(4)),
+		monitorapi.NewInterval(monitorapi.SourceDisruption, monitorapi.Error).
+			Locator(monitorapi.NewLocator().DisruptionRequiredOnly("ingress-to-console-new-connections", "ingress-to-console-new-connections")).
+			Message(monitorapi.NewMessage().Reason(monitorapi.DisruptionBeganEventReason).HumanMessage("disrupted")).
+			Build(at(3), at(4)),
+		// after the test
+		podInterval(monitorapi.Error, "e2e-failing", "worker-1", 6, 7),
+	}
+
+	report := Correlate(intervals)
+	require.Len(t, report.Tests, 1)
+	failed := report.Tests[0]
+	assert.Equal(t, "[sig-apps] failing", failed.Name)
+	assert.Equal(t, []string{"e2e-failing"}, failed.Namespaces)
+	assert.Equal(t, []string{"worker-1"}, failed.Nodes)
+
+	summary := []string{}
+	for _, interval := range failed.Intervals {
+		summary = append(summary, interval.Source+" "+strings.Join(interval.Relevance, ", "))
+	}
+	assert.Equal(t, []string{
+		"PodMonitor in test namespace e2e-failing, on test node worker-1",
+		"Disruption disruption of kube-api-new-connections used by the test",
+		"NodeState on test node worker-1",
+		"Disruption ",
+		"PodMonitor ",
+	}, summary)
+	assert.Equal(t, []int{17, 7, 6, 2, 1}, []int{
+		failed.Intervals[0].Score, failed.Intervals[1].Score, failed.Intervals[2].Score, failed.Intervals[3].Score, failed.Intervals[4].Score,
+	})
+
+	annotation := report.AnnotationFor("[sig-apps] failing", at(0), at(5))
+	assert.True(t, strings.HasPrefix(annotation, "Concurrent cluster events from 2024-01-01T10:00:00Z to 2024-01-01T10:05:00Z:\n"), annotation)
+	assert.Contains(t, annotation, "(in test namespace e2e-failing, on test node worker-1)")
+	assert.Empty(t, report.AnnotationFor("[sig-other] passing", at(-1), at(10)))
+	assert.Empty(t, report.AnnotationFor("[sig-apps] failing", at(6), at(9)), "a retry of the test gets the annotation of its own run only")
+}
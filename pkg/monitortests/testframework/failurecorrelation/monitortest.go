@@ -0,0 +1,50 @@
+package failurecorrelation
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+	"k8s.io/client-go/rest"
+)
+
+type failureCorrelation struct {
+}
+
+func NewFailureCorrelation() monitortestframework.MonitorTest {
+	return &failureCorrelation{}
+}
+
+func (w *failureCorrelation) StartCollection(ctx context.Context, adminRESTConfig *rest.Config, recorder monitorapi.RecorderWriter) error {
+	return nil
+}
+
+func (w *failureCorrelation) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
+	return nil, nil, nil
+}
+
+func (*failureCorrelation) ConstructComputedIntervals(ctx context.Context, startingIntervals monitorapi.Intervals, recordedResources monitorapi.ResourcesMap, beginning, end time.Time) (monitorapi.Intervals, error) {
+	return nil, nil
+}
+
+func (*failureCorrelation) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	return nil, nil
+}
+
+func (*failureCorrelation) WriteContentToStorage(ctx context.Context, storageDir, timeSuffix string, finalIntervals monitorapi.Intervals, finalResourceState monitorapi.ResourcesMap) error {
+	report := Correlate(finalIntervals)
+	jsonContent, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(storageDir, ReportFileName(timeSuffix)), jsonContent, 0644)
+}
+
+func (*failureCorrelation) Cleanup(ctx context.Context) error {
+	return nil
+}
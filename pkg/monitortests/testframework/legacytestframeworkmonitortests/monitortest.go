@@ -2,8 +2,11 @@ package legacytestframeworkmonitortests
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/openshift/origin/pkg/monitortestlibrary/allowedalerts"
+
 	"github.com/openshift/origin/pkg/monitortestframework"
 
 	"github.com/openshift/origin/pkg/monitortestlibrary/pathologicaleventlibrary"
@@ -21,6 +24,10 @@ type legacyMonitorTests struct {
 	duration                   time.Duration
 	recordedResources          monitorapi.ResourcesMap
 	clusterStabilityDuringTest *monitortestframework.ClusterStabilityDuringTest
+
+	// offlineClusterData is read from the saved data when it is replayed, the alert tests take the job type and
+	// feature set from it instead of asking the cluster.
+	offlineClusterData *platformidentification.ClusterData
 }
 
 func NewLegacyTests(info monitortestframework.MonitorTestInitializationInfo) monitortestframework.MonitorTest {
@@ -32,6 +39,18 @@ func (w *legacyMonitorTests) StartCollection(ctx context.Context, adminRESTConfi
 	return nil
 }
 
+func (w *legacyMonitorTests) PrepareForOfflineEvaluation(ctx context.Context, info monitortestframework.OfflineEvaluationInfo) error {
+	clusterData, err := platformidentification.ReadClusterDataFromDir(info.ArtifactDir)
+	if err != nil {
+		return &monitortestframework.NotSupportedError{Reason: fmt.Sprintf("alert tests require the job type: %v", err)}
+	}
+	if clusterData.FeatureSet == nil {
+		return &monitortestframework.NotSupportedError{Reason: "alert tests require the feature set, it is not in the saved cluster data"}
+	}
+	w.offlineClusterData = clusterData
+	return nil
+}
+
 func (w *legacyMonitorTests) CollectData(ctx context.Context, storageDir string, beginning, end time.Time) (monitorapi.Intervals, []*junitapi.JUnitTestCase, error) {
 	w.duration = end.Sub(beginning)
 	return nil, nil, nil
@@ -43,6 +62,10 @@ func (w *legacyMonitorTests) ConstructComputedIntervals(ctx context.Context, sta
 }
 
 func (w *legacyMonitorTests) EvaluateTestsFromConstructedIntervals(ctx context.Context, finalIntervals monitorapi.Intervals) ([]*junitapi.JUnitTestCase, error) {
+	if w.offlineClusterData != nil {
+		return w.evaluateOffline(finalIntervals), nil
+	}
+
 	jobType, err := platformidentification.GetJobType(context.TODO(), w.adminRESTConfig)
 	if err != nil {
 		// JobType will be nil here, but we want test cases to all fail if this is the case, so we rely on them to nil check
@@ -72,3 +95,23 @@ func (*legacyMonitorTests) WriteContentToStorage(ctx context.Context, storageDir
 func (*legacyMonitorTests) Cleanup(ctx context.Context) error {
 	return nil
 }
+
+// evaluateOffline runs the alert tests without the allowances that need the cluster, like the one for etcd revision
+// changes.  The pathological event tests are reported as skipped, their allowances need the cluster to know when
+// installation completed and what its infrastructure is.
+func (w *legacyMonitorTests) evaluateOffline(finalIntervals monitorapi.Intervals) []*junitapi.JUnitTestCase {
+	junits := pathologicaleventlibrary.SkipDuplicatedEventTests("pathological event tests need the cluster, they are not run on saved data")
+
+	allowancesFunc := alerts.AllowedAlertsDuringConformance
+	if platformidentification.DidUpgradeHappenDuringCollection(finalIntervals, time.Time{}, time.Time{}) {
+		allowancesFunc = alerts.AllowedAlertsDuringUpgrade
+	}
+	return append(junits, RunAlertTests(
+		&w.offlineClusterData.JobType,
+		w.clusterStabilityDuringTest,
+		allowancesFunc,
+		*w.offlineClusterData.FeatureSet,
+		allowedalerts.DefaultAllowances,
+		finalIntervals,
+		w.recordedResources)...)
+}
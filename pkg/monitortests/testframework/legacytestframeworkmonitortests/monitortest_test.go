@@ -0,0 +1,67 @@
+package legacytestframeworkmonitortests
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareForOfflineEvaluation(t *testing.T) {
+	tests := []struct {
+		name        string
+		clusterData string
+		expectedErr string
+	}{
+		{
+			name:        "no cluster data",
+			expectedErr: "alert tests require the job type",
+		},
+		{
+			name:        "cluster data without the feature set",
+			clusterData: `{"Release":"4.15","Platform":"aws","Architecture":"amd64","Network":"ovn","Topology":"ha"}`,
+			expectedErr: "alert tests require the feature set, it is not in the saved cluster data",
+		},
+		{
+			name:        "cluster data with the feature set",
+			clusterData: `{"Release":"4.15","Platform":"aws","Architecture":"amd64","Network":"ovn","Topology":"ha","FeatureSet":"TechPreviewNoUpgrade"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if len(tt.clusterData) > 0 {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "cluster-data_20240101-000000.json"), []byte(tt.clusterData), 0644))
+			}
+
+			w := NewLegacyTests(monitortestframework.MonitorTestInitializationInfo{}).(*legacyMonitorTests)
+			err := w.PrepareForOfflineEvaluation(context.TODO(), monitortestframework.OfflineEvaluationInfo{ArtifactDir: dir})
+			if len(tt.expectedErr) > 0 {
+				var nsErr *monitortestframework.NotSupportedError
+				require.True(t, errors.As(err, &nsErr), "expected a NotSupportedError, got %v", err)
+				assert.Contains(t, nsErr.Reason, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "TechPreviewNoUpgrade", string(*w.offlineClusterData.FeatureSet))
+
+			// the pathological event tests cannot run without the cluster, they are reported as skipped
+			junits, err := w.EvaluateTestsFromConstructedIntervals(context.TODO(), monitorapi.Intervals{})
+			require.NoError(t, err)
+			skipped := map[string]bool{}
+			for _, junit := range junits {
+				if junit.SkipMessage != nil {
+					skipped[junit.Name] = true
+				}
+			}
+			assert.True(t, skipped["[sig-arch] events should not repeat pathologically"])
+			assert.True(t, skipped["[sig-arch] events should not repeat pathologically in e2e namespaces"])
+		})
+	}
+}
@@ -47,51 +47,24 @@ type Options struct {
 func (opt *Options) Run() error {
 	logrus.Infof("Scanning for %s files in: %s", testFailureSummaryFilePrefix, opt.JUnitDir)
 
-	resultFiles, err := filepath.Glob(fmt.Sprintf("%s/%s*.json", opt.JUnitDir, testFailureSummaryFilePrefix))
+	prowJobRuns, err := readTestFailureSummaries(opt.JUnitDir)
 	if err != nil {
-		logrus.Infof("Error scanning for test failure summary files: %v", err)
+		logrus.Infof("Error reading test failure summary files: %v", err)
 		return nil
 	}
-	logrus.Infof("Found files: %v", resultFiles)
 
 	// we didn't find any files to process. log but don't return an error as the step may not have produced those files
-	if len(resultFiles) == 0 {
+	if len(prowJobRuns) == 0 {
 		logrus.Infof("Missing : %s file(s), exiting", testFailureSummaryFilePrefix)
 		return nil
 	}
 
-	prowJobRuns := []*ProwJobRun{}
-	// Read each result file into a ProwJobRun struct:
-	for _, rf := range resultFiles {
-		data, err := os.ReadFile(rf)
-		if err != nil {
-			logrus.Infof("Error reading test failure summary file: %s - %v", rf, err)
-			return nil
-		}
-		jobRun := &ProwJobRun{}
-		err = json.Unmarshal(data, jobRun)
-		if err != nil {
-			logrus.Infof("Error unmarshalling ProwJob json for: %s - %v", rf, err)
-			return nil
-		}
-		prowJobRuns = append(prowJobRuns, jobRun)
-	}
-
 	// We will often have more than one output file for this job run because openshift-tests is often
 	// invoked multiple times (pre/post upgrade). We need to merge the data together in this case.
-	var finalProwJobRun *ProwJobRun
-	for _, pjr := range prowJobRuns {
-		if finalProwJobRun == nil {
-			finalProwJobRun = pjr
-			continue
-		}
-		if pjr.ProwJob.Name != finalProwJobRun.ProwJob.Name {
-			logrus.Errorf("Mismatched job names found in %s files, %s != %s",
-				testFailureSummaryFilePrefix, finalProwJobRun.ProwJob.Name, pjr.ProwJob.Name)
-			return nil
-		}
-		finalProwJobRun.Tests = append(finalProwJobRun.Tests, pjr.Tests...)
-		finalProwJobRun.TestCount += pjr.TestCount
+	finalProwJobRun, err := mergeProwJobRuns(prowJobRuns)
+	if err != nil {
+		logrus.WithError(err).Error("Error merging test failure summaries")
+		return nil
 	}
 
 	inputBytes, err := json.Marshal(finalProwJobRun)
@@ -133,6 +106,49 @@ func (opt *Options) Run() error {
 	return nil
 }
 
+// readTestFailureSummaries reads the test failure summaries written to dir.
+func readTestFailureSummaries(dir string) ([]*ProwJobRun, error) {
+	resultFiles, err := filepath.Glob(fmt.Sprintf("%s/%s*.json", dir, testFailureSummaryFilePrefix))
+	if err != nil {
+		return nil, fmt.Errorf("error scanning for test failure summary files: %w", err)
+	}
+	logrus.Infof("Found files: %v", resultFiles)
+
+	prowJobRuns := []*ProwJobRun{}
+	// Read each result file into a ProwJobRun struct:
+	for _, rf := range resultFiles {
+		data, err := os.ReadFile(rf)
+		if err != nil {
+			return nil, fmt.Errorf("error reading test failure summary file: %s - %w", rf, err)
+		}
+		jobRun := &ProwJobRun{}
+		err = json.Unmarshal(data, jobRun)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling ProwJob json for: %s - %w", rf, err)
+		}
+		prowJobRuns = append(prowJobRuns, jobRun)
+	}
+	return prowJobRuns, nil
+}
+
+// mergeProwJobRuns merges the test failure summaries of several invocations of openshift-tests in one job run.
+func mergeProwJobRuns(prowJobRuns []*ProwJobRun) (*ProwJobRun, error) {
+	var finalProwJobRun *ProwJobRun
+	for _, pjr := range prowJobRuns {
+		if finalProwJobRun == nil {
+			finalProwJobRun = pjr
+			continue
+		}
+		if pjr.ProwJob.Name != finalProwJobRun.ProwJob.Name {
+			return nil, fmt.Errorf("mismatched job names found in %s files, %s != %s",
+				testFailureSummaryFilePrefix, finalProwJobRun.ProwJob.Name, pjr.ProwJob.Name)
+		}
+		finalProwJobRun.Tests = append(finalProwJobRun.Tests, pjr.Tests...)
+		finalProwJobRun.TestCount += pjr.TestCount
+	}
+	return finalProwJobRun, nil
+}
+
 // struct that records the timing and status of each RA http client request
 type raRequestLog struct {
 	RequestCount int // which iteration are we on for this job requesting RA
@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"strconv"
 
+	"k8s.io/apimachinery/pkg/util/sets"
+
 	"github.com/openshift/origin/pkg/clioptions/clusterinfo"
 
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
@@ -17,11 +19,42 @@ import (
 // job run, and what tests flaked and failed. (successful tests are omitted)
 // This is intended to be later submitted to sippy for a risk analysis of how unusual the
 // test failures were, but that final step is handled elsewhere.
-func WriteJobRunTestFailureSummary(artifactDir, timeSuffix string, finalSuiteResults *junitapi.JUnitTestSuite, wasMasterNodeUpdated, outputFileSubStr string) error {
+// informingTests are excluded entirely, their failures must not influence the risk analysis.
+func WriteJobRunTestFailureSummary(artifactDir, timeSuffix string, finalSuiteResults *junitapi.JUnitTestSuite, wasMasterNodeUpdated, outputFileSubStr string, informingTests sets.String) error {
+	testCount, failedTests := summarizeTestFailures(finalSuiteResults, informingTests)
+
+	// If we can't parse this, we submit without it, it is not required.
+	jobRunID, _ := strconv.Atoi(os.Getenv("BUILD_ID"))
+
+	restConfig, err := clusterinfo.GetMonitorRESTConfig()
+	if err != nil {
+		return err
+	}
+	jr := ProwJobRun{
+		ID:          jobRunID,
+		ProwJob:     ProwJob{Name: os.Getenv("JOB_NAME")},
+		ClusterData: clusterinfo.CollectClusterData(restConfig, wasMasterNodeUpdated),
+		Tests:       failedTests,
+		TestCount:   testCount,
+	}
 
+	jsonContent, err := json.MarshalIndent(jr, "", "    ")
+	if err != nil {
+		return err
+	}
+	outputFile := filepath.Join(artifactDir, fmt.Sprintf("%s%s%s.json",
+		testFailureSummaryFilePrefix, outputFileSubStr, timeSuffix))
+	return ioutil.WriteFile(outputFile, jsonContent, 0644)
+}
+
+// summarizeTestFailures returns the number of distinct tests and those that failed, informing tests are not counted.
+func summarizeTestFailures(finalSuiteResults *junitapi.JUnitTestSuite, informingTests sets.String) (int, []ProwJobRunTest) {
 	tests := map[string]*passFail{}
 
 	for _, testCase := range finalSuiteResults.TestCases {
+		if informingTests.Has(testCase.Name) {
+			continue
+		}
 		if _, ok := tests[testCase.Name]; !ok {
 			tests[testCase.Name] = &passFail{}
 		}
@@ -36,21 +69,7 @@ func WriteJobRunTestFailureSummary(artifactDir, timeSuffix string, finalSuiteRes
 		}
 	}
 
-	// If we can't parse this, we submit without it, it is not required.
-	jobRunID, _ := strconv.Atoi(os.Getenv("BUILD_ID"))
-
-	restConfig, err := clusterinfo.GetMonitorRESTConfig()
-	if err != nil {
-		return err
-	}
-	jr := ProwJobRun{
-		ID:          jobRunID,
-		ProwJob:     ProwJob{Name: os.Getenv("JOB_NAME")},
-		ClusterData: clusterinfo.CollectClusterData(restConfig, wasMasterNodeUpdated),
-		Tests:       []ProwJobRunTest{},
-		TestCount:   len(tests),
-	}
-
+	failedTests := []ProwJobRunTest{}
 	for k, v := range tests {
 		if !v.Failed {
 			// if no failures, it is neither a fail nor a flake:
@@ -60,20 +79,13 @@ func WriteJobRunTestFailureSummary(artifactDir, timeSuffix string, finalSuiteRes
 			// skip flakes for now, we're not ready to process them yet:
 			continue
 		}
-		jr.Tests = append(jr.Tests, ProwJobRunTest{
+		failedTests = append(failedTests, ProwJobRunTest{
 			Test:   Test{Name: k},
 			Suite:  Suite{Name: finalSuiteResults.Name},
 			Status: getSippyStatusCode(v),
 		})
 	}
-
-	jsonContent, err := json.MarshalIndent(jr, "", "    ")
-	if err != nil {
-		return err
-	}
-	outputFile := filepath.Join(artifactDir, fmt.Sprintf("%s%s%s.json",
-		testFailureSummaryFilePrefix, outputFileSubStr, timeSuffix))
-	return ioutil.WriteFile(outputFile, jsonContent, 0644)
+	return len(tests), failedTests
 }
 
 // passFail is a simple struct to track test names which can appear more than once.
@@ -94,3 +106,30 @@ func getSippyStatusCode(pf *passFail) int {
 	// we should not hit this given the above filtering
 	return 0
 }
+
+// MergeJobRunTestFailureSummaries merges the test failure summaries written to each of dirs, for instance by
+// the shards of a run, into a single summary in outputDir.  It returns false if there were none to merge.
+func MergeJobRunTestFailureSummaries(dirs []string, outputDir, timeSuffix string) (bool, error) {
+	var prowJobRuns []*ProwJobRun
+	for _, dir := range dirs {
+		runs, err := readTestFailureSummaries(dir)
+		if err != nil {
+			return false, err
+		}
+		prowJobRuns = append(prowJobRuns, runs...)
+	}
+	if len(prowJobRuns) == 0 {
+		return false, nil
+	}
+	merged, err := mergeProwJobRuns(prowJobRuns)
+	if err != nil {
+		return false, err
+	}
+
+	jsonContent, err := json.MarshalIndent(merged, "", "    ")
+	if err != nil {
+		return false, err
+	}
+	outputFile := filepath.Join(outputDir, fmt.Sprintf("%s%s.json", testFailureSummaryFilePrefix, timeSuffix))
+	return true, ioutil.WriteFile(outputFile, jsonContent, 0644)
+}
@@ -0,0 +1,35 @@
+package riskanalysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+func TestSummarizeTestFailures(t *testing.T) {
+	suite := &junitapi.JUnitTestSuite{
+		Name: "openshift-tests",
+		TestCases: []*junitapi.JUnitTestCase{
+			{Name: "passes"},
+			{Name: "fails", FailureOutput: &junitapi.FailureOutput{Output: "failed"}},
+			{Name: "flakes", FailureOutput: &junitapi.FailureOutput{Output: "failed"}},
+			{Name: "flakes"},
+			{Name: "skipped", SkipMessage: &junitapi.SkipMessage{Message: "skipped"}},
+			{Name: "informing fails", FailureOutput: &junitapi.FailureOutput{Output: "failed"}},
+			{Name: "informing passes"},
+		},
+	}
+
+	testCount, failedTests := summarizeTestFailures(suite, sets.NewString("informing fails", "informing passes"))
+	assert.Equal(t, 4, testCount, "informing tests must not be counted")
+	assert.Equal(t, []ProwJobRunTest{
+		{
+			Test:   Test{Name: "fails"},
+			Suite:  Suite{Name: "openshift-tests"},
+			Status: 12,
+		},
+	}, failedTests)
+}
@@ -1,6 +1,7 @@
 package extensions
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -36,6 +37,9 @@ type TestBinary struct {
 
 	// Cache the info after gathering it
 	info *ExtensionInfo
+
+	// cacheEntry persists info and list output across invocations when the binary came from a BinaryCache
+	cacheEntry *CacheEntry
 }
 
 // ImageSet maps a Kubernetes image ID to its corresponding configuration.
@@ -67,19 +71,23 @@ func (b *TestBinary) Info(ctx context.Context) (*ExtensionInfo, error) {
 	binName := filepath.Base(b.binaryPath)
 
 	logrus.Infof("Fetching info for %s", binName)
-	command := exec.Command(b.binaryPath, "info")
-	infoJson, err := runWithTimeout(ctx, command, 10*time.Minute)
-	if err != nil {
-		return nil, fmt.Errorf("failed running '%s info': %w", b.binaryPath, err)
+	infoJson, cached := b.cachedOutput(cacheInfoOutputFile)
+	if !cached {
+		command := exec.Command(b.binaryPath, "info")
+		var err error
+		infoJson, err = runWithTimeout(ctx, command, 10*time.Minute)
+		if err != nil {
+			return nil, fmt.Errorf("failed running '%s info': %w", b.binaryPath, err)
+		}
 	}
-	jsonBegins := bytes.IndexByte(infoJson, '{')
-	jsonEnds := bytes.LastIndexByte(infoJson, '}')
-	var info ExtensionInfo
-	err = json.Unmarshal(infoJson[jsonBegins:jsonEnds+1], &info)
+	info, err := parseExtensionInfo(infoJson)
 	if err != nil {
-		return nil, errors.Wrapf(err, "couldn't unmarshal extension info: %s", string(infoJson))
+		return nil, err
+	}
+	b.info = info
+	if !cached {
+		b.cacheOutput(cacheInfoOutputFile, infoJson)
 	}
-	b.info = &info
 
 	// Set fields origin knows or calculates:
 	b.info.Source.SourceBinary = binName
@@ -90,6 +98,20 @@ func (b *TestBinary) Info(ctx context.Context) (*ExtensionInfo, error) {
 	return b.info, nil
 }
 
+// parseExtensionInfo reads the output of a binary's info command, ignoring anything printed around the JSON.
+func parseExtensionInfo(infoJson []byte) (*ExtensionInfo, error) {
+	jsonBegins := bytes.IndexByte(infoJson, '{')
+	jsonEnds := bytes.LastIndexByte(infoJson, '}')
+	if jsonBegins < 0 || jsonEnds < jsonBegins {
+		return nil, fmt.Errorf("couldn't find extension info in: %s", string(infoJson))
+	}
+	var info ExtensionInfo
+	if err := json.Unmarshal(infoJson[jsonBegins:jsonEnds+1], &info); err != nil {
+		return nil, errors.Wrapf(err, "couldn't unmarshal extension info: %s", string(infoJson))
+	}
+	return &info, nil
+}
+
 // ListTests takes a list of EnvironmentFlags to pass to the command so it can determine for itself which tests are relevant.
 // returns which tests this binary advertises.
 func (b *TestBinary) ListTests(ctx context.Context, envFlags EnvironmentFlags) (ExtensionTestSpecs, error) {
@@ -101,12 +123,18 @@ func (b *TestBinary) ListTests(ctx context.Context, envFlags EnvironmentFlags) (
 	binLogger.Info("Listing tests")
 	binLogger.Infof("OTE API version is: %s", b.info.APIVersion)
 	envFlags = b.filterToApplicableEnvironmentFlags(envFlags)
-	command := exec.Command(b.binaryPath, "list", "-o", "jsonl")
-	binLogger.Infof("adding the following applicable flags to the list command: %s", envFlags.String())
-	command.Args = append(command.Args, envFlags.ArgStrings()...)
-	testList, err := runWithTimeout(ctx, command, 10*time.Minute)
-	if err != nil {
-		return nil, fmt.Errorf("failed running '%s list': %w", b.binaryPath, err)
+	testList, cached := b.cachedOutput(listOutputFile(envFlags))
+	if cached {
+		binLogger.Infof("Using cached list output for flags: %s", envFlags.String())
+	} else {
+		command := exec.Command(b.binaryPath, "list", "-o", "jsonl")
+		binLogger.Infof("adding the following applicable flags to the list command: %s", envFlags.String())
+		command.Args = append(command.Args, envFlags.ArgStrings()...)
+		var err error
+		testList, err = runWithTimeout(ctx, command, 10*time.Minute)
+		if err != nil {
+			return nil, fmt.Errorf("failed running '%s list': %w", b.binaryPath, err)
+		}
 	}
 	buf := bytes.NewBuffer(testList)
 	for {
@@ -126,14 +154,47 @@ func (b *TestBinary) ListTests(ctx context.Context, envFlags EnvironmentFlags) (
 		extensionTestSpec.Binary = b
 		tests = append(tests, extensionTestSpec)
 	}
+	if !cached {
+		b.cacheOutput(listOutputFile(envFlags), testList)
+	}
 	binLogger.Infof("Listed %d tests in %v", len(tests), time.Since(start))
 	return tests, nil
 }
 
+// cachedOutput returns the output cached under name for this binary, if it came from a BinaryCache.
+func (b *TestBinary) cachedOutput(name string) ([]byte, bool) {
+	if b.cacheEntry == nil {
+		return nil, false
+	}
+	return b.cacheEntry.Output(name)
+}
+
+// cacheOutput stores output under name for this binary, if it came from a BinaryCache.  Failing to do so is
+// not fatal, the command is simply run again next time.
+func (b *TestBinary) cacheOutput(name string, output []byte) {
+	if b.cacheEntry == nil {
+		return
+	}
+	if err := b.cacheEntry.SetOutput(name, output); err != nil {
+		logrus.WithError(err).Warningf("Failed to cache %s for %s", name, filepath.Base(b.binaryPath))
+	}
+}
+
 // RunTests executes the named tests and returns the results.
 func (b *TestBinary) RunTests(ctx context.Context, timeout time.Duration, env []string,
 	names ...string) []*ExtensionTestResult {
 	var results []*ExtensionTestResult
+	b.StreamTests(ctx, timeout, env, names, func(result *ExtensionTestResult) {
+		results = append(results, result)
+	})
+	return results
+}
+
+// StreamTests executes the named tests in a single process and calls resultFn with each result as soon as the
+// binary reports it, so a batch of tests can be reported on as it progresses.  Once the process exits, every
+// test the binary did not report a result for is reported as failed.
+func (b *TestBinary) StreamTests(ctx context.Context, timeout time.Duration, env []string, names []string,
+	resultFn func(result *ExtensionTestResult)) {
 	expectedTests := sets.New[string](names...)
 	binName := filepath.Base(b.binaryPath)
 
@@ -159,49 +220,71 @@ func (b *TestBinary) RunTests(ctx context.Context, timeout time.Duration, env []
 	}
 	command.Env = env
 
-	// Run test
-	testResult, _ := runWithTimeout(ctx, command, timeout) // error is ignored because external binaries return non-zero when a test fails, we only need to process the output
-	buf := bytes.NewBuffer(testResult)
+	// Run tests, reading results as they are written.  Lines that are not results are kept to report on
+	// tests that never produced a result.
+	testOutput := &bytes.Buffer{}
+	reader, writer := io.Pipe()
+	command.Stdout = writer
+	command.Stderr = writer
+	if err := command.Start(); err != nil {
+		fmt.Fprintf(testOutput, "failed to start %s: %v\n", binName, err)
+		writer.Close()
+	} else {
+		stopInterrupting := interruptAfterTimeout(ctx, command, timeout)
+		go func() {
+			// error is ignored because external binaries return non-zero when a test fails, we only need to process the output
+			command.Wait()
+			stopInterrupting()
+			writer.Close()
+		}()
+	}
+
+	buf := bufio.NewReader(reader)
 	for {
 		line, err := buf.ReadString('\n')
-		if err == io.EOF {
-			break
-		}
 		if !strings.HasPrefix(line, "{") {
-			continue
+			testOutput.WriteString(line)
+		} else {
+			result := new(ExtensionTestResult)
+			if unmarshalErr := json.Unmarshal([]byte(line), &result); unmarshalErr != nil {
+				// a binary killed by the timeout may leave a result it was writing incomplete, and a malformed
+				// result must not stop the rest of the batch from being read.  Tests without a result fail below.
+				logrus.Warningf("test binary %q returned unmarshallable result: %v", binName, unmarshalErr)
+				testOutput.WriteString(line)
+				if err != nil {
+					break
+				}
+				continue
+			}
+			// expectedTests starts with the list of test names we expect, and as we see them, we
+			// remove them from the set. If we encounter a test result that's not in expectedTests,
+			// then it means either:
+			//  - we already saw a result for this test, which breaks the invariant that run-test
+			//    returns one result for each test
+			//  - we got a test result we didn't expect at all (maybe the external binary improperly
+			//    mutated the name, or otherwise did something weird)
+			if !expectedTests.Has(result.Name) {
+				result.Result = ResultFailed
+				result.Error = fmt.Sprintf("test binary %q returned unexpected result: %s", binName, result.Name)
+			}
+			expectedTests.Delete(result.Name)
+			resultFn(result)
 		}
-		result := new(ExtensionTestResult)
-		err = json.Unmarshal([]byte(line), &result)
 		if err != nil {
-			panic(fmt.Sprintf("test binary %q returned unmarshallable result", binName))
-		}
-		// expectedTests starts with the list of test names we expect, and as we see them, we
-		// remove them from the set. If we encounter a test result that's not in expectedTests,
-		// then it means either:
-		//  - we already saw a result for this test, which breaks the invariant that run-test
-		//    returns one result for each test
-		//  - we got a test result we didn't expect at all (maybe the external binary improperly
-		//    mutated the name, or otherwise did something weird)
-		if !expectedTests.Has(result.Name) {
-			result.Result = ResultFailed
-			result.Error = fmt.Sprintf("test binary %q returned unexpected result: %s", binName, result.Name)
+			break
 		}
-		expectedTests.Delete(result.Name)
-		results = append(results, result)
 	}
 
 	// If we end up with anything left in expected tests, generate failures for them because
 	// we didn't get results for them.
-	for _, expectedTest := range expectedTests.UnsortedList() {
-		results = append(results, &ExtensionTestResult{
+	for _, expectedTest := range sets.List(expectedTests) {
+		resultFn(&ExtensionTestResult{
 			Name:   expectedTest,
 			Result: ResultFailed,
-			Output: string(testResult),
+			Output: testOutput.String(),
 			Error:  "external binary did not produce a result for this test",
 		})
 	}
-
-	return results
 }
 
 func (b *TestBinary) ListImages(ctx context.Context) (ImageSet, error) {
@@ -241,6 +324,81 @@ func ExtractAllTestBinaries(ctx context.Context, parallelism int) (func(), TestB
 		return nil, nil, errors.New("parallelism must be greater than zero")
 	}
 
+	externalBinaryProvider, cleanupAuth, err := newPayloadBinaryProvider()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cleanupAuth()
+
+	var (
+		binaries []*TestBinary
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		errCh    = make(chan error, len(extensionBinaries))
+		jobCh    = make(chan TestBinary)
+	)
+
+	// Producer: sends jobs to the jobCh channel
+	go func() {
+		defer close(jobCh)
+		for _, b := range extensionBinaries {
+			select {
+			case <-ctx.Done():
+				return // Exit if context is cancelled
+			case jobCh <- b:
+			}
+		}
+	}()
+
+	// Consumer workers: extract test binaries concurrently
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return // Context is cancelled
+				case b, ok := <-jobCh:
+					if !ok {
+						return // Channel is closed
+					}
+					testBinary, err := externalBinaryProvider.ExtractBinaryFromReleaseImage(b.imageTag, b.binaryPath)
+					if err != nil {
+						errCh <- err
+						continue
+					}
+					mu.Lock()
+					binaries = append(binaries, testBinary)
+					mu.Unlock()
+				}
+			}
+
+		}()
+	}
+
+	// Wait for all workers to finish
+	wg.Wait()
+	close(errCh)
+
+	// Check if any errors were reported
+	var errs []string
+	for err := range errCh {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		externalBinaryProvider.Cleanup()
+		return nil, nil, fmt.Errorf("encountered errors while extracting binaries: %s", strings.Join(errs, ";"))
+	}
+
+	return externalBinaryProvider.Cleanup, binaries, nil
+}
+
+// newPayloadBinaryProvider determines the optimal release payload to use and the credentials to pull its
+// images with, and returns a provider to extract binaries from it.  The returned func removes the credentials
+// once extraction is done.
+func newPayloadBinaryProvider() (*ExternalBinaryProvider, func(), error) {
+	cleanupAuth := func() {}
 	releaseImage, err := determineReleasePayloadImage()
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "couldn't determine release image")
@@ -300,7 +458,7 @@ func ExtractAllTestBinaries(ctx context.Context, parallelism int) (func(), TestB
 					return nil, nil, fmt.Errorf("unable to serialize target cluster pull-secret locally: %w", err)
 				}
 
-				defer os.RemoveAll(tmpDir)
+				cleanupAuth = func() { os.RemoveAll(tmpDir) }
 				logrus.Infof("Using target cluster pull-secrets for registry auth")
 			}
 		}
@@ -308,71 +466,32 @@ func ExtractAllTestBinaries(ctx context.Context, parallelism int) (func(), TestB
 
 	externalBinaryProvider, err := NewExternalBinaryProvider(releaseImage, registryAuthFilePath)
 	if err != nil {
+		cleanupAuth()
 		return nil, nil, errors.WithMessage(err, "could not create external binary provider")
 	}
+	return externalBinaryProvider, cleanupAuth, nil
+}
 
-	var (
-		binaries []*TestBinary
-		mu       sync.Mutex
-		wg       sync.WaitGroup
-		errCh    = make(chan error, len(extensionBinaries))
-		jobCh    = make(chan TestBinary)
-	)
-
-	// Producer: sends jobs to the jobCh channel
-	go func() {
-		defer close(jobCh)
-		for _, b := range extensionBinaries {
-			select {
-			case <-ctx.Done():
-				return // Exit if context is cancelled
-			case jobCh <- b:
-			}
-		}
-	}()
-
-	// Consumer workers: extract test binaries concurrently
-	for i := 0; i < parallelism; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for {
-				select {
-				case <-ctx.Done():
-					return // Context is cancelled
-				case b, ok := <-jobCh:
-					if !ok {
-						return // Channel is closed
-					}
-					testBinary, err := externalBinaryProvider.ExtractBinaryFromReleaseImage(b.imageTag, b.binaryPath)
-					if err != nil {
-						errCh <- err
-						continue
-					}
-					mu.Lock()
-					binaries = append(binaries, testBinary)
-					mu.Unlock()
-				}
-			}
-
-		}()
+// ExtractPayloadBinary extracts a single binary from the image with the given tag in the release payload.  The
+// returned func cleans up the extracted content.
+func ExtractPayloadBinary(tag, binary string) (*TestBinary, func(), error) {
+	externalBinaryProvider, cleanupAuth, err := newPayloadBinaryProvider()
+	if err != nil {
+		return nil, nil, err
 	}
+	defer cleanupAuth()
 
-	// Wait for all workers to finish
-	wg.Wait()
-	close(errCh)
-
-	// Check if any errors were reported
-	var errs []string
-	for err := range errCh {
-		errs = append(errs, err.Error())
-	}
-	if len(errs) > 0 {
+	testBinary, err := externalBinaryProvider.ExtractBinaryFromReleaseImage(tag, binary)
+	if err != nil {
 		externalBinaryProvider.Cleanup()
-		return nil, nil, fmt.Errorf("encountered errors while extracting binaries: %s", strings.Join(errs, ";"))
+		return nil, nil, err
 	}
+	return testBinary, externalBinaryProvider.Cleanup, nil
+}
 
-	return externalBinaryProvider.Cleanup, binaries, nil
+// BinaryPath returns the path the binary was extracted to.
+func (b *TestBinary) BinaryPath() string {
+	return b.binaryPath
 }
 
 type TestBinaries []*TestBinary
@@ -594,6 +713,33 @@ func runWithTimeout(ctx context.Context, c *exec.Cmd, timeout time.Duration) ([]
 	return c.CombinedOutput()
 }
 
+// interruptAfterTimeout interrupts the started command c once timeout passes or ctx is done, and aborts it if it
+// does not exit a minute later.  The returned func stops the timers and must be called once c has exited.
+func interruptAfterTimeout(ctx context.Context, c *exec.Cmd, timeout time.Duration) func() {
+	exited := make(chan struct{})
+	if timeout <= 0 {
+		return func() { close(exited) }
+	}
+	go func() {
+		select {
+		// interrupt tests after timeout, and abort if they don't complete quick enough
+		case <-time.After(timeout):
+			c.Process.Signal(syscall.SIGINT)
+			// if the process appears to be hung a significant amount of time after the timeout
+			// send an ABRT so we get a stack dump
+			select {
+			case <-time.After(time.Minute):
+				c.Process.Signal(syscall.SIGABRT)
+			case <-exited:
+			}
+		case <-ctx.Done():
+			c.Process.Signal(syscall.SIGINT)
+		case <-exited:
+		}
+	}()
+	return func() { close(exited) }
+}
+
 var safePathRegexp = regexp.MustCompile(`[<>:"/\\|?*\s]+`)
 
 // safeComponentPath sanitizes a component identifier to be safe for use as a file or directory name.
@@ -0,0 +1,126 @@
+package extensions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStreamTests(t *testing.T) {
+	binaryPath := filepath.Join(t.TempDir(), "fake-tests-ext")
+	script := `#!/bin/sh
+if [ "$1" = "info" ]; then
+  echo '{"apiVersion":"v1.1","component":{"product":"openshift","type":"payload","name":"fake"}}'
+  exit 0
+fi
+echo 'setting up the suite'
+echo '{"name":"a","result":"passed"}'
+echo 'more output'
+echo '{"name":"b","result":"failed","error":"boom"}'
+echo '{"name":"unexpected","result":"passed"}'
+exit 1
+`
+	if err := os.WriteFile(binaryPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	binary := &TestBinary{binaryPath: binaryPath}
+	var results []*ExtensionTestResult
+	binary.StreamTests(context.Background(), time.Minute, nil, []string{"a", "b", "c"}, func(result *ExtensionTestResult) {
+		results = append(results, result)
+	})
+
+	var names []string
+	var outcomes []Result
+	for _, result := range results {
+		names = append(names, result.Name)
+		outcomes = append(outcomes, result.Result)
+	}
+	if expected := []string{"a", "b", "unexpected", "c"}; !reflect.DeepEqual(expected, names) {
+		t.Fatalf("expected results for %v in order, got %v", expected, names)
+	}
+	if expected := []Result{ResultPassed, ResultFailed, ResultFailed, ResultFailed}; !reflect.DeepEqual(expected, outcomes) {
+		t.Errorf("expected %v, got %v", expected, outcomes)
+	}
+	if expected := "setting up the suite\nmore output\n"; results[3].Output != expected {
+		t.Errorf("expected the output of the process for the missing result, got %q", results[3].Output)
+	}
+}
+
+func TestStreamTestsTruncatedResult(t *testing.T) {
+	binaryPath := filepath.Join(t.TempDir(), "fake-tests-ext")
+	// the binary is killed while writing the second result
+	script := `#!/bin/sh
+if [ "$1" = "info" ]; then
+  echo '{"apiVersion":"v1.1","component":{"product":"openshift","type":"payload","name":"fake"}}'
+  exit 0
+fi
+echo '{"name":"a","result":"passed"}'
+printf '{"name":"b","resu'
+exit 1
+`
+	if err := os.WriteFile(binaryPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	binary := &TestBinary{binaryPath: binaryPath}
+	var results []*ExtensionTestResult
+	binary.StreamTests(context.Background(), time.Minute, nil, []string{"a", "b"}, func(result *ExtensionTestResult) {
+		results = append(results, result)
+	})
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Name != "a" || results[0].Result != ResultPassed {
+		t.Errorf("expected a to pass, got %s %s", results[0].Name, results[0].Result)
+	}
+	if results[1].Name != "b" || results[1].Result != ResultFailed {
+		t.Errorf("expected b to fail, got %s %s", results[1].Name, results[1].Result)
+	}
+	if expected := `{"name":"b","resu`; results[1].Output != expected {
+		t.Errorf("expected the incomplete result in the output, got %q", results[1].Output)
+	}
+}
+
+func TestStreamTestsMalformedResult(t *testing.T) {
+	binaryPath := filepath.Join(t.TempDir(), "fake-tests-ext")
+	script := `#!/bin/sh
+if [ "$1" = "info" ]; then
+  echo '{"apiVersion":"v1.1","component":{"product":"openshift","type":"payload","name":"fake"}}'
+  exit 0
+fi
+echo '{"name":"a","result":"passed"}'
+echo '{"name":"b",result:failed}'
+echo '{"name":"c","result":"passed"}'
+exit 1
+`
+	if err := os.WriteFile(binaryPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	binary := &TestBinary{binaryPath: binaryPath}
+	var results []*ExtensionTestResult
+	binary.StreamTests(context.Background(), time.Minute, nil, []string{"a", "b", "c"}, func(result *ExtensionTestResult) {
+		results = append(results, result)
+	})
+
+	var names []string
+	var outcomes []Result
+	for _, result := range results {
+		names = append(names, result.Name)
+		outcomes = append(outcomes, result.Result)
+	}
+	if expected := []string{"a", "c", "b"}; !reflect.DeepEqual(expected, names) {
+		t.Fatalf("expected results for %v in order, got %v", expected, names)
+	}
+	if expected := []Result{ResultPassed, ResultPassed, ResultFailed}; !reflect.DeepEqual(expected, outcomes) {
+		t.Errorf("expected %v, got %v", expected, outcomes)
+	}
+	if expected := "{\"name\":\"b\",result:failed}\n"; results[2].Output != expected {
+		t.Errorf("expected the malformed result in the output, got %q", results[2].Output)
+	}
+}
@@ -0,0 +1,427 @@
+package extensions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// defaultCacheMaxSize is used when OPENSHIFT_TESTS_CACHE_MAX_SIZE is unset.
+	defaultCacheMaxSize = "10Gi"
+
+	cacheBinariesDir    = "binaries"
+	cachePayloadsDir    = "payloads"
+	cacheMetadataFile   = "metadata.json"
+	cacheInfoOutputFile = "info.json"
+	cacheTempPrefix     = ".tmp-"
+)
+
+// BinaryCache is a content addressed cache of extension binaries extracted from release payloads.  Entries are
+// keyed by the digest of the image a binary was extracted from and the path of the binary within that image, so
+// binaries are reused across payloads, or across pulls of a moving tag, whenever their image did not change.
+// Each entry records the sha256 of the binary, verified before every use, and the output of the binary's info
+// and list commands, so those do not need to be rerun either.  The cache is kept below a size cap by evicting
+// the least recently used entries.
+type BinaryCache struct {
+	dir     string
+	maxSize int64
+
+	lock sync.Mutex
+	// inUse holds the keys of entries handed out by this cache, which are never evicted from under us.
+	inUse sets.Set[string]
+}
+
+// CacheEntry describes one cached binary.
+type CacheEntry struct {
+	Key string `json:"key"`
+	// Image is the pull spec the binary was extracted from.
+	Image string `json:"image"`
+	// ImageDigest is the digest of Image at the time of extraction.
+	ImageDigest string `json:"imageDigest"`
+	// BinaryPath is the path of the binary within Image.
+	BinaryPath string `json:"binaryPath"`
+	// SHA256 is the checksum of the extracted, decompressed binary.
+	SHA256   string    `json:"sha256"`
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"lastUsed"`
+
+	// Size is the size on disk of the entry, including cached command output.  It is computed, not stored.
+	Size int64 `json:"-"`
+
+	dir string
+}
+
+// NewBinaryCache returns a cache rooted at dir that evicts entries beyond maxSize bytes.  A maxSize of zero
+// or less disables eviction.
+func NewBinaryCache(dir string, maxSize int64) (*BinaryCache, error) {
+	for _, subdir := range []string{cacheBinariesDir, cachePayloadsDir} {
+		if err := os.MkdirAll(filepath.Join(dir, subdir), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		}
+	}
+	return &BinaryCache{
+		dir:     dir,
+		maxSize: maxSize,
+		inUse:   sets.New[string](),
+	}, nil
+}
+
+// DefaultBinaryCacheDir returns $XDG_CACHE_HOME, falling back to $HOME/.cache/openshift-tests.
+func DefaultBinaryCacheDir() string {
+	cacheBase := os.Getenv("XDG_CACHE_HOME")
+	if cacheBase == "" {
+		cacheBase = path.Join(os.Getenv("HOME"), ".cache", "openshift-tests")
+	}
+	return cacheBase
+}
+
+// DefaultBinaryCacheMaxSize returns the size cap from OPENSHIFT_TESTS_CACHE_MAX_SIZE, a quantity like 5Gi,
+// or 10Gi if it is unset.
+func DefaultBinaryCacheMaxSize() (int64, error) {
+	maxSize := os.Getenv("OPENSHIFT_TESTS_CACHE_MAX_SIZE")
+	if len(maxSize) == 0 {
+		maxSize = defaultCacheMaxSize
+	}
+	quantity, err := resource.ParseQuantity(maxSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid OPENSHIFT_TESTS_CACHE_MAX_SIZE %q: %w", maxSize, err)
+	}
+	return quantity.Value(), nil
+}
+
+// Dir returns the root directory of the cache.
+func (c *BinaryCache) Dir() string {
+	return c.dir
+}
+
+// PayloadDir returns a directory for content extracted from the release payload with the given digest.
+func (c *BinaryCache) PayloadDir(payloadDigest string) string {
+	return filepath.Join(c.dir, cachePayloadsDir, pullSpecToDirName(payloadDigest))
+}
+
+// Lookup returns the entry for binaryPath in the image with the given digest, or nil if there is none.  An
+// entry whose binary no longer matches its checksum is removed and reported as missing.
+func (c *BinaryCache) Lookup(imageDigest, binaryPath string) (*CacheEntry, error) {
+	entry, err := c.readEntry(cacheKey(imageDigest, binaryPath))
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	if err := entry.verify(); err != nil {
+		logrus.WithError(err).Warningf("Removing corrupt cache entry for %s in %s", binaryPath, entry.Image)
+		if err := os.RemoveAll(entry.dir); err != nil {
+			return nil, fmt.Errorf("failed to remove corrupt cache entry %s: %w", entry.dir, err)
+		}
+		return nil, nil
+	}
+
+	c.markInUse(entry.Key)
+	entry.LastUsed = time.Now()
+	if err := entry.writeMetadata(); err != nil {
+		logrus.WithError(err).Warningf("Failed to update cache entry %s", entry.dir)
+	}
+	return entry, nil
+}
+
+// Add stores the binary produced by extract under the given image digest and binary path.  extract is called
+// with an empty directory and must return the path of the extracted binary within it.  The cache is pruned
+// to its size cap afterwards.
+func (c *BinaryCache) Add(image, imageDigest, binaryPath string, extract func(dir string) (string, error)) (*CacheEntry, error) {
+	key := cacheKey(imageDigest, binaryPath)
+	tmpDir, err := os.MkdirTemp(filepath.Join(c.dir, cacheBinariesDir), cacheTempPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	extracted, err := extract(tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Dir(extracted) != tmpDir {
+		return nil, fmt.Errorf("extracted binary %q is not in %q", extracted, tmpDir)
+	}
+	checksum, err := fileSHA256(extracted)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entry := &CacheEntry{
+		Key:         key,
+		Image:       image,
+		ImageDigest: imageDigest,
+		BinaryPath:  binaryPath,
+		SHA256:      checksum,
+		Created:     now,
+		LastUsed:    now,
+		dir:         tmpDir,
+	}
+	if err := entry.writeMetadata(); err != nil {
+		return nil, err
+	}
+
+	// Publish the entry atomically.  If another process won the race, use its entry instead.
+	entry.dir = filepath.Join(c.dir, cacheBinariesDir, key)
+	if err := os.Rename(tmpDir, entry.dir); err != nil {
+		existing, lookupErr := c.Lookup(imageDigest, binaryPath)
+		if lookupErr != nil || existing == nil {
+			return nil, fmt.Errorf("failed to add cache entry %s: %w", entry.dir, err)
+		}
+		return existing, nil
+	}
+	c.markInUse(key)
+
+	if c.maxSize > 0 {
+		if _, err := c.Prune(c.maxSize); err != nil {
+			logrus.WithError(err).Warning("Failed to prune the binary cache")
+		}
+	}
+	return c.readEntry(key)
+}
+
+// Entries returns every entry in the cache, most recently used first.
+func (c *BinaryCache) Entries() ([]*CacheEntry, error) {
+	dirEntries, err := os.ReadDir(filepath.Join(c.dir, cacheBinariesDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	var entries []*CacheEntry
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() || strings.HasPrefix(dirEntry.Name(), cacheTempPrefix) {
+			continue
+		}
+		entry, err := c.readEntry(dirEntry.Name())
+		if err != nil {
+			logrus.WithError(err).Warningf("Ignoring unreadable cache entry %s", dirEntry.Name())
+			continue
+		}
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+// CachedExtensionInfo returns the info cached for the binaries in the cache rooted at dir, most recently used
+// first.  The binaries may have been extracted from different payloads.  The cache is not created if it does
+// not exist.
+func CachedExtensionInfo(dir string) ([]*ExtensionInfo, error) {
+	if _, err := os.Stat(filepath.Join(dir, cacheBinariesDir)); os.IsNotExist(err) {
+		return nil, nil
+	}
+	cache := &BinaryCache{dir: dir, inUse: sets.New[string]()}
+	entries, err := cache.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []*ExtensionInfo
+	for _, entry := range entries {
+		infoJson, ok := entry.Output(cacheInfoOutputFile)
+		if !ok {
+			continue
+		}
+		info, err := parseExtensionInfo(infoJson)
+		if err != nil {
+			logrus.WithError(err).Warningf("Ignoring unreadable info of cache entry %s", entry.Key)
+			continue
+		}
+		info.Source.SourceBinary = filepath.Base(entry.BinaryPath)
+		info.Source.SourceImage = entry.Image
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// Prune evicts the least recently used entries until the cache is no larger than maxSize bytes, and returns
+// the evicted entries.  Entries in use by this process are never evicted.  Leftovers of interrupted
+// extractions and entries without readable metadata are removed as well.
+func (c *BinaryCache) Prune(maxSize int64) ([]*CacheEntry, error) {
+	binariesDir := filepath.Join(c.dir, cacheBinariesDir)
+	dirEntries, err := os.ReadDir(binariesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if strings.HasPrefix(name, cacheTempPrefix) {
+			// an extraction may still be in progress in another process, give it time to finish
+			if info, err := dirEntry.Info(); err != nil || time.Since(info.ModTime()) < time.Hour {
+				continue
+			}
+		} else if entry, err := c.readEntry(name); err == nil && entry != nil {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(binariesDir, name)); err != nil {
+			logrus.WithError(err).Warningf("Failed to remove stale cache entry %s", name)
+		}
+	}
+
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	var evicted []*CacheEntry
+	for i := len(entries) - 1; i >= 0 && total > maxSize; i-- {
+		entry := entries[i]
+		if c.isInUse(entry.Key) {
+			continue
+		}
+		if err := os.RemoveAll(entry.dir); err != nil {
+			return evicted, fmt.Errorf("failed to evict cache entry %s: %w", entry.dir, err)
+		}
+		logrus.Infof("Evicted %s from %s from the binary cache (last used %v)", entry.BinaryPath, entry.Image, entry.LastUsed.Format(time.RFC3339))
+		total -= entry.Size
+		evicted = append(evicted, entry)
+	}
+	return evicted, nil
+}
+
+// Remove deletes an entry from the cache.
+func (c *BinaryCache) Remove(entry *CacheEntry) error {
+	return os.RemoveAll(entry.dir)
+}
+
+func (c *BinaryCache) readEntry(key string) (*CacheEntry, error) {
+	dir := filepath.Join(c.dir, cacheBinariesDir, key)
+	data, err := os.ReadFile(filepath.Join(dir, cacheMetadataFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache entry %s: %w", dir, err)
+	}
+
+	entry := &CacheEntry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, fmt.Errorf("failed to read cache entry %s: %w", dir, err)
+	}
+	entry.dir = dir
+	err = filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		entry.Size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache entry %s: %w", dir, err)
+	}
+	return entry, nil
+}
+
+func (c *BinaryCache) markInUse(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.inUse.Insert(key)
+}
+
+func (c *BinaryCache) isInUse(key string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.inUse.Has(key)
+}
+
+// BinaryFile returns the path of the cached binary.
+func (e *CacheEntry) BinaryFile() string {
+	return filepath.Join(e.dir, strings.TrimSuffix(filepath.Base(e.BinaryPath), ".gz"))
+}
+
+// Output returns the cached output stored under name, if there is any.
+func (e *CacheEntry) Output(name string) ([]byte, bool) {
+	data, err := os.ReadFile(filepath.Join(e.dir, name))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// SetOutput caches output under name.
+func (e *CacheEntry) SetOutput(name string, output []byte) error {
+	return writeFileAtomically(filepath.Join(e.dir, name), output)
+}
+
+func (e *CacheEntry) verify() error {
+	checksum, err := fileSHA256(e.BinaryFile())
+	if err != nil {
+		return err
+	}
+	if checksum != e.SHA256 {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", e.BinaryFile(), e.SHA256, checksum)
+	}
+	return nil
+}
+
+func (e *CacheEntry) writeMetadata() error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(filepath.Join(e.dir, cacheMetadataFile), data)
+}
+
+// listOutputFile names the cached list output for a set of environment flags.
+func listOutputFile(envFlags EnvironmentFlags) string {
+	hash := sha256.Sum256([]byte(strings.Join(envFlags.ArgStrings(), "\x00")))
+	return fmt.Sprintf("list-%x.jsonl", hash[:8])
+}
+
+func cacheKey(imageDigest, binaryPath string) string {
+	hash := sha256.Sum256([]byte(imageDigest + "\x00" + binaryPath))
+	return hex.EncodeToString(hash[:])
+}
+
+func fileSHA256(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", fmt.Errorf("failed to checksum %s: %w", filename, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// writeFileAtomically keeps concurrent readers, possibly in other processes, from seeing partial content.
+func writeFileAtomically(filename string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), cacheTempPrefix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
@@ -0,0 +1,264 @@
+package extensions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// fakeRegistry stands in for the release payload: images map to directories holding their content.
+type fakeRegistry struct {
+	images      map[string]string
+	extractions int
+}
+
+func (r *fakeRegistry) extract(image, src, dst, _ string) error {
+	r.extractions++
+	root, ok := r.images[image]
+	if !ok {
+		return fmt.Errorf("manifest unknown: %s", image)
+	}
+	data, err := os.ReadFile(filepath.Join(root, src))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dst, filepath.Base(src)), data, 0644)
+}
+
+func (r *fakeRegistry) resolveDigest(image, _ string) (string, error) {
+	if i := strings.LastIndex(image, "@"); i >= 0 {
+		return image[i+1:], nil
+	}
+	return "", fmt.Errorf("unexpected tag reference %s", image)
+}
+
+func TestExternalBinaryProviderCache(t *testing.T) {
+	// the test binary itself is an executable for the host architecture
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(self)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imageRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(imageRoot, "usr", "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(imageRoot, "usr", "bin", "fake-tests-ext"), content, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	image := "quay.io/openshift/fake@sha256:1111"
+	registry := &fakeRegistry{images: map[string]string{image: imageRoot}}
+	cache, err := NewBinaryCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := &ExternalBinaryProvider{
+		binPath: cache.PayloadDir("sha256:0000"),
+		imageStream: &imagev1.ImageStream{Spec: imagev1.ImageStreamSpec{Tags: []imagev1.TagReference{
+			{Name: "fake", From: &corev1.ObjectReference{Name: image}},
+		}}},
+		cache:         cache,
+		extract:       registry.extract,
+		resolveDigest: registry.resolveDigest,
+	}
+
+	binary, err := provider.ExtractBinaryFromReleaseImage("fake", "/usr/bin/fake-tests-ext")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.ExtractBinaryFromReleaseImage("fake", "/usr/bin/fake-tests-ext"); err != nil {
+		t.Fatal(err)
+	}
+	if registry.extractions != 1 {
+		t.Errorf("expected the second extraction to be served from the cache, got %d extractions", registry.extractions)
+	}
+
+	// a binary that no longer matches its checksum is extracted again
+	if err := os.WriteFile(binary.binaryPath, []byte("corrupt"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	binary, err = provider.ExtractBinaryFromReleaseImage("fake", "/usr/bin/fake-tests-ext")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registry.extractions != 2 {
+		t.Errorf("expected a corrupt binary to be extracted again, got %d extractions", registry.extractions)
+	}
+	if err := binary.cacheEntry.verify(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBinaryCacheOutput(t *testing.T) {
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "invocations")
+	script := fmt.Sprintf(`#!/bin/sh
+echo "$1" >> %s
+case "$1" in
+info) echo '{"apiVersion":"v1.1","component":{"product":"openshift","type":"payload","name":"fake"}}' ;;
+list) echo '{"name":"test a"}'; echo '{"name":"test b"}' ;;
+esac
+`, counter)
+
+	cache, err := NewBinaryCache(filepath.Join(dir, "cache"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := cache.Add("fake@sha256:1111", "sha256:1111", "/usr/bin/fake-tests-ext", func(dir string) (string, error) {
+		filename := filepath.Join(dir, "fake-tests-ext")
+		return filename, os.WriteFile(filename, []byte(script), 0755)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		// a new TestBinary, as in a new invocation of openshift-tests
+		binary := &TestBinary{binaryPath: entry.BinaryFile(), cacheEntry: entry}
+		info, err := binary.Info(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Component.Name != "fake" {
+			t.Errorf("unexpected info %#v", info)
+		}
+		tests, err := binary.ListTests(ctx, EnvironmentFlags{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tests) != 2 {
+			t.Errorf("expected 2 tests, got %d", len(tests))
+		}
+	}
+
+	invocations, err := os.ReadFile(counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(invocations) != "info\nlist\n" {
+		t.Errorf("expected the binary to be invoked once per command, got %q", invocations)
+	}
+}
+
+func TestCachedExtensionInfo(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	infos, err := CachedExtensionInfo(dir)
+	if err != nil || len(infos) != 0 {
+		t.Fatalf("expected no info for a missing cache, got %v, %v", infos, err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected a missing cache not to be created")
+	}
+
+	cache, err := NewBinaryCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, binaryPath := range []string{"/usr/bin/fake-tests-ext", "/usr/bin/other-tests-ext"} {
+		entry, err := cache.Add("fake@sha256:1111", "sha256:1111", binaryPath, func(dir string) (string, error) {
+			filename := filepath.Join(dir, filepath.Base(binaryPath))
+			return filename, os.WriteFile(filename, []byte("binary"), 0755)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// only binaries that were asked for their info have it cached
+		if binaryPath == "/usr/bin/fake-tests-ext" {
+			info := `warning: something
+{"apiVersion":"v1.1","component":{"product":"openshift","type":"payload","name":"fake"},"suites":[{"name":"fake/suite","qualifiers":["true"]}]}`
+			if err := entry.SetOutput(cacheInfoOutputFile, []byte(info)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	infos, err = CachedExtensionInfo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 {
+		t.Fatalf("expected the info of one binary, got %d", len(infos))
+	}
+	if infos[0].Source.SourceBinary != "fake-tests-ext" || len(infos[0].Suites) != 1 || infos[0].Suites[0].Name != "fake/suite" {
+		t.Errorf("unexpected info %#v", infos[0])
+	}
+}
+
+func TestBinaryCachePrune(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewBinaryCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	add := func(c *BinaryCache, digest string) *CacheEntry {
+		entry, err := c.Add("fake@"+digest, digest, "/usr/bin/fake-tests-ext", func(dir string) (string, error) {
+			filename := filepath.Join(dir, "fake-tests-ext")
+			return filename, os.WriteFile(filename, make([]byte, 1024), 0755)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return entry
+	}
+	oldest := add(cache, "sha256:1111")
+	middle := add(cache, "sha256:2222")
+	newest := add(cache, "sha256:3333")
+	for i, entry := range []*CacheEntry{oldest, middle, newest} {
+		entry.LastUsed = time.Now().Add(time.Duration(i-3) * time.Hour)
+		if err := entry.writeMetadata(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// entries in use by this process are never evicted
+	evicted, err := cache.Prune(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evicted) != 0 {
+		t.Errorf("expected entries in use not to be evicted, got %d", len(evicted))
+	}
+
+	// another process evicts the least recently used entries first
+	other, err := NewBinaryCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Lookup("sha256:1111", "/usr/bin/fake-tests-ext"); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := other.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+	evicted, err = other.Prune(total - 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evicted) != 1 || evicted[0].Key != middle.Key {
+		t.Errorf("expected only the least recently used entry not in use to be evicted, got %v", evicted)
+	}
+	entries, err = other.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Key != oldest.Key || entries[1].Key != newest.Key {
+		t.Errorf("unexpected remaining entries %v", entries)
+	}
+}
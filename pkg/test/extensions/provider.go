@@ -6,6 +6,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,16 +17,22 @@ import (
 )
 
 // ExternalBinaryProvider handles extracting external test binaries from a given payload. By
-// default, it uses a cache directory for extracted binaries assuming they'll be reused,
-// especially when developing locally. Set OPENSHIFT_TESTS_DISABLE_CACHE to any non-empty
-// value to use a temporary directory instead that will be removed at end of execution. When
-// using caching, files older than 7 days will be removed.
+// default, it uses a BinaryCache for extracted binaries assuming they'll be reused, especially
+// when developing locally. Set OPENSHIFT_TESTS_DISABLE_CACHE to any non-empty value to use a
+// temporary directory instead that will be removed at end of execution. When using caching,
+// the least recently used binaries are evicted once the cache grows beyond
+// OPENSHIFT_TESTS_CACHE_MAX_SIZE, and other cached data older than 7 days is removed.
 type ExternalBinaryProvider struct {
 	oc                   *util.CLI
 	binPath              string
 	tmpDir               string
 	registryAuthFilePath string
 	imageStream          *imagev1.ImageStream
+	cache                *BinaryCache
+
+	// extract and resolveDigest talk to the registry, tests may replace them.
+	extract       func(image, src, dst, dockerConfigJsonPath string) error
+	resolveDigest func(image, dockerConfigJsonPath string) (string, error)
 }
 
 func NewExternalBinaryProvider(releaseImage, registryAuthfilePath string) (*ExternalBinaryProvider,
@@ -33,16 +40,29 @@ func NewExternalBinaryProvider(releaseImage, registryAuthfilePath string) (*Exte
 	oc := util.NewCLIWithoutNamespace("default")
 
 	// Use a fixed cache or tmp directory for storing binaries
+	var cache *BinaryCache
 	tmpDir := ""
 	binDir := pullSpecToDirName(releaseImage)
 	if len(os.Getenv("OPENSHIFT_TESTS_DISABLE_CACHE")) == 0 {
-		// Determine cache path
-		cacheBase := os.Getenv("XDG_CACHE_HOME")
-		if cacheBase == "" {
-			cacheBase = path.Join(os.Getenv("HOME"), ".cache", "openshift-tests")
+		cacheBase := DefaultBinaryCacheDir()
+		maxSize, err := DefaultBinaryCacheMaxSize()
+		if err != nil {
+			return nil, err
+		}
+		cache, err = NewBinaryCache(cacheBase, maxSize)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "error creating cache %s", cacheBase)
+		}
+		// binaries are evicted by size, the rest of the cached data by age
+		cleanOldCacheFiles(cacheBase, cacheBinariesDir, cachePayloadsDir)
+		cleanOldCacheFiles(filepath.Join(cacheBase, cachePayloadsDir))
+
+		// key the release payload content by digest, since the pull spec may be a tag that moves
+		payloadDigest, err := resolveImageDigest(releaseImage, registryAuthfilePath)
+		if err != nil {
+			return nil, errors.WithMessage(err, "couldn't resolve release payload digest")
 		}
-		cleanOldCacheFiles(cacheBase)
-		binDir = path.Join(cacheBase, binDir)
+		binDir = cache.PayloadDir(payloadDigest)
 		logrus.WithField("cache_dir", cacheBase).Infof("External binary cache is enabled")
 	} else {
 		logrus.Infof("External binary cache is disabled, using a temp directory instead")
@@ -58,6 +78,13 @@ func NewExternalBinaryProvider(releaseImage, registryAuthfilePath string) (*Exte
 	if err := createBinPath(binDir); err != nil {
 		return nil, errors.WithMessagef(err, "error creating cache path %s", binDir)
 	}
+	if cache != nil {
+		// mark the payload as recently used
+		now := time.Now()
+		if err := os.Chtimes(binDir, now, now); err != nil {
+			logrus.WithError(err).Warningf("Failed to update modification time of %s", binDir)
+		}
+	}
 
 	releasePayloadImageStream, releaseImage, err := extractReleaseImageStream(binDir, releaseImage, registryAuthfilePath)
 	if err != nil {
@@ -70,6 +97,9 @@ func NewExternalBinaryProvider(releaseImage, registryAuthfilePath string) (*Exte
 		imageStream:          releasePayloadImageStream,
 		binPath:              binDir,
 		tmpDir:               tmpDir,
+		cache:                cache,
+		extract:              runImageExtract,
+		resolveDigest:        resolveImageDigest,
 	}, nil
 }
 
@@ -111,6 +141,10 @@ func (provider *ExternalBinaryProvider) ExtractBinaryFromReleaseImage(tag, binar
 		return nil, fmt.Errorf("%s not found", tag)
 	}
 
+	if provider.cache != nil {
+		return provider.extractCachedBinary(tag, image, binary)
+	}
+
 	// Define the path for the binary
 	binPath := filepath.Join(provider.binPath, strings.TrimSuffix(filepath.Base(binary), ".gz"))
 
@@ -123,46 +157,86 @@ func (provider *ExternalBinaryProvider) ExtractBinaryFromReleaseImage(tag, binar
 		}, nil
 	}
 
+	extractedBinary, err := provider.extractBinary(tag, image, binary, provider.binPath)
+	if err != nil {
+		return nil, err
+	}
+	return &TestBinary{
+		imageTag:   tag,
+		binaryPath: extractedBinary,
+	}, nil
+}
+
+// extractCachedBinary returns the binary from the cache, extracting it into the cache first if needed.
+func (provider *ExternalBinaryProvider) extractCachedBinary(tag, image, binary string) (*TestBinary, error) {
+	digest, err := provider.resolveDigest(image, provider.registryAuthFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := provider.cache.Lookup(digest, binary)
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil {
+		logrus.Infof("Using cached binary %s for tag %s", entry.BinaryFile(), tag)
+	} else {
+		entry, err = provider.cache.Add(image, digest, binary, func(dir string) (string, error) {
+			return provider.extractBinary(tag, image, binary, dir)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &TestBinary{
+		imageTag:   tag,
+		binaryPath: entry.BinaryFile(),
+		cacheEntry: entry,
+	}, nil
+}
+
+// extractBinary extracts binary from image into dir and returns the path of the extracted, executable binary.
+func (provider *ExternalBinaryProvider) extractBinary(tag, image, binary, dir string) (string, error) {
 	// Start the extraction process.
 	startTime := time.Now()
-	if err := runImageExtract(image, binary, provider.binPath, provider.registryAuthFilePath); err != nil {
-		return nil, fmt.Errorf("failed extracting %q from %q: %w", binary, image, err)
+	if err := provider.extract(image, binary, dir, provider.registryAuthFilePath); err != nil {
+		return "", fmt.Errorf("failed extracting %q from %q: %w", binary, image, err)
 	}
 	extractDuration := time.Since(startTime)
 
-	extractedBinary := filepath.Join(provider.binPath, filepath.Base(binary))
+	extractedBinary := filepath.Join(dir, filepath.Base(binary))
 
 	// Support gzipped external binaries (handle decompression).
 	extractedBinary, err := ungzipFile(extractedBinary)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decompress external binary %q: %w", binary, err)
+		return "", fmt.Errorf("failed to decompress external binary %q: %w", binary, err)
 	}
 
 	// Make the extracted binary executable.
 	if err := os.Chmod(extractedBinary, 0755); err != nil {
-		return nil, fmt.Errorf("failed making the extracted binary %q executable: %w", extractedBinary, err)
+		return "", fmt.Errorf("failed making the extracted binary %q executable: %w", extractedBinary, err)
 	}
 
 	// Verify the binary actually exists
 	fileInfo, err := os.Stat(extractedBinary)
 	if err != nil {
-		return nil, fmt.Errorf("failed stat on extracted binary %q: %w", extractedBinary, err)
+		return "", fmt.Errorf("failed stat on extracted binary %q: %w", extractedBinary, err)
 	}
 
 	// Verify the binary is compatible with our architecture
 	if err := checkCompatibleArchitecture(extractedBinary); err != nil {
-		return nil, errors.WithMessage(err, "error checking binary architecture compatability")
+		return "", errors.WithMessage(err, "error checking binary architecture compatability")
 	}
 
 	logrus.Infof("Extracted %s for tag %s from %s (disk size %v, extraction duration %v)",
 		binary, tag, image, fileInfo.Size(), extractDuration)
 
-	return &TestBinary{
-		binaryPath: extractedBinary,
-	}, nil
+	return extractedBinary, nil
 }
 
-func cleanOldCacheFiles(dir string) {
+// cleanOldCacheFiles removes entries of dir not modified in 7 days, except for those named in keep.
+func cleanOldCacheFiles(dir string, keep ...string) {
 	maxAge := 24 * 7 * time.Hour // 7 days
 	logrus.Infof("Cleaning up older cached data...")
 	entries, err := os.ReadDir(dir)
@@ -173,6 +247,9 @@ func cleanOldCacheFiles(dir string) {
 
 	start := time.Now()
 	for _, entry := range entries {
+		if slices.Contains(keep, entry.Name()) {
+			continue
+		}
 		info, err := entry.Info()
 		if err != nil || start.Sub(info.ModTime()) < maxAge {
 			continue
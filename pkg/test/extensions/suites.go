@@ -0,0 +1,148 @@
+package extensions
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/cel-go/cel"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Filter returns the specs selected by any of the CEL qualifiers.  Qualifiers may refer to name, originalName,
+// labels (list of strings), tags (map of strings), source, and lifecycle.  No qualifiers selects nothing.
+func (specs ExtensionTestSpecs) Filter(qualifiers []string) (ExtensionTestSpecs, error) {
+	if len(qualifiers) == 0 {
+		return nil, nil
+	}
+
+	env, err := cel.NewEnv(
+		cel.Variable("name", cel.StringType),
+		cel.Variable("originalName", cel.StringType),
+		cel.Variable("labels", cel.ListType(cel.StringType)),
+		cel.Variable("tags", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("source", cel.StringType),
+		cel.Variable("lifecycle", cel.StringType),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var programs []cel.Program
+	for _, qualifier := range qualifiers {
+		ast, issues := env.Compile(qualifier)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("invalid qualifier %q: %w", qualifier, issues.Err())
+		}
+		if !ast.OutputType().IsExactType(cel.BoolType) {
+			return nil, fmt.Errorf("invalid qualifier %q: must evaluate to a bool, not %v", qualifier, ast.OutputType())
+		}
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("invalid qualifier %q: %w", qualifier, err)
+		}
+		programs = append(programs, program)
+	}
+
+	var filtered ExtensionTestSpecs
+	for _, spec := range specs {
+		tags := spec.Tags
+		if tags == nil {
+			tags = map[string]string{}
+		}
+		activation := map[string]interface{}{
+			"name":         spec.Name,
+			"originalName": spec.OriginalName,
+			"labels":       sets.List(spec.Labels),
+			"tags":         tags,
+			"source":       spec.Source,
+			"lifecycle":    string(spec.Lifecycle),
+		}
+		for _, program := range programs {
+			out, _, err := program.Eval(activation)
+			// a qualifier that cannot be evaluated for a spec, for instance because of a missing tag, does not select it
+			if err != nil {
+				continue
+			}
+			if selected, ok := out.Value().(bool); ok && selected {
+				filtered = append(filtered, spec)
+				break
+			}
+		}
+	}
+	return filtered, nil
+}
+
+// SuiteQualifiers returns the qualifiers advertised by extensions for the named suite, including the qualifiers of
+// every advertised suite that lists it as a parent, directly or through other advertised suites.  The bool is true
+// if any extension advertises the suite itself or a child of it.
+func SuiteQualifiers(name string, infos []*ExtensionInfo) ([]string, bool) {
+	qualifiersBySuite := map[string][]string{}
+	childrenBySuite := map[string]sets.Set[string]{}
+	for _, info := range infos {
+		if info == nil {
+			continue
+		}
+		for _, suite := range info.Suites {
+			qualifiersBySuite[suite.Name] = append(qualifiersBySuite[suite.Name], suite.Qualifiers...)
+			for _, parent := range suite.Parents {
+				if childrenBySuite[parent] == nil {
+					childrenBySuite[parent] = sets.New[string]()
+				}
+				childrenBySuite[parent].Insert(suite.Name)
+			}
+		}
+	}
+
+	// walk down from the requested suite, guarding against cycles
+	visited := sets.New[string]()
+	pending := []string{name}
+	for len(pending) > 0 {
+		curr := pending[0]
+		pending = pending[1:]
+		if visited.Has(curr) {
+			continue
+		}
+		visited.Insert(curr)
+		pending = append(pending, sets.List(childrenBySuite[curr])...)
+	}
+
+	found := false
+	qualifiers := sets.New[string]()
+	for suiteName := range visited {
+		suiteQualifiers, ok := qualifiersBySuite[suiteName]
+		if !ok {
+			continue
+		}
+		found = true
+		qualifiers.Insert(suiteQualifiers...)
+	}
+	return sets.List(qualifiers), found
+}
+
+// AdvertisedSuites returns every suite advertised by the extensions, merged by name.
+func AdvertisedSuites(infos []*ExtensionInfo) []Suite {
+	merged := map[string]*Suite{}
+	var names []string
+	for _, info := range infos {
+		if info == nil {
+			continue
+		}
+		for _, suite := range info.Suites {
+			curr, ok := merged[suite.Name]
+			if !ok {
+				curr = &Suite{Name: suite.Name}
+				merged[suite.Name] = curr
+				names = append(names, suite.Name)
+			}
+			curr.Parents = sets.List(sets.New(curr.Parents...).Insert(suite.Parents...))
+			curr.Qualifiers = append(curr.Qualifiers, suite.Qualifiers...)
+		}
+	}
+
+	sort.Strings(names)
+	ret := make([]Suite, 0, len(names))
+	for _, name := range names {
+		ret = append(ret, *merged[name])
+	}
+	return ret
+}
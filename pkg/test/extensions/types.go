@@ -107,7 +107,10 @@ type Resources struct {
 }
 
 type Isolation struct {
-	Mode     string   `json:"mode,omitempty"`
+	// Mode is how the test must be isolated from other tests.
+	Mode string `json:"mode,omitempty"`
+	// Conflict lists resources the test needs exclusive access to.  Tests that share a conflict
+	// are never run at the same time.
 	Conflict []string `json:"conflict,omitempty"`
 }
 
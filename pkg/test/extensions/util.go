@@ -131,6 +131,32 @@ func runImageExtract(image, src, dst string, dockerConfigJsonPath string) error
 	return fmt.Errorf("error during image extract: %w (%v)", err, string(out))
 }
 
+// resolveImageDigest returns the digest of image, asking the registry when the pull spec is not by digest.
+func resolveImageDigest(image, dockerConfigJsonPath string) (string, error) {
+	if i := strings.LastIndex(image, "@"); i >= 0 {
+		return image[i+1:], nil
+	}
+
+	args := []string{"image", "info", image, "-o", "json", fmt.Sprintf("--filter-by-os=linux/%s", runtime.GOARCH)}
+	if len(dockerConfigJsonPath) > 0 {
+		args = append(args, fmt.Sprintf("--registry-config=%s", dockerConfigJsonPath))
+	}
+	out, err := exec.Command("oc", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("error resolving digest of %q: %w", image, err)
+	}
+	info := struct {
+		Digest string `json:"digest"`
+	}{}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return "", fmt.Errorf("error resolving digest of %q: %w", image, err)
+	}
+	if len(info.Digest) == 0 {
+		return "", fmt.Errorf("error resolving digest of %q: no digest reported", image)
+	}
+	return info.Digest, nil
+}
+
 // pullSpecToDirName converts a release pullspec to a directory, for use with caching.
 func pullSpecToDirName(input string) string {
 	// Remove any non-alphanumeric characters (except '-') and replace them with '_'.
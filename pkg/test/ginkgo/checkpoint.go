@@ -0,0 +1,241 @@
+package ginkgo
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	"github.com/openshift/origin/pkg/test/extensions"
+)
+
+// checkpointFilename is written to the junit dir as tests complete, so an interrupted run can be resumed with
+// --resume-from.
+const checkpointFilename = "openshift-tests-checkpoint.jsonl"
+
+// checkpointRecord is one line of a checkpoint file.  A checkpoint starts with a run record, followed by a test
+// record for every test as it completes.  Lines are appended, so a process killed while writing leaves at worst
+// a truncated last line, which is ignored.
+type checkpointRecord struct {
+	Run  *checkpointRun  `json:"run,omitempty"`
+	Test *checkpointTest `json:"test,omitempty"`
+}
+
+type checkpointRun struct {
+	Suite      string `json:"suite"`
+	RandomSeed int64  `json:"randomSeed"`
+	// MonitorStartTime is when the first attempt started monitoring, which resumed attempts report from.
+	MonitorStartTime time.Time `json:"monitorStartTime"`
+	// Start is when this attempt started.
+	Start time.Time `json:"start"`
+}
+
+type checkpointTest struct {
+	Name                string                          `json:"name"`
+	State               TestState                       `json:"state"`
+	Start               time.Time                       `json:"start"`
+	End                 time.Time                       `json:"end"`
+	Output              []byte                          `json:"output,omitempty"`
+	ExtensionTestResult *extensions.ExtensionTestResult `json:"extensionTestResult,omitempty"`
+}
+
+// checkpoint is the progress of an interrupted run.
+type checkpoint struct {
+	checkpointRun
+
+	// lastProgress is the last time the interrupted attempt was known to be running.
+	lastProgress time.Time
+	tests        map[string]*checkpointTest
+}
+
+// loadCheckpoint reads the checkpoint written to dir by an earlier run.
+func loadCheckpoint(dir string) (*checkpoint, error) {
+	filename := filepath.Join(dir, checkpointFilename)
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read checkpoint: %w", err)
+	}
+	defer f.Close()
+
+	cp := &checkpoint{tests: map[string]*checkpointTest{}}
+	sawRun := false
+	reader := bufio.NewReader(f)
+	for lineNumber := 1; ; lineNumber++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, fmt.Errorf("could not read checkpoint %s: %w", filename, readErr)
+		}
+		if len(line) > 0 {
+			record := &checkpointRecord{}
+			if err := json.Unmarshal(line, record); err != nil {
+				if readErr == io.EOF {
+					logrus.Warningf("Ignoring truncated last line of checkpoint %s", filename)
+					break
+				}
+				return nil, fmt.Errorf("invalid checkpoint %s line %d: %w", filename, lineNumber, err)
+			}
+			switch {
+			case record.Run != nil:
+				if sawRun && record.Run.Suite != cp.Suite {
+					return nil, fmt.Errorf("invalid checkpoint %s: runs of both %q and %q", filename, cp.Suite, record.Run.Suite)
+				}
+				sawRun = true
+				cp.checkpointRun = *record.Run
+				if record.Run.Start.After(cp.lastProgress) {
+					cp.lastProgress = record.Run.Start
+				}
+			case record.Test != nil:
+				// the first attempt is authoritative.  A later record of the same test is a retry, and the resumed
+				// run retries the failures it replays itself, so that flakes are still told from failures.
+				if _, ok := cp.tests[record.Test.Name]; !ok {
+					cp.tests[record.Test.Name] = record.Test
+				}
+				if record.Test.End.After(cp.lastProgress) {
+					cp.lastProgress = record.Test.End
+				}
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+	if !sawRun {
+		return nil, fmt.Errorf("invalid checkpoint %s: no run recorded", filename)
+	}
+	return cp, nil
+}
+
+// resume sets the results recorded by the checkpoint on the tests that completed, and returns them apart from
+// the tests that still need to run.
+func (cp *checkpoint) resume(tests []*testCase) (completed, remaining []*testCase) {
+	for _, test := range tests {
+		recorded, ok := cp.tests[test.name]
+		if !ok {
+			remaining = append(remaining, test)
+			continue
+		}
+		mutateTestCaseWithResults(test, &testRunResultHandle{testRunResult: &testRunResult{
+			name:                recorded.Name,
+			start:               recorded.Start,
+			end:                 recorded.End,
+			testState:           recorded.State,
+			testOutputBytes:     recorded.Output,
+			extensionTestResult: recorded.ExtensionTestResult,
+		}})
+		completed = append(completed, test)
+	}
+	return completed, remaining
+}
+
+// interruption returns an interval covering the time the run was not running.
+func (cp *checkpoint) interruption(resumed time.Time) monitorapi.Interval {
+	return monitorapi.NewInterval(monitorapi.SourceE2ETest, monitorapi.Warning).
+		Locator(monitorapi.NewLocator().E2ETestSuite(cp.Suite)).
+		Message(monitorapi.NewMessage().Reason(monitorapi.E2ETestRunInterrupted).
+			HumanMessagef("test run was interrupted, %d tests had completed", len(cp.tests))).
+		Display().
+		Build(cp.lastProgress, resumed)
+}
+
+// checkpointWriter appends completed tests to a checkpoint file.  A nil checkpointWriter records nothing.
+type checkpointWriter struct {
+	lock sync.Mutex
+	file *os.File
+}
+
+// newCheckpointWriter starts a new checkpoint in dir for run, including the tests already completed by an
+// earlier attempt so that the run can be resumed from dir again.
+func newCheckpointWriter(dir string, run checkpointRun, completed []*testCase) (*checkpointWriter, error) {
+	filename := filepath.Join(dir, checkpointFilename)
+	// write to a new file first, dir may hold the checkpoint being resumed from
+	tmp, err := os.CreateTemp(dir, checkpointFilename)
+	if err != nil {
+		return nil, fmt.Errorf("could not create checkpoint: %w", err)
+	}
+	w := &checkpointWriter{file: tmp}
+	records := []*checkpointRecord{{Run: &run}}
+	for _, test := range completed {
+		records = append(records, &checkpointRecord{Test: newCheckpointTest(test)})
+	}
+	for _, record := range records {
+		if err = w.write(record); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filename)
+	}
+	if err != nil {
+		w.Close()
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("could not create checkpoint: %w", err)
+	}
+	return w, nil
+}
+
+// recordTest appends the result of test.  Failures are logged, they must not fail the run.
+func (w *checkpointWriter) recordTest(test *testCase) {
+	if w == nil {
+		return
+	}
+	if err := w.write(&checkpointRecord{Test: newCheckpointTest(test)}); err != nil {
+		logrus.WithError(err).Warningf("Failed to checkpoint %q", test.name)
+	}
+}
+
+func (w *checkpointWriter) write(record *checkpointRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if _, err := w.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	// the point of a checkpoint is to survive the process being killed
+	return w.file.Sync()
+}
+
+func (w *checkpointWriter) Close() error {
+	if w == nil {
+		return nil
+	}
+	return w.file.Close()
+}
+
+func newCheckpointTest(test *testCase) *checkpointTest {
+	return &checkpointTest{
+		Name:                test.name,
+		State:               testState(test),
+		Start:               test.start,
+		End:                 test.end,
+		Output:              test.testOutputBytes,
+		ExtensionTestResult: test.extensionTestResult,
+	}
+}
+
+// testState is the inverse of mutateTestCaseWithResults.
+func testState(test *testCase) TestState {
+	switch {
+	case test.flake:
+		return TestFlaked
+	case test.success:
+		return TestSucceeded
+	case test.skipped:
+		return TestSkipped
+	case test.timedOut:
+		return TestFailedTimeout
+	case test.failed:
+		return TestFailed
+	}
+	return TestUnknown
+}
@@ -0,0 +1,113 @@
+package ginkgo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/test/extensions"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	dir := t.TempDir()
+	monitorStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	resumed := &testCase{name: "resumed", start: monitorStart, end: monitorStart.Add(time.Minute), success: true}
+	w, err := newCheckpointWriter(dir, checkpointRun{Suite: "suite", RandomSeed: 42, MonitorStartTime: monitorStart, Start: monitorStart}, []*testCase{resumed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := &testCase{
+		name:            "failed",
+		start:           monitorStart.Add(time.Minute),
+		end:             monitorStart.Add(2 * time.Minute),
+		failed:          true,
+		testOutputBytes: []byte("boom"),
+		extensionTestResult: &extensions.ExtensionTestResult{
+			Name:   "failed",
+			Result: extensions.ResultFailed,
+			Output: "boom",
+		},
+	}
+	w.recordTest(failed)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the process was killed while appending the next test
+	f, err := os.OpenFile(filepath.Join(dir, checkpointFilename), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"test":{"name":"trunc`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	cp, err := loadCheckpoint(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Suite != "suite" || cp.RandomSeed != 42 || !cp.MonitorStartTime.Equal(monitorStart) {
+		t.Errorf("unexpected run %#v", cp.checkpointRun)
+	}
+
+	completed, remaining := cp.resume([]*testCase{{name: "resumed"}, {name: "failed"}, {name: "truncated"}})
+	if expected := []string{"resumed", "failed"}; !reflect.DeepEqual(expected, testNames(completed)) {
+		t.Errorf("expected completed %v, got %v", expected, testNames(completed))
+	}
+	if expected := []string{"truncated"}; !reflect.DeepEqual(expected, testNames(remaining)) {
+		t.Errorf("expected remaining %v, got %v", expected, testNames(remaining))
+	}
+	if !completed[0].success || completed[0].failed {
+		t.Errorf("expected resumed test to succeed, got %#v", completed[0])
+	}
+	if !completed[1].failed || string(completed[1].testOutputBytes) != "boom" || !completed[1].end.Equal(failed.end) {
+		t.Errorf("expected failed test to keep its result, got %#v", completed[1])
+	}
+	if completed[1].extensionTestResult == nil || completed[1].extensionTestResult.Result != extensions.ResultFailed {
+		t.Errorf("expected failed test to keep its extension result, got %#v", completed[1].extensionTestResult)
+	}
+
+	interruption := cp.interruption(failed.end.Add(time.Hour))
+	if !interruption.From.Equal(failed.end) || !interruption.To.Equal(failed.end.Add(time.Hour)) {
+		t.Errorf("expected the interruption to start after the last completed test, got %v to %v", interruption.From, interruption.To)
+	}
+}
+
+func TestCheckpointResumeKeepsFirstAttempt(t *testing.T) {
+	dir := t.TempDir()
+	monitorStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	w, err := newCheckpointWriter(dir, checkpointRun{Suite: "suite", MonitorStartTime: monitorStart, Start: monitorStart}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := &testCase{name: "flaky", start: monitorStart, end: monitorStart.Add(time.Minute), failed: true, testOutputBytes: []byte("boom")}
+	w.recordTest(failed)
+	retry := failed.Retry()
+	retry.start, retry.end, retry.success = failed.end, failed.end.Add(time.Minute), true
+	w.recordTest(retry)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cp, err := loadCheckpoint(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	completed, remaining := cp.resume([]*testCase{{name: "flaky"}})
+	if len(completed) != 1 || len(remaining) != 0 {
+		t.Fatalf("expected the test to be completed, got completed %v and remaining %v", testNames(completed), testNames(remaining))
+	}
+	if !completed[0].failed || completed[0].success || !completed[0].end.Equal(failed.end) {
+		t.Errorf("expected the failed first attempt to be replayed, got %#v", completed[0])
+	}
+
+	// the replayed failure is retried by the resumed run, which tells the flake from a failure
+	if retries := testsToRetry(completed, 1); len(retries) != 1 || retries[0].name != "flaky" {
+		t.Errorf("expected the replayed failure to be retried, got %v", testNames(retries))
+	}
+}
@@ -23,14 +23,21 @@ import (
 	"github.com/openshift/origin/pkg/clioptions/clusterinfo"
 	"github.com/openshift/origin/pkg/defaultmonitortests"
 	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/monitor/intervalserver"
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
 	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
 	"github.com/openshift/origin/pkg/monitortestframework"
+	"github.com/openshift/origin/pkg/monitortestlibrary/chaos"
+	"github.com/openshift/origin/pkg/monitortestlibrary/disruptionpolicy"
+	"github.com/openshift/origin/pkg/monitortestlibrary/inclusterdisruption"
+	"github.com/openshift/origin/pkg/monitortests/testframework/failurecorrelation"
 	"github.com/openshift/origin/pkg/riskanalysis"
 	"github.com/openshift/origin/pkg/test/extensions"
 	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 	"golang.org/x/mod/semver"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/discovery"
@@ -56,6 +63,11 @@ type GinkgoRunSuiteOptions struct {
 	Timeout     time.Duration
 	JUnitDir    string
 
+	// MaxParallelMemory, if set, limits the sum of the memory declared by tests running in parallel.
+	MaxParallelMemory string
+	// ExtensionBatchSize is the largest number of extension tests run by a single process.
+	ExtensionBatchSize int
+
 	// SyntheticEventTests allows the caller to translate events or outside
 	// context into a failure.
 	SyntheticEventTests JUnitsForEvents
@@ -74,6 +86,38 @@ type GinkgoRunSuiteOptions struct {
 
 	ExactMonitorTests   []string
 	DisableMonitorTests []string
+	// MonitorPlugins are monitor test plugin binaries, see the monitortestframework/plugin package.
+	MonitorPlugins []string
+	// MonitorPhaseTimeouts are phase=duration pairs limiting how long a monitor test may take for a phase.
+	MonitorPhaseTimeouts map[string]string
+
+	// ChaosSchedule is a file of faults to inject during a Disruptive run.
+	ChaosSchedule string
+
+	// DisruptionBackends is a file of backend specs to sample from inside the cluster.
+	DisruptionBackends string
+
+	// DisruptionPolicy is a file of disruption budgets the availability tests apply before historical data.
+	DisruptionPolicy string
+
+	// MonitorRecorderDir, if set, persists monitor intervals and resources as they are recorded so
+	// they survive the process being killed.
+	MonitorRecorderDir string
+
+	// MonitorListenAddress, if set, serves the monitor intervals on this address while the suite runs.
+	MonitorListenAddress string
+
+	// ResumeFrom, if set, is the junit dir of an interrupted run whose completed tests are not run again.
+	ResumeFrom string
+
+	// Shard, if set, runs only one part of the suite, in the form i/N.
+	Shard string
+	// ShardDurations are the results of prior runs used to balance shards.
+	ShardDurations []string
+
+	// Extensions, if set, were extracted to select the suite and are used instead of extracting them again.
+	// Run cleans them up.
+	Extensions *ExtensionBinaries
 }
 
 func NewGinkgoRunSuiteOptions(streams genericclioptions.IOStreams) *GinkgoRunSuiteOptions {
@@ -95,9 +139,21 @@ func (o *GinkgoRunSuiteOptions) BindFlags(flags *pflag.FlagSet) {
 	flags.DurationVar(&o.Timeout, "timeout", o.Timeout, "Set the maximum time a test can run before being aborted. This is read from the suite by default, but will be 10 minutes otherwise.")
 	flags.BoolVar(&o.IncludeSuccessOutput, "include-success", o.IncludeSuccessOutput, "Print output from successful tests.")
 	flags.IntVar(&o.Parallelism, "max-parallel-tests", o.Parallelism, "Maximum number of tests running in parallel. 0 defaults to test suite recommended value, which is different in each suite.")
+	flags.StringVar(&o.MaxParallelMemory, "max-parallel-memory", o.MaxParallelMemory, "Maximum sum of the memory declared by tests running in parallel, for instance 16Gi.  Tests that do not declare memory are only limited by --max-parallel-tests.")
+	flags.IntVar(&o.ExtensionBatchSize, "extension-batch-size", o.ExtensionBatchSize, "Maximum number of extension tests run by a single process.  Retries and tests that declare isolation always run on their own.  Values below 2 run every test in its own process.")
 	flags.StringSliceVar(&o.ExactMonitorTests, "monitor", o.ExactMonitorTests,
 		fmt.Sprintf("list of exactly which monitors to enable. All others will be disabled.  Current monitors are: [%s]", strings.Join(monitorNames, ", ")))
 	flags.StringSliceVar(&o.DisableMonitorTests, "disable-monitor", o.DisableMonitorTests, "list of monitors to disable.  Defaults for others will be honored.")
+	flags.StringToStringVar(&o.MonitorPhaseTimeouts, "monitor-phase-timeout", o.MonitorPhaseTimeouts, "How long a single monitor test may take for a phase, for instance CollectData=90m, before it fails that phase and is abandoned.  Phases that are not given have no timeout.")
+	flags.StringSliceVar(&o.MonitorPlugins, "monitor-plugin", o.MonitorPlugins, "Monitor test plugin binaries to run in addition to the built-in monitors, either a local path or <image tag>:<path> to extract the binary from the release payload.")
+	flags.StringVar(&o.ChaosSchedule, "chaos-schedule", o.ChaosSchedule, fmt.Sprintf("A file of faults to inject while the suite runs, for instance node reboots or dropped traffic, each recorded as a Chaos interval.  Only Disruptive suites inject faults, and only in clusters whose ClusterVersion is labelled %s=true.", chaos.AllowedLabel))
+	flags.StringVar(&o.DisruptionBackends, "disruption-backends", o.DisruptionBackends, "A file of backend specs to sample for disruption from pods inside the cluster, in addition to the built-in disruption checks.  Also samples the cluster DNS from every node.")
+	flags.StringVar(&o.DisruptionPolicy, "disruption-policy", o.DisruptionPolicy, "A file of disruption budgets for backends, applied by the disruption tests instead of or in addition to historical data, see the evaluate-disruption-policy dev command.")
+	flags.StringVar(&o.MonitorRecorderDir, "monitor-recorder-dir", o.MonitorRecorderDir, "If set, monitor intervals and resources are persisted to this directory as they are recorded, so they survive the process being killed.  Existing content is loaded on start.")
+	flags.StringVar(&o.MonitorListenAddress, "monitor-listen-address", o.MonitorListenAddress, "If set, for instance to localhost:8080, the intervals recorded by the monitor are served on this address while the suite runs, including a server-sent-events stream of intervals as they are recorded.")
+	flags.StringVar(&o.Shard, "shard", o.Shard, "Run only the i-th of N parts of the suite, in the form i/N.  Every shard must select the same tests, parts are balanced by the expected duration of the tests.  Serial, early and late tests all run in shard 1.")
+	flags.StringSliceVar(&o.ShardDurations, "shard-durations", o.ShardDurations, "Directories holding the results of prior runs, or test-durations.json files written by merge-results, to balance shards by.  Tests without a recorded duration use the duration they declare.")
+	flags.StringVar(&o.ResumeFrom, "resume-from", o.ResumeFrom, "The --junit-dir of an interrupted run of the same suite.  Tests that completed in that run are not run again, and their results are included in the reports of this run.")
 }
 
 func (o *GinkgoRunSuiteOptions) Validate() error {
@@ -106,9 +162,44 @@ func (o *GinkgoRunSuiteOptions) Validate() error {
 	default:
 		return fmt.Errorf("unknown --cluster-stability, %q, expected Stable or Disruptive", o.ClusterStabilityDuringTest)
 	}
+	if len(o.Shard) > 0 {
+		if _, err := ParseShard(o.Shard); err != nil {
+			return fmt.Errorf("invalid --shard: %w", err)
+		}
+	} else if len(o.ShardDurations) > 0 {
+		return fmt.Errorf("--shard-durations requires --shard")
+	}
+	if _, err := monitortestframework.ParsePhaseTimeouts(o.MonitorPhaseTimeouts); err != nil {
+		return fmt.Errorf("invalid --monitor-phase-timeout: %w", err)
+	}
+	if len(o.ChaosSchedule) > 0 {
+		if _, err := chaos.ReadSchedule(o.ChaosSchedule); err != nil {
+			return fmt.Errorf("invalid --chaos-schedule: %w", err)
+		}
+	}
+	if len(o.DisruptionBackends) > 0 {
+		if _, err := inclusterdisruption.ReadBackendSpecs(o.DisruptionBackends); err != nil {
+			return fmt.Errorf("invalid --disruption-backends: %w", err)
+		}
+	}
+	if _, err := o.ReadDisruptionPolicy(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// ReadDisruptionPolicy returns the policy of --disruption-policy, or nil if it is not set.
+func (o *GinkgoRunSuiteOptions) ReadDisruptionPolicy() (*disruptionpolicy.Policy, error) {
+	if len(o.DisruptionPolicy) == 0 {
+		return nil, nil
+	}
+	policy, err := disruptionpolicy.ReadPolicy(o.DisruptionPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --disruption-policy: %w", err)
+	}
+	return policy, nil
+}
+
 func (o *GinkgoRunSuiteOptions) AsEnv() []string {
 	var args []string
 	args = append(args, fmt.Sprintf("TEST_SUITE_START_TIME=%d", o.StartTime.Unix()))
@@ -141,29 +232,21 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 	var fallbackSyntheticTestResult []*junitapi.JUnitTestCase
 	var externalTestCases []*testCase
 	if len(os.Getenv("OPENSHIFT_SKIP_EXTERNAL_TESTS")) == 0 {
-		// Extract all test binaries
-		extractionContext, extractionContextCancel := context.WithTimeout(context.Background(), 30*time.Minute)
-		defer extractionContextCancel()
-		cleanUpFn, externalBinaries, err := extensions.ExtractAllTestBinaries(extractionContext, 10)
-		if err != nil {
-			return err
+		extensionBinaries := o.Extensions
+		if extensionBinaries == nil {
+			extensionBinaries, err = ExtractExtensionBinaries()
+			if err != nil {
+				return err
+			}
+			// suites selected without the extensions, like the upgrade suites, still get the qualifiers they advertise
+			suite.AddExtensionQualifiers(extensionBinaries.Info)
 		}
-		defer cleanUpFn()
-
+		defer extensionBinaries.CleanUp()
+		externalBinaries := extensionBinaries.Binaries
 		defaultBinaryParallelism := 10
 
-		// Learn about the extension binaries available
-		// TODO(stbenjam): we'll eventually use this information to get suite information -- but not yet in this iteration
-		infoContext, infoContextCancel := context.WithTimeout(context.Background(), 30*time.Minute)
-		defer infoContextCancel()
-		extensionsInfo, err := externalBinaries.Info(infoContext, defaultBinaryParallelism)
-		if err != nil {
-			return err
-		}
-		logrus.Infof("Discovered %d extensions", len(extensionsInfo))
-		for _, e := range extensionsInfo {
-			id := fmt.Sprintf("%s:%s:%s", e.Component.Product, e.Component.Kind, e.Component.Name)
-			logrus.Infof("Extension %s found in %s:%s using API version %s", id, e.Source.SourceImage, e.Source.SourceBinary, e.APIVersion)
+		if len(suite.Qualifiers) > 0 {
+			logrus.WithField("suite", suite.Name).Infof("Extensions qualify tests for this suite with: %s", strings.Join(suite.Qualifiers, " || "))
 		}
 
 		// List tests from all available binaries and convert them to origin's testCase format
@@ -201,25 +284,75 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		logrus.Infof("Using built-in tests only due to OPENSHIFT_SKIP_EXTERNAL_TESTS being set")
 	}
 
+	var cp *checkpoint
+	if len(o.ResumeFrom) > 0 {
+		if cp, err = loadCheckpoint(o.ResumeFrom); err != nil {
+			return err
+		}
+		if cp.Suite != suite.Name {
+			return fmt.Errorf("--resume-from %s is a run of suite %q, not %q", o.ResumeFrom, cp.Suite, suite.Name)
+		}
+		logrus.Infof("Resuming the run of suite %q started at %s, %d tests had completed", cp.Suite, cp.MonitorStartTime.Format(time.RFC3339), len(cp.tests))
+	}
+
 	// this ensures the tests are always run in random order to avoid
 	// any intra-tests dependencies
 	suiteConfig, _ := ginkgo.GinkgoConfiguration()
-	r := rand.New(rand.NewSource(suiteConfig.RandomSeed))
+	seed := suiteConfig.RandomSeed
+	if cp != nil {
+		// keep the order of the interrupted run
+		seed = cp.RandomSeed
+	}
+	r := rand.New(rand.NewSource(seed))
 	r.Shuffle(len(tests), func(i, j int) { tests[i], tests[j] = tests[j], tests[i] })
 
-	tests = suite.Filter(tests)
+	tests, err = suite.Filter(tests)
+	if err != nil {
+		return err
+	}
 	if len(tests) == 0 {
 		return fmt.Errorf("suite %q does not contain any tests", suite.Name)
 	}
 
 	logrus.Infof("Found %d filtered tests", len(tests))
 
+	if len(o.Shard) > 0 {
+		shard, err := ParseShard(o.Shard)
+		if err != nil {
+			return fmt.Errorf("invalid --shard: %w", err)
+		}
+		durations, err := LoadTestDurations(o.ShardDurations...)
+		if err != nil {
+			return err
+		}
+		tests = shardTests(tests, shard, durations)
+		logrus.Infof("Running %d tests in shard %s, %d have a recorded duration", len(tests), shard, durations.countRecorded(tests))
+		if len(tests) == 0 {
+			// small suites leave some shards without tests, which is not a failure of the shard
+			logrus.Infof("Shard %s of suite %q does not contain any tests", shard, suite.Name)
+			if len(o.JUnitDir) == 0 || o.DryRun || o.PrintCommands {
+				return nil
+			}
+			return writeEmptyShardResults(junitSuiteName, o.JUnitDir, o.ErrOut)
+		}
+	}
+
 	count := o.Count
 	if count == 0 {
 		count = suite.Count
 	}
 
 	start := time.Now()
+	var resumedTests []*testCase
+	if cp != nil {
+		if count < 0 || count > 1 {
+			return fmt.Errorf("--resume-from cannot be used when each test runs more than once")
+		}
+		resumedTests, tests = cp.resume(tests)
+		logrus.Infof("Skipping %d tests completed by the interrupted run, %d tests remain", len(resumedTests), len(tests))
+		// report intervals from the start of the interrupted run
+		start = cp.MonitorStartTime
+	}
 	if o.StartTime.IsZero() {
 		o.StartTime = start
 	}
@@ -234,8 +367,17 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 
 	testRunnerContext := newCommandContext(o.AsEnv(), timeout)
 
+	var maxParallelMemory int64
+	if len(o.MaxParallelMemory) > 0 {
+		quantity, err := resource.ParseQuantity(o.MaxParallelMemory)
+		if err != nil {
+			return fmt.Errorf("invalid --max-parallel-memory %q: %w", o.MaxParallelMemory, err)
+		}
+		maxParallelMemory = quantity.Value()
+	}
+
 	if o.PrintCommands {
-		newParallelTestQueue(testRunnerContext).OutputCommands(ctx, tests, o.Out)
+		newParallelTestQueue(testRunnerContext, maxParallelMemory, o.ExtensionBatchSize).OutputCommands(ctx, tests, o.Out)
 		return nil
 	}
 	if o.DryRun {
@@ -267,6 +409,20 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		}
 	}
 
+	var checkpointer *checkpointWriter
+	if len(o.JUnitDir) > 0 {
+		checkpointer, err = newCheckpointWriter(o.JUnitDir, checkpointRun{
+			Suite:            suite.Name,
+			RandomSeed:       seed,
+			MonitorStartTime: start,
+			Start:            time.Now(),
+		}, resumedTests)
+		if err != nil {
+			return err
+		}
+		defer checkpointer.Close()
+	}
+
 	parallelism := o.Parallelism
 	if parallelism == 0 {
 		parallelism = suite.Parallelism
@@ -304,7 +460,27 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		logrus.Errorf("Error getting monitor tests: %v", err)
 	}
 
-	monitorEventRecorder := monitor.NewRecorder()
+	var monitorEventRecorder monitorapi.Recorder = monitor.NewRecorder()
+	if len(o.MonitorRecorderDir) > 0 {
+		durableRecorder, err := monitor.NewDurableRecorder(ctx, o.MonitorRecorderDir)
+		if err != nil {
+			return err
+		}
+		defer durableRecorder.Close()
+		logrus.Infof("Persisting monitor data to %s, loaded %d existing intervals", o.MonitorRecorderDir, len(durableRecorder.Intervals(time.Time{}, time.Time{})))
+		monitorEventRecorder = durableRecorder
+	}
+	if len(o.MonitorListenAddress) > 0 {
+		streamingRecorder := monitor.WrapWithStreamingRecorder(monitorEventRecorder)
+		server := intervalserver.NewServer(streamingRecorder)
+		address, err := server.Start(o.MonitorListenAddress)
+		if err != nil {
+			return err
+		}
+		defer server.Shutdown(context.Background())
+		logrus.Infof("Serving monitor intervals on http://%s/api/v1/intervals", address)
+		monitorEventRecorder = streamingRecorder
+	}
 	m := monitor.NewMonitor(
 		monitorEventRecorder,
 		restConfig,
@@ -314,6 +490,9 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 	if err := m.Start(ctx); err != nil {
 		return err
 	}
+	if cp != nil {
+		monitorEventRecorder.AddIntervals(cp.interruption(time.Now()))
+	}
 
 	pc, err := SetupNewPodCollector(ctx)
 	if err != nil {
@@ -329,7 +508,7 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		includeSuccess = true
 	}
 	testOutputLock := &sync.Mutex{}
-	testOutputConfig := newTestOutputConfig(testOutputLock, o.Out, monitorEventRecorder, includeSuccess)
+	testOutputConfig := newTestOutputConfig(testOutputLock, o.Out, monitorEventRecorder, checkpointer, includeSuccess)
 
 	early, notEarly := splitTests(tests, func(t *testCase) bool {
 		return strings.Contains(t.name, "[Early]")
@@ -377,7 +556,7 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 	tests = nil
 
 	// run our Early tests
-	q := newParallelTestQueue(testRunnerContext)
+	q := newParallelTestQueue(testRunnerContext, maxParallelMemory, o.ExtensionBatchSize)
 	q.Execute(testCtx, early, parallelism, testOutputConfig, abortFn)
 	tests = append(tests, early...)
 
@@ -430,6 +609,9 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		}
 	}
 
+	// tests completed by the interrupted run are reported as if they ran in this one
+	tests = append(tests, resumedTests...)
+
 	// calculate the effective test set we ran, excluding any incompletes
 	tests, _ = splitTests(tests, func(t *testCase) bool { return t.success || t.flake || t.failed || t.skipped })
 
@@ -439,26 +621,18 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		duration = duration.Round(time.Second)
 	}
 
-	pass, fail, skip, failing := summarizeTests(tests)
+	// informing tests are reported like every other test, but their failures never fail the job.  They are
+	// counted separately and are not retried, so they cannot use up the flakes allowed by the suite.
+	informingTests, blockingTests := splitTests(tests, isInformingTest)
+	pass, fail, skip, failing := summarizeTests(blockingTests)
+	informingPass, informingFail, informingSkip, informingFailing := summarizeTests(informingTests)
 
 	// attempt to retry failures to do flake detection
-	if fail > 0 && fail <= suite.MaximumAllowedFlakes {
-		var retries []*testCase
-
-		// Make a copy of the all failing tests (subject to the max allowed flakes) so we can have
-		// a list of tests to retry.
-		for _, test := range failing {
-			retry := test.Retry()
-			retries = append(retries, retry)
-			if len(retries) > suite.MaximumAllowedFlakes {
-				break
-			}
-		}
-
+	if retries := testsToRetry(tests, suite.MaximumAllowedFlakes); len(retries) > 0 {
 		logrus.Warningf("Retry count: %d", len(retries))
 
 		// Run the tests in the retries list.
-		q := newParallelTestQueue(testRunnerContext)
+		q := newParallelTestQueue(testRunnerContext, maxParallelMemory, o.ExtensionBatchSize)
 		q.Execute(testCtx, retries, parallelism, testOutputConfig, abortFn)
 
 		var flaky, skipped []string
@@ -524,6 +698,21 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		fmt.Fprintf(o.ErrOut, "error: Failed to serialize run-data: %v\n", err)
 	}
 
+	// the failure correlation monitor test reports the cluster events concurrent with every failed test run
+	if len(o.JUnitDir) > 0 {
+		failureCorrelation, err := failurecorrelation.ReadReport(o.JUnitDir, timeSuffix)
+		if err != nil {
+			fmt.Fprintf(o.ErrOut, "error: Failed to read failure correlation: %v\n", err)
+		}
+		if failureCorrelation != nil {
+			for _, test := range tests {
+				if test.failed || test.flake {
+					test.failureAnnotation = failureCorrelation.AnnotationFor(test.name, test.start, test.end)
+				}
+			}
+		}
+	}
+
 	// default is empty string as that is what entries prior to adding this will have
 	wasMasterNodeUpdated := ""
 	if events := monitorEventRecorder.Intervals(start, end); len(events) > 0 {
@@ -578,6 +767,10 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 	}
 
 	// report the outcome of the test
+	if len(informingFailing) > 0 {
+		names := sets.NewString(testNames(informingFailing)...).List()
+		fmt.Fprintf(o.Out, "Informing failures, these do not fail the job:\n\n%s\n\n", strings.Join(names, "\n"))
+	}
 	if len(failing) > 0 {
 		names := sets.NewString(testNames(failing)...).List()
 		fmt.Fprintf(o.Out, "Failing tests:\n\n%s\n\n", strings.Join(names, "\n"))
@@ -593,7 +786,7 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 			fmt.Fprintf(o.Out, "error: Unable to write e2e Extension Test Result JSON results: %v", err)
 		}
 
-		if err := riskanalysis.WriteJobRunTestFailureSummary(o.JUnitDir, timeSuffix, finalSuiteResults, wasMasterNodeUpdated, ""); err != nil {
+		if err := riskanalysis.WriteJobRunTestFailureSummary(o.JUnitDir, timeSuffix, finalSuiteResults, wasMasterNodeUpdated, "", sets.NewString(testNames(informingTests)...)); err != nil {
 			fmt.Fprintf(o.Out, "error: Unable to write e2e job run failures summary: %v", err)
 		}
 	}
@@ -612,10 +805,32 @@ func (o *GinkgoRunSuiteOptions) Run(suite *TestSuite, junitSuiteName string, mon
 		return fmt.Errorf("failed due to a MonitorTest failure")
 	}
 
+	if len(informingTests) > 0 {
+		fmt.Fprintf(o.Out, "%d informing fail, %d informing pass, %d informing skip\n", informingFail, informingPass, informingSkip)
+	}
 	fmt.Fprintf(o.Out, "%d pass, %d skip (%s)\n", pass, skip, duration)
 	return ctx.Err()
 }
 
+// testsToRetry returns a copy of the failing tests to run again for flake detection, none if more tests failed than
+// the suite allows flakes.  Informing tests are never retried.
+func testsToRetry(tests []*testCase, maximumAllowedFlakes int) []*testCase {
+	_, blockingTests := splitTests(tests, isInformingTest)
+	_, fail, _, failing := summarizeTests(blockingTests)
+	if fail == 0 || fail > maximumAllowedFlakes {
+		return nil
+	}
+
+	var retries []*testCase
+	for _, test := range failing {
+		retries = append(retries, test.Retry())
+		if len(retries) > maximumAllowedFlakes {
+			break
+		}
+	}
+	return retries
+}
+
 func writeExtensionTestResults(tests []*testCase, dir, filePrefix, fileSuffix string, out io.Writer) error {
 	// Ensure the directory exists
 	err := os.MkdirAll(dir, 0755)
@@ -694,6 +909,53 @@ outerLoop:
 	return matches, nil
 }
 
+// ExtensionBinaries are the extension test binaries extracted from the release payload and the info they report,
+// including the suites they advertise.
+type ExtensionBinaries struct {
+	Binaries extensions.TestBinaries
+	Info     []*extensions.ExtensionInfo
+
+	cleanUpFn func()
+}
+
+// ExtractExtensionBinaries extracts the extension test binaries from the release payload and fetches their info.
+func ExtractExtensionBinaries() (*ExtensionBinaries, error) {
+	extractionContext, extractionContextCancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer extractionContextCancel()
+	cleanUpFn, externalBinaries, err := extensions.ExtractAllTestBinaries(extractionContext, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	// Learn about the extension binaries available and the suites they advertise
+	infoContext, infoContextCancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer infoContextCancel()
+	extensionsInfo, err := externalBinaries.Info(infoContext, 10)
+	if err != nil {
+		cleanUpFn()
+		return nil, err
+	}
+	logrus.Infof("Discovered %d extensions", len(extensionsInfo))
+	for _, e := range extensionsInfo {
+		id := fmt.Sprintf("%s:%s:%s", e.Component.Product, e.Component.Kind, e.Component.Name)
+		logrus.Infof("Extension %s found in %s:%s using API version %s", id, e.Source.SourceImage, e.Source.SourceBinary, e.APIVersion)
+	}
+
+	return &ExtensionBinaries{
+		Binaries:  externalBinaries,
+		Info:      extensionsInfo,
+		cleanUpFn: cleanUpFn,
+	}, nil
+}
+
+// CleanUp removes the extracted binaries.  It is safe to call on nil.
+func (e *ExtensionBinaries) CleanUp() {
+	if e == nil || e.cleanUpFn == nil {
+		return
+	}
+	e.cleanUpFn()
+}
+
 func determineEnvironmentFlags(upgrade bool, dryRun bool) (extensions.EnvironmentFlags, error) {
 	clientConfig, err := e2e.LoadConfig(true)
 	if err != nil {
@@ -52,7 +52,7 @@ func generateJUnitTestSuiteResults(
 				SystemOut: string(test.testOutputBytes),
 				Duration:  test.duration.Seconds(),
 				FailureOutput: &junitapi.FailureOutput{
-					Output: lastLinesUntil(string(test.testOutputBytes), 100, "fail ["),
+					Output: withFailureAnnotation(test, lastLinesUntil(string(test.testOutputBytes), 100, "fail [")),
 				},
 			})
 		case test.flake:
@@ -63,7 +63,7 @@ func generateJUnitTestSuiteResults(
 				SystemOut: string(test.testOutputBytes),
 				Duration:  test.duration.Seconds(),
 				FailureOutput: &junitapi.FailureOutput{
-					Output: lastLinesUntil(string(test.testOutputBytes), 100, "flake:"),
+					Output: withFailureAnnotation(test, lastLinesUntil(string(test.testOutputBytes), 100, "flake:")),
 				},
 			})
 
@@ -136,3 +136,11 @@ func stringStartsWithAny(s string, contains []string) bool {
 	}
 	return false
 }
+
+// withFailureAnnotation appends the failure annotation of test, if it has one, to output.
+func withFailureAnnotation(test *testCase, output string) string {
+	if len(test.failureAnnotation) == 0 {
+		return output
+	}
+	return output + "\n\n" + test.failureAnnotation
+}
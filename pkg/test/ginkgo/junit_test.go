@@ -33,3 +33,17 @@ func Test_lastLines(t *testing.T) {
 		})
 	}
 }
+
+func Test_generateJUnitTestSuiteResultsFailureAnnotation(t *testing.T) {
+	tests := []*testCase{
+		{name: "annotated", failed: true, testOutputBytes: []byte("fail [it broke]"), failureAnnotation: "Concurrent cluster events"},
+		{name: "retried", failed: true, testOutputBytes: []byte("fail [it broke]")},
+	}
+	suite := generateJUnitTestSuiteResults("suite", 0, tests)
+	if got, want := suite.TestCases[0].FailureOutput.Output, "fail [it broke]\n\nConcurrent cluster events"; got != want {
+		t.Errorf("expected the annotation after the failure, got %q", got)
+	}
+	if got, want := suite.TestCases[1].FailureOutput.Output, "fail [it broke]"; got != want {
+		t.Errorf("expected a failure without annotation to be kept as is, got %q", got)
+	}
+}
@@ -0,0 +1,178 @@
+package ginkgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/riskanalysis"
+	"github.com/openshift/origin/pkg/test/extensions"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+// MergeResultsOptions merges the results written to the --junit-dir of each shard of a run into one report.
+type MergeResultsOptions struct {
+	// Dirs are the junit dirs of the shards.
+	Dirs []string
+	// OutputDir is where the merged results are written.
+	OutputDir string
+
+	genericclioptions.IOStreams
+}
+
+func (o *MergeResultsOptions) Run() error {
+	if err := os.MkdirAll(o.OutputDir, 0755); err != nil {
+		return fmt.Errorf("could not create output dir: %w", err)
+	}
+	timeSuffix := fmt.Sprintf("_%s", time.Now().UTC().Format("20060102-150405"))
+
+	suite, err := mergeJUnitReports(o.Dirs)
+	if err != nil {
+		return err
+	}
+	if suite != nil {
+		if err := writeJUnitReport(suite, "junit_e2e", timeSuffix, o.OutputDir, o.ErrOut); err != nil {
+			return err
+		}
+	}
+
+	results, err := mergeExtensionTestResults(o.Dirs)
+	if err != nil {
+		return err
+	}
+	if len(results) > 0 {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(o.OutputDir, fmt.Sprintf("extension_test_result_e2e_%s.json", timeSuffix)), data, 0644); err != nil {
+			return err
+		}
+	}
+
+	intervals, err := mergeIntervals(o.Dirs)
+	if err != nil {
+		return err
+	}
+	if len(intervals) > 0 {
+		filename := filepath.Join(o.OutputDir, fmt.Sprintf("events_used_for_junits%s.json", timeSuffix))
+		if err := monitorserialization.EventsToFile(filename, intervals); err != nil {
+			return err
+		}
+	}
+
+	merged, err := riskanalysis.MergeJobRunTestFailureSummaries(o.Dirs, o.OutputDir, timeSuffix)
+	if err != nil {
+		return fmt.Errorf("could not merge test failure summaries: %w", err)
+	}
+
+	durations, err := LoadTestDurations(o.Dirs...)
+	if err != nil {
+		return err
+	}
+	if err := writeTestDurations(durations, o.OutputDir); err != nil {
+		return err
+	}
+
+	if suite != nil {
+		fmt.Fprintf(o.Out, "Merged %d tests, %d failed, %d skipped\n", suite.NumTests, suite.NumFailed, suite.NumSkipped)
+	}
+	fmt.Fprintf(o.Out, "Merged %d extension test results, %d intervals, test failure summaries: %t\n", len(results), len(intervals), merged)
+	return nil
+}
+
+// mergeJUnitReports combines the junit_e2e reports found in dirs.  Shards run in parallel, so the duration of
+// the merged suite is the longest of them.
+func mergeJUnitReports(dirs []string) (*junitapi.JUnitTestSuite, error) {
+	filenames, err := globAll(dirs, "junit_e2e_*.xml")
+	if err != nil {
+		return nil, err
+	}
+	var merged *junitapi.JUnitTestSuite
+	for _, filename := range filenames {
+		suite, err := readJUnitReport(filename)
+		if err != nil {
+			return nil, err
+		}
+		if merged == nil {
+			merged = &junitapi.JUnitTestSuite{Name: suite.Name, Properties: suite.Properties}
+		}
+		if suite.Name != merged.Name {
+			return nil, fmt.Errorf("cannot merge results of suite %q with %q from %s", merged.Name, suite.Name, filename)
+		}
+		merged.NumTests += suite.NumTests
+		merged.NumFailed += suite.NumFailed
+		merged.NumSkipped += suite.NumSkipped
+		merged.Duration = math.Max(merged.Duration, suite.Duration)
+		merged.TestCases = append(merged.TestCases, suite.TestCases...)
+		merged.Children = append(merged.Children, suite.Children...)
+	}
+	return merged, nil
+}
+
+func mergeExtensionTestResults(dirs []string) (extensions.ExtensionTestResults, error) {
+	filenames, err := globAll(dirs, "extension_test_result_e2e_*.json")
+	if err != nil {
+		return nil, err
+	}
+	var merged extensions.ExtensionTestResults
+	for _, filename := range filenames {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		var results extensions.ExtensionTestResults
+		if err := json.Unmarshal(data, &results); err != nil {
+			return nil, fmt.Errorf("invalid extension test results %s: %w", filename, err)
+		}
+		merged = append(merged, results...)
+	}
+	return merged, nil
+}
+
+// mergeIntervals combines the intervals the shards evaluated their monitor tests with.  Shards of a run
+// against the same cluster observe the same intervals, which are only kept once.
+func mergeIntervals(dirs []string) (monitorapi.Intervals, error) {
+	filenames, err := globAll(dirs, "events_used_for_junits_*.json")
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var merged monitorapi.Intervals
+	for _, filename := range filenames {
+		intervals, err := monitorserialization.EventsFromFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("invalid intervals %s: %w", filename, err)
+		}
+		for _, interval := range intervals {
+			key := fmt.Sprintf("%s %s %s %s", interval.Source, interval.From, interval.To, interval.String())
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, interval)
+		}
+	}
+	sort.Sort(merged)
+	return merged, nil
+}
+
+func globAll(dirs []string, pattern string) ([]string, error) {
+	var filenames []string
+	for _, dir := range dirs {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		filenames = append(filenames, matches...)
+	}
+	return filenames, nil
+}
@@ -0,0 +1,90 @@
+package ginkgo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/openshift/origin/pkg/monitor/monitorapi"
+	monitorserialization "github.com/openshift/origin/pkg/monitor/serialization"
+	"github.com/openshift/origin/pkg/test/extensions"
+)
+
+func TestMergeResults(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	shared := monitorapi.NewInterval(monitorapi.SourceE2ETest, monitorapi.Info).
+		Locator(monitorapi.NewLocator().E2ETestSuite("suite")).
+		Message(monitorapi.NewMessage().HumanMessage("seen by every shard")).
+		Build(start, start.Add(time.Minute))
+
+	var dirs []string
+	for i, name := range []string{"first", "second"} {
+		dir := t.TempDir()
+		dirs = append(dirs, dir)
+
+		test := &testCase{name: name, duration: time.Duration(i+1) * time.Minute, success: i == 0, failed: i != 0}
+		suite := generateJUnitTestSuiteResults("suite", test.duration, []*testCase{test})
+		if err := writeJUnitReport(suite, "junit_e2e", "_20240101-000000", dir, &bytes.Buffer{}); err != nil {
+			t.Fatal(err)
+		}
+		test.extensionTestResult = &extensions.ExtensionTestResult{Name: name, Result: extensions.ResultPassed}
+		if err := writeExtensionTestResults([]*testCase{test}, dir, "extension_test_result_e2e", "_20240101-000000", &bytes.Buffer{}); err != nil {
+			t.Fatal(err)
+		}
+		own := monitorapi.NewInterval(monitorapi.SourceE2ETest, monitorapi.Info).
+			Locator(monitorapi.NewLocator().E2ETestSuite("suite")).
+			Message(monitorapi.NewMessage().HumanMessage(name)).
+			Build(start.Add(time.Duration(i)*time.Second), start.Add(time.Minute))
+		if err := monitorserialization.EventsToFile(filepath.Join(dir, "events_used_for_junits_20240101-000000.json"), monitorapi.Intervals{shared, own}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output := t.TempDir()
+	o := &MergeResultsOptions{
+		Dirs:      dirs,
+		OutputDir: output,
+		IOStreams: genericclioptions.IOStreams{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}},
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	suite, err := mergeJUnitReports([]string{output})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suite.NumTests != 2 || suite.NumFailed != 1 || suite.Duration != (2*time.Minute).Seconds() {
+		t.Errorf("unexpected merged suite %d tests, %d failed, %fs", suite.NumTests, suite.NumFailed, suite.Duration)
+	}
+
+	results, err := mergeExtensionTestResults([]string{output})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Errorf("expected 2 extension test results, got %d", len(results))
+	}
+
+	intervals, err := mergeIntervals([]string{output})
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := sets.New[string]()
+	for _, interval := range intervals {
+		messages.Insert(interval.Message.HumanMessage)
+	}
+	if expected := []string{"first", "second", "seen by every shard"}; len(intervals) != 3 || !reflect.DeepEqual(expected, sets.List(messages)) {
+		t.Errorf("expected intervals %v, got %v", expected, intervals.Strings())
+	}
+
+	if _, err := os.Stat(filepath.Join(output, testDurationsFilename)); err != nil {
+		t.Errorf("expected a duration database to be written: %v", err)
+	}
+}
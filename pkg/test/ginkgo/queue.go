@@ -4,30 +4,39 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"sync"
 )
 
 // parallelByFileTestQueue runs tests in parallel unless they have
-// the `[Serial]` tag on their name or if another test with the
-// testExclusion field is currently running. Serial tests are
-// defered until all other tests are completed.
+// the `[Serial]` tag on their name or if another test with the same
+// testExclusion or isolation conflict is currently running. Serial
+// tests are defered until all other tests are completed.
 type parallelByFileTestQueue struct {
 	commandContext *commandContext
+
+	// maxParallelMemory, if positive, limits the sum of the declared memory of the tests running in parallel.
+	maxParallelMemory int64
+	// batchSize is the largest number of extension tests run by a single process.
+	batchSize int
 }
 
 type TestFunc func(ctx context.Context, test *testCase)
 
-func newParallelTestQueue(commandContext *commandContext) *parallelByFileTestQueue {
+func newParallelTestQueue(commandContext *commandContext, maxParallelMemory int64, batchSize int) *parallelByFileTestQueue {
 	return &parallelByFileTestQueue{
-		commandContext: commandContext,
+		commandContext:    commandContext,
+		maxParallelMemory: maxParallelMemory,
+		batchSize:         batchSize,
 	}
 }
 
 // OutputCommand prints to stdout what would have been executed.
 func (q *parallelByFileTestQueue) OutputCommands(ctx context.Context, tests []*testCase, out io.Writer) {
 	// for some reason we split the serial and parallel when printing the command
-	serial, parallel := splitTests(tests, func(t *testCase) bool { return strings.Contains(t.name, "[Serial]") })
+	serial, parallel := splitTests(tests, isSerialTest)
+	parallel = sortByExpectedDuration(parallel)
 
 	for _, curr := range parallel {
 		commandString := q.commandContext.commandString(curr)
@@ -54,38 +63,6 @@ func abortOnFailure(parentContext context.Context) (testAbortFunc, context.Conte
 	}, testCtx
 }
 
-// queueAllTests writes all the tests to the channel and closes it when all are finished
-// even with buffering, this can take a while since we don't infinitely buffer.
-func queueAllTests(remainingParallelTests chan *testCase, tests []*testCase) {
-	for i := range tests {
-		curr := tests[i]
-		remainingParallelTests <- curr
-	}
-
-	close(remainingParallelTests)
-}
-
-// runTestsUntilChannelEmpty reads from the channel to consume tests, run them, and return when the channel is closed.
-func runTestsUntilChannelEmpty(ctx context.Context, remainingParallelTests chan *testCase, testSuiteRunner testSuiteRunner) {
-	for {
-		select {
-		// if the context is finished, simply return
-		case <-ctx.Done():
-			return
-
-		case test, ok := <-remainingParallelTests:
-			if !ok { // channel closed, then we're done
-				return
-			}
-			// if the context is finished, simply return
-			if ctx.Err() != nil {
-				return
-			}
-			testSuiteRunner.RunOneTest(ctx, test)
-		}
-	}
-}
-
 // tests are currently being mutated during the run process.
 func (q *parallelByFileTestQueue) Execute(ctx context.Context, tests []*testCase, parallelism int, testOutput testOutputConfig, maybeAbortOnFailureFn testAbortFunc) {
 	testSuiteProgress := newTestSuiteProgress(len(tests))
@@ -96,26 +73,26 @@ func (q *parallelByFileTestQueue) Execute(ctx context.Context, tests []*testCase
 		maybeAbortOnFailureFn: maybeAbortOnFailureFn,
 	}
 
-	execute(ctx, testSuiteRunner, tests, parallelism)
+	execute(ctx, testSuiteRunner, tests, parallelism, q.maxParallelMemory, q.batchSize)
 }
 
 // execute is a convenience for unit testing
-func execute(ctx context.Context, testSuiteRunner testSuiteRunner, tests []*testCase, parallelism int) {
+func execute(ctx context.Context, testSuiteRunner testSuiteRunner, tests []*testCase, parallelism int, maxParallelMemory int64, batchSize int) {
 	if ctx.Err() != nil {
 		return
 	}
 
 	serial, parallel := splitTests(tests, isSerialTest)
 
-	remainingParallelTests := make(chan *testCase, 100)
-	go queueAllTests(remainingParallelTests, parallel)
+	scheduler := newTestScheduler(ctx, parallel, maxParallelMemory)
+	defer scheduler.stop()
 
 	var wg sync.WaitGroup
 	for i := 0; i < parallelism; i++ {
 		wg.Add(1)
 		go func(ctx context.Context) {
 			defer wg.Done()
-			runTestsUntilChannelEmpty(ctx, remainingParallelTests, testSuiteRunner)
+			runTestsUntilSchedulerEmpty(ctx, scheduler, testSuiteRunner, batchSize)
 		}(ctx)
 	}
 	wg.Wait()
@@ -128,6 +105,167 @@ func execute(ctx context.Context, testSuiteRunner testSuiteRunner, tests []*test
 	}
 }
 
+// runTestsUntilSchedulerEmpty takes tests from the scheduler, runs them, and returns when no tests remain.
+func runTestsUntilSchedulerEmpty(ctx context.Context, scheduler *testScheduler, testSuiteRunner testSuiteRunner, batchSize int) {
+	for {
+		tests := scheduler.next(ctx, batchSize)
+		// no tests left or the context is finished
+		if len(tests) == 0 {
+			return
+		}
+		if len(tests) == 1 {
+			testSuiteRunner.RunOneTest(ctx, tests[0])
+		} else {
+			testSuiteRunner.RunTestBatch(ctx, tests)
+		}
+		scheduler.done(tests...)
+	}
+}
+
+// testScheduler decides which parallel test runs next.  Tests with the longest expected duration are
+// handed out first.  A test is held back while another test sharing a testExclusion or an isolation
+// conflict is running, or while starting it would exceed the memory limit.  Held back tests do not
+// block the tests behind them.  Extension tests without isolation requirements may be handed out in
+// batches, to be run by a single process.
+type testScheduler struct {
+	lock sync.Mutex
+	cond *sync.Cond
+
+	pending []*testCase
+
+	runningCount     int
+	runningMemory    int64
+	runningConflicts map[string]int
+	memoryLimit      int64
+
+	stopWakeup func() bool
+}
+
+func newTestScheduler(ctx context.Context, tests []*testCase, memoryLimit int64) *testScheduler {
+	s := &testScheduler{
+		pending:          sortByExpectedDuration(tests),
+		runningConflicts: map[string]int{},
+		memoryLimit:      memoryLimit,
+	}
+	s.cond = sync.NewCond(&s.lock)
+	// waiting workers must notice the context finishing
+	s.stopWakeup = context.AfterFunc(ctx, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.cond.Broadcast()
+	})
+	return s
+}
+
+func (s *testScheduler) stop() {
+	s.stopWakeup()
+}
+
+// next blocks until a test may start and returns it, along with up to batchSize-1 more tests that can run
+// in the same process.  It returns nil when no tests remain or ctx is finished.
+func (s *testScheduler) next(ctx context.Context, batchSize int) []*testCase {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for {
+		if ctx.Err() != nil || len(s.pending) == 0 {
+			return nil
+		}
+		for i, test := range s.pending {
+			if !s.canStart(test) {
+				continue
+			}
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			s.start(test)
+			batch := []*testCase{test}
+			if !isBatchable(test) {
+				return batch
+			}
+
+			var remaining []*testCase
+			for _, candidate := range s.pending {
+				if len(batch) < batchSize && candidate.binary == test.binary && isBatchable(candidate) && s.canStart(candidate) {
+					s.start(candidate)
+					batch = append(batch, candidate)
+					continue
+				}
+				remaining = append(remaining, candidate)
+			}
+			s.pending = remaining
+			return batch
+		}
+		s.cond.Wait()
+	}
+}
+
+func (s *testScheduler) start(test *testCase) {
+	s.runningCount++
+	s.runningMemory += test.memory
+	for _, conflict := range testConflicts(test) {
+		s.runningConflicts[conflict]++
+	}
+}
+
+// done releases the resources held by tests.
+func (s *testScheduler) done(tests ...*testCase) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, test := range tests {
+		s.runningCount--
+		s.runningMemory -= test.memory
+		for _, conflict := range testConflicts(test) {
+			s.runningConflicts[conflict]--
+			if s.runningConflicts[conflict] == 0 {
+				delete(s.runningConflicts, conflict)
+			}
+		}
+	}
+	s.cond.Broadcast()
+}
+
+func (s *testScheduler) canStart(test *testCase) bool {
+	for _, conflict := range testConflicts(test) {
+		if s.runningConflicts[conflict] > 0 {
+			return false
+		}
+	}
+	// a test that needs more than the limit is allowed to run on its own instead of never running.
+	if s.memoryLimit > 0 && s.runningCount > 0 && s.runningMemory+test.memory > s.memoryLimit {
+		return false
+	}
+	return true
+}
+
+// isBatchable is true for extension tests that may share a process with other tests.  Retries run on their
+// own, so a failure is retried without the rest of its batch, as do tests that declare any isolation.
+func isBatchable(test *testCase) bool {
+	return test.binary != nil &&
+		test.previous == nil &&
+		len(test.testExclusion) == 0 &&
+		len(test.isolation.Mode) == 0 &&
+		len(test.isolation.Conflict) == 0
+}
+
+func testConflicts(test *testCase) []string {
+	conflicts := test.isolation.Conflict
+	if len(test.testExclusion) > 0 {
+		conflicts = append([]string{test.testExclusion}, conflicts...)
+	}
+	return conflicts
+}
+
+// sortByExpectedDuration returns the tests ordered longest expected duration first.  Tests without an
+// expected duration keep their relative order at the end.
+func sortByExpectedDuration(tests []*testCase) []*testCase {
+	sorted := make([]*testCase, len(tests))
+	copy(sorted, tests)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].expectedDuration > sorted[j].expectedDuration
+	})
+	return sorted
+}
+
 func isSerialTest(test *testCase) bool {
 	if strings.Contains(test.name, "[Serial]") {
 		return true
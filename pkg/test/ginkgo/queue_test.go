@@ -2,13 +2,18 @@ package ginkgo
 
 import (
 	"context"
+	"fmt"
 	"math/rand"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	_ "embed"
+
+	"github.com/openshift/origin/pkg/test/extensions"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 //go:embed testNames.txt
@@ -31,9 +36,25 @@ func makeTestCases() []*testCase {
 type testingSuiteRunner struct {
 	lock     sync.Mutex
 	testsRun []string
+
+	// running tracks the tests currently running so scheduling decisions can be verified.
+	running    []*testCase
+	maxRunning int
+	violations []string
+	// memoryOnStart and runningOnStart record, for every test started, the declared memory and the number of the
+	// tests running alongside it, itself included
+	memoryOnStart  map[string]int64
+	runningOnStart map[string]int
+	// started records the order tests were started in
+	started []string
+	// batches records the tests run by each RunTestBatch
+	batches [][]*testCase
 }
 
 func (r *testingSuiteRunner) RunOneTest(ctx context.Context, test *testCase) {
+	r.start(test)
+	defer r.finish(test)
+
 	var delay int64
 	delay = rand.Int63n(30)
 
@@ -44,6 +65,63 @@ func (r *testingSuiteRunner) RunOneTest(ctx context.Context, test *testCase) {
 	r.testsRun = append(r.testsRun, test.name)
 }
 
+func (r *testingSuiteRunner) RunTestBatch(ctx context.Context, tests []*testCase) {
+	for _, test := range tests {
+		r.start(test)
+		defer r.finish(test)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.batches = append(r.batches, tests)
+	for _, test := range tests {
+		r.testsRun = append(r.testsRun, test.name)
+	}
+}
+
+func (r *testingSuiteRunner) start(test *testCase) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.started = append(r.started, test.name)
+	for _, running := range r.running {
+		for _, conflict := range testConflicts(test) {
+			if sets.NewString(testConflicts(running)...).Has(conflict) {
+				r.violations = append(r.violations, fmt.Sprintf("%q and %q both hold %q", test.name, running.name, conflict))
+			}
+		}
+		if isSerialTest(test) || isSerialTest(running) {
+			r.violations = append(r.violations, fmt.Sprintf("%q and %q ran in parallel", test.name, running.name))
+		}
+	}
+	r.running = append(r.running, test)
+
+	if len(r.running) > r.maxRunning {
+		r.maxRunning = len(r.running)
+	}
+	var memory int64
+	for _, running := range r.running {
+		memory += running.memory
+	}
+	if r.memoryOnStart == nil {
+		r.memoryOnStart, r.runningOnStart = map[string]int64{}, map[string]int{}
+	}
+	r.memoryOnStart[test.name] = memory
+	r.runningOnStart[test.name] = len(r.running)
+}
+
+func (r *testingSuiteRunner) finish(test *testCase) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i := range r.running {
+		if r.running[i] == test {
+			r.running = append(r.running[:i], r.running[i+1:]...)
+			return
+		}
+	}
+}
+
 func (r *testingSuiteRunner) getTestsRun() []string {
 	r.lock.Lock()
 	defer r.lock.Unlock()
@@ -57,10 +135,172 @@ func Test_execute(t *testing.T) {
 	tests := makeTestCases()
 	testSuiteRunner := &testingSuiteRunner{}
 	parallelism := 30
-	execute(context.TODO(), testSuiteRunner, tests, parallelism)
+	execute(context.TODO(), testSuiteRunner, tests, parallelism, 0, 1)
 
 	testsCompleted := testSuiteRunner.getTestsRun()
 	if len(tests) != len(testsCompleted) {
 		t.Errorf("expected %v, got %v", len(tests), len(testsCompleted))
 	}
 }
+
+func Test_executeHonoursIsolation(t *testing.T) {
+	tests := []*testCase{
+		{name: "a", isolation: extensions.Isolation{Conflict: []string{"etcd"}}},
+		{name: "b", isolation: extensions.Isolation{Conflict: []string{"etcd", "ingress"}}},
+		{name: "c", isolation: extensions.Isolation{Conflict: []string{"ingress"}}},
+		{name: "d", testExclusion: "storage"},
+		{name: "e", testExclusion: "storage"},
+		{name: "f [Serial]"},
+		{name: "g [Serial]"},
+	}
+	for i := 0; i < 20; i++ {
+		tests = append(tests, &testCase{name: fmt.Sprintf("plain-%d", i)})
+	}
+
+	testSuiteRunner := &testingSuiteRunner{}
+	execute(context.TODO(), testSuiteRunner, tests, 10, 0, 1)
+
+	if len(testSuiteRunner.violations) > 0 {
+		t.Fatalf("isolation not honoured: %v", testSuiteRunner.violations)
+	}
+	if len(testSuiteRunner.started) != len(tests) {
+		t.Fatalf("expected %d tests, got %d", len(tests), len(testSuiteRunner.started))
+	}
+	// serial tests run at the end
+	if last := sets.NewString(testSuiteRunner.started[len(tests)-2:]...); !last.Equal(sets.NewString("f [Serial]", "g [Serial]")) {
+		t.Fatalf("expected serial tests last, got %v", testSuiteRunner.started)
+	}
+}
+
+func Test_executeHonoursMemory(t *testing.T) {
+	const limit = 3 << 30
+	tests := []*testCase{}
+	for i := 0; i < 20; i++ {
+		tests = append(tests, &testCase{name: fmt.Sprintf("small-%d", i), memory: 1 << 30})
+	}
+	for i := 0; i < 10; i++ {
+		tests = append(tests, &testCase{name: fmt.Sprintf("medium-%d", i), memory: 2 << 30})
+	}
+	// larger than the limit, must still run, alone
+	tests = append(tests, &testCase{name: "huge", memory: 8 << 30})
+
+	testSuiteRunner := &testingSuiteRunner{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		execute(context.TODO(), testSuiteRunner, tests, 10, limit, 1)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Minute):
+		t.Fatalf("tests did not complete, started %v", testSuiteRunner.started)
+	}
+
+	if len(testSuiteRunner.started) != len(tests) {
+		t.Fatalf("expected %d tests, got %d", len(tests), len(testSuiteRunner.started))
+	}
+	for _, test := range tests {
+		memory, running := testSuiteRunner.memoryOnStart[test.name], testSuiteRunner.runningOnStart[test.name]
+		if test.memory > limit {
+			if running != 1 {
+				t.Errorf("expected %q, which is larger than the limit, to run alone, %d tests were running", test.name, running)
+			}
+			continue
+		}
+		if memory > limit {
+			t.Errorf("memory limit exceeded when %q started: %d tests declaring %d", test.name, running, memory)
+		}
+	}
+}
+
+func Test_executeLongestFirst(t *testing.T) {
+	tests := []*testCase{
+		{name: "short", expectedDuration: time.Minute},
+		{name: "unknown"},
+		{name: "long", expectedDuration: time.Hour},
+		{name: "medium", expectedDuration: 10 * time.Minute},
+	}
+
+	testSuiteRunner := &testingSuiteRunner{}
+	execute(context.TODO(), testSuiteRunner, tests, 1, 0, 1)
+
+	expected := []string{"long", "medium", "short", "unknown"}
+	if !reflect.DeepEqual(expected, testSuiteRunner.started) {
+		t.Fatalf("expected %v, got %v", expected, testSuiteRunner.started)
+	}
+}
+
+func Test_executeCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tests := []*testCase{
+		{name: "a", isolation: extensions.Isolation{Conflict: []string{"etcd"}}},
+		{name: "b", isolation: extensions.Isolation{Conflict: []string{"etcd"}}},
+		{name: "c", isolation: extensions.Isolation{Conflict: []string{"etcd"}}},
+	}
+
+	testSuiteRunner := &cancellingSuiteRunner{cancel: cancel}
+	execute(ctx, testSuiteRunner, tests, 3, 0, 1)
+
+	if testSuiteRunner.count != 1 {
+		t.Fatalf("expected waiting tests to be abandoned once cancelled, %d ran", testSuiteRunner.count)
+	}
+}
+
+func Test_executeBatches(t *testing.T) {
+	binaryA := &extensions.TestBinary{}
+	binaryB := &extensions.TestBinary{}
+	tests := []*testCase{
+		{name: "isolated", binary: binaryA, isolation: extensions.Isolation{Conflict: []string{"etcd"}}},
+		{name: "retry", binary: binaryA, previous: &testCase{name: "retry"}},
+		{name: "origin"},
+	}
+	for i := 0; i < 7; i++ {
+		tests = append(tests,
+			&testCase{name: fmt.Sprintf("a-%d", i), binary: binaryA},
+			&testCase{name: fmt.Sprintf("b-%d", i), binary: binaryB},
+		)
+	}
+
+	testSuiteRunner := &testingSuiteRunner{}
+	execute(context.TODO(), testSuiteRunner, tests, 2, 0, 3)
+
+	if len(testSuiteRunner.getTestsRun()) != len(tests) {
+		t.Fatalf("expected %d tests, got %d", len(tests), len(testSuiteRunner.getTestsRun()))
+	}
+	batched := 0
+	for _, batch := range testSuiteRunner.batches {
+		if len(batch) > 3 {
+			t.Errorf("batch larger than 3: %v", testNames(batch))
+		}
+		for _, test := range batch {
+			if test.binary != batch[0].binary {
+				t.Errorf("batch mixes binaries: %v", testNames(batch))
+			}
+			if !isBatchable(test) {
+				t.Errorf("%q must not be batched", test.name)
+			}
+		}
+		batched += len(batch)
+	}
+	// the batched tests are all but the isolated, retried, and origin tests, and possibly the last of each binary
+	if batched < 12 {
+		t.Errorf("expected the extension tests to be batched, only %d were", batched)
+	}
+}
+
+type cancellingSuiteRunner struct {
+	cancel context.CancelFunc
+	count  int
+}
+
+func (r *cancellingSuiteRunner) RunOneTest(ctx context.Context, test *testCase) {
+	// tests conflict, so only one runs at a time
+	r.count++
+	r.cancel()
+}
+
+func (r *cancellingSuiteRunner) RunTestBatch(ctx context.Context, tests []*testCase) {
+	for _, test := range tests {
+		r.RunOneTest(ctx, test)
+	}
+}
@@ -0,0 +1,317 @@
+package ginkgo
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/origin/pkg/test/extensions"
+	"github.com/openshift/origin/pkg/test/ginkgo/junitapi"
+)
+
+const (
+	// testDurationsFilename is the duration database written by merge-results.
+	testDurationsFilename = "test-durations.json"
+
+	// defaultShardTestDuration is assumed for tests with neither a recorded nor a declared duration.
+	defaultShardTestDuration = time.Minute
+)
+
+// Shard selects one of Count disjoint parts of a suite.  Index is 1-based.
+type Shard struct {
+	Index int
+	Count int
+}
+
+// ParseShard parses a shard in the form i/N.
+func ParseShard(value string) (Shard, error) {
+	index, count, ok := strings.Cut(value, "/")
+	if !ok {
+		return Shard{}, fmt.Errorf("invalid shard %q, expected i/N", value)
+	}
+	var shard Shard
+	var err error
+	if shard.Index, err = strconv.Atoi(index); err != nil {
+		return Shard{}, fmt.Errorf("invalid shard %q, expected i/N", value)
+	}
+	if shard.Count, err = strconv.Atoi(count); err != nil {
+		return Shard{}, fmt.Errorf("invalid shard %q, expected i/N", value)
+	}
+	if shard.Count < 1 || shard.Index < 1 || shard.Index > shard.Count {
+		return Shard{}, fmt.Errorf("invalid shard %q, expected 1 <= i <= N", value)
+	}
+	return shard, nil
+}
+
+func (s Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// TestDuration is the recorded duration of a test, averaged over Runs.
+type TestDuration struct {
+	Runs    int     `json:"runs"`
+	Seconds float64 `json:"seconds"`
+}
+
+// TestDurations is a database of recorded test durations keyed by test name.
+type TestDurations map[string]*TestDuration
+
+func (d TestDurations) add(name string, duration time.Duration) {
+	if len(name) == 0 || duration <= 0 {
+		return
+	}
+	recorded, ok := d[name]
+	if !ok {
+		recorded = &TestDuration{}
+		d[name] = recorded
+	}
+	recorded.Seconds = (recorded.Seconds*float64(recorded.Runs) + duration.Seconds()) / float64(recorded.Runs+1)
+	recorded.Runs++
+}
+
+func (d TestDurations) merge(other TestDurations) {
+	for name, o := range other {
+		recorded, ok := d[name]
+		if !ok {
+			d[name] = &TestDuration{Runs: o.Runs, Seconds: o.Seconds}
+			continue
+		}
+		runs := recorded.Runs + o.Runs
+		if runs == 0 {
+			continue
+		}
+		recorded.Seconds = (recorded.Seconds*float64(recorded.Runs) + o.Seconds*float64(o.Runs)) / float64(runs)
+		recorded.Runs = runs
+	}
+}
+
+// Duration returns the recorded duration of a test, if any.
+func (d TestDurations) Duration(name string) (time.Duration, bool) {
+	recorded, ok := d[name]
+	if !ok || recorded.Runs == 0 {
+		return 0, false
+	}
+	return time.Duration(recorded.Seconds * float64(time.Second)), true
+}
+
+// countRecorded returns how many of tests have a recorded duration.
+func (d TestDurations) countRecorded(tests []*testCase) int {
+	count := 0
+	for _, test := range tests {
+		if _, ok := d.Duration(test.name); ok {
+			count++
+		}
+	}
+	return count
+}
+
+// LoadTestDurations builds a duration database from the results of prior runs.  Each path is either a
+// directory, which is searched for extension_test_result_e2e_*.json and junit_e2e_*.xml results and for
+// test-durations.json databases, or a database file.  Skipped tests do not count.
+func LoadTestDurations(paths ...string) (TestDurations, error) {
+	durations := TestDurations{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not load test durations: %w", err)
+		}
+		if !info.IsDir() {
+			if err := durations.loadDatabase(path); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		err = filepath.WalkDir(path, func(filename string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			name := entry.Name()
+			switch {
+			case name == testDurationsFilename:
+				return durations.loadDatabase(filename)
+			case strings.HasPrefix(name, "extension_test_result_e2e_") && strings.HasSuffix(name, ".json"):
+				return durations.loadExtensionTestResults(filename)
+			case strings.HasPrefix(name, "junit_e2e_") && strings.HasSuffix(name, ".xml"):
+				return durations.loadJUnit(filename)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not load test durations from %s: %w", path, err)
+		}
+	}
+	return durations, nil
+}
+
+func (d TestDurations) loadDatabase(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	database := TestDurations{}
+	if err := json.Unmarshal(data, &database); err != nil {
+		return fmt.Errorf("invalid test durations %s: %w", filename, err)
+	}
+	d.merge(database)
+	return nil
+}
+
+func (d TestDurations) loadExtensionTestResults(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	var results extensions.ExtensionTestResults
+	if err := json.Unmarshal(data, &results); err != nil {
+		return fmt.Errorf("invalid extension test results %s: %w", filename, err)
+	}
+	for _, result := range results {
+		if result == nil || result.Result == extensions.ResultSkipped {
+			continue
+		}
+		if result.StartTime != nil && result.EndTime != nil {
+			d.add(result.Name, extensions.Time(result.EndTime).Sub(extensions.Time(result.StartTime)))
+			continue
+		}
+		// extensions report the duration in milliseconds
+		d.add(result.Name, time.Duration(result.Duration)*time.Millisecond)
+	}
+	return nil
+}
+
+func (d TestDurations) loadJUnit(filename string) error {
+	suite, err := readJUnitReport(filename)
+	if err != nil {
+		return err
+	}
+	for _, test := range suite.TestCases {
+		if test.SkipMessage != nil {
+			continue
+		}
+		d.add(test.Name, time.Duration(test.Duration*float64(time.Second)))
+	}
+	return nil
+}
+
+// writeTestDurations writes the duration database to dir.
+func writeTestDurations(durations TestDurations, dir string) error {
+	data, err := json.MarshalIndent(durations, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, testDurationsFilename), data, 0644)
+}
+
+func readJUnitReport(filename string) (*junitapi.JUnitTestSuite, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	suite := &junitapi.JUnitTestSuite{}
+	if err := xml.Unmarshal(data, suite); err != nil {
+		return nil, fmt.Errorf("invalid junit %s: %w", filename, err)
+	}
+	return suite, nil
+}
+
+// shardDuration is the duration a test is expected to take for balancing shards: its recorded duration,
+// its declared duration, or a default.
+func shardDuration(test *testCase, durations TestDurations) time.Duration {
+	if duration, ok := durations.Duration(test.name); ok {
+		return duration
+	}
+	if test.expectedDuration > 0 {
+		return test.expectedDuration
+	}
+	return defaultShardTestDuration
+}
+
+// pinnedToFirstShard reports whether test must run in the first shard.  Shards run at the same time against
+// the same cluster, so serial tests only keep the cluster to themselves, and early and late tests only run
+// before and after everything else, when all of them are in a single shard.
+func pinnedToFirstShard(test *testCase) bool {
+	return isSerialTest(test) || strings.Contains(test.name, "[Early]") || strings.Contains(test.name, "[Late]")
+}
+
+// shardTests returns the tests that belong to shard, in their original order.  Serial, early and late tests
+// all go to the first shard.  The other tests are assigned longest first to the shard with the least total
+// duration so far, counting the pinned tests, so shards take about as long as each other.  The assignment
+// depends only on the names and durations of the tests, so every shard computes the same split as long as
+// all of them are given the same tests and durations.
+func shardTests(tests []*testCase, shard Shard, durations TestDurations) []*testCase {
+	if shard.Count <= 1 {
+		return tests
+	}
+
+	type weighted struct {
+		test     *testCase
+		duration time.Duration
+	}
+	totals := make([]time.Duration, shard.Count)
+	selected := map[*testCase]bool{}
+	sorted := make([]weighted, 0, len(tests))
+	for _, test := range tests {
+		duration := shardDuration(test, durations)
+		if pinnedToFirstShard(test) {
+			totals[0] += duration
+			if shard.Index == 1 {
+				selected[test] = true
+			}
+			continue
+		}
+		sorted = append(sorted, weighted{test: test, duration: duration})
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].duration != sorted[j].duration {
+			return sorted[i].duration > sorted[j].duration
+		}
+		return sorted[i].test.name < sorted[j].test.name
+	})
+
+	for _, w := range sorted {
+		lightest := 0
+		for i := range totals {
+			if totals[i] < totals[lightest] {
+				lightest = i
+			}
+		}
+		totals[lightest] += w.duration
+		if lightest == shard.Index-1 {
+			selected[w.test] = true
+		}
+	}
+	logrus.Infof("Shard %s is expected to take %s, shards range from %s to %s",
+		shard, totals[shard.Index-1].Round(time.Second), slices.Min(totals).Round(time.Second), slices.Max(totals).Round(time.Second))
+
+	var shardTests []*testCase
+	for _, test := range tests {
+		if selected[test] {
+			shardTests = append(shardTests, test)
+		}
+	}
+	return shardTests
+}
+
+// writeEmptyShardResults writes the results of a shard that has no tests to run, so that merging the results
+// of all shards finds one for every shard.
+func writeEmptyShardResults(junitSuiteName, dir string, errOut io.Writer) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("could not create --junit-dir: %w", err)
+	}
+	timeSuffix := fmt.Sprintf("_%s", time.Now().UTC().Format("20060102-150405"))
+	if err := writeJUnitReport(generateJUnitTestSuiteResults(junitSuiteName, 0, nil), "junit_e2e", timeSuffix, dir, errOut); err != nil {
+		return err
+	}
+	return writeExtensionTestResults(nil, dir, "extension_test_result_e2e", timeSuffix, errOut)
+}
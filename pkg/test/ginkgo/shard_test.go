@@ -0,0 +1,203 @@
+package ginkgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/test/extensions"
+)
+
+func TestParseShard(t *testing.T) {
+	for _, value := range []string{"", "1", "0/3", "4/3", "a/3", "1/b", "1/0"} {
+		if _, err := ParseShard(value); err == nil {
+			t.Errorf("expected %q to be invalid", value)
+		}
+	}
+	shard, err := ParseShard("2/3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shard != (Shard{Index: 2, Count: 3}) {
+		t.Errorf("unexpected shard %v", shard)
+	}
+}
+
+func TestShardTests(t *testing.T) {
+	durations := TestDurations{
+		"recorded long":  {Runs: 2, Seconds: 600},
+		"recorded short": {Runs: 1, Seconds: 10},
+	}
+	newTests := func() []*testCase {
+		tests := []*testCase{
+			{name: "recorded long"},
+			{name: "recorded short"},
+			{name: "declared", expectedDuration: 5 * time.Minute},
+		}
+		for i := 0; i < 10; i++ {
+			tests = append(tests, &testCase{name: fmt.Sprintf("default %d", i)})
+		}
+		return tests
+	}
+
+	tests := newTests()
+	seen := map[string]int{}
+	var totals []time.Duration
+	for i := 1; i <= 3; i++ {
+		shard := shardTests(tests, Shard{Index: i, Count: 3}, durations)
+		var total time.Duration
+		for _, test := range shard {
+			seen[test.name]++
+			total += shardDuration(test, durations)
+		}
+		totals = append(totals, total)
+
+		// the same tests in another order are split the same way
+		reversed := newTests()
+		for l, r := 0, len(reversed)-1; l < r; l, r = l+1, r-1 {
+			reversed[l], reversed[r] = reversed[r], reversed[l]
+		}
+		again := shardTests(reversed, Shard{Index: i, Count: 3}, durations)
+		if !reflect.DeepEqual(testNames(sortedTests(shard)), testNames(sortedTests(again))) {
+			t.Errorf("shard %d is not deterministic: %v != %v", i, testNames(sortedTests(shard)), testNames(sortedTests(again)))
+		}
+	}
+	if len(seen) != len(tests) {
+		t.Errorf("expected every test to be in a shard, got %v", seen)
+	}
+	for name, count := range seen {
+		if count != 1 {
+			t.Errorf("expected %q to be in exactly one shard, got %d", name, count)
+		}
+	}
+	// longest first to the shard with the least so far: 600s alone, 300s + 3 * 60s, and 7 * 60s + 10s
+	if expected := []time.Duration{10 * time.Minute, 8 * time.Minute, 7*time.Minute + 10*time.Second}; !reflect.DeepEqual(expected, totals) {
+		t.Errorf("expected shards to take %v, got %v", expected, totals)
+	}
+
+	// shards keep the order of the tests they are given
+	shard := shardTests(tests, Shard{Index: 3, Count: 3}, durations)
+	for i := 1; i < len(shard); i++ {
+		if indexOf(tests, shard[i-1]) > indexOf(tests, shard[i]) {
+			t.Errorf("expected shard to keep the order of the tests, got %v", testNames(shard))
+		}
+	}
+	// only the recorded durations of the tests in the shard count
+	if count := durations.countRecorded(shard); count != 1 {
+		t.Errorf("expected one test of %v to have a recorded duration, got %d", testNames(shard), count)
+	}
+}
+
+func TestShardTestsPinsSerialEarlyAndLateTests(t *testing.T) {
+	pinned := []*testCase{
+		{name: "[Serial] serial"},
+		{name: "[Early] early"},
+		{name: "[Late] late"},
+	}
+	tests := append([]*testCase{}, pinned...)
+	for i := 0; i < 8; i++ {
+		tests = append(tests, &testCase{name: fmt.Sprintf("parallel %d", i)})
+	}
+
+	first := shardTests(tests, Shard{Index: 1, Count: 4}, TestDurations{})
+	for _, test := range pinned {
+		if indexOf(first, test) < 0 {
+			t.Errorf("expected %q to be in the first shard, got %v", test.name, testNames(first))
+		}
+	}
+	// the pinned tests count towards the first shard, so the parallel tests go to the others
+	if len(first) != len(pinned) {
+		t.Errorf("expected only the pinned tests in the first shard, got %v", testNames(first))
+	}
+	for i := 2; i <= 4; i++ {
+		for _, test := range shardTests(tests, Shard{Index: i, Count: 4}, TestDurations{}) {
+			if pinnedToFirstShard(test) {
+				t.Errorf("expected %q not to be in shard %d", test.name, i)
+			}
+		}
+	}
+}
+
+func TestWriteEmptyShardResults(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "junit")
+	if err := writeEmptyShardResults("suite", dir, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	junits, err := filepath.Glob(filepath.Join(dir, "junit_e2e_*.xml"))
+	if err != nil || len(junits) != 1 {
+		t.Fatalf("expected one junit, got %v %v", junits, err)
+	}
+	suite, err := readJUnitReport(junits[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suite.Name != "suite" || suite.NumTests != 0 {
+		t.Errorf("expected an empty suite, got %s with %d tests", suite.Name, suite.NumTests)
+	}
+	// an empty shard does not break loading the durations of the shards
+	if _, err := LoadTestDurations(dir); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoadTestDurations(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	results := extensions.ExtensionTestResults{
+		{Name: "timed", Result: extensions.ResultPassed, StartTime: extensions.TimePtr(start), EndTime: extensions.TimePtr(start.Add(40 * time.Second))},
+		{Name: "milliseconds", Result: extensions.ResultFailed, Duration: 20000},
+		{Name: "skipped", Result: extensions.ResultSkipped, Duration: 20000},
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "extension_test_result_e2e__20240101-000000.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	junit := `<testsuite name="suite"><testcase name="timed" time="20"></testcase><testcase name="junit" time="5"></testcase></testsuite>`
+	if err := os.WriteFile(filepath.Join(dir, "junit_e2e__20240101-000000.xml"), []byte(junit), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	durations, err := LoadTestDurations(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]time.Duration{"timed": 30 * time.Second, "milliseconds": 20 * time.Second, "junit": 5 * time.Second}
+	for name, duration := range expected {
+		if actual, ok := durations.Duration(name); !ok || actual != duration {
+			t.Errorf("expected %q to take %s, got %s", name, duration, actual)
+		}
+	}
+	if _, ok := durations.Duration("skipped"); ok {
+		t.Errorf("expected skipped tests not to be recorded")
+	}
+
+	// a database written by merge-results loads the same
+	output := t.TempDir()
+	if err := writeTestDurations(durations, output); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadTestDurations(filepath.Join(output, testDurationsFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(durations, reloaded) {
+		t.Errorf("expected %v, got %v", durations, reloaded)
+	}
+}
+
+func indexOf(tests []*testCase, test *testCase) int {
+	for i := range tests {
+		if tests[i] == test {
+			return i
+		}
+	}
+	return -1
+}
@@ -23,6 +23,8 @@ import (
 
 type testSuiteRunner interface {
 	RunOneTest(ctx context.Context, test *testCase)
+	// RunTestBatch runs tests that share an extension binary in a single process.
+	RunTestBatch(ctx context.Context, tests []*testCase)
 }
 
 // testRunner contains all the content required to run a test.  It must be threadsafe and must be re-useable
@@ -36,30 +38,46 @@ type testSuiteRunnerImpl struct {
 
 // RunOneTest runs a test, mutates the testCase with result, and reports the result
 func (r *testSuiteRunnerImpl) RunOneTest(ctx context.Context, test *testCase) {
-	// this construct is a little odd, but the defer statements that ensure we run at the end have the variables
-	// assigned/resolved at the time defer statement is created, which means that pointer must remain consistent.
-	// however, we can change the values of the content, so we assign the content lower down to have a cleaner set of
-	// the many defers in this function.
-	// remember that defers are last-added, first-executed.
-	testRunResult := &testRunResultHandle{}
+	finishTest := r.startTest(ctx, test)
+	finishTest(r.commandContext.RunTestInNewProcess(ctx, test))
+}
 
-	// if we need to abort, then abort
-	defer r.maybeAbortOnFailureFn(testRunResult)
+// RunTestBatch runs tests in one process, and mutates and reports each testCase as its result arrives.
+func (r *testSuiteRunnerImpl) RunTestBatch(ctx context.Context, tests []*testCase) {
+	finishTests := map[*testCase]func(*testRunResult){}
+	for _, test := range tests {
+		finishTests[test] = r.startTest(ctx, test)
+	}
+	r.commandContext.RunTestBatchInNewProcess(ctx, tests, func(test *testCase, result *testRunResult) {
+		finishTests[test](result)
+	})
+}
 
+// startTest reports the start of test and returns the func to report its result with.
+func (r *testSuiteRunnerImpl) startTest(ctx context.Context, test *testCase) func(*testRunResult) {
 	// record the test happening with the monitor
 	r.testOutput.monitorRecorder.AddIntervals(monitorapi.NewInterval(monitorapi.SourceE2ETest, monitorapi.Info).
 		Locator(monitorapi.NewLocator().E2ETest(test.name)).
 		Message(monitorapi.NewMessage().HumanMessage("started").Reason(monitorapi.E2ETestStarted)).BuildNow())
 
-	defer recordTestResultInMonitor(testRunResult, r.testOutput.monitorRecorder)
-
 	// log the results to systemout
 	r.testSuiteProgress.LogTestStart(r.testOutput.out, test.name)
-	defer r.testSuiteProgress.TestEnded(test.name, testRunResult)
-	defer recordTestResultInLogWithoutOverlap(testRunResult, r.testOutput.testOutputLock, r.testOutput.out, r.testOutput.includeSuccessfulOutput)
 
-	testRunResult.testRunResult = r.commandContext.RunTestInNewProcess(ctx, test)
-	mutateTestCaseWithResults(test, testRunResult)
+	return func(result *testRunResult) {
+		testRunResult := &testRunResultHandle{testRunResult: result}
+		mutateTestCaseWithResults(test, testRunResult)
+		recordTestResultInLogWithoutOverlap(testRunResult, r.testOutput.testOutputLock, r.testOutput.out, r.testOutput.includeSuccessfulOutput)
+		r.testSuiteProgress.TestEnded(test.name, testRunResult)
+		recordTestResultInMonitor(testRunResult, r.testOutput.monitorRecorder)
+		// tests cut short by cancellation did not complete, and retries are run again on resume anyway
+		if ctx.Err() == nil && test.previous == nil {
+			r.testOutput.checkpoint.recordTest(test)
+		}
+		// if we need to abort, then abort.  Informing tests never fail the run, so they do not abort it either.
+		if !isInformingTest(test) {
+			r.maybeAbortOnFailureFn(testRunResult)
+		}
+	}
 }
 
 func mutateTestCaseWithResults(test *testCase, testRunResult *testRunResultHandle) {
@@ -130,6 +148,8 @@ type testOutputConfig struct {
 	testOutputLock  *sync.Mutex
 	out             io.Writer
 	monitorRecorder monitorapi.Recorder
+	// checkpoint, if set, records completed tests so the run can be resumed
+	checkpoint *checkpointWriter
 
 	includeSuccessfulOutput bool
 }
@@ -179,11 +199,12 @@ func isTestFailed(testState TestState) bool {
 }
 
 // testOutputLock prevents parallel tests from interleaving their output.
-func newTestOutputConfig(testOutputLock *sync.Mutex, out io.Writer, monitorRecorder monitorapi.Recorder, includeSuccessfulOutput bool) testOutputConfig {
+func newTestOutputConfig(testOutputLock *sync.Mutex, out io.Writer, monitorRecorder monitorapi.Recorder, checkpoint *checkpointWriter, includeSuccessfulOutput bool) testOutputConfig {
 	return testOutputConfig{
 		testOutputLock:          testOutputLock,
 		out:                     out,
 		monitorRecorder:         monitorRecorder,
+		checkpoint:              checkpoint,
 		includeSuccessfulOutput: includeSuccessfulOutput,
 	}
 }
@@ -307,23 +328,11 @@ func (c *commandContext) RunTestInNewProcess(ctx context.Context, test *testCase
 	testEnv := append(os.Environ(), updateEnvVars(c.env)...)
 
 	if test.binary != nil {
-		results := test.binary.RunTests(ctx, c.timeout, testEnv, test.name)
+		results := test.binary.RunTests(ctx, c.testTimeout(test), testEnv, test.name)
 		if len(results) != 1 {
 			fmt.Fprintf(os.Stderr, "warning: expected 1 result from external binary; received %d", len(results))
 		}
-		switch results[0].Result {
-		case extensions.ResultFailed:
-			ret.testState = TestFailed
-			ret.testOutputBytes = []byte(fmt.Sprintf("%s\n%s", results[0].Output, results[0].Error))
-		case extensions.ResultPassed:
-			ret.testState = TestSucceeded
-		case extensions.ResultSkipped:
-			ret.testState = TestSkipped
-		}
-		ret.start = extensions.Time(results[0].StartTime)
-		ret.end = extensions.Time(results[0].EndTime)
-		ret.extensionTestResult = results[0]
-		return ret
+		return extensionTestRunResult(test, results[0])
 	}
 
 	testName := test.rawName
@@ -334,12 +343,7 @@ func (c *commandContext) RunTestInNewProcess(ctx context.Context, test *testCase
 	command := exec.Command(os.Args[0], "run-test", testName)
 	command.Env = testEnv
 
-	timeout := c.timeout
-	if test.testTimeout != 0 {
-		timeout = test.testTimeout
-	}
-
-	testOutputBytes, err := runWithTimeout(ctx, command, timeout)
+	testOutputBytes, err := runWithTimeout(ctx, command, c.testTimeout(test))
 	ret.end = time.Now()
 
 	ret.testOutputBytes = testOutputBytes
@@ -377,6 +381,69 @@ func (c *commandContext) RunTestInNewProcess(ctx context.Context, test *testCase
 	return ret
 }
 
+// RunTestBatchInNewProcess runs tests from the same extension binary in a single process, and calls resultFn
+// with each test and its result as the result arrives.
+func (c *commandContext) RunTestBatchInNewProcess(ctx context.Context, tests []*testCase, resultFn func(test *testCase, result *testRunResult)) {
+	testsByName := map[string]*testCase{}
+	var names []string
+	var timeout time.Duration
+	for _, test := range tests {
+		// if the test was already marked as skipped, skip it.
+		if test.skipped {
+			resultFn(test, &testRunResult{name: test.name, testState: TestSkipped})
+			continue
+		}
+		testsByName[test.name] = test
+		names = append(names, test.name)
+		// the batch gets as long as its tests would have had when run one at a time
+		timeout += c.testTimeout(test)
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	testEnv := append(os.Environ(), updateEnvVars(c.env)...)
+	tests[0].binary.StreamTests(ctx, timeout, testEnv, names, func(result *extensions.ExtensionTestResult) {
+		// results for tests that were not requested are not attributed to any test.
+		test, ok := testsByName[result.Name]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "warning: unexpected result from external binary for %q: %s\n", result.Name, result.Error)
+			return
+		}
+		delete(testsByName, result.Name)
+		resultFn(test, extensionTestRunResult(test, result))
+	})
+}
+
+// extensionTestRunResult converts the result reported by an extension binary.
+func extensionTestRunResult(test *testCase, result *extensions.ExtensionTestResult) *testRunResult {
+	ret := &testRunResult{
+		name:                test.name,
+		testState:           TestUnknown,
+		start:               extensions.Time(result.StartTime),
+		end:                 extensions.Time(result.EndTime),
+		extensionTestResult: result,
+	}
+	switch result.Result {
+	case extensions.ResultFailed:
+		ret.testState = TestFailed
+		ret.testOutputBytes = []byte(fmt.Sprintf("%s\n%s", result.Output, result.Error))
+	case extensions.ResultPassed:
+		ret.testState = TestSucceeded
+	case extensions.ResultSkipped:
+		ret.testState = TestSkipped
+	}
+	return ret
+}
+
+// testTimeout returns the timeout of test, which may override the suite timeout.
+func (c *commandContext) testTimeout(test *testCase) time.Duration {
+	if test.testTimeout != 0 {
+		return test.testTimeout
+	}
+	return c.timeout
+}
+
 func updateEnvVars(envs []string) []string {
 	result := []string{}
 	for _, env := range envs {
@@ -0,0 +1,63 @@
+package ginkgo
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/monitor"
+	"github.com/openshift/origin/pkg/test/extensions"
+)
+
+func TestFailFastIgnoresInformingTests(t *testing.T) {
+	tests := []struct {
+		name      string
+		test      *testCase
+		wantAbort bool
+	}{
+		{
+			name:      "blocking failure aborts",
+			test:      &testCase{name: "[sig-a] blocking"},
+			wantAbort: true,
+		},
+		{
+			name: "informing lifecycle failure does not abort",
+			test: &testCase{name: "[sig-a] informing", lifecycle: extensions.LifecycleInforming},
+		},
+		{
+			name: "informing label failure does not abort",
+			test: &testCase{name: "[sig-a] informing [Lifecycle:informing]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			abortFn, ctx := abortOnFailure(context.Background())
+			runner := &testSuiteRunnerImpl{
+				testOutput: testOutputConfig{
+					testOutputLock:  &sync.Mutex{},
+					out:             io.Discard,
+					monitorRecorder: monitor.NewRecorder(),
+				},
+				testSuiteProgress:     newTestSuiteProgress(1),
+				maybeAbortOnFailureFn: abortFn,
+			}
+
+			finishTest := runner.startTest(ctx, tt.test)
+			finishTest(&testRunResult{
+				name:      tt.test.name,
+				start:     time.Now(),
+				end:       time.Now(),
+				testState: TestFailed,
+			})
+
+			if !tt.test.failed {
+				t.Errorf("expected the test to be marked failed")
+			}
+			if aborted := ctx.Err() != nil; aborted != tt.wantAbort {
+				t.Errorf("expected aborted=%v, got %v", tt.wantAbort, aborted)
+			}
+		})
+	}
+}
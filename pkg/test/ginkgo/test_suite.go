@@ -1,13 +1,18 @@
 package ginkgo
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/ginkgo/v2/types"
+	"github.com/sirupsen/logrus"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	k8sgenerated "k8s.io/kubernetes/openshift-hack/e2e/annotate/generated"
 
@@ -52,11 +57,30 @@ var re = regexp.MustCompile(`.*\[Timeout:(.[^\]]*)\]`)
 func externalBinaryTestsToOriginTestCases(specs extensions.ExtensionTestSpecs) []*testCase {
 	var tests []*testCase
 	for _, spec := range specs {
-		tests = append(tests, &testCase{
-			name:    spec.Name,
-			rawName: spec.Name,
-			binary:  spec.Binary,
-		})
+		tc := &testCase{
+			name:      spec.Name,
+			rawName:   spec.Name,
+			binary:    spec.Binary,
+			isolation: spec.Resources.Isolation,
+			lifecycle: spec.Lifecycle,
+
+			extensionTestSpec: spec,
+		}
+		if len(spec.Resources.Memory) > 0 {
+			if memory, err := resource.ParseQuantity(spec.Resources.Memory); err != nil {
+				logrus.WithError(err).Warningf("ignoring invalid memory %q for test %q", spec.Resources.Memory, spec.Name)
+			} else {
+				tc.memory = memory.Value()
+			}
+		}
+		if len(spec.Resources.Duration) > 0 {
+			if duration, err := time.ParseDuration(spec.Resources.Duration); err != nil {
+				logrus.WithError(err).Warningf("ignoring invalid duration %q for test %q", spec.Resources.Duration, spec.Name)
+			} else {
+				tc.expectedDuration = duration
+			}
+		}
+		tests = append(tests, tc)
 	}
 	return tests
 }
@@ -76,10 +100,22 @@ func newTestCaseFromGinkgoSpec(spec types.TestSpec) (*testCase, error) {
 		}
 		tc.testTimeout = testTimeOut
 	}
+	if strings.Contains(name, informingLabel) {
+		tc.lifecycle = extensions.LifecycleInforming
+	}
 
 	return tc, nil
 }
 
+// informingLabel marks origin tests whose failures are reported but do not fail the job, the same as
+// extension tests with an informing lifecycle.
+const informingLabel = "[Lifecycle:informing]"
+
+// isInformingTest returns true if the failure of the test must not fail the job.
+func isInformingTest(test *testCase) bool {
+	return test.lifecycle == extensions.LifecycleInforming || strings.Contains(test.name, informingLabel)
+}
+
 type testCase struct {
 	// name is the fully labeled test name as reported by openshift-tests
 	// this is being used for placing tests in buckets, as well as filtering
@@ -92,12 +128,20 @@ type testCase struct {
 	binaryName string
 	// binary is the reference when using an external binary
 	binary *extensions.TestBinary
+	// extensionTestSpec is the spec listed by the external binary, used to evaluate suite qualifiers
+	extensionTestSpec *extensions.ExtensionTestSpec
 
 	spec      types.TestSpec
 	locations []types.CodeLocation
 
 	// identifies which tests can be run in parallel (ginkgo runs suites linearly)
 	testExclusion string
+	// lifecycle is informing for tests whose failures must not fail the job
+	lifecycle extensions.Lifecycle
+	// isolation, memory, and expectedDuration are declared by extension tests and used for scheduling
+	isolation        extensions.Isolation
+	memory           int64
+	expectedDuration time.Duration
 	// specific timeout for the current test. When set, it overrides the current
 	// suite timeout
 	testTimeout time.Duration
@@ -106,6 +150,8 @@ type testCase struct {
 	end             time.Time
 	duration        time.Duration
 	testOutputBytes []byte
+	// failureAnnotation is added to the junit failure of the test, the cluster events concurrent with it for instance.
+	failureAnnotation string
 
 	flake               bool
 	failed              bool
@@ -123,9 +169,15 @@ func (t *testCase) Retry() *testCase {
 		spec:          t.spec,
 		rawName:       t.rawName,
 		binaryName:    t.binaryName,
+		binary:        t.binary,
 		locations:     t.locations,
 		testExclusion: t.testExclusion,
 
+		lifecycle:        t.lifecycle,
+		isolation:        t.isolation,
+		memory:           t.memory,
+		expectedDuration: t.expectedDuration,
+
 		previous: t,
 	}
 	return copied
@@ -148,6 +200,9 @@ type TestSuite struct {
 	Description string
 
 	Matches TestMatchFunc
+	// Qualifiers are CEL expressions evaluated against the extension test spec of each test.  A test is
+	// part of the suite if Matches or any qualifier selects it.
+	Qualifiers []string
 
 	// The number of times to execute each test in this suite.
 	Count int
@@ -159,34 +214,111 @@ type TestSuite struct {
 	ClusterStabilityDuringTest ClusterStabilityDuringTest
 
 	TestTimeout time.Duration
+
+	// requiredMatches must all match a test selected by Matches or Qualifiers for it to be part of the suite.
+	requiredMatches []TestMatchFunc
 }
 
 type TestMatchFunc func(name string) bool
 
-func (s *TestSuite) Filter(tests []*testCase) []*testCase {
+// originTestSource is the source reported to qualifiers for tests built into openshift-tests.
+const originTestSource = "openshift:payload:origin"
+
+func (s *TestSuite) Filter(tests []*testCase) ([]*testCase, error) {
+	qualified := sets.New[*extensions.ExtensionTestSpec]()
+	specs := make(map[*testCase]*extensions.ExtensionTestSpec, len(tests))
+	if len(s.Qualifiers) > 0 {
+		allSpecs := make(extensions.ExtensionTestSpecs, 0, len(tests))
+		for _, test := range tests {
+			spec := test.extensionTestSpec
+			if spec == nil {
+				spec = &extensions.ExtensionTestSpec{
+					Name:   test.name,
+					Labels: sets.New[string](),
+					Source: originTestSource,
+				}
+			}
+			specs[test] = spec
+			allSpecs = append(allSpecs, spec)
+		}
+		qualifiedSpecs, err := allSpecs.Filter(s.Qualifiers)
+		if err != nil {
+			return nil, fmt.Errorf("suite %q: %w", s.Name, err)
+		}
+		qualified.Insert(qualifiedSpecs...)
+	}
+
 	matches := make([]*testCase, 0, len(tests))
 	for _, test := range tests {
-		if !s.Matches(test.name) {
+		selected := (s.Matches != nil && s.Matches(test.name)) || qualified.Has(specs[test])
+		if !selected || !s.matchesRequired(test.name) {
 			continue
 		}
 		matches = append(matches, test)
 	}
-	return matches
+	return matches, nil
+}
+
+func (s *TestSuite) matchesRequired(name string) bool {
+	for _, matchFn := range s.requiredMatches {
+		if !matchFn(name) {
+			return false
+		}
+	}
+	return true
 }
 
 func (s *TestSuite) AddRequiredMatchFunc(matchFn TestMatchFunc) {
 	if matchFn == nil {
 		return
 	}
-	if s.Matches == nil {
-		s.Matches = matchFn
-		return
+	s.requiredMatches = append(s.requiredMatches, matchFn)
+}
+
+// AddExtensionQualifiers adds the qualifiers advertised by extensions for the suite, including the qualifiers of
+// advertised suites that list it as a parent.
+func (s *TestSuite) AddExtensionQualifiers(infos []*extensions.ExtensionInfo) {
+	qualifiers, _ := extensions.SuiteQualifiers(s.Name, infos)
+	s.Qualifiers = append(s.Qualifiers, qualifiers...)
+}
+
+// MergeExtensionSuites returns copies of suites with the qualifiers advertised by extensions added, followed by the
+// advertised suites that are not built in. The suites passed in are left unchanged.
+func MergeExtensionSuites(suites []*TestSuite, infos []*extensions.ExtensionInfo) []*TestSuite {
+	known := sets.New[string]()
+	merged := make([]*TestSuite, 0, len(suites))
+	for _, suite := range suites {
+		known.Insert(suite.Name)
+		suite = suite.copy()
+		suite.AddExtensionQualifiers(infos)
+		merged = append(merged, suite)
 	}
 
-	originalMatchFn := s.Matches
-	s.Matches = func(name string) bool {
-		return originalMatchFn(name) && matchFn(name)
+	for _, advertised := range extensions.AdvertisedSuites(infos) {
+		if known.Has(advertised.Name) {
+			continue
+		}
+		qualifiers, _ := extensions.SuiteQualifiers(advertised.Name, infos)
+		description := "Suite advertised by an extension."
+		if len(advertised.Parents) > 0 {
+			description = fmt.Sprintf("Suite advertised by an extension, part of %s.", strings.Join(advertised.Parents, ", "))
+		}
+		merged = append(merged, &TestSuite{
+			Name:        advertised.Name,
+			Description: description,
+			Qualifiers:  qualifiers,
+		})
 	}
+	return merged
+}
+
+// copy returns a shallow copy of the suite whose qualifiers and required matches can be added to without changing
+// the original.
+func (s *TestSuite) copy() *TestSuite {
+	c := *s
+	c.Qualifiers = append([]string(nil), s.Qualifiers...)
+	c.requiredMatches = append([]TestMatchFunc(nil), s.requiredMatches...)
+	return &c
 }
 
 func testNames(tests []*testCase) []string {
@@ -0,0 +1,163 @@
+package ginkgo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/openshift/origin/pkg/test/extensions"
+)
+
+func TestMergeExtensionSuites(t *testing.T) {
+	infos := []*extensions.ExtensionInfo{
+		{
+			Suites: []extensions.Suite{
+				{
+					Name:       "openshift/network/conformance",
+					Parents:    []string{"openshift/conformance/parallel"},
+					Qualifiers: []string{`labels.exists(l, l == "network")`},
+				},
+				{
+					Name:       "openshift/network/conformance/ipv6",
+					Parents:    []string{"openshift/network/conformance"},
+					Qualifiers: []string{`name.contains("[IPv6]")`},
+				},
+			},
+		},
+		{
+			Suites: []extensions.Suite{
+				{
+					Name:       "openshift/conformance/parallel",
+					Qualifiers: []string{`tags["team"] == "storage"`},
+				},
+			},
+		},
+	}
+
+	parallel := &TestSuite{
+		Name:    "openshift/conformance/parallel",
+		Matches: func(name string) bool { return strings.Contains(name, "[Suite:openshift/conformance/parallel") },
+	}
+	serial := &TestSuite{
+		Name:    "openshift/conformance/serial",
+		Matches: func(name string) bool { return strings.Contains(name, "[Suite:openshift/conformance/serial") },
+	}
+
+	builtIn := []*TestSuite{parallel, serial}
+	suites := MergeExtensionSuites(builtIn, infos)
+
+	if len(parallel.Qualifiers) != 0 || len(serial.Qualifiers) != 0 {
+		t.Errorf("expected the built-in suites to be left unchanged, got %v and %v", parallel.Qualifiers, serial.Qualifiers)
+	}
+	if builtIn[0] != parallel || builtIn[1] != serial {
+		t.Errorf("expected the built-in suites to stay in place")
+	}
+	if len(suites[1].Qualifiers) != 0 {
+		t.Errorf("expected no qualifiers for a suite no extension advertises, got %v", suites[1].Qualifiers)
+	}
+	expectedParallel := []string{`labels.exists(l, l == "network")`, `name.contains("[IPv6]")`, `tags["team"] == "storage"`}
+	if !reflect.DeepEqual(sets.List(sets.New(suites[0].Qualifiers...)), sets.List(sets.New(expectedParallel...))) {
+		t.Errorf("expected %v, got %v", expectedParallel, suites[0].Qualifiers)
+	}
+	// merging the same suites again does not add the qualifiers twice
+	if again := MergeExtensionSuites(builtIn, infos); !reflect.DeepEqual(again[0].Qualifiers, suites[0].Qualifiers) {
+		t.Errorf("expected %v when merged again, got %v", suites[0].Qualifiers, again[0].Qualifiers)
+	}
+	if len(suites) != 4 || suites[2].Name != "openshift/network/conformance" || suites[3].Name != "openshift/network/conformance/ipv6" {
+		t.Fatalf("expected the advertised suites to follow the built-in ones, got %v", suites)
+	}
+	expectedNetwork := []string{`labels.exists(l, l == "network")`, `name.contains("[IPv6]")`}
+	if !reflect.DeepEqual(suites[2].Qualifiers, expectedNetwork) {
+		t.Errorf("expected %v, got %v", expectedNetwork, suites[2].Qualifiers)
+	}
+
+	tests := []*testCase{
+		{name: "origin [Suite:openshift/conformance/parallel]"},
+		{name: "origin serial [Suite:openshift/conformance/serial]"},
+		{name: "network", extensionTestSpec: &extensions.ExtensionTestSpec{Name: "network", Labels: sets.New("network")}},
+		{name: "network [IPv6]", extensionTestSpec: &extensions.ExtensionTestSpec{Name: "network [IPv6]"}},
+		{name: "storage", extensionTestSpec: &extensions.ExtensionTestSpec{Name: "storage", Tags: map[string]string{"team": "storage"}}},
+		{name: "other", extensionTestSpec: &extensions.ExtensionTestSpec{Name: "other"}},
+	}
+
+	filtered, err := suites[0].Filter(tests)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"origin [Suite:openshift/conformance/parallel]", "network", "network [IPv6]", "storage"}
+	if actual := testNames(filtered); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	// required match funcs apply to tests selected by qualifiers too
+	network := suites[2]
+	network.AddRequiredMatchFunc(func(name string) bool { return !strings.Contains(name, "IPv6") })
+	filtered, err = network.Filter(tests)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{"network"}
+	if actual := testNames(filtered); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestFilterInvalidQualifier(t *testing.T) {
+	suite := &TestSuite{Name: "invalid", Qualifiers: []string{`name ==`}}
+	if _, err := suite.Filter([]*testCase{{name: "test"}}); err == nil {
+		t.Fatal("expected an error for an invalid qualifier")
+	}
+}
+
+func TestIsInformingTest(t *testing.T) {
+	specs := extensions.ExtensionTestSpecs{
+		{Name: "informing extension test", Lifecycle: extensions.LifecycleInforming},
+		{Name: "blocking extension test", Lifecycle: extensions.LifecycleBlocking},
+	}
+	tests := append(externalBinaryTestsToOriginTestCases(specs),
+		&testCase{name: "origin test [Lifecycle:informing]"},
+		&testCase{name: "origin test"},
+	)
+
+	informing, blocking := splitTests(tests, isInformingTest)
+	if expected := []string{"informing extension test", "origin test [Lifecycle:informing]"}; !reflect.DeepEqual(expected, testNames(informing)) {
+		t.Errorf("expected informing %v, got %v", expected, testNames(informing))
+	}
+	if expected := []string{"blocking extension test", "origin test"}; !reflect.DeepEqual(expected, testNames(blocking)) {
+		t.Errorf("expected blocking %v, got %v", expected, testNames(blocking))
+	}
+	if retry := informing[0].Retry(); !isInformingTest(retry) {
+		t.Errorf("expected retries to keep the informing lifecycle")
+	}
+}
+
+func TestTestsToRetry(t *testing.T) {
+	newTests := func() []*testCase {
+		return []*testCase{
+			{name: "blocking pass", success: true},
+			{name: "blocking fail", failed: true},
+			{name: "informing fail", lifecycle: extensions.LifecycleInforming, failed: true},
+			{name: "informing label fail [Lifecycle:informing]", failed: true},
+		}
+	}
+
+	retries := testsToRetry(newTests(), 1)
+	if expected := []string{"blocking fail"}; !reflect.DeepEqual(expected, testNames(retries)) {
+		t.Errorf("expected retries %v, got %v", expected, testNames(retries))
+	}
+	if retries[0].failed || retries[0].previous == nil {
+		t.Errorf("expected a fresh retry of the failed test")
+	}
+
+	// informing failures do not count against the flakes allowed
+	tests := newTests()
+	tests[1].failed, tests[1].success = false, true
+	if retries := testsToRetry(tests, 1); len(retries) != 0 {
+		t.Errorf("expected no retries without blocking failures, got %v", testNames(retries))
+	}
+	if retries := testsToRetry(newTests(), 0); len(retries) != 0 {
+		t.Errorf("expected no retries when the suite allows no flakes, got %v", testNames(retries))
+	}
+}
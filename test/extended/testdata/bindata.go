@@ -53631,6 +53631,10 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
         return false
     }
 
+    function isDisruptionCause(eventInterval) {
+        return eventInterval.source === "DisruptionCause"
+    }
+
     function isNodeState(eventInterval) {
         return eventInterval.source === "NodeState"
     }
@@ -53758,6 +53762,11 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
         return [buildLocatorDisplayString(item.locator), "", "Disruption"]
     }
 
+    function disruptionCauseValue(item) {
+        // colored by the likely cause of the disruption interval it spans
+        return [buildLocatorDisplayString(item.locator), " (cause)", item.message.annotations.cause]
+    }
+
     function apiserverShutdownEventsValue(item) {
         // TODO: isolate DNS error into CIClusterDisruption
         return [buildLocatorDisplayString(item.locator), "", "GracefulShutdownWindow"]
@@ -53957,6 +53966,9 @@ var _e2echartE2eChartTemplateHtml = []byte(`<html lang="en">
         timelineGroups.push({group: "disruption", data: []})
         createTimelineData(disruptionValue, timelineGroups[timelineGroups.length - 1].data, eventIntervals, isEndpointConnectivity, regex)
 
+        timelineGroups.push({group: "disruption-cause", data: []})
+        createTimelineData(disruptionCauseValue, timelineGroups[timelineGroups.length - 1].data, eventIntervals, isDisruptionCause, regex)
+
         timelineGroups.push({group: "apiserver-shutdown", data: []})
         createTimelineData(apiserverShutdownValue, timelineGroups[timelineGroups.length - 1].data, eventIntervals, isGracefulShutdownActivity, regex)
 